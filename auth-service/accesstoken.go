@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+// accessTokenTTL is how long an access token lasts, and so how long a
+// rotated-out signing key must stay trusted.
+const accessTokenTTL = 24 * time.Hour
+
+// signAccessToken issues the JWT, signed with the active key. authTime is
+// when the user last proved their credentials; refreshes carry it over so
+// requireRecentAuth can tell a fresh login from a long-refreshed one.
+// claimsEnricher may add claims of its own.
+func (s *Server) signAccessToken(ctx context.Context, userID int, email string, authTime time.Time) (string, error) {
+	now := s.clock.Now()
+	claims := jwt.MapClaims{
+		"sub":       strconv.Itoa(userID),
+		"email":     email,
+		"iat":       now.Unix(),
+		"exp":       now.Add(accessTokenTTL).Unix(),
+		"auth_time": authTime.Unix(),
+	}
+	if err := s.enrichClaims(ctx, userID, claims); err != nil {
+		return "", err
+	}
+	return s.accessKeys.Current(ctx).Sign(claims)
+}
+
+// accessTokenKeyfunc picks the verification key from the token's kid.
+// Tokens from before signing keys were introduced have no kid and were
+// signed with JWT_SECRET using HS256; they're accepted until they expire,
+// but only with that method, so a kid-less token can't pass off an HMAC
+// over a public key as valid.
+func (s *Server) accessTokenKeyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(t *jwt.Token) (any, error) {
+		kid, hasKid := t.Header["kid"].(string)
+		if !hasKid {
+			if t.Method != jwt.SigningMethodHS256 {
+				return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+			}
+			return s.cfg.JWTSecret, nil
+		}
+		if t.Method != jwt.SigningMethodES256 {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		return s.accessKeys.Lookup(ctx, kid).Keyfunc(t)
+	}
+}
+
+func (s *Server) jwtMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Step 1: Read the auth token cookie
+		cookie, err := r.Cookie("auth_token")
+		if err != nil {
+			apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+			return
+		}
+
+		// Step 2: Parse and validate the token signature AND signing method
+		token, err := jwt.Parse(cookie.Value, s.accessTokenKeyfunc(r.Context()),
+			jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg(), jwt.SigningMethodHS256.Alg()}),
+			jwt.WithTimeFunc(s.clock.Now),
+		)
+
+		// If parsing failed or token is invalid/expired → reject
+		if err != nil || !token.Valid {
+			apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid or expired token"))
+			return
+		}
+
+		// Step 3: Safely extract claims from token
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid token claims"))
+			return
+		}
+
+		// Extract the user email (identity)
+		email, ok := claims["email"].(string)
+		if !ok {
+			apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid identity in token"))
+			return
+		}
+
+		// Tokens issued before the sub claim was added carry only the
+		// email; look the id up for those until they expire.
+		userID, err := strconv.Atoi(fmt.Sprint(claims["sub"]))
+		if err != nil {
+			var u User
+			u, err = s.users.GetByEmail(r.Context(), email)
+			userID = u.ID
+			if errors.Is(err, ErrNotFound) {
+				apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid identity in token"))
+				return
+			}
+			if err != nil {
+				dbErrorsTotal.WithLabelValues("jwt_user_id").Inc()
+				log.Printf("token user lookup error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+				writeDBError(w, r, err)
+				return
+			}
+		}
+
+		// Step 4: Store identity in request context (for handlers to use)
+		user := auth.User{ID: userID, Email: email}
+		if authTime, ok := claims["auth_time"].(float64); ok {
+			user.AuthTime = time.Unix(int64(authTime), 0)
+		}
+		ctxWithUser := auth.SetUser(r.Context(), user)
+		ctxWithUser = withUserBaggage(ctxWithUser, email)
+
+		// Pass request down the middleware chain
+		next.ServeHTTP(w, r.WithContext(ctxWithUser))
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FuzzAccessToken parses arbitrary auth_token cookie values the way
+// jwtMiddleware does. Only the tokens we signed may come out valid.
+func FuzzAccessToken(f *testing.F) {
+	s, _ := newTestServer(f)
+	s.cfg.JWTSecret = []byte("fuzz-legacy-secret")
+
+	ctx := context.Background()
+	valid, err := s.signAccessToken(ctx, 42, "a@example.com", time.Now())
+	if err != nil {
+		f.Fatal(err)
+	}
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"email": "a@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString(s.cfg.JWTSecret)
+	if err != nil {
+		f.Fatal(err)
+	}
+	// An HS256 token claiming a kid, as if signed with a public key used
+	// as an HMAC secret.
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"email": "a@example.com"})
+	confused.Header["kid"] = s.accessKeys.Current(ctx).ActiveID()
+	confusedStr, _ := confused.SignedString([]byte("public key bytes"))
+	// The same claims unsigned.
+	none, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"email": "a@example.com"}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	for _, seed := range []string{valid, legacy, confusedStr, none, "", ".", "..", "a.b.c", valid + "x", legacy[:len(legacy)-2]} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		token, err := jwt.Parse(raw, s.accessTokenKeyfunc(ctx),
+			jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg(), jwt.SigningMethodHS256.Alg()}),
+		)
+		if err != nil {
+			return
+		}
+		if !token.Valid {
+			t.Fatal("no error, but token not valid")
+		}
+		if raw != valid && raw != legacy {
+			t.Fatalf("accepted a token we didn't sign: %q", raw)
+		}
+	})
+}
+
+// An access token passes step-up only while its login is younger than
+// StepUpMaxAge, and authenticates at all only until accessTokenTTL.
+func TestAccessTokenLifetime(t *testing.T) {
+	s, _ := newTestServer(t)
+	clock := s.clock.(*fakeClock)
+	token, err := s.signAccessToken(context.Background(), 42, "a@example.com", clock.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(h http.Handler) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/me/export", nil)
+		r.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	sensitive := s.jwtMiddleware(s.requireRecentAuth(ok))
+	plain := s.jwtMiddleware(http.HandlerFunc(ok))
+
+	if rec := serve(sensitive); rec.Code != http.StatusNoContent {
+		t.Fatalf("just after login: %d %s", rec.Code, rec.Body)
+	}
+
+	clock.Advance(s.cfg.StepUpMaxAge + time.Second)
+	if env := readEnvelope(t, serve(sensitive), http.StatusUnauthorized); env.Error.Code != "reauthentication_required" {
+		t.Errorf("past StepUpMaxAge: code %q, want reauthentication_required", env.Error.Code)
+	}
+	if rec := serve(plain); rec.Code != http.StatusNoContent {
+		t.Errorf("past StepUpMaxAge, without step-up: %d", rec.Code)
+	}
+
+	clock.Advance(accessTokenTTL)
+	if env := readEnvelope(t, serve(plain), http.StatusUnauthorized); env.Error.Code != "token_invalid" {
+		t.Errorf("past accessTokenTTL: code %q, want token_invalid", env.Error.Code)
+	}
+}
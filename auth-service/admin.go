@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+	"resilient-auth-service/pagination"
+)
+
+// adminHandler wraps h in the middleware chain shared by every /admin route.
+func (s *Server) adminHandler(h http.HandlerFunc) http.Handler {
+	return recoverMiddleware(s.securityHeadersMiddleware(s.noStoreMiddleware(s.corsMiddleware(gzipMiddleware(
+		s.jwtMiddleware(s.rateLimitMiddleware(loggingMiddleware(s.requireAdmin(h)))),
+	)))))
+}
+
+// requireAdmin must run after jwtMiddleware. The role is read from the
+// database on every request so a demotion takes effect immediately.
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+			return
+		}
+
+		u, err := s.users.GetByEmail(r.Context(), user.Email)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.Println("admin role lookup error:", err)
+			writeDBError(w, r, err)
+			return
+		}
+		if u.Role != "admin" {
+			apperror.WriteError(w, r, apperror.Forbidden("forbidden", "Forbidden"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type adminUser struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAdminUser(u User) adminUser {
+	return adminUser{ID: u.ID, Email: u.Email, Role: u.Role, CreatedAt: u.CreatedAt}
+}
+
+// adminExportPageSize is how many users an export reads at a time.
+const adminExportPageSize = 500
+
+// adminUsersHandler serves GET /admin/users?limit=&cursor= using keyset
+// pagination on (created_at, id), which stays fast at any depth unlike OFFSET.
+func (s *Server) adminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := pagination.Limit(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	after, hasCursor, err := pagination.FromRequest[UserKey](r, s.cfg.CursorSecret)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	var from *UserKey
+	if hasCursor {
+		from = &after
+	}
+	format, err := exportFormatFromRequest(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	if format.name != "json" {
+		s.adminExportUsers(w, r, format, from)
+		return
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	page, err := s.users.List(r.Context(), from, limit+1)
+	if err != nil {
+		log.Println("admin list users error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	users := make([]adminUser, len(page))
+	for i, u := range page {
+		users[i] = newAdminUser(u)
+	}
+
+	writeJSON(w, http.StatusOK, pagination.NewList(users, limit, func(u adminUser) string {
+		return pagination.Encode(UserKey{CreatedAt: u.CreatedAt, ID: u.ID}, s.cfg.CursorSecret)
+	}))
+}
+
+// adminExportUsers serves GET /admin/users?format=csv|ndjson: every user
+// from the cursor on, in one download read a page at a time, so the full
+// table never has to fit in memory. limit doesn't apply.
+func (s *Server) adminExportUsers(w http.ResponseWriter, r *http.Request, format exportFormat, from *UserKey) {
+	ev := s.auditEventFromRequest(r, "admin.users_export")
+	ev.Metadata = map[string]any{"format": format.name}
+	s.auditor.Record(r.Context(), ev)
+
+	page, err := s.users.List(r.Context(), from, adminExportPageSize)
+	if err != nil {
+		log.Println("admin export users error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	format.setHeaders(w, "users-"+time.Now().UTC().Format("20060102"))
+
+	var write func(u adminUser) error
+	if format.name == "csv" {
+		cw := csv.NewWriter(w)
+		defer cw.Flush()
+		cw.Write([]string{"id", "email", "role", "created_at"})
+		write = func(u adminUser) error {
+			return cw.Write([]string{strconv.Itoa(u.ID), csvCell(u.Email), csvCell(u.Role),
+				u.CreatedAt.UTC().Format(time.RFC3339)})
+		}
+	} else {
+		enc := json.NewEncoder(w)
+		write = func(u adminUser) error { return enc.Encode(u) }
+	}
+
+	for len(page) > 0 {
+		for _, u := range page {
+			if err = write(newAdminUser(u)); err != nil {
+				break
+			}
+		}
+		if err != nil || len(page) < adminExportPageSize {
+			break
+		}
+		last := userKey(page[len(page)-1])
+		page, err = s.users.List(r.Context(), &last, adminExportPageSize)
+		if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		// As with /me/export, a truncated download must not look complete.
+		log.Println("admin export users stream error:", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// adminDeleteUserHandler serves DELETE /admin/users/{id}. The user is kept,
+// marked deleted, which hides them from every lookup; their refresh tokens
+// and sessions end immediately.
+func (s *Server) adminDeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid user id"))
+		return
+	}
+
+	user, err := s.users.GetByID(r.Context(), id)
+	if err == nil {
+		err = s.users.SetStatus(r.Context(), id, UserDeleted)
+	}
+	if errors.Is(err, ErrNotFound) {
+		apperror.WriteError(w, r, apperror.NotFound("user_not_found", "User not found"))
+		return
+	}
+	if err == nil {
+		// Deleted users' tokens can't refresh anyway; this is so they
+		// don't come back if the user is restored.
+		err = s.revokeUserRefreshTokens(r.Context(), id)
+	}
+	if err != nil {
+		log.Println("admin delete user error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	if err := s.revokeUserSessions(r.Context(), user.Email); err != nil {
+		log.Println("admin delete user session revocation error:", err)
+	}
+
+	ev := s.auditEventFromRequest(r, "admin.user_delete")
+	ev.Level = "warning"
+	ev.Target = "user:" + strconv.Itoa(id)
+	s.auditor.Record(r.Context(), ev)
+	s.webhooks.Enqueue(r.Context(), "user.deleted", map[string]any{"user_id": id})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminSchemaVersionHandler serves GET /admin/schema-version.
+func (s *Server) adminSchemaVersionHandler(w http.ResponseWriter, r *http.Request) {
+	schema, appliedAt, err := schemaVersion(r.Context(), s.db)
+	if err != nil {
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schema_version":  schema,
+		"applied_at":      appliedAt.UTC(),
+		"service_version": version,
+	})
+}
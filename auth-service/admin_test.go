@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"resilient-auth-service/pagination"
+)
+
+// TestAdminUsersPagination pages through 1000 users 20 at a time. They
+// were created seven to a second, so the cursor has to break ties on the
+// ID, including across page boundaries.
+func TestAdminUsersPagination(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1000, time.Minute)
+	h := s.Handler()
+	admin := newTestUser(t, s, "admin@example.com", "admin")
+	cookie := accessTokenCookie(t, s, admin)
+	base := time.Now().UTC()
+	for i := range 999 {
+		u, err := s.users.Create(context.Background(), fmt.Sprintf("u%d@example.com", i), "x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		updateTestUser(s, u.ID, func(u *User) { u.CreatedAt = base.Add(time.Duration(i/7) * time.Second) })
+	}
+
+	get := func(query url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/admin/users?"+query.Encode(), nil)
+		r.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	seen := map[int]bool{}
+	pages := 0
+	query := url.Values{"limit": {"20"}}
+	for {
+		rec := get(query)
+		var page pagination.ListResponse[adminUser]
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &page) != nil {
+			t.Fatalf("page %d: %d %s", pages+1, rec.Code, rec.Body)
+		}
+		pages++
+		for _, u := range page.Items {
+			if seen[u.ID] {
+				t.Fatalf("user %d returned twice", u.ID)
+			}
+			seen[u.ID] = true
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		if len(page.Items) != 20 {
+			t.Fatalf("page %d has %d users and a next cursor", pages, len(page.Items))
+		}
+		query.Set("cursor", *page.NextCursor)
+	}
+	if len(seen) != 1000 || pages != 50 {
+		t.Errorf("%d users in %d pages, want 1000 in 50", len(seen), pages)
+	}
+
+	// A cursor signed with another secret is refused.
+	forged := pagination.Encode(UserKey{ID: 1}, []byte("not the secret"))
+	if env := readEnvelope(t, get(url.Values{"cursor": {forged}}), http.StatusBadRequest); env.Error.Code != "invalid_cursor" {
+		t.Errorf("forged cursor: %q, want invalid_cursor", env.Error.Code)
+	}
+}
+
+// checkSchemaVersion asks GET /admin/schema-version and GET /health for the
+// schema version and checks both report want.
+func checkSchemaVersion(t *testing.T, s *Server, want int) {
+	t.Helper()
+	h := s.Handler()
+	cookie := accessTokenCookie(t, s, newTestUser(t, s, "admin@example.com", "admin"))
+	for _, path := range []string{"/v1/admin/schema-version", "/health"} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		var resp struct {
+			SchemaVersion  int    `json:"schema_version"`
+			ServiceVersion string `json:"service_version"`
+		}
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil {
+			t.Fatalf("%s: %d %s", path, rec.Code, rec.Body)
+		}
+		if resp.SchemaVersion != want || resp.ServiceVersion != version {
+			t.Errorf("%s: %s, want schema_version %d and service_version %q", path, rec.Body, want, version)
+		}
+	}
+}
+
+func TestAdminSchemaVersion(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	s.db = sql.OpenDB(&staticRowsDB{fakeRows{
+		cols: []string{"version", "applied_at"},
+		rows: [][]driver.Value{{int64(3), time.Now()}},
+	}})
+	t.Cleanup(func() { s.db.Close() })
+	checkSchemaVersion(t, s, 3)
+}
+
+// TestAdminSchemaVersionPostgres migrates an empty database to version 3
+// when TEST_DATABASE_URL names one. It drops the public schema and leaves
+// the database fully migrated, so point it at a scratch database.
+func TestAdminSchemaVersionPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	quietLog(t)
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, `DROP SCHEMA public CASCADE; CREATE SCHEMA public; GRANT USAGE ON SCHEMA public TO PUBLIC`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := migrations
+	t.Cleanup(func() {
+		migrations = all
+		if err := (&Migrator{db: db}).Up(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+	migrations = all[:3]
+	if err := (&Migrator{db: db}).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := newTestServer(t)
+	s.db = db
+	checkSchemaVersion(t, s, 3)
+}
+
+// TestAdminDeleteUser soft-deletes a user: every lookup stops finding
+// them, their session and refresh token end, and they can't log in.
+func TestAdminDeleteUser(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	fake := useFakeRefreshDB(t, s)
+	h := s.Handler()
+	admin := newTestUser(t, s, "admin@example.com", "admin")
+	user := newTestUser(t, s, "a@example.com", "user")
+	ctx := context.Background()
+	sessionID, err := s.createSession(ctx, user.Email, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := s.issueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	del := func(id int, as User) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/admin/users/%d", id), nil)
+		r.AddCookie(accessTokenCookie(t, s, as))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	if rec := del(admin.ID, user); rec.Code != http.StatusForbidden {
+		t.Errorf("delete as a user: %d, want 403", rec.Code)
+	}
+	if rec := del(user.ID, admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: %d %s", rec.Code, rec.Body)
+	}
+
+	if _, err := s.users.GetByEmail(ctx, user.Email); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByEmail: got %v, want ErrNotFound", err)
+	}
+	if env := readEnvelope(t, del(user.ID, admin), http.StatusNotFound); env.Error.Code != "user_not_found" {
+		t.Errorf("delete again: code %q, want user_not_found", env.Error.Code)
+	}
+	if _, _, err := s.lookupSession(ctx, sessionID, false); err == nil {
+		t.Error("the session survived the delete")
+	}
+	if !fake.byHash(refresh).revoked {
+		t.Error("the refresh token survived the delete")
+	}
+	login := postJSON(h, "/v1/login", `{"email":"a@example.com","password":"correct horse"}`)
+	if env := readEnvelope(t, login, http.StatusUnauthorized); env.Error.Code != "invalid_credentials" {
+		t.Errorf("login: code %q, want invalid_credentials", env.Error.Code)
+	}
+}
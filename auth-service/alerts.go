@@ -0,0 +1,20 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// alertRulesYAML is the recommended Prometheus alerting rules, written
+// against the metrics this service exports; keep them in step when
+// renaming or relabeling a metric.
+//
+//go:embed monitoring/alerts.yaml
+var alertRulesYAML []byte
+
+// adminAlertRulesHandler serves GET /admin/monitoring/alerts, the embedded
+// rule file as is.
+func adminAlertRulesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(alertRulesYAML)
+}
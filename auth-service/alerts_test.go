@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// alertRuleGroups is the Prometheus rule file format, as far as alerting
+// rules use it.
+type alertRuleGroups struct {
+	Groups []struct {
+		Name     string `yaml:"name"`
+		Interval string `yaml:"interval"`
+		Rules    []struct {
+			Alert       string            `yaml:"alert"`
+			Expr        string            `yaml:"expr"`
+			For         string            `yaml:"for"`
+			Labels      map[string]string `yaml:"labels"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"rules"`
+	} `yaml:"groups"`
+}
+
+// TestAlertsYAMLValid checks the embedded rules' structure, so a broken
+// file fails CI rather than the ops team's rule reload. It doesn't parse
+// the PromQL; promtool check rules does.
+func TestAlertsYAMLValid(t *testing.T) {
+	var f alertRuleGroups
+	dec := yaml.NewDecoder(bytes.NewReader(alertRulesYAML))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		t.Fatalf("parse monitoring/alerts.yaml: %v", err)
+	}
+	if len(f.Groups) == 0 {
+		t.Fatal("no rule groups")
+	}
+
+	seen := map[string]bool{}
+	for _, g := range f.Groups {
+		if g.Name == "" {
+			t.Error("group without a name")
+		}
+		if len(g.Rules) == 0 {
+			t.Errorf("group %q has no rules", g.Name)
+		}
+		if g.Interval != "" {
+			if _, err := model.ParseDuration(g.Interval); err != nil {
+				t.Errorf("group %q: interval: %v", g.Name, err)
+			}
+		}
+		for _, rule := range g.Rules {
+			if rule.Alert == "" || rule.Expr == "" {
+				t.Errorf("group %q: every rule needs alert and expr", g.Name)
+				continue
+			}
+			if rule.For != "" {
+				if _, err := model.ParseDuration(rule.For); err != nil {
+					t.Errorf("alert %s: for: %v", rule.Alert, err)
+				}
+			}
+			if rule.Labels["severity"] == "" {
+				t.Errorf("alert %s: no severity label", rule.Alert)
+			}
+			if rule.Annotations["summary"] == "" {
+				t.Errorf("alert %s: no summary annotation", rule.Alert)
+			}
+			if seen[rule.Alert] {
+				t.Errorf("duplicate alert %s", rule.Alert)
+			}
+			seen[rule.Alert] = true
+		}
+	}
+
+	for _, name := range []string{
+		"AuthHighLoginFailureRate",
+		"AuthSessionStoreDown",
+		"AuthHighLatencyP99",
+		"AuthRateLimitedRequests",
+		"AuthDatabaseConnectionsNearLimit",
+	} {
+		if !seen[name] {
+			t.Errorf("missing alert %s", name)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package apperror defines the JSON error envelope every endpoint returns:
+//
+//	{"error": {"code": "invalid_credentials", "message": "...", "fields": [...]}, "request_id": "..."}
+//
+// Code is stable and meant for programs; Message is for people and may
+// change. The same goes for the codes of the per-field errors in fields.
+package apperror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// Error is an error with everything needed to render it to a client. Err,
+// if set, is the underlying cause; it is never sent to the client.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+	// Verbatim marks Message as text this service didn't write, e.g. an
+	// operator's maintenance notice, so it is never translated.
+	Verbatim bool
+}
+
+// FieldError is a problem with one input field or query parameter, so a
+// client can point at the exact input. Code is stable, e.g. "required",
+// "too_short" or "invalid_email".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithField returns a copy of e with a validation error for field added.
+func (e *Error) WithField(field, code, message string) *Error {
+	c := *e
+	c.Fields = append(slices.Clip(e.Fields), FieldError{Field: field, Code: code, Message: message})
+	return &c
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(http.StatusForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(http.StatusNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+func Unprocessable(code, message string) *Error {
+	return New(http.StatusUnprocessableEntity, code, message)
+}
+
+func TooManyRequests(code, message string) *Error {
+	return New(http.StatusTooManyRequests, code, message)
+}
+
+func Unavailable(code, message string) *Error {
+	return New(http.StatusServiceUnavailable, code, message)
+}
+
+// Internal wraps an unexpected error. Clients only ever see a generic
+// message; err is kept for logging.
+func Internal(err error) *Error {
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Code:    "internal_error",
+		Message: "Internal server error",
+		Err:     err,
+	}
+}
+
+// RequestID extracts the request ID to include in the envelope. The server
+// sets it at startup; it lives there so this package doesn't depend on the
+// server's context keys.
+var RequestID = func(ctx context.Context) string { return "" }
+
+// Localize translates a message for the request ctx belongs to. key is the
+// error code, or "field." and the code for a field error; message is the
+// English text, returned as is when there's no translation. Like
+// RequestID, the server sets it at startup.
+var Localize = func(ctx context.Context, key, message string) string { return message }
+
+type envelope struct {
+	Error     body   `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type body struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// WriteError renders err as the JSON envelope. Errors that aren't an *Error
+// become a generic 500.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = Internal(err)
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Del("Content-Length")
+	w.WriteHeader(e.Status)
+
+	ctx := r.Context()
+	var fields []FieldError
+	for _, f := range e.Fields {
+		f.Message = Localize(ctx, "field."+f.Code, f.Message)
+		fields = append(fields, f)
+	}
+	message := e.Message
+	if !e.Verbatim {
+		message = Localize(ctx, e.Code, message)
+	}
+	json.NewEncoder(w).Encode(envelope{
+		Error:     body{Code: e.Code, Message: message, Fields: fields},
+		RequestID: RequestID(ctx),
+	})
+}
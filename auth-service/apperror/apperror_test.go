@@ -0,0 +1,134 @@
+package apperror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type ctxKey struct{}
+
+// withTestHooks installs a RequestID and a Localize that marks what it
+// translated, restoring the defaults afterwards.
+func withTestHooks(t *testing.T) {
+	t.Helper()
+	savedID, savedLocalize := RequestID, Localize
+	RequestID = func(ctx context.Context) string {
+		id, _ := ctx.Value(ctxKey{}).(string)
+		return id
+	}
+	Localize = func(ctx context.Context, key, message string) string {
+		return "[" + key + "] " + message
+	}
+	t.Cleanup(func() { RequestID, Localize = savedID, savedLocalize })
+}
+
+func writeTo(t *testing.T, err error, requestID string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if requestID != "" {
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, requestID))
+	}
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Length", "999")
+	WriteError(rec, r, err)
+
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("body %q: %v", rec.Body, err)
+	}
+	return rec, got
+}
+
+func TestWriteErrorEnvelopes(t *testing.T) {
+	withTestHooks(t)
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   map[string]any
+	}{
+		{"401", Unauthorized("invalid_credentials", "Invalid email or password"), http.StatusUnauthorized,
+			map[string]any{"error": map[string]any{
+				"code": "invalid_credentials", "message": "[invalid_credentials] Invalid email or password",
+			}, "request_id": "req-1"}},
+		{"404", NotFound("not_found", "Not found"), http.StatusNotFound,
+			map[string]any{"error": map[string]any{
+				"code": "not_found", "message": "[not_found] Not found",
+			}, "request_id": "req-1"}},
+		{"409", Conflict("email_taken", "Email already registered"), http.StatusConflict,
+			map[string]any{"error": map[string]any{
+				"code": "email_taken", "message": "[email_taken] Email already registered",
+			}, "request_id": "req-1"}},
+		{"422", Unprocessable("validation_failed", "Invalid input").
+			WithField("email", "invalid_email", "must be a valid email address").
+			WithField("password", "too_short", "must be at least 8 characters"), http.StatusUnprocessableEntity,
+			map[string]any{"error": map[string]any{
+				"code": "validation_failed", "message": "[validation_failed] Invalid input",
+				"fields": []any{
+					map[string]any{"field": "email", "code": "invalid_email", "message": "[field.invalid_email] must be a valid email address"},
+					map[string]any{"field": "password", "code": "too_short", "message": "[field.too_short] must be at least 8 characters"},
+				},
+			}, "request_id": "req-1"}},
+		{"429", TooManyRequests("rate_limited", "Too many requests"), http.StatusTooManyRequests,
+			map[string]any{"error": map[string]any{
+				"code": "rate_limited", "message": "[rate_limited] Too many requests",
+			}, "request_id": "req-1"}},
+		{"500", Internal(errors.New("pq: connection refused")), http.StatusInternalServerError,
+			map[string]any{"error": map[string]any{
+				"code": "internal_error", "message": "[internal_error] Internal server error",
+			}, "request_id": "req-1"}},
+		{"500 from a plain error", errors.New("secret detail"), http.StatusInternalServerError,
+			map[string]any{"error": map[string]any{
+				"code": "internal_error", "message": "[internal_error] Internal server error",
+			}, "request_id": "req-1"}},
+		{"wrapped", fmt.Errorf("loading: %w", Forbidden("forbidden", "Forbidden")), http.StatusForbidden,
+			map[string]any{"error": map[string]any{
+				"code": "forbidden", "message": "[forbidden] Forbidden",
+			}, "request_id": "req-1"}},
+	}
+	for _, tt := range tests {
+		rec, got := writeTo(t, tt.err, "req-1")
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.status)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: envelope\n got %v\nwant %v", tt.name, got, tt.want)
+		}
+		h := rec.Header()
+		if h.Get("Content-Type") != "application/json; charset=utf-8" ||
+			h.Get("X-Content-Type-Options") != "nosniff" || h.Get("Content-Length") != "" {
+			t.Errorf("%s: headers %v", tt.name, h)
+		}
+	}
+}
+
+func TestWriteErrorVerbatim(t *testing.T) {
+	withTestHooks(t)
+	e := Unavailable("maintenance", "default")
+	e.Message, e.Verbatim = "Back at 10:00 UTC", true
+	rec, got := writeTo(t, e, "")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d", rec.Code)
+	}
+	want := map[string]any{"error": map[string]any{"code": "maintenance", "message": "Back at 10:00 UTC"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("envelope %v, want %v (no request_id when there is none)", got, want)
+	}
+}
+
+func TestErrorUnwrap(t *testing.T) {
+	cause := errors.New("pq: connection refused")
+	e := Internal(cause)
+	if !errors.Is(e, cause) {
+		t.Error("Internal doesn't wrap its cause")
+	}
+	if e.Error() == "" || NotFound("not_found", "Not found").Error() == "" {
+		t.Error("empty Error()")
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+	"resilient-auth-service/pagination"
+)
+
+// AuditEvent is one row of the append-only audit_events table.
+type AuditEvent struct {
+	ID        int64          `json:"id"`
+	Level     string         `json:"level"` // "info", "warning" or "critical"
+	ActorID   *int           `json:"actor_id"`
+	Action    string         `json:"action"`
+	Target    string         `json:"target,omitempty"`
+	IP        string         `json:"ip,omitempty"`
+	UserAgent string         `json:"user_agent,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// auditQueueWait bounds how long Record blocks a request when the writer is
+// behind. Past that the event is only logged, so a slow database degrades
+// the audit trail instead of taking down logins.
+const auditQueueWait = time.Second
+
+var errAuditorClosed = errors.New("auditor closed")
+
+// Auditor writes audit events to Postgres from a single background
+// goroutine fed by a buffered channel, then publishes each stored event
+// to live subscribers through rdb (if not nil).
+type Auditor struct {
+	db     *sql.DB
+	rdb    *redis.Client
+	events chan AuditEvent
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewAuditor(db *sql.DB, rdb *redis.Client, bufferSize int) *Auditor {
+	a := &Auditor{
+		db:     db,
+		rdb:    rdb,
+		events: make(chan AuditEvent, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Record queues ev. Every event is also written to the service log right
+// away, so nothing is silently lost even if the insert later fails.
+// Synthetic checks aren't audited.
+func (a *Auditor) Record(ctx context.Context, ev AuditEvent) error {
+	if isSynthetic(ctx) {
+		return nil
+	}
+	if ev.Level == "" {
+		ev.Level = "info"
+	}
+	if ev.RequestID == "" {
+		ev.RequestID = requestIDFromContext(ctx)
+	}
+	ev.CreatedAt = time.Now().UTC()
+
+	log.Printf("AUDIT level=%s action=%s actor_id=%s target=%s ip=%s request_id=%s",
+		ev.Level, ev.Action, formatActor(ev.ActorID), ev.Target, ev.IP, ev.RequestID)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return errAuditorClosed
+	}
+
+	timer := time.NewTimer(auditQueueWait)
+	defer timer.Stop()
+
+	select {
+	case a.events <- ev:
+		return nil
+	case <-timer.C:
+		log.Printf("audit queue full, dropped action=%s request_id=%s", ev.Action, ev.RequestID)
+		return errors.New("audit queue full")
+	}
+}
+
+// Close stops accepting events and waits for the queue to drain.
+func (a *Auditor) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.events)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *Auditor) run() {
+	defer close(a.done)
+
+	for ev := range a.events {
+		id, err := a.insert(ev)
+		if err != nil {
+			log.Printf("audit insert error action=%s request_id=%s err=%v", ev.Action, ev.RequestID, err)
+			continue
+		}
+		ev.ID = id
+		a.publish(ev)
+	}
+}
+
+func (a *Auditor) insert(ev AuditEvent) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	meta, err := json.Marshal(ev.Metadata)
+	if err != nil {
+		return 0, err
+	}
+	if ev.Metadata == nil {
+		meta = []byte("{}")
+	}
+
+	var id int64
+	err = a.db.QueryRowContext(ctx,
+		`INSERT INTO audit_events (level, actor_id, action, target, ip, user_agent, request_id, metadata, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		ev.Level, ev.ActorID, ev.Action, ev.Target, ev.IP, ev.UserAgent, ev.RequestID, meta, ev.CreatedAt,
+	).Scan(&id)
+	return id, err
+}
+
+// auditEventFromRequest fills in the request-derived fields of an event.
+func (s *Server) auditEventFromRequest(r *http.Request, action string) AuditEvent {
+	ev := AuditEvent{
+		Action:    action,
+		IP:        s.realIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: requestIDFromContext(r.Context()),
+	}
+	if user, ok := auth.UserFromContext(r.Context()); ok && user.ID != 0 {
+		ev.ActorID = &user.ID
+	}
+	return ev
+}
+
+func formatActor(id *int) string {
+	if id == nil {
+		return "-"
+	}
+	return strconv.Itoa(*id)
+}
+
+// adminAuditHandler serves GET /admin/audit, filtered by any of actor,
+// action, from and to (RFC 3339), newest first.
+func (s *Server) adminAuditHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	query := `SELECT id, level, actor_id, action, target, ip, user_agent, request_id, metadata, created_at
+	          FROM audit_events WHERE true`
+	var args []any
+	addFilter := func(cond string, v any) {
+		args = append(args, v)
+		query += " AND " + cond + " $" + strconv.Itoa(len(args))
+	}
+
+	if v := q.Get("actor"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			apperror.WriteError(w, r, apperror.BadRequest("invalid_parameter", "Invalid actor").WithField("actor", "invalid_value", "must be a user id"))
+			return
+		}
+		addFilter("actor_id =", id)
+	}
+	if v := q.Get("action"); v != "" {
+		addFilter("action =", v)
+	}
+	for _, f := range []struct{ param, cond string }{{"from", "created_at >="}, {"to", "created_at <"}} {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apperror.WriteError(w, r, apperror.BadRequest("invalid_parameter", "Invalid "+f.param).WithField(f.param, "invalid_timestamp", "must be an RFC 3339 timestamp"))
+			return
+		}
+		addFilter(f.cond, t.UTC())
+	}
+
+	limit, err := pagination.Limit(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	args = append(args, limit)
+	query += " ORDER BY created_at DESC, id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := s.db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		log.Println("admin audit query error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		var (
+			ev   AuditEvent
+			meta []byte
+		)
+		err := rows.Scan(&ev.ID, &ev.Level, &ev.ActorID, &ev.Action, &ev.Target, &ev.IP,
+			&ev.UserAgent, &ev.RequestID, &meta, &ev.CreatedAt)
+		if err == nil {
+			err = json.Unmarshal(meta, &ev.Metadata)
+		}
+		if err != nil {
+			log.Println("admin audit scan error:", err)
+			writeDBError(w, r, err)
+			return
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("admin audit rows error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"events": events})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"resilient-auth-service/apperror"
+)
+
+// auditLiveChannel carries each audit event once it has been stored, for
+// GET /admin/audit/stream and GET /admin/events/stream.
+const auditLiveChannel = "audit_events_live"
+
+// publish sends a stored event to live subscribers. Failures are only
+// logged: the event is in the table either way, the stream just misses it.
+func (a *Auditor) publish(ev AuditEvent) {
+	if a.rdb == nil {
+		return
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		log.Println("audit event publish error:", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := a.rdb.Publish(ctx, auditLiveChannel, b).Err(); err != nil {
+		log.Println("audit event publish error:", err)
+	}
+}
+
+// adminAuditStreamHandler serves GET /admin/audit/stream[?action=...], a
+// Server-Sent Events stream of audit events from every replica as they
+// are stored, for live dashboards. Each event is sent as the same JSON as
+// in GET /admin/audit. Events from before the stream opened, or while it
+// was disconnected, aren't replayed; read those from /admin/audit.
+func (s *Server) adminAuditStreamHandler(w http.ResponseWriter, r *http.Request) {
+	action := r.URL.Query().Get("action")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	sub := s.rdb.Subscribe(r.Context(), auditLiveChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(r.Context()); err != nil {
+		log.Println("audit stream subscribe error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Event stream unavailable"))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	flush := func() {
+		flusher.Flush()
+		rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+	w.WriteHeader(http.StatusOK)
+	flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	messages := sub.Channel()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flush()
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if !auditEventMatches(msg.Payload, action) {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
+			flush()
+		}
+	}
+}
+
+// auditEventMatches reports whether a published event has the given
+// action; every event matches "".
+func auditEventMatches(payload, action string) bool {
+	if action == "" {
+		return true
+	}
+	var ev struct {
+		Action string `json:"action"`
+	}
+	return json.Unmarshal([]byte(payload), &ev) == nil && ev.Action == action
+}
+
+const (
+	// auditSocketPingInterval is how often the server pings a socket; a
+	// client that hasn't answered within auditSocketPongWait is dropped.
+	auditSocketPingInterval = 30 * time.Second
+	auditSocketPongWait     = auditSocketPingInterval + 10*time.Second
+	auditSocketWriteWait    = 10 * time.Second
+)
+
+// adminEventsSocketHandler serves GET /admin/events/stream[?event_type=...],
+// the same feed as /admin/audit/stream over a WebSocket: one text message
+// per audit event, holding its JSON. event_type keeps only events with
+// that action, e.g. login.failure. The server pings every 30 seconds and
+// hangs up on a client that stops answering; anything the client sends
+// is ignored. The socket's Redis subscription ends with it.
+func (s *Server) adminEventsSocketHandler(w http.ResponseWriter, r *http.Request) {
+	action := r.URL.Query().Get("event_type")
+
+	// Subscribed before the upgrade, so a Redis failure is still an HTTP
+	// error and every event after the handshake is delivered.
+	sub := s.rdb.Subscribe(r.Context(), auditLiveChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(r.Context()); err != nil {
+		log.Println("audit socket subscribe error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Event stream unavailable"))
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.socketOriginAllowed}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has answered the request.
+		return
+	}
+	defer conn.Close()
+
+	// Reading runs the pong handler and notices the client going away.
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(auditSocketPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(auditSocketPongWait))
+	})
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(auditSocketPingInterval)
+	defer ping.Stop()
+	messages := sub.Channel()
+
+	for {
+		select {
+		case <-gone:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(auditSocketWriteWait)); err != nil {
+				return
+			}
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if !auditEventMatches(msg.Payload, action) {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(auditSocketWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// socketOriginAllowed accepts a WebSocket handshake from a page on this
+// host or on an origin CORS allows. Browsers send cookies with any
+// handshake, so without this another site could open the feed as a
+// logged-in admin. Clients that aren't browsers send no Origin.
+func (s *Server) socketOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return originAllowed(origin, s.cfg.CORSAllowedOrigins)
+}
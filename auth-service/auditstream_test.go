@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// useAuditDB gives s an Auditor whose inserts succeed, so events go on to
+// be published on auditLiveChannel.
+func useAuditDB(t *testing.T, s *Server) {
+	t.Helper()
+	s.auditor.Close(context.Background())
+	db := sql.OpenDB(&staticRowsDB{fakeRows{cols: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}})
+	t.Cleanup(func() { db.Close() })
+	s.auditor = NewAuditor(db, s.rdb, 64)
+}
+
+// dialEvents opens GET /v1/admin/events/stream on srv.
+func dialEvents(srv *httptest.Server, query string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/admin/events/stream" + query
+	return websocket.DefaultDialer.Dial(url, header)
+}
+
+func cookieHeader(c *http.Cookie) http.Header {
+	return http.Header{"Cookie": {c.String()}}
+}
+
+// readAuditEvent reads the next event from a socket.
+func readAuditEvent(t *testing.T, conn *websocket.Conn) AuditEvent {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	kind, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ev AuditEvent
+	if kind != websocket.TextMessage || json.Unmarshal(data, &ev) != nil {
+		t.Fatalf("got message %d %q, want an AuditEvent", kind, data)
+	}
+	return ev
+}
+
+// TestAdminEventsSocket has two dashboards open, one filtered to failed
+// logins, while an event is recorded and a login fails.
+func TestAdminEventsSocket(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	useAuditDB(t, s)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	admin := accessTokenCookie(t, s, newTestUser(t, s, "admin@example.com", "admin"))
+
+	all, _, err := dialEvents(srv, "", cookieHeader(admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer all.Close()
+	failures, _, err := dialEvents(srv, "?event_type=login.failure", cookieHeader(admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer failures.Close()
+
+	s.auditor.Record(context.Background(), AuditEvent{Action: "user.registered", Target: "a@example.com"})
+	if rec := postJSON(s.Handler(), "/v1/login", `{"email":"nobody@example.com","password":"wrong"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body)
+	}
+
+	if ev := readAuditEvent(t, all); ev.Action != "user.registered" || ev.Target != "a@example.com" || ev.ID != 1 {
+		t.Errorf("first event %+v, want the stored user.registered", ev)
+	}
+	if ev := readAuditEvent(t, all); ev.Action != "login.failure" {
+		t.Errorf("second event %q, want login.failure", ev.Action)
+	}
+	if ev := readAuditEvent(t, failures); ev.Action != "login.failure" || ev.Metadata["email"] != "nobody@example.com" {
+		t.Errorf("filtered socket got %+v, want only the login.failure", ev)
+	}
+
+	// Each socket's subscription ends when its client hangs up.
+	all.Close()
+	failures.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		subs, err := s.rdb.PubSubNumSub(context.Background(), auditLiveChannel).Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subs[auditLiveChannel] == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d subscriptions left after the clients hung up", subs[auditLiveChannel])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAdminEventsSocketRefused(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	user := accessTokenCookie(t, s, newTestUser(t, s, "a@example.com", "user"))
+	admin := accessTokenCookie(t, s, newTestUser(t, s, "admin@example.com", "admin"))
+
+	crossSite := cookieHeader(admin)
+	crossSite.Set("Origin", "https://evil.example")
+	for _, tt := range []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"unauthenticated", nil, http.StatusUnauthorized},
+		{"not an admin", cookieHeader(user), http.StatusForbidden},
+		{"another site's page", crossSite, http.StatusForbidden},
+	} {
+		conn, resp, err := dialEvents(srv, "", tt.header)
+		if err == nil {
+			conn.Close()
+			t.Errorf("%s: connected", tt.name)
+			continue
+		}
+		if resp == nil || resp.StatusCode != tt.status {
+			t.Errorf("%s: got %v, %v; want %d", tt.name, resp, err, tt.status)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package auth carries the authenticated user, and the organization they
+// are acting in, through a request's context. The key types are
+// unexported, so only SetUser and SetOrg can store them and nothing else
+// can collide with or overwrite them.
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// User is the caller as established by the authentication middleware. Which
+// fields are set depends on how they authenticated: a session cookie gives
+// Email and SessionID, an access token gives Email, ID and, for tokens that
+// carry it, AuthTime.
+type User struct {
+	ID        int
+	Email     string
+	SessionID string
+	// AuthTime is when the user last entered their password; zero if
+	// unknown.
+	AuthTime time.Time
+}
+
+type userKey struct{}
+
+// SetUser returns a copy of ctx carrying u.
+func SetUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// UserFromContext returns the user stored by SetUser. ok is false if the
+// request didn't pass through authentication, which handlers should answer
+// with 401 rather than assume it can't happen.
+func UserFromContext(ctx context.Context) (u User, ok bool) {
+	u, ok = ctx.Value(userKey{}).(User)
+	return u, ok
+}
+
+// Org is the organization the caller's session is acting in, with the
+// caller's role there: "owner", "admin" or "member".
+type Org struct {
+	ID   int
+	Role string
+}
+
+type orgKey struct{}
+
+// SetOrg returns a copy of ctx carrying o.
+func SetOrg(ctx context.Context, o Org) context.Context {
+	return context.WithValue(ctx, orgKey{}, o)
+}
+
+// OrgFromContext returns the organization stored by SetOrg. ok is false if
+// the session has no active organization, or the request didn't go
+// through the middleware that sets it.
+func OrgFromContext(ctx context.Context) (o Org, ok bool) {
+	o, ok = ctx.Value(orgKey{}).(Org)
+	return o, ok
+}
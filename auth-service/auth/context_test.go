@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUserFromContext(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := UserFromContext(ctx); ok {
+		t.Error("found a user in an empty context")
+	}
+	// A string key spelled like ours must not be mistaken for it.
+	if _, ok := UserFromContext(context.WithValue(ctx, "user", User{ID: 1})); ok {
+		t.Error("found a user stored under a string key")
+	}
+
+	want := User{ID: 42, Email: "a@example.com", SessionID: "s1", AuthTime: time.Unix(1700000000, 0)}
+	got, ok := UserFromContext(SetUser(ctx, want))
+	if !ok || got != want {
+		t.Errorf("got %+v, %v; want %+v", got, ok, want)
+	}
+
+	if _, ok := OrgFromContext(SetUser(ctx, want)); ok {
+		t.Error("found an org that was never set")
+	}
+	if o, ok := OrgFromContext(SetOrg(ctx, Org{ID: 7, Role: "owner"})); !ok || o.ID != 7 || o.Role != "owner" {
+		t.Errorf("org %+v, %v", o, ok)
+	}
+}
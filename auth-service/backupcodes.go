@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+const (
+	backupCodeCount  = 10
+	backupCodeLength = 8
+	// backupCodeAlphabet is lower case only; codes are matched case
+	// insensitively, so users can type them however they were printed.
+	backupCodeAlphabet     = "abcdefghijklmnopqrstuvwxyz0123456789"
+	backupCodeEmailTimeout = 30 * time.Second
+)
+
+var errTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+
+func generateBackupCodes() ([]string, error) {
+	alphabetLen := big.NewInt(int64(len(backupCodeAlphabet)))
+	codes := make([]string, backupCodeCount)
+	for i := range codes {
+		b := make([]byte, backupCodeLength)
+		for j := range b {
+			n, err := rand.Int(rand.Reader, alphabetLen)
+			if err != nil {
+				return nil, err
+			}
+			b[j] = backupCodeAlphabet[n.Int64()]
+		}
+		codes[i] = string(b)
+	}
+	return codes, nil
+}
+
+// normalizeBackupCode accepts a code typed with spaces, dashes or capitals.
+func normalizeBackupCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
+
+// hashBackupCodes hashes one code at a time: submitting all of them at
+// once could overflow the bcrypt queue on a small replica.
+func (s *Server) hashBackupCodes(ctx context.Context, codes []string) ([][]byte, error) {
+	hashes := make([][]byte, len(codes))
+	for i, code := range codes {
+		hash, err := s.passwordHasher.HashPassword(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+	return hashes, nil
+}
+
+// replaceBackupCodes swaps the user's backup codes, used or not, for new
+// ones.
+func replaceBackupCodes(ctx context.Context, tx *sql.Tx, userID int, hashes [][]byte) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM totp_backup_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO totp_backup_codes (user_id, code_hash) VALUES ($1, $2)", userID, string(hash),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redeemBackupCode checks code against the user's unused backup codes and
+// marks the one it matches used, returning how many are left. The hashes
+// are compared outside any transaction, since that takes a while; the
+// conditional update then makes sure two logins racing with the same code
+// can't both get in.
+func (s *Server) redeemBackupCode(ctx context.Context, userID int, code string) (ok bool, remaining int, err error) {
+	code = normalizeBackupCode(code)
+	if len(code) != backupCodeLength {
+		return false, 0, nil
+	}
+
+	type storedCode struct {
+		id   int
+		hash []byte
+	}
+	var stored []storedCode
+	err = s.withUserScope(ctx, userID, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			"SELECT id, code_hash FROM totp_backup_codes WHERE user_id = $1 AND used_at IS NULL", userID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var c storedCode
+			if err := rows.Scan(&c.id, &c.hash); err != nil {
+				return err
+			}
+			stored = append(stored, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return false, 0, err
+	}
+
+	matched := 0
+	for _, c := range stored {
+		err := s.passwordHasher.ComparePassword(ctx, c.hash, code)
+		if err == nil {
+			matched = c.id
+			break
+		}
+		if !errors.Is(err, errPasswordMismatch) && !errors.Is(err, errInvalidPasswordHash) {
+			return false, 0, err
+		}
+	}
+	if matched == 0 {
+		return false, len(stored), nil
+	}
+
+	err = s.withUserScope(ctx, userID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE totp_backup_codes SET used_at = now() WHERE id = $1 AND used_at IS NULL", matched)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			ok = true
+		}
+		return tx.QueryRowContext(ctx,
+			"SELECT count(*) FROM totp_backup_codes WHERE user_id = $1 AND used_at IS NULL", userID,
+		).Scan(&remaining)
+	})
+	return ok, remaining, err
+}
+
+// passBackupCode runs the TOTP step with a backup code in place of the
+// TOTP code. It reports whether the login may carry on; otherwise it has
+// answered the request. If the bcrypt pool is busy the flow is put back,
+// so the client can retry the step with the same flow_id.
+func (s *Server) passBackupCode(w http.ResponseWriter, r *http.Request, flow *AuthFlow, code string) bool {
+	ok, remaining, err := s.redeemBackupCode(r.Context(), flow.UserID, code)
+	if errors.Is(err, errBcryptBusy) {
+		if err := s.saveAuthFlow(r.Context(), flow); err != nil {
+			log.Println("auth flow save error:", err)
+		}
+		writeServerBusy(w, r)
+		return false
+	}
+	if err != nil {
+		log.Println("backup code check error:", err)
+		writeDBError(w, r, err)
+		return false
+	}
+	if !ok {
+		s.failLoginStep(w, r, flow, "invalid_backup_code")
+		return false
+	}
+
+	ev := s.auditEventFromRequest(r, "login.backup_code_used")
+	ev.Level = "warning"
+	ev.ActorID = &flow.UserID
+	ev.Metadata = map[string]any{"email": flow.Email, "remaining": remaining}
+	s.auditor.Record(r.Context(), ev)
+
+	data := EmailTemplateData{
+		Email:           flow.Email,
+		IP:              ev.IP,
+		Device:          r.UserAgent(),
+		Time:            time.Now().UTC(),
+		BackupCodesLeft: remaining,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), backupCodeEmailTimeout)
+		defer cancel()
+		if err := s.sendBackupCodeUsedEmail(ctx, data); err != nil {
+			log.Println("backup code email error:", err)
+		}
+	}()
+	return true
+}
+
+func (s *Server) sendBackupCodeUsedEmail(ctx context.Context, data EmailTemplateData) error {
+	msg, err := newEmail(ctx, data.Email, "backup-code-used", data)
+	if err != nil {
+		return err
+	}
+	return s.emailSender.Send(ctx, msg)
+}
+
+// totpPendingTTL is how long a secret from /2fa/setup waits to be
+// confirmed with a code from it.
+const totpPendingTTL = 10 * time.Minute
+
+func totpPendingKey(userID int) string {
+	return "totp_pending:" + strconv.Itoa(userID)
+}
+
+type twoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+	ExpiresIn  int    `json:"expires_in"`
+}
+
+// twoFactorSetupHandler serves POST /2fa/setup. It generates a new secret
+// and keeps it pending for totpPendingTTL; nothing changes for the user
+// until /2fa/confirm shows the secret made it into an authenticator, so
+// a setup abandoned halfway can't lock them out. Calling it again
+// replaces the pending secret, not the one in use.
+func (s *Server) twoFactorSetupHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok || user.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		apperror.WriteError(w, r, apperror.Internal(err))
+		return
+	}
+	if err := s.rdb.Set(r.Context(), totpPendingKey(user.ID), secret, totpPendingTTL).Err(); err != nil {
+		log.Println("2fa setup error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, twoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURL: s.totpURL(secret, user.Email),
+		ExpiresIn:  int(totpPendingTTL.Seconds()),
+	})
+}
+
+type twoFactorConfirmRequest struct {
+	Code secret `json:"code"`
+}
+
+func (req twoFactorConfirmRequest) validate() error {
+	var v validator
+	v.required("code", string(req.Code))
+	return v.err()
+}
+
+// twoFactorConfirmHandler serves POST /2fa/confirm: {"code": "123456"}
+// from the authenticator the pending secret was added to. A right code
+// turns TOTP on with that secret, replacing any previous one, and issues
+// a fresh set of backup codes. A wrong one leaves the secret pending for
+// another try; there is nothing to guess, since the caller was given it.
+func (s *Server) twoFactorConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok || user.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+	var req twoFactorConfirmRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	secret, err := s.rdb.Get(r.Context(), totpPendingKey(user.ID)).Result()
+	if err == redis.Nil {
+		apperror.WriteError(w, r, apperror.Conflict("totp_setup_expired", "Two-factor setup expired; start it again"))
+		return
+	}
+	if err != nil {
+		log.Println("2fa confirm error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+	counter, valid := verifyTOTP(secret, string(req.Code), s.clock.Now())
+	if valid {
+		// Claimed so the same code can't also pass a login.
+		valid, err = s.claimTOTPStep(r.Context(), user.ID, counter)
+		if err != nil {
+			log.Println("totp replay check error:", err)
+			apperror.WriteError(w, r, apperror.Internal(nil))
+			return
+		}
+	}
+	if !valid {
+		apperror.WriteError(w, r, apperror.Unauthorized("invalid_code", "Invalid code"))
+		return
+	}
+
+	codes, hashes, ok := s.newBackupCodes(w, r)
+	if !ok {
+		return
+	}
+	err = s.withUserScope(r.Context(), user.ID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(r.Context(),
+			"UPDATE active_users SET totp_secret = $1 WHERE id = $2", secret, user.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return replaceBackupCodes(r.Context(), tx, user.ID, hashes)
+	})
+	if err == sql.ErrNoRows {
+		apperror.WriteError(w, r, apperror.NotFound("user_not_found", "User not found"))
+		return
+	}
+	if err != nil {
+		log.Println("2fa confirm error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	if err := s.rdb.Del(r.Context(), totpPendingKey(user.ID)).Err(); err != nil {
+		// It expires on its own; confirming it again only repeats this.
+		log.Println("2fa pending secret delete error:", err)
+	}
+
+	ev := s.auditEventFromRequest(r, "user.totp_enable")
+	ev.Level = "warning"
+	ev.ActorID = &user.ID
+	s.auditor.Record(r.Context(), ev)
+
+	// BackupCodes are shown this once; only their hashes are kept.
+	writeJSON(w, http.StatusOK, map[string][]string{"backup_codes": codes})
+}
+
+// twoFactorBackupCodesHandler serves POST /2fa/backup-codes, which replaces
+// the backup codes, for when they run out or may have been seen.
+func (s *Server) twoFactorBackupCodesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok || user.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+
+	codes, hashes, ok := s.newBackupCodes(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.withUserScope(r.Context(), user.ID, func(tx *sql.Tx) error {
+		var enabled bool
+		err := tx.QueryRowContext(r.Context(),
+			"SELECT totp_secret IS NOT NULL FROM active_users WHERE id = $1", user.ID,
+		).Scan(&enabled)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return errTwoFactorNotEnabled
+		}
+		return replaceBackupCodes(r.Context(), tx, user.ID, hashes)
+	})
+	switch {
+	case err == sql.ErrNoRows:
+		apperror.WriteError(w, r, apperror.NotFound("user_not_found", "User not found"))
+		return
+	case errors.Is(err, errTwoFactorNotEnabled):
+		apperror.WriteError(w, r, apperror.Conflict("two_factor_not_enabled", "Set up two-factor authentication first"))
+		return
+	case err != nil:
+		log.Println("backup code regenerate error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "user.backup_codes_regenerate")
+	ev.Level = "warning"
+	ev.ActorID = &user.ID
+	s.auditor.Record(r.Context(), ev)
+
+	writeJSON(w, http.StatusOK, map[string][]string{"backup_codes": codes})
+}
+
+// newBackupCodes generates and hashes a set of codes, answering the
+// request itself if that fails.
+func (s *Server) newBackupCodes(w http.ResponseWriter, r *http.Request) ([]string, [][]byte, bool) {
+	codes, err := generateBackupCodes()
+	if err != nil {
+		apperror.WriteError(w, r, apperror.Internal(err))
+		return nil, nil, false
+	}
+	hashes, err := s.hashBackupCodes(r.Context(), codes)
+	if errors.Is(err, errBcryptBusy) {
+		writeServerBusy(w, r)
+		return nil, nil, false
+	}
+	if err != nil {
+		log.Println("backup code hash error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return nil, nil, false
+	}
+	return codes, hashes, true
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNormalizeBackupCode(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"abcd2345", "abcd2345"},
+		{"ABCD2345", "abcd2345"},
+		{"abcd-2345", "abcd2345"},
+		{"abcd 2345", "abcd2345"},
+		{" Ab-Cd 23-45 ", "abcd2345"},
+		{"", ""},
+		{"abcd_2345", "abcd_2345"},
+	}
+	for _, tt := range tests {
+		if got := normalizeBackupCode(tt.in); got != tt.want {
+			t.Errorf("normalizeBackupCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateBackupCodes(t *testing.T) {
+	codes, err := generateBackupCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != backupCodeCount {
+		t.Fatalf("got %d codes, want %d", len(codes), backupCodeCount)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != backupCodeLength {
+			t.Errorf("code %q has length %d, want %d", code, len(code), backupCodeLength)
+		}
+		if strings.Trim(code, backupCodeAlphabet) != "" {
+			t.Errorf("code %q has characters outside the alphabet", code)
+		}
+		if normalizeBackupCode(code) != code {
+			t.Errorf("code %q isn't already normalized", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+// fakeBackupCodeDB is a database/sql driver holding an in-memory
+// totp_backup_codes table and the users' TOTP secrets. It understands
+// exactly the statements redeemBackupCode and twoFactorConfirmHandler run,
+// with used_at IS NULL honoured, and fails loudly on anything else.
+type fakeBackupCodeDB struct {
+	mu          sync.Mutex
+	codes       []*fakeBackupCode
+	totpSecrets map[int]string
+}
+
+type fakeBackupCode struct {
+	id     int
+	userID int
+	hash   []byte
+	used   bool
+}
+
+func (f *fakeBackupCodeDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeBackupCodeDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeBackupCodeDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeBackupCodeDB: prepared statements not supported")
+}
+func (f *fakeBackupCodeDB) Close() error              { return nil }
+func (f *fakeBackupCodeDB) Begin() (driver.Tx, error) { return f, nil }
+func (f *fakeBackupCodeDB) Commit() error             { return nil }
+func (f *fakeBackupCodeDB) Rollback() error           { return nil }
+
+func (f *fakeBackupCodeDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch query {
+	case "SET LOCAL ROLE authdb_app", "SELECT set_config('app.user_id', $1, true)":
+		return driver.RowsAffected(0), nil
+	case "UPDATE totp_backup_codes SET used_at = now() WHERE id = $1 AND used_at IS NULL":
+		for _, c := range f.codes {
+			if int64(c.id) == args[0].Value.(int64) && !c.used {
+				c.used = true
+				return driver.RowsAffected(1), nil
+			}
+		}
+		return driver.RowsAffected(0), nil
+	case "UPDATE active_users SET totp_secret = $1 WHERE id = $2":
+		if f.totpSecrets == nil {
+			f.totpSecrets = map[int]string{}
+		}
+		f.totpSecrets[int(args[1].Value.(int64))] = args[0].Value.(string)
+		return driver.RowsAffected(1), nil
+	case "DELETE FROM totp_backup_codes WHERE user_id = $1":
+		userID := int(args[0].Value.(int64))
+		f.codes = slices.DeleteFunc(f.codes, func(c *fakeBackupCode) bool { return c.userID == userID })
+		return driver.RowsAffected(0), nil
+	case "INSERT INTO totp_backup_codes (user_id, code_hash) VALUES ($1, $2)":
+		f.codes = append(f.codes, &fakeBackupCode{
+			id:     len(f.codes) + 1,
+			userID: int(args[0].Value.(int64)),
+			hash:   []byte(args[1].Value.(string)),
+		})
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("fakeBackupCodeDB: unexpected exec %q", query)
+}
+
+func (f *fakeBackupCodeDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	userID := args[0].Value.(int64)
+	switch query {
+	case "SELECT id, code_hash FROM totp_backup_codes WHERE user_id = $1 AND used_at IS NULL":
+		rows := &fakeRows{cols: []string{"id", "code_hash"}}
+		for _, c := range f.codes {
+			if int64(c.userID) == userID && !c.used {
+				rows.rows = append(rows.rows, []driver.Value{int64(c.id), c.hash})
+			}
+		}
+		return rows, nil
+	case "SELECT count(*) FROM totp_backup_codes WHERE user_id = $1 AND used_at IS NULL":
+		n := 0
+		for _, c := range f.codes {
+			if int64(c.userID) == userID && !c.used {
+				n++
+			}
+		}
+		return &fakeRows{cols: []string{"count"}, rows: [][]driver.Value{{int64(n)}}}, nil
+	}
+	return nil, fmt.Errorf("fakeBackupCodeDB: unexpected query %q", query)
+}
+
+// useBackupCodes stores fresh backup codes for userID in a fake database
+// behind s and returns them in plain text.
+func useBackupCodes(t *testing.T, s *Server, userID int) (*fakeBackupCodeDB, []string) {
+	t.Helper()
+
+	codes, err := generateBackupCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes, err := s.hashBackupCodes(context.Background(), codes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeBackupCodeDB{}
+	for i, hash := range hashes {
+		fake.codes = append(fake.codes, &fakeBackupCode{id: i + 1, userID: userID, hash: hash})
+	}
+
+	s.db = sql.OpenDB(fake)
+	t.Cleanup(func() { s.db.Close() })
+	return fake, codes
+}
+
+func TestRedeemBackupCodeTwice(t *testing.T) {
+	s, _ := newTestServer(t)
+	_, codes := useBackupCodes(t, s, 42)
+	ctx := context.Background()
+
+	// Typed as printed, in capitals with a dash.
+	typed := strings.ToUpper(codes[3][:4] + "-" + codes[3][4:])
+	ok, remaining, err := s.redeemBackupCode(ctx, 42, typed)
+	if err != nil || !ok || remaining != backupCodeCount-1 {
+		t.Fatalf("first use: ok %v, remaining %d, err %v", ok, remaining, err)
+	}
+
+	ok, remaining, err = s.redeemBackupCode(ctx, 42, codes[3])
+	if err != nil || ok || remaining != backupCodeCount-1 {
+		t.Fatalf("second use: ok %v, remaining %d, err %v; want rejected", ok, remaining, err)
+	}
+
+	if ok, _, err := s.redeemBackupCode(ctx, 7, codes[4]); err != nil || ok {
+		t.Errorf("another user's code: ok %v, err %v", ok, err)
+	}
+	if ok, _, err := s.redeemBackupCode(ctx, 42, "short"); err != nil || ok {
+		t.Errorf("malformed code: ok %v, err %v", ok, err)
+	}
+}
+
+// Two logins racing with the same code: both may match the hash, but only
+// one gets through the conditional update.
+func TestRedeemBackupCodeConcurrently(t *testing.T) {
+	s, _ := newTestServer(t)
+	fake, codes := useBackupCodes(t, s, 42)
+
+	const racers = 8
+	var wg sync.WaitGroup
+	results := make(chan bool, racers)
+	for range racers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, _, err := s.redeemBackupCode(context.Background(), 42, codes[0])
+			if err != nil {
+				t.Error(err)
+			}
+			results <- ok
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	wins := 0
+	for ok := range results {
+		if ok {
+			wins++
+		}
+	}
+	if wins != 1 {
+		t.Errorf("%d logins redeemed the same code, want 1", wins)
+	}
+	if !fake.codes[0].used {
+		t.Error("the code wasn't marked used")
+	}
+}
+
+// TestTwoFactorSetup turns TOTP on: the secret from /2fa/setup only takes
+// effect once /2fa/confirm is given a code from it.
+func TestTwoFactorSetup(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	fake := &fakeBackupCodeDB{}
+	s.db = sql.OpenDB(fake)
+	t.Cleanup(func() { s.db.Close() })
+	h := s.Handler()
+	user := newTestUser(t, s, "a@example.com", "user")
+	cookie := accessTokenCookie(t, s, user)
+
+	rec := postJSON(h, "/v1/2fa/setup", "", cookie)
+	var setup twoFactorSetupResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &setup) != nil || setup.Secret == "" {
+		t.Fatalf("setup: %d %s", rec.Code, rec.Body)
+	}
+	if setup.ExpiresIn != int(totpPendingTTL.Seconds()) || !strings.Contains(setup.OTPAuthURL, "secret="+setup.Secret) {
+		t.Errorf("setup response %+v", setup)
+	}
+	if len(fake.totpSecrets) != 0 || len(fake.codes) != 0 {
+		t.Fatal("setup turned TOTP on before it was confirmed")
+	}
+
+	rec = postJSON(h, "/v1/2fa/confirm", `{"code":"000000"}`, cookie)
+	if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "invalid_code" {
+		t.Errorf("wrong code: %q, want invalid_code", env.Error.Code)
+	}
+	if len(fake.totpSecrets) != 0 || !mr.Exists(totpPendingKey(user.ID)) {
+		t.Fatal("a wrong code should leave the secret pending and TOTP off")
+	}
+
+	rec = postJSON(h, "/v1/2fa/confirm", `{"code":"`+currentTOTP(t, s, setup.Secret)+`"}`, cookie)
+	var confirmed struct {
+		BackupCodes []string `json:"backup_codes"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &confirmed) != nil {
+		t.Fatalf("confirm: %d %s", rec.Code, rec.Body)
+	}
+	if len(confirmed.BackupCodes) != backupCodeCount || len(fake.codes) != backupCodeCount {
+		t.Errorf("%d backup codes returned, %d stored; want %d", len(confirmed.BackupCodes), len(fake.codes), backupCodeCount)
+	}
+	if fake.totpSecrets[user.ID] != setup.Secret {
+		t.Error("confirming didn't turn TOTP on with the pending secret")
+	}
+
+	// The secret was used up.
+	rec = postJSON(h, "/v1/2fa/confirm", `{"code":"`+currentTOTP(t, s, setup.Secret)+`"}`, cookie)
+	if env := readEnvelope(t, rec, http.StatusConflict); env.Error.Code != "totp_setup_expired" {
+		t.Errorf("confirmed twice: %q, want totp_setup_expired", env.Error.Code)
+	}
+}
+
+func TestTwoFactorSetupExpires(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	h := s.Handler()
+	user := newTestUser(t, s, "a@example.com", "user")
+	cookie := accessTokenCookie(t, s, user)
+
+	rec := postJSON(h, "/v1/2fa/setup", "", cookie)
+	var setup twoFactorSetupResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &setup) != nil {
+		t.Fatalf("setup: %d %s", rec.Code, rec.Body)
+	}
+	mr.FastForward(totpPendingTTL + time.Second)
+	rec = postJSON(h, "/v1/2fa/confirm", `{"code":"`+currentTOTP(t, s, setup.Secret)+`"}`, cookie)
+	if env := readEnvelope(t, rec, http.StatusConflict); env.Error.Code != "totp_setup_expired" {
+		t.Errorf("after expiry: %q, want totp_setup_expired", env.Error.Code)
+	}
+}
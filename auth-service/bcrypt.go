@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/crypto/bcrypt"
+
+	"resilient-auth-service/apperror"
+)
+
+var bcryptBuckets = []float64{.05, .075, .1, .15, .2, .3, .5, 1}
+
+var bcryptHashDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "bcrypt_hash_duration_seconds",
+	Help:    "Time spent hashing a password with the current algorithm.",
+	Buckets: bcryptBuckets,
+})
+
+var bcryptCompareDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "bcrypt_compare_duration_seconds",
+	Help:    "Time spent verifying a password against its stored hash.",
+	Buckets: bcryptBuckets,
+})
+
+var bcryptQueueWait = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "bcrypt_queue_wait_duration_seconds",
+	Help:    "Time a password hashing job waited for a free worker.",
+	Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+})
+
+var bcryptInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "bcrypt_in_flight",
+	Help: "Password hashing operations running on a worker.",
+})
+
+var bcryptQueued = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "bcrypt_queued",
+	Help: "Password hashing operations waiting for a free worker.",
+})
+
+var bcryptRejectedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "bcrypt_rejected_total",
+	Help: "Password hashing operations refused because the queue was full or the wait timed out.",
+})
+
+// errBcryptBusy means the pool is saturated: the queue was full, or the
+// job waited longer than the queue timeout. Handlers answer it with
+// writeServerBusy.
+var errBcryptBusy = errors.New("bcrypt pool busy")
+
+// writeServerBusy answers a request refused with errBcryptBusy.
+func writeServerBusy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", "1")
+	apperror.WriteError(w, r, apperror.Unavailable("server_busy", "Server busy, try again shortly"))
+}
+
+// bcryptMaxPasswordLen is the most bcrypt looks at; it ignores any bytes
+// past it. It bounds passwords whatever the current algorithm, so a
+// deployment can always switch back to bcrypt.
+const bcryptMaxPasswordLen = 72
+
+// BcryptWorkerPool runs password hashing on a fixed number of goroutines.
+// It is named for bcrypt, which it was written for, but runs whichever
+// Hasher is current, and verifies hashes from any of them. Password
+// hashing is deliberately expensive, so without a bound a burst of logins
+// would starve every other request; with one, the burst queues instead,
+// and bcrypt_queue_wait_duration_seconds shows when the pool is too small
+// for the configured cost.
+//
+// The queue is bounded too: at most maxQueue jobs wait, each for at most
+// queueTimeout, and the rest fail fast with errBcryptBusy. Otherwise a
+// long enough burst would only grow latency until every client timed out
+// anyway.
+type BcryptWorkerPool struct {
+	hasher       Hasher
+	maxQueue     int64
+	queueTimeout time.Duration
+	jobs         chan bcryptJob
+	queued       atomic.Int64
+	wg           sync.WaitGroup
+	// dummyHash is made on first use, with the pool's hasher, for
+	// CompareDummy.
+	dummyHash func() string
+}
+
+type bcryptJob struct {
+	enqueued time.Time
+	run      func()
+}
+
+func NewBcryptWorkerPool(workers int, hasher Hasher, maxQueue int, queueTimeout time.Duration) *BcryptWorkerPool {
+	p := &BcryptWorkerPool{
+		hasher:       hasher,
+		maxQueue:     int64(maxQueue),
+		queueTimeout: queueTimeout,
+		jobs:         make(chan bcryptJob),
+	}
+	p.dummyHash = sync.OnceValue(func() string {
+		hash, _ := hasher.Hash("dummy password")
+		return hash
+	})
+	p.wg.Add(workers)
+	for range workers {
+		go p.worker()
+	}
+	return p
+}
+
+func (p *BcryptWorkerPool) worker() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		bcryptQueueWait.Observe(time.Since(job.enqueued).Seconds())
+		bcryptInFlight.Inc()
+		job.run()
+		bcryptInFlight.Dec()
+	}
+}
+
+// do runs fn on a worker and waits for it. If ctx ends first do returns
+// ctx.Err(); a job a worker already picked up still runs to completion.
+// A job that can't be queued, or isn't picked up within the queue timeout,
+// fails with errBcryptBusy.
+func (p *BcryptWorkerPool) do(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	job := bcryptJob{enqueued: time.Now(), run: func() { done <- fn() }}
+
+	if err := p.enqueue(ctx, job); err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *BcryptWorkerPool) enqueue(ctx context.Context, job bcryptJob) error {
+	// A free worker takes the job straight away, without queueing.
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+	}
+
+	if p.queued.Add(1) > p.maxQueue {
+		p.queued.Add(-1)
+		bcryptRejectedTotal.Inc()
+		return errBcryptBusy
+	}
+	bcryptQueued.Inc()
+	defer func() {
+		p.queued.Add(-1)
+		bcryptQueued.Dec()
+	}()
+
+	timer := time.NewTimer(p.queueTimeout)
+	defer timer.Stop()
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		bcryptRejectedTotal.Inc()
+		return errBcryptBusy
+	}
+}
+
+func (p *BcryptWorkerPool) HashPassword(ctx context.Context, password string) ([]byte, error) {
+	var hash []byte
+	err := p.do(ctx, func() error {
+		start := time.Now()
+		defer func() { bcryptHashDuration.Observe(time.Since(start).Seconds()) }()
+
+		h, err := p.hasher.Hash(password)
+		hash = []byte(h)
+		return err
+	})
+	if err != nil {
+		// If ctx ended first, the worker may still be writing hash.
+		return nil, err
+	}
+	return hash, nil
+}
+
+// errInvalidPasswordHash means a stored hash is empty or wasn't made by
+// any Hasher.
+var errInvalidPasswordHash = errors.New("invalid password hash")
+
+// ComparePassword verifies password with whichever Hasher made hash. It
+// returns nil on a match and errPasswordMismatch on a mismatch. Passwords
+// longer than bcrypt can check never match (bcrypt.ErrPasswordTooLong):
+// otherwise anything sharing the first 72 bytes of a long password would.
+// Nor does an empty or malformed hash (errInvalidPasswordHash); that check
+// still costs a full compare, so it looks like any other mismatch from
+// outside.
+func (p *BcryptWorkerPool) ComparePassword(ctx context.Context, hash []byte, password string) error {
+	if len(password) > bcryptMaxPasswordLen {
+		return bcrypt.ErrPasswordTooLong
+	}
+	h, err := ParseHasher(string(hash))
+	if err != nil {
+		if err := p.CompareDummy(ctx, password); err != nil {
+			return err
+		}
+		return errInvalidPasswordHash
+	}
+	return p.do(ctx, func() error {
+		start := time.Now()
+		defer func() { bcryptCompareDuration.Observe(time.Since(start).Seconds()) }()
+
+		return h.Verify(password, string(hash))
+	})
+}
+
+// NeedsRehash reports whether hash, which a password just matched, was
+// made by another algorithm or with other parameters than the pool's
+// hasher, so the password should be hashed again while it's at hand.
+func (p *BcryptWorkerPool) NeedsRehash(hash []byte) bool {
+	h, err := ParseHasher(string(hash))
+	return err == nil && h != p.hasher
+}
+
+// CompareDummy does the work of a ComparePassword that fails, for when
+// there is no user to compare against: a login for an unknown email then
+// takes as long as one with a wrong password, so timing doesn't reveal
+// which emails are registered. It returns only do's errors (ctx's or
+// errBcryptBusy), never the mismatch; a busy pool must get the same
+// answer for unknown users as for real ones.
+func (p *BcryptWorkerPool) CompareDummy(ctx context.Context, password string) error {
+	err := p.do(ctx, func() error {
+		return p.hasher.Verify(password, p.dummyHash())
+	})
+	if errors.Is(err, errPasswordMismatch) {
+		return nil
+	}
+	return err
+}
+
+// Close stops the workers once queued jobs are done. Callers must not
+// submit work after Close.
+func (p *BcryptWorkerPool) Close() {
+	close(p.jobs)
+	p.wg.Wait()
+}
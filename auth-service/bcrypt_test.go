@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/crypto/bcrypt"
+
+	"resilient-auth-service/flags"
+)
+
+// The truncation bug: bcrypt only reads 72 bytes, so a long password and
+// any other sharing its first 72 bytes verified as each other. Neither
+// may authenticate now, whichever hasher made the stored hash.
+func TestComparePasswordRefusesMismatchedTails(t *testing.T) {
+	prefix := strings.Repeat("correct-horse-battery-staple-", 3)[:bcryptMaxPasswordLen]
+	for name, hasher := range testHashers {
+		t.Run(name, func(t *testing.T) {
+			pool := NewBcryptWorkerPool(1, hasher, 10, time.Second)
+			defer pool.Close()
+			ctx := context.Background()
+
+			hash, err := pool.HashPassword(ctx, prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := pool.ComparePassword(ctx, hash, prefix); err != nil {
+				t.Fatalf("the 72-byte password itself: %v", err)
+			}
+			for _, tail := range []string{"X", "Y", strings.Repeat("Z", 1000)} {
+				err := pool.ComparePassword(ctx, hash, prefix+tail)
+				if !errors.Is(err, bcrypt.ErrPasswordTooLong) {
+					t.Errorf("72 bytes plus %d more: got %v, want bcrypt.ErrPasswordTooLong", len(tail), err)
+				}
+			}
+		})
+	}
+}
+
+// Over-long passwords are refused by validation, before any hashing or
+// database work, on every endpoint that takes one.
+func TestLongPasswordsRefused(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+
+	long := strings.Repeat("x", bcryptMaxPasswordLen+1)
+	for _, tt := range []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+	}{
+		{"login", s.loginHandler, `{"email":"a@example.com","password":"` + long + `"}`},
+		{"register", s.registerHandler, `{"email":"a@example.com","password":"` + long + `"}`},
+		{"reset", s.resetPasswordHandler, `{"token":"t","password":"` + long + `"}`},
+		{"invitation sign-up", s.signUpWithOrgInvitationHandler, `{"password":"` + long + `"}`},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		tt.handler(rec, r)
+
+		env := readEnvelope(t, rec, http.StatusUnprocessableEntity)
+		if len(env.Error.Fields) != 1 || env.Error.Fields[0].Field != "password" || env.Error.Fields[0].Code != "too_long" {
+			t.Errorf("%s: got %+v, want password too_long", tt.name, env.Error)
+		}
+	}
+}
+
+// histogramSnapshot reads h's current sample count and buckets.
+func histogramSnapshot(t testing.TB, h prometheus.Histogram) *dto.Histogram {
+	t.Helper()
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram()
+}
+
+// bucketQuantile returns the upper bound of the bucket holding quantile q
+// of the samples observed between two snapshots, +Inf if it is past the
+// last bucket and NaN if there were none.
+func bucketQuantile(q float64, before, after *dto.Histogram) float64 {
+	total := after.GetSampleCount() - before.GetSampleCount()
+	if total == 0 {
+		return math.NaN()
+	}
+	for i, b := range after.GetBucket() {
+		n := b.GetCumulativeCount() - before.GetBucket()[i].GetCumulativeCount()
+		if float64(n) >= q*float64(total) {
+			return b.GetUpperBound()
+		}
+	}
+	return math.Inf(1)
+}
+
+func TestBcryptHistograms(t *testing.T) {
+	pool := NewBcryptWorkerPool(1, BcryptHasher{Cost: bcrypt.MinCost}, 10, time.Second)
+	defer pool.Close()
+	ctx := context.Background()
+	histograms := map[string]prometheus.Histogram{
+		"hash": bcryptHashDuration, "compare": bcryptCompareDuration, "queue wait": bcryptQueueWait,
+	}
+	before := map[string]*dto.Histogram{}
+	for name, h := range histograms {
+		before[name] = histogramSnapshot(t, h)
+	}
+
+	hash, err := pool.HashPassword(ctx, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.ComparePassword(ctx, []byte(hash), "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]uint64{"hash": 1, "compare": 1, "queue wait": 2} {
+		got := histogramSnapshot(t, histograms[name]).GetSampleCount() - before[name].GetSampleCount()
+		if got != want {
+			t.Errorf("%s: %d samples, want %d", name, got, want)
+		}
+	}
+}
+
+// BenchmarkBcryptPool verifies passwords at the default cost from as many
+// goroutines as the benchmark runs, through a pool sized like production's
+// default, and fails if the compare p99 reaches 300ms. It reports the p99
+// compare time and queue wait read back from the histograms.
+func BenchmarkBcryptPool(b *testing.B) {
+	pool := NewBcryptWorkerPool(runtime.NumCPU(), BcryptHasher{Cost: bcrypt.DefaultCost}, 10000, time.Minute)
+	defer pool.Close()
+	ctx := context.Background()
+	hash, err := pool.HashPassword(ctx, "correct horse")
+	if err != nil {
+		b.Fatal(err)
+	}
+	compareBefore := histogramSnapshot(b, bcryptCompareDuration)
+	waitBefore := histogramSnapshot(b, bcryptQueueWait)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := pool.ComparePassword(ctx, []byte(hash), "correct horse"); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+	b.StopTimer()
+
+	compareP99 := bucketQuantile(0.99, compareBefore, histogramSnapshot(b, bcryptCompareDuration))
+	waitP99 := bucketQuantile(0.99, waitBefore, histogramSnapshot(b, bcryptQueueWait))
+	b.ReportMetric(compareP99, "p99-compare-s")
+	b.ReportMetric(waitP99, "p99-queue-wait-s")
+	if compareP99 > 0.3 {
+		b.Errorf("compare p99 in the %vs bucket, want under 300ms", compareP99)
+	}
+}
+
+// blockingHasher is a BcryptHasher whose Hash waits until release is
+// closed, to hold a pool's worker busy.
+type blockingHasher struct {
+	BcryptHasher
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h blockingHasher) Hash(password string) (string, error) {
+	h.started <- struct{}{}
+	<-h.release
+	return h.BcryptHasher.Hash(password)
+}
+
+// occupiedPool returns a one-worker pool whose worker is busy until the
+// returned release function is called. Cleanup releases and closes it.
+func occupiedPool(t *testing.T, maxQueue int, queueTimeout time.Duration) (*BcryptWorkerPool, func()) {
+	t.Helper()
+	hasher := blockingHasher{BcryptHasher{Cost: bcrypt.MinCost}, make(chan struct{}), make(chan struct{})}
+	pool := NewBcryptWorkerPool(1, hasher, maxQueue, queueTimeout)
+	done := make(chan error, 1)
+	go func() {
+		// With no queue the job is refused until the worker is waiting.
+		for {
+			_, err := pool.HashPassword(context.Background(), "correct horse")
+			if !errors.Is(err, errBcryptBusy) {
+				done <- err
+				return
+			}
+		}
+	}()
+	<-hasher.started
+	release := sync.OnceFunc(func() {
+		close(hasher.release)
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+		pool.Close()
+	})
+	t.Cleanup(release)
+	return pool, release
+}
+
+// TestBcryptPoolSheds fills a one-worker pool with a queue of two. A
+// third waiting job is refused at once, the queued ones are refused when
+// the queue timeout passes, and the gauges follow along.
+func TestBcryptPoolSheds(t *testing.T) {
+	inFlight := testutil.ToFloat64(bcryptInFlight)
+	queued := testutil.ToFloat64(bcryptQueued)
+	rejected := testutil.ToFloat64(bcryptRejectedTotal)
+	pool, release := occupiedPool(t, 2, 200*time.Millisecond)
+	ctx := context.Background()
+
+	if got := testutil.ToFloat64(bcryptInFlight) - inFlight; got != 1 {
+		t.Errorf("bcrypt_in_flight rose by %v, want 1", got)
+	}
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := pool.HashPassword(ctx, "correct horse")
+			errs <- err
+		}()
+	}
+	for deadline := time.Now().Add(5 * time.Second); testutil.ToFloat64(bcryptQueued)-queued < 2; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the jobs never queued")
+		}
+	}
+
+	start := time.Now()
+	if _, err := pool.HashPassword(ctx, "correct horse"); !errors.Is(err, errBcryptBusy) {
+		t.Errorf("past the queue limit: %v, want errBcryptBusy", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("refused after %s, want at once", elapsed)
+	}
+	for range 2 {
+		if err := <-errs; !errors.Is(err, errBcryptBusy) {
+			t.Errorf("past the queue timeout: %v, want errBcryptBusy", err)
+		}
+	}
+	if got := testutil.ToFloat64(bcryptRejectedTotal) - rejected; got != 3 {
+		t.Errorf("bcrypt_rejected_total rose by %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(bcryptQueued) - queued; got != 0 {
+		t.Errorf("bcrypt_queued is %v above where it started", got)
+	}
+
+	release()
+	if got := testutil.ToFloat64(bcryptInFlight) - inFlight; got != 0 {
+		t.Errorf("bcrypt_in_flight is %v above where it started", got)
+	}
+}
+
+// TestHashPasswordCancelled gives up on a hash the worker is still
+// computing, and lets the worker finish at the same time. HashPassword
+// returns either the hash or the context's error with no hash, and
+// doesn't race with the worker (go test -race).
+func TestHashPasswordCancelled(t *testing.T) {
+	hasher := blockingHasher{BcryptHasher{Cost: bcrypt.MinCost}, make(chan struct{}), make(chan struct{})}
+	pool := NewBcryptWorkerPool(1, hasher, 1, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	type result struct {
+		hash []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		hash, err := pool.HashPassword(ctx, "correct horse")
+		done <- result{hash, err}
+	}()
+	<-hasher.started
+	cancel()
+	// Let the worker finish while HashPassword returns, not after it.
+	close(hasher.release)
+	res := <-done
+	pool.Close()
+	switch {
+	case res.err == nil:
+		// The hash was done before the cancellation was noticed.
+		if err := hasher.Verify("correct horse", string(res.hash)); err != nil {
+			t.Errorf("hash %q: %v", res.hash, err)
+		}
+	case res.hash != nil || !errors.Is(res.err, context.Canceled):
+		t.Errorf("got %q, %v, want no hash and context.Canceled", res.hash, res.err)
+	}
+}
+
+// TestServerBusy saturates the pool: register and login, for a known or
+// an unknown email alike, answer 503 server_busy with Retry-After.
+func TestServerBusy(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	newTestUser(t, s, "a@example.com", "user")
+	saved := s.passwordHasher
+	s.passwordHasher, _ = occupiedPool(t, 0, time.Second)
+	t.Cleanup(func() { s.passwordHasher = saved })
+	h := s.Handler()
+
+	for _, tt := range []struct{ name, path, body string }{
+		{"login", "/v1/login", `{"email":"a@example.com","password":"correct horse"}`},
+		{"login, unknown email", "/v1/login", `{"email":"b@example.com","password":"correct horse"}`},
+		{"register", "/v1/register", `{"email":"c@example.com","password":"a long enough passphrase"}`},
+	} {
+		rec := postJSON(h, tt.path, tt.body)
+		if env := readEnvelope(t, rec, http.StatusServiceUnavailable); env.Error.Code != "server_busy" {
+			t.Errorf("%s: code %q, want server_busy", tt.name, env.Error.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("%s: Retry-After %q, want 1", tt.name, got)
+		}
+	}
+}
+
+// BenchmarkHealthDuringLoginFlood times GET /health with the service
+// idle and during a flood of logins at bcrypt's default cost, from four
+// clients per CPU. The pool bounds bcrypt to one worker per CPU and sheds
+// the excess, so /health only competes with those workers, not with
+// every login. Compare the two p99s; on a single CPU the one worker still
+// takes turns with /health.
+func BenchmarkHealthDuringLoginFlood(b *testing.B) {
+	quietLog(b)
+	s, _ := newTestServer(b)
+	s.passwordHasher.Close()
+	s.passwordHasher = NewBcryptWorkerPool(runtime.NumCPU(), BcryptHasher{Cost: bcrypt.DefaultCost}, 4*runtime.NumCPU(), 2*time.Second)
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1_000_000, time.Minute)
+	if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagDeviceTrust}); err != nil {
+		b.Fatal(err)
+	}
+	newTestUser(b, s, "a@example.com", "user")
+	h := s.Handler()
+
+	health := func(b *testing.B) {
+		var latencies []time.Duration
+		for b.Loop() {
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+			latencies = append(latencies, time.Since(start))
+			if rec.Code != http.StatusOK {
+				b.Fatalf("/health: %d", rec.Code)
+			}
+		}
+		slices.Sort(latencies)
+		b.ReportMetric(latencies[len(latencies)*99/100].Seconds(), "p99-health-s")
+	}
+
+	b.Run("idle", health)
+	b.Run("login flood", func(b *testing.B) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		for range 4 * runtime.NumCPU() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for ctx.Err() == nil {
+					postJSON(h, "/v1/login", `{"email":"a@example.com","password":"correct horse"}`)
+				}
+			}()
+		}
+		health(b)
+		cancel()
+		wg.Wait()
+	})
+}
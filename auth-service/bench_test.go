@@ -0,0 +1,109 @@
+//go:build loadtest
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+
+	"resilient-auth-service/flags"
+)
+
+// The end-to-end benchmark only builds with the loadtest tag:
+//
+//	go test -tags loadtest -run TestBenchmarkEndToEnd -bench-rate 200 -bench-duration 30s
+//
+// It attacks the router through a real listener, so every middleware is in
+// the path, with Redis in process and the fake refresh-token database.
+// Passwords are hashed at bcrypt's minimum cost, so it catches middleware
+// and query regressions, not a slower hasher; cmd/loadtest is for a real
+// deployment.
+var (
+	benchRate     = flag.Int("bench-rate", 100, "requests per second for each target")
+	benchDuration = flag.Duration("bench-duration", 10*time.Second, "how long to attack each target")
+)
+
+const (
+	benchMaxLoginP99  = 300 * time.Millisecond
+	benchMinSuccesses = 0.99
+)
+
+func TestBenchmarkEndToEnd(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	useFakeRefreshDB(t, s)
+	// All the traffic comes from one address and logs in from one device.
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1_000_000, time.Minute)
+	if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagDeviceTrust}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	user := newTestUser(t, s, "bench@example.com", "user")
+	cookie := accessTokenCookie(t, s, user)
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	targets := []struct {
+		name   string
+		target vegeta.Target
+		maxP99 time.Duration
+	}{
+		{"login", vegeta.Target{
+			Method: http.MethodPost,
+			URL:    srv.URL + "/v1/login",
+			Body:   []byte(`{"email":"bench@example.com","password":"correct horse"}`),
+			Header: jsonHeader,
+		}, benchMaxLoginP99},
+		// Validating the access token is what every authenticated request
+		// pays; no limit is asserted, the numbers are for comparing runs.
+		{"me", vegeta.Target{
+			Method: http.MethodGet,
+			URL:    srv.URL + "/v2/me",
+			Header: http.Header{"Cookie": {cookie.String()}},
+		}, 0},
+	}
+
+	results := map[string]*vegeta.Metrics{}
+	for _, tt := range targets {
+		attacker := vegeta.NewAttacker(vegeta.Client(srv.Client()))
+		rate := vegeta.Rate{Freq: *benchRate, Per: time.Second}
+		var m vegeta.Metrics
+		for res := range attacker.Attack(vegeta.NewStaticTargeter(tt.target), rate, *benchDuration, tt.name) {
+			m.Add(res)
+		}
+		m.Close()
+		results[tt.name] = &m
+
+		t.Logf("%s: %d requests, p50 %s, p95 %s, p99 %s, success %.2f%%",
+			tt.name, m.Requests, m.Latencies.P50, m.Latencies.P95, m.Latencies.P99, m.Success*100)
+		if m.Success < benchMinSuccesses {
+			t.Errorf("%s: success rate %.2f%%, want at least %.0f%%; status codes %v, errors %v",
+				tt.name, m.Success*100, benchMinSuccesses*100, m.StatusCodes, m.Errors)
+		}
+		if tt.maxP99 > 0 && m.Latencies.P99 > tt.maxP99 {
+			t.Errorf("%s: p99 latency %s, want under %s", tt.name, m.Latencies.P99, tt.maxP99)
+		}
+	}
+
+	// Kept in testdata, which ignores bench-*.json, so runs can be
+	// compared.
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join("testdata", "bench-"+time.Now().UTC().Format("20060102T150405Z")+".json")
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("results in %s", file)
+}
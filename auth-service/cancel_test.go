@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestCancelledRequest sends requests whose client has already gone away:
+// the handlers give up without answering, writing to Redis or counting a
+// failed login.
+func TestCancelledRequest(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	user := newTestUser(t, s, "a@example.com", "user")
+	sessionID, err := s.createSession(context.Background(), user.Email, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reached := false
+	protected := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true }))
+	login := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(body)).WithContext(ctx)
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+	session := httptest.NewRequest(http.MethodGet, "/v1/me/sessions", nil).WithContext(ctx)
+	session.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+
+	for _, tt := range []struct {
+		name string
+		h    http.Handler
+		r    *http.Request
+	}{
+		{"login", http.HandlerFunc(s.loginHandler), login(`{"email":"a@example.com","password":"correct horse"}`)},
+		{"login, unknown user", http.HandlerFunc(s.loginHandler), login(`{"email":"b@example.com","password":"correct horse"}`)},
+		{"session", protected, session},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := mr.Keys()
+			commands := mr.CommandCount()
+			failures := testutil.ToFloat64(loginFailuresTotal.WithLabelValues("unknown_user"))
+			rec := httptest.NewRecorder()
+			tt.h.ServeHTTP(rec, tt.r)
+			if rec.Body.Len() != 0 || len(rec.Header()) != 0 {
+				t.Errorf("answered %d %v %s", rec.Code, rec.Header(), rec.Body)
+			}
+			if got := mr.CommandCount(); got != commands {
+				t.Errorf("%d Redis commands sent", got-commands)
+			}
+			if got := mr.Keys(); strings.Join(got, " ") != strings.Join(keys, " ") {
+				t.Errorf("Redis keys went from %v to %v", keys, got)
+			}
+			if got := testutil.ToFloat64(loginFailuresTotal.WithLabelValues("unknown_user")); got != failures {
+				t.Error("counted as a failed login")
+			}
+		})
+	}
+	if reached {
+		t.Error("the handler ran for a cancelled request")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+// Captcha providers, for cfg.CaptchaProvider.
+const (
+	captchaReCAPTCHA = "recaptcha"
+	captchaHCaptcha  = "hcaptcha"
+)
+
+// errCaptchaRejected means the provider checked the token and refused it.
+// Any other Verify error means it couldn't be checked.
+var errCaptchaRejected = errors.New("captcha rejected")
+
+// CaptchaVerifier checks the token a CAPTCHA widget gave the client.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// newCaptchaVerifier picks the verifier named by CAPTCHA_PROVIDER.
+func newCaptchaVerifier(cfg Config) (CaptchaVerifier, error) {
+	switch cfg.CaptchaProvider {
+	case "":
+		return nil, nil
+	case captchaReCAPTCHA:
+		return NewSiteVerifyCaptcha("https://www.google.com/recaptcha/api/siteverify", cfg.CaptchaSecret, cfg.CaptchaTimeout), nil
+	case captchaHCaptcha:
+		return NewSiteVerifyCaptcha("https://api.hcaptcha.com/siteverify", cfg.CaptchaSecret, cfg.CaptchaTimeout), nil
+	}
+	return nil, errors.New("unknown CAPTCHA_PROVIDER " + cfg.CaptchaProvider)
+}
+
+// SiteVerifyCaptcha verifies tokens with a siteverify endpoint. reCAPTCHA
+// and hCaptcha share the protocol: a form POST of the secret, the token
+// and the client's IP, answered with {"success": bool, "error-codes": [...]}.
+type SiteVerifyCaptcha struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewSiteVerifyCaptcha(verifyURL, secret string, timeout time.Duration) *SiteVerifyCaptcha {
+	return &SiteVerifyCaptcha{
+		url:    verifyURL,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *SiteVerifyCaptcha) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{"secret": {c.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha verify: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("captcha verify: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", errCaptchaRejected, strings.Join(result.ErrorCodes, ","))
+	}
+	return nil
+}
+
+// verifyCaptcha checks a registration's CAPTCHA token, if CAPTCHA is on,
+// before any work is spent on the request. It reports whether the request
+// may go on; otherwise it has answered it. A rejected or missing token is
+// the client's problem; a provider that can't be reached is ours, and gets
+// a 503 so the client retries instead of showing the user an error about
+// their input. Either way the registration is refused: failing open would
+// let bots through whenever the provider is down. Synthetic checks have no
+// widget to solve and skip it.
+func (s *Server) verifyCaptcha(w http.ResponseWriter, r *http.Request, token string) bool {
+	if s.captchaVerifier == nil || isSynthetic(r.Context()) {
+		return true
+	}
+	if token == "" {
+		var v validator
+		v.required("captcha_token", token)
+		apperror.WriteError(w, r, v.err())
+		return false
+	}
+
+	err := s.captchaVerifier.Verify(r.Context(), token, s.realIP(r))
+	if errors.Is(err, errCaptchaRejected) {
+		log.Printf("captcha rejected request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		apperror.WriteError(w, r, apperror.BadRequest("captcha_invalid", "CAPTCHA verification failed"))
+		return false
+	}
+	if err != nil {
+		log.Printf("captcha verify error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		w.Header().Set("Retry-After", "1")
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// captchaFunc adapts a function to CaptchaVerifier.
+type captchaFunc func(ctx context.Context, token, remoteIP string) error
+
+func (f captchaFunc) Verify(ctx context.Context, token, remoteIP string) error {
+	return f(ctx, token, remoteIP)
+}
+
+// TestSiteVerifyCaptcha runs SiteVerifyCaptcha against a fake siteverify
+// endpoint. Only a refusal from the provider is errCaptchaRejected; a
+// provider that errors, answers garbage or is too slow is an outage.
+func TestSiteVerifyCaptcha(t *testing.T) {
+	var answer func(w http.ResponseWriter)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("secret") != "s3cret" || r.FormValue("remoteip") != "192.0.2.1" {
+			t.Errorf("request %s %v", r.Method, r.Form)
+		}
+		switch r.FormValue("response") {
+		case "good":
+			w.Write([]byte(`{"success":true}`))
+		case "bad":
+			w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		default:
+			answer(w)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	c := NewSiteVerifyCaptcha(srv.URL, "s3cret", 50*time.Millisecond)
+	ctx := context.Background()
+
+	if err := c.Verify(ctx, "good", "192.0.2.1"); err != nil {
+		t.Errorf("valid token: %v", err)
+	}
+	if err := c.Verify(ctx, "bad", "192.0.2.1"); !errors.Is(err, errCaptchaRejected) {
+		t.Errorf("invalid token: %v, want errCaptchaRejected", err)
+	}
+
+	for name, a := range map[string]func(w http.ResponseWriter){
+		"server error": func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+		"not JSON":     func(w http.ResponseWriter) { w.Write([]byte("<html>")) },
+		"timeout":      func(w http.ResponseWriter) { time.Sleep(200 * time.Millisecond) },
+	} {
+		answer = a
+		if err := c.Verify(ctx, "other", "192.0.2.1"); err == nil || errors.Is(err, errCaptchaRejected) {
+			t.Errorf("%s: %v, want an error other than errCaptchaRejected", name, err)
+		}
+	}
+}
+
+// TestRegisterCaptcha registers through the handler with a mock verifier:
+// a valid token goes through, a rejected one is a 400, a missing one a 422
+// and an unreachable provider a 503. With CAPTCHA off no token is needed.
+func TestRegisterCaptcha(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	h := s.Handler()
+	var verified []string
+	verifyErr := error(nil)
+	s.captchaVerifier = captchaFunc(func(_ context.Context, token, remoteIP string) error {
+		verified = append(verified, token+" "+remoteIP)
+		return verifyErr
+	})
+	register := func(email, token string) *httptest.ResponseRecorder {
+		body := `{"email":"` + email + `","password":"a long enough passphrase"`
+		if token != "" {
+			body += `,"captcha_token":"` + token + `"`
+		}
+		return postJSON(h, "/v1/register", body+"}")
+	}
+
+	if rec := register("a@example.com", "good"); rec.Code != http.StatusCreated {
+		t.Errorf("valid token: %d %s", rec.Code, rec.Body)
+	}
+	if len(verified) != 1 || verified[0] != "good 192.0.2.1" {
+		t.Errorf("verified %q, want the token and client IP", verified)
+	}
+
+	verifyErr = errCaptchaRejected
+	if env := readEnvelope(t, register("b@example.com", "bad"), http.StatusBadRequest); env.Error.Code != "captcha_invalid" {
+		t.Errorf("invalid token: code %q, want captcha_invalid", env.Error.Code)
+	}
+
+	verifyErr = context.DeadlineExceeded
+	rec := register("b@example.com", "good")
+	if env := readEnvelope(t, rec, http.StatusServiceUnavailable); env.Error.Code != "service_unavailable" {
+		t.Errorf("provider timeout: code %q, want service_unavailable", env.Error.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("provider timeout: no Retry-After")
+	}
+
+	verified = nil
+	if env := readEnvelope(t, register("b@example.com", ""), http.StatusUnprocessableEntity); env.Error.Code != "validation_failed" {
+		t.Errorf("missing token: code %q, want validation_failed", env.Error.Code)
+	}
+	if len(verified) != 0 {
+		t.Errorf("missing token was verified: %q", verified)
+	}
+	if _, err := s.users.GetByEmail(context.Background(), "b@example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("refused registration created a user: %v", err)
+	}
+
+	s.captchaVerifier = nil
+	if rec := register("c@example.com", ""); rec.Code != http.StatusCreated {
+		t.Errorf("captcha disabled: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestNewCaptchaVerifier(t *testing.T) {
+	for provider, want := range map[string]bool{"": false, captchaReCAPTCHA: true, captchaHCaptcha: true} {
+		v, err := newCaptchaVerifier(Config{CaptchaProvider: provider, CaptchaSecret: "s", CaptchaTimeout: time.Second})
+		if err != nil || (v != nil) != want {
+			t.Errorf("%q: %v, %v", provider, v, err)
+		}
+	}
+	if _, err := newCaptchaVerifier(Config{CaptchaProvider: "turnstile"}); err == nil {
+		t.Error("unknown provider accepted")
+	}
+}
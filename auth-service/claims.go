@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ClaimsEnricher adds application-specific claims (a tenant, a plan, ...)
+// to access tokens at issue time. claims starts empty; whatever the
+// enricher puts there is merged into the token, except reservedClaims.
+type ClaimsEnricher interface {
+	EnrichClaims(ctx context.Context, userID int, claims map[string]interface{}) error
+}
+
+// reservedClaims are set by signAccessToken and can't be overridden: an
+// enricher must not be able to change who a token is for or how long it
+// lives.
+var reservedClaims = map[string]bool{
+	"sub":       true,
+	"email":     true,
+	"iat":       true,
+	"exp":       true,
+	"auth_time": true,
+}
+
+// NoOpClaimsEnricher adds nothing.
+type NoOpClaimsEnricher struct{}
+
+func (NoOpClaimsEnricher) EnrichClaims(context.Context, int, map[string]interface{}) error {
+	return nil
+}
+
+// RoleClaimsEnricher adds the user's roles as "roles", so downstream
+// services can authorize without calling back here. Users have a single
+// role today; the claim is a list so that can change without breaking
+// consumers.
+type RoleClaimsEnricher struct {
+	db *sql.DB
+}
+
+func NewRoleClaimsEnricher(db *sql.DB) *RoleClaimsEnricher {
+	return &RoleClaimsEnricher{db: db}
+}
+
+func (e *RoleClaimsEnricher) EnrichClaims(ctx context.Context, userID int, claims map[string]interface{}) error {
+	var role string
+	err := e.db.QueryRowContext(ctx, "SELECT role FROM active_users WHERE id = $1", userID).Scan(&role)
+	if err != nil {
+		return fmt.Errorf("loading roles for user %d: %w", userID, err)
+	}
+	claims["roles"] = []string{role}
+	return nil
+}
+
+// newClaimsEnricher picks the enricher named by CLAIMS_ENRICHER.
+func newClaimsEnricher(name string, db *sql.DB) (ClaimsEnricher, error) {
+	switch name {
+	case "":
+		return NoOpClaimsEnricher{}, nil
+	case "roles":
+		return NewRoleClaimsEnricher(db), nil
+	}
+	return nil, errors.New("unknown CLAIMS_ENRICHER " + name)
+}
+
+// enrichClaims runs claimsEnricher and merges its claims into claims.
+func (s *Server) enrichClaims(ctx context.Context, userID int, claims map[string]interface{}) error {
+	extra := map[string]interface{}{}
+	if err := s.claimsEnricher.EnrichClaims(ctx, userID, extra); err != nil {
+		return err
+	}
+	for k, v := range extra {
+		if reservedClaims[k] {
+			log.Printf("claims enricher tried to set reserved claim %q; ignored", k)
+			continue
+		}
+		claims[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// claimsFunc adapts a function to ClaimsEnricher.
+type claimsFunc func(ctx context.Context, userID int, claims map[string]interface{}) error
+
+func (f claimsFunc) EnrichClaims(ctx context.Context, userID int, claims map[string]interface{}) error {
+	return f(ctx, userID, claims)
+}
+
+// issuedClaims signs an access token for user 42 and returns its claims.
+func issuedClaims(t *testing.T, s *Server) jwt.MapClaims {
+	t.Helper()
+	ctx := context.Background()
+	raw, err := s.signAccessToken(ctx, 42, "a@example.com", s.clock.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.Parse(raw, s.accessTokenKeyfunc(ctx),
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithTimeFunc(s.clock.Now),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token.Claims.(jwt.MapClaims)
+}
+
+// TestClaimsEnricher has an enricher add a tenant and try to take over the
+// subject and lifetime: the tenant is in the issued token, the standard
+// claims are untouched.
+func TestClaimsEnricher(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	s.claimsEnricher = claimsFunc(func(ctx context.Context, userID int, claims map[string]interface{}) error {
+		claims["tenant_id"] = "tenant-" + strconv.Itoa(userID)
+		claims["sub"] = "1"
+		claims["exp"] = s.clock.Now().Add(365 * 24 * time.Hour).Unix()
+		return nil
+	})
+	claims := issuedClaims(t, s)
+	if claims["tenant_id"] != "tenant-42" {
+		t.Errorf("tenant_id %v, want tenant-42", claims["tenant_id"])
+	}
+	if claims["sub"] != "42" {
+		t.Errorf("sub %v, want 42", claims["sub"])
+	}
+	if exp, _ := claims.GetExpirationTime(); !exp.Equal(s.clock.Now().Add(accessTokenTTL).Truncate(time.Second)) {
+		t.Errorf("exp %v, want accessTokenTTL from now", exp)
+	}
+}
+
+func TestRoleClaimsEnricher(t *testing.T) {
+	s, _ := newTestServer(t)
+	db := sql.OpenDB(&staticRowsDB{fakeRows{cols: []string{"role"}, rows: [][]driver.Value{{"admin"}}}})
+	t.Cleanup(func() { db.Close() })
+	enricher, err := newClaimsEnricher("roles", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.claimsEnricher = enricher
+	if roles := issuedClaims(t, s)["roles"]; !reflect.DeepEqual(roles, []any{"admin"}) {
+		t.Errorf("roles %v, want [admin]", roles)
+	}
+
+	if _, err := newClaimsEnricher("tenants", db); err == nil {
+		t.Error("an unknown CLAIMS_ENRICHER was accepted")
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"resilient-auth-service/apperror"
+)
+
+// fakeService answers /v1/login and /v2/me the way the service does.
+func fakeService(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/login", func(w http.ResponseWriter, r *http.Request) {
+		creds := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+			t.Errorf("login body: %v", err)
+		}
+		switch creds["email"] {
+		case "totp@example.com":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"flow_id":"f1","next_step":"totp","expires_in":300}`))
+		case "a@example.com":
+			http.SetCookie(w, &http.Cookie{Name: "auth_token", Value: "access"})
+			http.SetCookie(w, &http.Cookie{Name: "refresh_token", Value: "refresh"})
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "session"})
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("Logged in"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"code":"invalid_credentials","message":"Invalid email or password"}}`))
+		}
+	})
+	mux.HandleFunc("GET /v2/me", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("auth_token"); err != nil || c.Value != "access" {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>bad gateway</html>"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":7,"email":"a@example.com","created_at":"2024-01-02T03:04:05Z","roles":["user"]}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	srv := fakeService(t)
+	c := New(srv.URL+"/", srv.Client())
+	ctx := context.Background()
+
+	flow, err := c.Login(ctx, "totp@example.com", "pw")
+	if err != nil || flow.Flow == nil || flow.Flow.FlowId != "f1" || flow.Flow.NextStep != "totp" || flow.AccessToken != "" {
+		t.Errorf("login needing TOTP: %+v, %v", flow, err)
+	}
+
+	login, err := c.Login(ctx, "a@example.com", "pw")
+	if err != nil || login.Flow != nil || login.AccessToken != "access" || login.RefreshToken != "refresh" || login.SessionID != "session" {
+		t.Fatalf("login: %+v, %v", login, err)
+	}
+
+	me, err := c.GetCurrentUser(ctx, login.AccessToken)
+	if err != nil || me.Id != 7 || me.Email != "a@example.com" || len(me.Roles) != 1 {
+		t.Errorf("current user: %+v, %v", me, err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	srv := fakeService(t)
+	c := New(srv.URL, nil)
+	ctx := context.Background()
+
+	var e *apperror.Error
+	_, err := c.Login(ctx, "nobody@example.com", "pw")
+	if !errors.As(err, &e) || e.Status != http.StatusUnauthorized || e.Code != "invalid_credentials" || e.Message == "" {
+		t.Errorf("wrong password: %#v", err)
+	}
+
+	// A body that isn't the Error envelope, as from a proxy.
+	_, err = c.GetCurrentUser(ctx, "expired")
+	if !errors.As(err, &e) || e.Status != http.StatusBadGateway || e.Code != "unexpected_response" {
+		t.Errorf("proxy error: %#v", err)
+	}
+}
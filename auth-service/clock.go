@@ -0,0 +1,17 @@
+package main
+
+import "time"
+
+// Clock is where the service reads the time for everything it times
+// itself: access and refresh token lifetimes, step-up age, TOTP steps,
+// session expiry shown to users, the session cache and the in-memory rate
+// limiter. Expiry that Redis or Postgres enforce runs on their clocks
+// instead. Tests use a fake that only moves when told to.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock outside tests.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
@@ -0,0 +1,357 @@
+// Command loadtest drives register, login and /me traffic at a running
+// auth service and reports per-operation latency percentiles.
+//
+//	go run ./cmd/loadtest -url http://localhost:8080 -duration 30s -concurrency 16 -mix login=2,me=7,register=1
+//
+// Each worker registers and logs in users of its own under the
+// loadtest.invalid domain, then picks operations at random in the -mix
+// proportions until -duration is up. The users are left behind, so point
+// it at a throwaway database. The target's RATE_LIMIT must allow the
+// traffic, or most requests will be 429s; and its bcrypt worker pool is
+// the usual ceiling for register and login, visible in its
+// bcrypt_queue_wait_duration_seconds and bcrypt_rejected_total metrics.
+//
+// The report is plain columns, one line per operation, so runs against
+// two commits can be compared with diff.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	mrand "math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// ops are the operations loadtest knows, in report order.
+var ops = []string{"register", "login", "me"}
+
+func main() {
+	target := flag.String("url", "http://localhost:8080", "base URL of the service")
+	duration := flag.Duration("duration", 30*time.Second, "how long to send traffic")
+	concurrency := flag.Int("concurrency", 8, "concurrent clients")
+	mixFlag := flag.String("mix", "login=2,me=7,register=1", "relative weights of register, login and me")
+	timeout := flag.Duration("timeout", 10*time.Second, "per-request timeout")
+	flag.Parse()
+
+	if *concurrency <= 0 {
+		log.Fatal("-concurrency must be positive")
+	}
+	mix, err := parseMix(*mixFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = *concurrency
+	client := &http.Client{Transport: transport, Timeout: *timeout}
+	base := strings.TrimSuffix(*target, "/")
+
+	// Every worker needs a logged-in user before the clock starts, so
+	// setup cost doesn't skew the first seconds of the run.
+	workers := make([]*worker, *concurrency)
+	for i := range workers {
+		w := &worker{client: client, base: base, mix: mix, stats: newStats()}
+		if err := w.setup(ctx); err != nil {
+			log.Fatalf("setup: %v", err)
+		}
+		workers[i] = w
+	}
+
+	var ms0 runtime.MemStats
+	runtime.ReadMemStats(&ms0)
+
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(runCtx)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	var ms1 runtime.MemStats
+	runtime.ReadMemStats(&ms1)
+
+	total := newStats()
+	for _, w := range workers {
+		total.merge(w.stats)
+	}
+	report(os.Stdout, total, elapsed, ms1.Mallocs-ms0.Mallocs)
+}
+
+// parseMix parses "op=weight,..." into weights for ops. Operations left
+// out get no traffic.
+func parseMix(s string) (map[string]int, error) {
+	mix := map[string]int{}
+	for _, part := range strings.Split(s, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !slices.Contains(ops, name) {
+			return nil, fmt.Errorf("-mix: want op=weight with op one of %s, got %q", strings.Join(ops, ", "), part)
+		}
+		n, err := strconv.Atoi(weight)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("-mix: bad weight %q for %s", weight, name)
+		}
+		mix[name] = n
+	}
+	sum := 0
+	for _, n := range mix {
+		sum += n
+	}
+	if sum == 0 {
+		return nil, errors.New("-mix: all weights are zero")
+	}
+	return mix, nil
+}
+
+// worker is one client. It keeps the cookies of its last login, which
+// include the device cookie, so repeat logins don't stop at new-device
+// verification.
+type worker struct {
+	client *http.Client
+	base   string
+	mix    map[string]int
+	stats  *stats
+
+	email, password string
+	cookies         map[string]*http.Cookie
+}
+
+func (w *worker) setup(ctx context.Context) error {
+	w.email, w.password = newUser()
+	if status, err := w.register(ctx, w.email, w.password); err != nil {
+		return err
+	} else if status != http.StatusCreated {
+		return fmt.Errorf("register: status %d", status)
+	}
+	if status, err := w.login(ctx); err != nil {
+		return err
+	} else if status != http.StatusOK {
+		return fmt.Errorf("login: status %d", status)
+	}
+	return nil
+}
+
+func (w *worker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		op := w.pick()
+		start := time.Now()
+		var status int
+		var err error
+		switch op {
+		case "register":
+			email, password := newUser()
+			status, err = w.register(ctx, email, password)
+		case "login":
+			status, err = w.login(ctx)
+		case "me":
+			status, err = w.me(ctx)
+		}
+		if ctx.Err() != nil {
+			// Cut off by the end of the run, not a result.
+			return
+		}
+		w.stats.record(op, time.Since(start), status, err)
+	}
+}
+
+func (w *worker) pick() string {
+	sum := 0
+	for _, n := range w.mix {
+		sum += n
+	}
+	r := mrand.IntN(sum)
+	for _, op := range ops {
+		if r < w.mix[op] {
+			return op
+		}
+		r -= w.mix[op]
+	}
+	return ops[len(ops)-1]
+}
+
+func (w *worker) register(ctx context.Context, email, password string) (int, error) {
+	resp, err := w.post(ctx, "/v1/register", email, password)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// login counts as a success only if it got a session: a 200 that starts a
+// TOTP or device verification flow is reported as status 0.
+func (w *worker) login(ctx context.Context) (int, error) {
+	resp, err := w.post(ctx, "/v1/login", w.email, w.password)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+
+	cookies := map[string]*http.Cookie{}
+	for _, c := range w.cookies {
+		cookies[c.Name] = c
+	}
+	for _, c := range resp.Cookies() {
+		cookies[c.Name] = c
+	}
+	if _, ok := cookies["session_id"]; !ok {
+		return 0, errors.New("login: no session (TOTP or device verification required?)")
+	}
+	w.cookies = cookies
+	return resp.StatusCode, nil
+}
+
+func (w *worker) me(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.base+"/v1/me", nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range w.cookies {
+		req.AddCookie(c)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func (w *worker) post(ctx context.Context, path, email, password string) (*http.Response, error) {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.base+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, c := range w.cookies {
+		req.AddCookie(c)
+	}
+	return w.client.Do(req)
+}
+
+func newUser() (email, password string) {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "loadtest-" + hex.EncodeToString(b[:8]) + "@loadtest.invalid", hex.EncodeToString(b[8:]) + "-Loadtest"
+}
+
+// stats holds the latencies of successful requests and counts of the
+// rest by status, 0 meaning the request failed without one.
+type stats struct {
+	latencies map[string][]time.Duration
+	failures  map[string]map[int]int
+}
+
+func newStats() *stats {
+	return &stats{latencies: map[string][]time.Duration{}, failures: map[string]map[int]int{}}
+}
+
+func (s *stats) record(op string, d time.Duration, status int, err error) {
+	if err == nil && status >= 200 && status < 300 {
+		s.latencies[op] = append(s.latencies[op], d)
+		return
+	}
+	if err != nil {
+		status = 0
+	}
+	if s.failures[op] == nil {
+		s.failures[op] = map[int]int{}
+	}
+	s.failures[op][status]++
+}
+
+func (s *stats) merge(o *stats) {
+	for op, ds := range o.latencies {
+		s.latencies[op] = append(s.latencies[op], ds...)
+	}
+	for op, byStatus := range o.failures {
+		if s.failures[op] == nil {
+			s.failures[op] = map[int]int{}
+		}
+		for status, n := range byStatus {
+			s.failures[op][status] += n
+		}
+	}
+}
+
+// report prints one line per operation with latencies in milliseconds,
+// then the failures by status. mallocs is the driver's own allocations,
+// to tell whether it, rather than the service, was the bottleneck.
+func report(out io.Writer, s *stats, elapsed time.Duration, mallocs uint64) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tok\tfailed\trps\tp50_ms\tp90_ms\tp99_ms\tmax_ms\t")
+	for _, op := range ops {
+		ds := s.latencies[op]
+		failed := 0
+		for _, n := range s.failures[op] {
+			failed += n
+		}
+		if len(ds) == 0 && failed == 0 {
+			continue
+		}
+		slices.Sort(ds)
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n", op, len(ds), failed,
+			float64(len(ds))/elapsed.Seconds(),
+			ms(percentile(ds, 50)), ms(percentile(ds, 90)), ms(percentile(ds, 99)), ms(percentile(ds, 100)))
+	}
+	tw.Flush()
+
+	for _, op := range ops {
+		statuses := make([]int, 0, len(s.failures[op]))
+		for status := range s.failures[op] {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(out, "failed op=%s status=%d count=%d\n", op, status, s.failures[op][status])
+		}
+	}
+	fmt.Fprintf(out, "elapsed=%s driver_mallocs=%d\n", elapsed.Round(time.Millisecond), mallocs)
+}
+
+// percentile uses the nearest-rank method on sorted ds.
+func percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(ds))))
+	return ds[max(rank, 1)-1]
+}
+
+func ms(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 2, 64)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := parseMix(" login=2, me=7,register=0")
+	if err != nil || mix["login"] != 2 || mix["me"] != 7 || mix["register"] != 0 {
+		t.Errorf("got %v, %v", mix, err)
+	}
+	for _, bad := range []string{"", "login", "logout=1", "login=-1", "login=x", "login=0,me=0"} {
+		if _, err := parseMix(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var ds []time.Duration
+	for i := 1; i <= 100; i++ {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+	for p, want := range map[float64]time.Duration{0: time.Millisecond, 50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if got := percentile(ds, p); got != want {
+			t.Errorf("p%v = %v, want %v", p, got, want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("empty: %v", got)
+	}
+}
+
+// TestReport checks the report's layout, which runs are diffed by: one
+// aligned line per operation that saw traffic, then the failures.
+func TestReport(t *testing.T) {
+	s := newStats()
+	for i := 1; i <= 4; i++ {
+		s.record("login", time.Duration(i)*time.Millisecond, http.StatusOK, nil)
+	}
+	s.record("login", time.Second, http.StatusTooManyRequests, nil)
+	s.record("me", 500*time.Microsecond, http.StatusOK, nil)
+	other := newStats()
+	other.record("me", time.Second, 0, context.DeadlineExceeded)
+	s.merge(other)
+
+	var out strings.Builder
+	report(&out, s, 2*time.Second, 42)
+	want := `     op  ok  failed  rps  p50_ms  p90_ms  p99_ms  max_ms
+  login   4       1  2.0    2.00    4.00    4.00    4.00
+     me   1       1  0.5    0.50    0.50    0.50    0.50
+failed op=login status=429 count=1
+failed op=me status=0 count=1
+elapsed=2s driver_mallocs=42
+`
+	if got := out.String(); got != want {
+		t.Errorf("report:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// fakeService answers register, login and /me the way the auth service
+// does, for the worker to drive.
+func fakeService(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	users := map[string]string{}
+	mux := http.NewServeMux()
+	credentials := func(r *http.Request) (string, string) {
+		var body struct{ Email, Password string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("%s: %v", r.URL.Path, err)
+		}
+		return body.Email, body.Password
+	}
+	mux.HandleFunc("POST /v1/register", func(w http.ResponseWriter, r *http.Request) {
+		email, password := credentials(r)
+		mu.Lock()
+		users[email] = password
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /v1/login", func(w http.ResponseWriter, r *http.Request) {
+		email, password := credentials(r)
+		mu.Lock()
+		ok := password != "" && users[email] == password
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session_id", Value: email})
+	})
+	mux.HandleFunc("GET /v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session_id"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestWorker runs a worker against fakeService: it sets up a user and
+// logs in, then sends only the operations in its mix, all succeeding.
+func TestWorker(t *testing.T) {
+	srv := fakeService(t)
+	w := &worker{client: srv.Client(), base: srv.URL, mix: map[string]int{"login": 1, "me": 3}, stats: newStats()}
+	if err := w.setup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w.cookies["session_id"] == nil {
+		t.Fatal("setup kept no session cookie")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	w.run(ctx)
+
+	if len(w.stats.failures) != 0 {
+		t.Errorf("failures %v", w.stats.failures)
+	}
+	if len(w.stats.latencies["login"]) == 0 || len(w.stats.latencies["me"]) == 0 || len(w.stats.latencies["register"]) != 0 {
+		t.Errorf("requests per op: login %d, me %d, register %d",
+			len(w.stats.latencies["login"]), len(w.stats.latencies["me"]), len(w.stats.latencies["register"]))
+	}
+
+	// A login that gets no session, as when verification is needed, fails.
+	w.password = "wrong"
+	if status, err := w.login(context.Background()); status != http.StatusUnauthorized || err != nil {
+		t.Errorf("wrong password: %d, %v", status, err)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveGzip runs h behind gzipMiddleware for a client that accepts gzip.
+func serveGzip(t *testing.T, h http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip, br")
+	rec := httptest.NewRecorder()
+	gzipMiddleware(h).ServeHTTP(rec, r)
+	return rec
+}
+
+func gunzip(t *testing.T, b []byte) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestGzipLargeJSON(t *testing.T) {
+	items := make([]map[string]string, 200)
+	for i := range items {
+		items[i] = map[string]string{"id": strings.Repeat("x", 10)}
+	}
+	rec := serveGzip(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, items)
+	})
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("got %d, Content-Encoding %q; want gzip", rec.Code, rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Header().Get("Content-Length") != "" {
+		t.Error("Content-Length of the uncompressed body left on")
+	}
+	if !strings.Contains(rec.Header().Get("Vary"), "Accept-Encoding") {
+		t.Errorf("Vary %q", rec.Header().Get("Vary"))
+	}
+	var got []map[string]string
+	if err := json.Unmarshal(gunzip(t, rec.Body.Bytes()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(items) {
+		t.Errorf("got %d items back, want %d", len(got), len(items))
+	}
+}
+
+func TestGzipNoContentUntouched(t *testing.T) {
+	rec := serveGzip(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 0 {
+		t.Errorf("got %d, Content-Encoding %q, body %q", rec.Code, rec.Header().Get("Content-Encoding"), rec.Body)
+	}
+}
+
+func TestGzipThreshold(t *testing.T) {
+	tests := []struct {
+		size        int
+		contentType string
+		want        bool
+	}{
+		{compressMinSize - 1, "application/json", false},
+		{compressMinSize, "application/json", true},
+		{compressMinSize * 4, "text/plain; charset=utf-8", true},
+		{compressMinSize * 4, "image/png", false},
+		{compressMinSize * 4, "text/event-stream", false},
+	}
+	for _, tt := range tests {
+		body := strings.Repeat("a", tt.size)
+		rec := serveGzip(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tt.contentType)
+			// Split the writes so the threshold is crossed mid-stream.
+			io.WriteString(w, body[:tt.size/2])
+			io.WriteString(w, body[tt.size/2:])
+		})
+
+		gzipped := rec.Header().Get("Content-Encoding") == "gzip"
+		if gzipped != tt.want {
+			t.Errorf("%d bytes of %s: gzipped %v, want %v", tt.size, tt.contentType, gzipped, tt.want)
+			continue
+		}
+		got := rec.Body.Bytes()
+		if gzipped {
+			got = gunzip(t, got)
+		}
+		if string(got) != body {
+			t.Errorf("%d bytes of %s: body changed", tt.size, tt.contentType)
+		}
+	}
+}
+
+// deadlineRecorder is a recorder that can also take a write deadline.
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	deadline time.Time
+}
+
+func (d *deadlineRecorder) SetWriteDeadline(t time.Time) error {
+	d.deadline = t
+	return nil
+}
+
+// The wrapper keeps http.ResponseController working, for both what it
+// handles itself and what it passes down.
+func TestGzipResponseController(t *testing.T) {
+	rec := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+	deadline := time.Now().Add(time.Minute)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	gzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(deadline); err != nil {
+			t.Errorf("SetWriteDeadline: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: hi\n\n")
+		if err := rc.Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	})).ServeHTTP(rec, r)
+
+	if !rec.deadline.Equal(deadline) {
+		t.Error("the write deadline didn't reach the underlying writer")
+	}
+	if !rec.Flushed || rec.Body.String() != "data: hi\n\n" {
+		t.Errorf("flushed %v, body %q", rec.Flushed, rec.Body)
+	}
+}
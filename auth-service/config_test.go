@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckEnvironment(t *testing.T) {
+	quietLog(t)
+	strong := []byte(strings.Repeat("k", minJWTSecretLen))
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"development allows plain HTTP", Config{Environment: envDevelopment}, ""},
+		{"staging warns about plain HTTP", Config{Environment: envStaging, JWTSecret: strong}, ""},
+		{"production with TLS and secure cookies", Config{Environment: envProduction, TLSEnabled: true, CookieSecure: true, JWTSecret: strong}, ""},
+		{"production without TLS returns an error", Config{Environment: envProduction, CookieSecure: true, JWTSecret: strong}, "TLS_ENABLED is off"},
+		{"production without secure cookies", Config{Environment: envProduction, TLSEnabled: true, JWTSecret: strong}, "COOKIE_SECURE is off"},
+		{"production with neither", Config{Environment: envProduction, JWTSecret: strong}, "TLS_ENABLED is off and COOKIE_SECURE is off"},
+		{"production with a short JWT secret", Config{Environment: envProduction, TLSEnabled: true, CookieSecure: true, JWTSecret: []byte("short")}, "JWT_SECRET must be at least"},
+		{"staging without a JWT secret", Config{Environment: envStaging, TLSEnabled: true, CookieSecure: true}, "JWT_SECRET must be at least"},
+		{"unknown environment", Config{Environment: "prod"}, "ENVIRONMENT must be"},
+	}
+	for _, tt := range tests {
+		c := tt.cfg
+		err := c.checkEnvironment()
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.wantErr)
+		case err == nil && len(c.JWTSecret) < minJWTSecretLen:
+			t.Errorf("%s: left a %d-byte JWT secret", tt.name, len(c.JWTSecret))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNoStringContextKeys fails on any context.WithValue or Value call in
+// the module's code keyed by a string, literal or untyped constant: such
+// keys collide across packages. Use an unexported key type, as package
+// auth does.
+func TestNoStringContextKeys(t *testing.T) {
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && file != "." && d.Name() == "testdata" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			var key ast.Expr
+			switch pkg, _ := sel.X.(*ast.Ident); {
+			case pkg != nil && pkg.Name == "context" && sel.Sel.Name == "WithValue" && len(call.Args) == 3:
+				key = call.Args[1]
+			case sel.Sel.Name == "Value" && len(call.Args) == 1:
+				key = call.Args[0]
+			default:
+				return true
+			}
+			if isStringKey(key) {
+				t.Errorf("%s: context key %s is a string", fset.Position(key.Pos()), types.ExprString(key))
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// isStringKey reports whether key is a string literal or a constant
+// declared, in the same file, as an untyped string.
+func isStringKey(key ast.Expr) bool {
+	if lit, ok := key.(*ast.BasicLit); ok {
+		return lit.Kind == token.STRING
+	}
+	id, ok := key.(*ast.Ident)
+	if !ok || id.Obj == nil || id.Obj.Kind != ast.Con {
+		return false
+	}
+	spec, ok := id.Obj.Decl.(*ast.ValueSpec)
+	if !ok || spec.Type != nil {
+		return false
+	}
+	for i, name := range spec.Names {
+		if name.Name == id.Name && i < len(spec.Values) {
+			lit, ok := spec.Values[i].(*ast.BasicLit)
+			return ok && lit.Kind == token.STRING
+		}
+	}
+	return false
+}
+
+// TestHandlersWithoutUser calls handlers that need an authenticated user
+// without the middleware that provides one: each answers 401 instead of
+// panicking.
+func TestHandlersWithoutUser(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	for name, h := range map[string]http.HandlerFunc{
+		"me":            s.meHandler,
+		"me/sessions":   s.mySessionsHandler,
+		"me/export":     s.meExportHandler,
+		"me/metadata":   s.meMetadataHandler,
+		"me/logins":     s.loginHistoryHandler,
+		"sessions/live": s.sessionEventsHandler,
+	} {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/v1/"+name, nil))
+		if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "unauthenticated" {
+			t.Errorf("%s: code %q, want unauthenticated", name, env.Error.Code)
+		}
+	}
+}
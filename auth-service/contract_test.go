@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// The contract tests are request/response pairs in testdata/contract:
+// NAME.http is a request, written by hand, and NAME.golden the response
+// the router gives it, rewritten with -update. Each response is also
+// validated against openapi.yaml, so a golden file can't pin down
+// behaviour the spec doesn't allow.
+//
+// A request file is a request line, headers and an optional body:
+//
+//	POST /v1/login
+//	Content-Type: application/json
+//
+//	{"email":"a@example.com","password":"correct horse"}
+//
+// {{auth_token EMAIL}} in a header is replaced with an access token for
+// that user. Each pair runs against a fresh server with these users, all
+// with the password "correct horse": a@example.com, admin@example.com
+// (an admin) and totp@example.com (with TOTP on).
+
+// contractHeaders are the response headers a golden file records; the
+// security headers every response gets are left to their own tests.
+var contractHeaders = []string{"Cache-Control", "Content-Type", "Location", "Retry-After", "Set-Cookie", "WWW-Authenticate"}
+
+// contractVolatile are JSON keys whose values change from run to run.
+var contractVolatile = []string{"created_at", "flow_id", "go_version", "request_id", "uptime_seconds"}
+
+var (
+	authTokenPlaceholder = regexp.MustCompile(`\{\{auth_token ([^}]+)\}\}`)
+	cookieValue          = regexp.MustCompile(`^([^=]+)=[^;]*`)
+	cookieExpires        = regexp.MustCompile(`Expires=[^;]*`)
+)
+
+func TestContract(t *testing.T) {
+	quietLog(t)
+	router := loadOpenAPI(t)
+	files, err := filepath.Glob(filepath.Join("testdata", "contract", "*.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no contract tests in testdata/contract")
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".http")
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestServer(t)
+			useFakeRefreshDB(t, s)
+			users := map[string]User{
+				"a@example.com":     newTestUser(t, s, "a@example.com", "user"),
+				"admin@example.com": newTestUser(t, s, "admin@example.com", "admin"),
+			}
+			users["totp@example.com"], _ = newTOTPUser(t, s, "totp@example.com")
+
+			r, body := readContractRequest(t, file, func(email string) string {
+				user, ok := users[email]
+				if !ok {
+					t.Fatalf("no test user %s", email)
+				}
+				return accessTokenCookie(t, s, user).Value
+			})
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, r)
+
+			checkGolden(t, filepath.Join("contract", name+".golden"), formatContractResponse(t, rec))
+			validateContractResponse(t, router, r, body, rec)
+		})
+	}
+}
+
+// readContractRequest parses a request file, filling in placeholders with
+// token.
+func readContractRequest(t *testing.T, file string, token func(email string) string) (*http.Request, []byte) {
+	t.Helper()
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(raw)))
+	line, err := tp.ReadLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	method, target, ok := strings.Cut(line, " ")
+	if !ok {
+		t.Fatalf("%s: request line %q, want METHOD PATH", file, line)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(tp.R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body = bytes.TrimSuffix(body, []byte("\n"))
+
+	r := httptest.NewRequest(method, target, bytes.NewReader(body))
+	for key, values := range header {
+		for _, v := range values {
+			r.Header.Add(key, authTokenPlaceholder.ReplaceAllStringFunc(v, func(m string) string {
+				return token(authTokenPlaceholder.FindStringSubmatch(m)[1])
+			}))
+		}
+	}
+	return r, body
+}
+
+// formatContractResponse renders the status, contractHeaders and the body,
+// with cookie values, expiry times and contractVolatile values masked.
+func formatContractResponse(t *testing.T, rec *httptest.ResponseRecorder) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%d %s\n", rec.Code, http.StatusText(rec.Code))
+	for _, key := range contractHeaders {
+		values := slices.Clone(rec.Header().Values(key))
+		slices.Sort(values)
+		for _, v := range values {
+			if key == "Set-Cookie" {
+				v = cookieValue.ReplaceAllString(v, "$1=…")
+				v = cookieExpires.ReplaceAllString(v, "Expires=…")
+			}
+			fmt.Fprintf(&out, "%s: %s\n", key, v)
+		}
+	}
+	out.WriteString("\n")
+
+	var body any
+	if json.Unmarshal(rec.Body.Bytes(), &body) == nil {
+		maskVolatile(body)
+		b, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.Write(b)
+		out.WriteString("\n")
+	} else if rec.Body.Len() > 0 {
+		out.Write(rec.Body.Bytes())
+		out.WriteString("\n")
+	}
+	return out.Bytes()
+}
+
+func maskVolatile(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if slices.Contains(contractVolatile, key) {
+				v[key] = "…"
+				continue
+			}
+			maskVolatile(value)
+		}
+	case []any:
+		for _, value := range v {
+			maskVolatile(value)
+		}
+	}
+}
+
+// validateContractResponse checks rec against the operation r matches in
+// openapi.yaml.
+func validateContractResponse(t *testing.T, router routers.Router, r *http.Request, body []byte, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	route, params, err := router.FindRoute(r)
+	if err != nil {
+		t.Fatalf("%s %s isn't in openapi.yaml: %v", r.Method, r.URL.Path, err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+		},
+		Status:  rec.Code,
+		Header:  rec.Header(),
+		Body:    io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options: &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	if err != nil {
+		t.Errorf("response doesn't match openapi.yaml: %v", err)
+	}
+}
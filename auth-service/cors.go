@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"resilient-auth-service/apperror"
+)
+
+// corsMiddleware lets browser clients on the configured origins call the API
+// with cookies. Preflight requests are answered here and never reach the
+// rate limiter or the handler, so it must wrap both.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	methods := strings.Join(s.cfg.CORSAllowedMethods, ", ")
+	headers := strings.Join(s.cfg.CORSAllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(s.cfg.CORSMaxAge.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		allowed := originAllowed(origin, s.cfg.CORSAllowedOrigins)
+
+		isPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if isPreflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			if !allowed {
+				apperror.WriteError(w, r, apperror.Forbidden("cors_origin_not_allowed", "CORS origin not allowed"))
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Max-Age", maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed matches origin against exact entries and "scheme://*.suffix"
+// patterns. A pattern matches subdomains only, not the bare suffix itself.
+func originAllowed(origin string, allowed []string) bool {
+	origin = strings.ToLower(origin)
+
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			if origin == pattern {
+				return true
+			}
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(origin, scheme+"://")
+		if !ok {
+			continue
+		}
+		sub, ok := strings.CutSuffix(rest, "."+host)
+		if ok && sub != "" && !strings.ContainsAny(sub, "/:@") {
+			return true
+		}
+	}
+	return false
+}
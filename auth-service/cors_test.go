@@ -0,0 +1,138 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{
+		"https://app.example.org",
+		"https://*.example.com",
+		"http://*.local.test:8080",
+	}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.org", true},
+		{"HTTPS://APP.EXAMPLE.ORG", true},
+		{"http://app.example.org", false},
+		{"https://app.example.org:443", false},
+
+		{"https://a.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://example.com", false},
+		{"https://.example.com", false},
+		{"http://a.example.com", false},
+		{"https://badexample.com", false},
+		{"https://a.example.com.evil.test", false},
+		{"https://evil.test@a.example.com", false},
+		{"https://evil.test/.example.com", false},
+		{"https://a.example.com:8443", false},
+		{"https://evil.test:1.example.com", false},
+
+		{"http://a.local.test:8080", true},
+		{"http://a.local.test", false},
+		{"http://a.local.test:9090", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := originAllowed(tt.origin, allowed); got != tt.want {
+			t.Errorf("originAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	s := &Server{cfg: testConfig}
+	s.cfg.CORSAllowedOrigins = []string{"https://*.example.com"}
+	s.cfg.CORSAllowedMethods = []string{"GET", "POST"}
+	s.cfg.CORSAllowedHeaders = []string{"Content-Type", "X-CSRF-Token"}
+	s.cfg.CORSMaxAge = 10 * time.Minute
+
+	reached := false
+	h := s.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	serve := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		reached = false
+		r := httptest.NewRequest(method, "/login", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if preflight {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	t.Run("preflight allowed", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://app.example.com", true)
+		if rec.Code != http.StatusNoContent || reached {
+			t.Fatalf("got %d, reached handler %v; want 204 answered here", rec.Code, reached)
+		}
+		want := map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "GET, POST",
+			"Access-Control-Allow-Headers":     "Content-Type, X-CSRF-Token",
+			"Access-Control-Max-Age":           "600",
+		}
+		for k, v := range want {
+			if got := rec.Header().Get(k); got != v {
+				t.Errorf("%s: %q, want %q", k, got, v)
+			}
+		}
+		vary := rec.Header().Values("Vary")
+		for _, v := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+			found := false
+			for _, got := range vary {
+				found = found || got == v
+			}
+			if !found {
+				t.Errorf("Vary %q lacks %s", vary, v)
+			}
+		}
+	})
+
+	t.Run("preflight refused", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://evil.test", true)
+		env := readEnvelope(t, rec, http.StatusForbidden)
+		if env.Error.Code != "cors_origin_not_allowed" || reached {
+			t.Errorf("got %+v, reached handler %v", env, reached)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin %q on a refused preflight", got)
+		}
+	})
+
+	t.Run("simple request allowed", func(t *testing.T) {
+		rec := serve(http.MethodPost, "https://app.example.com", false)
+		if !reached || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+			rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Errorf("reached %v, headers %v", reached, rec.Header())
+		}
+		if rec.Header().Get("Access-Control-Allow-Methods") != "" {
+			t.Error("preflight headers on a simple request")
+		}
+	})
+
+	t.Run("simple request from another origin", func(t *testing.T) {
+		rec := serve(http.MethodPost, "https://evil.test", false)
+		if !reached || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("reached %v, headers %v", reached, rec.Header())
+		}
+	})
+
+	t.Run("no origin", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "", true)
+		if !reached || len(rec.Header()) != 0 {
+			t.Errorf("reached %v, headers %v", reached, rec.Header())
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"resilient-auth-service/flags"
+)
+
+// criticalPathServer is a Server for benchmarks with every store in
+// memory (users in a MemoryUserStore, sessions in miniredis, refresh
+// tokens in fakeRefreshDB) and bcrypt at its minimum cost, so what is
+// measured is the service's own request handling. BenchmarkBcryptPool measures hashing on its own.
+func criticalPathServer(b *testing.B) (*Server, http.Handler) {
+	quietLog(b)
+	s, _ := newTestServer(b)
+	useFakeRefreshDB(b, s)
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1_000_000_000, time.Minute)
+	if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagDeviceTrust}); err != nil {
+		b.Fatal(err)
+	}
+	newTestUser(b, s, "a@example.com", "user")
+	return s, s.Handler()
+}
+
+// reportLatencies adds p50 and p99 of ds to the benchmark's output, next
+// to ns/op and allocs/op, so two runs compare with benchstat or diff.
+func reportLatencies(b *testing.B, ds []time.Duration) {
+	if len(ds) == 0 {
+		return
+	}
+	slices.Sort(ds)
+	b.ReportMetric(ds[len(ds)*50/100].Seconds(), "p50-s")
+	b.ReportMetric(ds[len(ds)*99/100].Seconds(), "p99-s")
+}
+
+// BenchmarkCriticalPath sends register, login and /me requests through the
+// full router, one operation per sub-benchmark.
+//
+//	go test -run '^$' -bench CriticalPath -count 6 . > new.txt
+func BenchmarkCriticalPath(b *testing.B) {
+	const login = `{"email":"a@example.com","password":"correct horse"}`
+	var n atomic.Int64
+	ops := []struct {
+		name   string
+		status int
+		req    func(s *Server, h http.Handler, cookies []*http.Cookie) *httptest.ResponseRecorder
+	}{
+		{"register", http.StatusCreated, func(s *Server, h http.Handler, _ []*http.Cookie) *httptest.ResponseRecorder {
+			email := "bench-" + strconv.FormatInt(n.Add(1), 10) + "@example.com"
+			return postJSON(h, "/v1/register", `{"email":"`+email+`","password":"a long enough passphrase"}`)
+		}},
+		{"login", http.StatusOK, func(s *Server, h http.Handler, _ []*http.Cookie) *httptest.ResponseRecorder {
+			return postJSON(h, "/v1/login", login)
+		}},
+		{"me", http.StatusOK, func(s *Server, h http.Handler, cookies []*http.Cookie) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+			for _, c := range cookies {
+				r.AddCookie(c)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			return rec
+		}},
+	}
+
+	for _, op := range ops {
+		b.Run(op.name, func(b *testing.B) {
+			s, h := criticalPathServer(b)
+			rec := postJSON(h, "/v1/login", login)
+			if rec.Code != http.StatusOK {
+				b.Fatalf("login: %d %s", rec.Code, rec.Body)
+			}
+			cookies := rec.Result().Cookies()
+
+			b.ReportAllocs()
+			var latencies []time.Duration
+			for b.Loop() {
+				start := time.Now()
+				rec := op.req(s, h, cookies)
+				latencies = append(latencies, time.Since(start))
+				if rec.Code != op.status {
+					b.Fatalf("%d %s", rec.Code, rec.Body)
+				}
+			}
+			reportLatencies(b, latencies)
+		})
+	}
+}
+
+// BenchmarkSessionStore times the session operations behind every login
+// and authenticated request, against miniredis.
+func BenchmarkSessionStore(b *testing.B) {
+	ctx := context.Background()
+	b.Run("create", func(b *testing.B) {
+		s, _ := newTestServer(b)
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := s.createSession(ctx, "a@example.com", ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("lookup", func(b *testing.B) {
+		s, _ := newTestServer(b)
+		s.sessionCache = nil
+		id, err := s.createSession(ctx, "a@example.com", "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, _, err := s.lookupSession(ctx, id, true); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("lookup cached", func(b *testing.B) {
+		s, _ := newTestServer(b)
+		s.sessionCache = NewSessionCache(s.clock, 100, time.Minute)
+		id, err := s.createSession(ctx, "a@example.com", "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, _, err := s.lookupSession(ctx, id, true); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("create and delete", func(b *testing.B) {
+		s, _ := newTestServer(b)
+		b.ReportAllocs()
+		for b.Loop() {
+			id, err := s.createSession(ctx, "a@example.com", "")
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := s.DeleteSession(ctx, id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+
+	"resilient-auth-service/apperror"
+)
+
+const (
+	dbRetryMaxAttempts = 4
+	dbRetryBaseDelay   = 50 * time.Millisecond
+	dbRetryMaxDelay    = 500 * time.Millisecond
+	// dbRetryBudget caps the total time spent retrying when the request
+	// context has a later (or no) deadline.
+	dbRetryBudget = 3 * time.Second
+)
+
+// retryDB runs an idempotent query, retrying it with jittered exponential
+// backoff while it fails with a transient error (failover, connection reset,
+// serialization failure, too many clients). It must only wrap reads or
+// statements that are safe to repeat, and must be called before anything has
+// been written to the response.
+func retryDB(ctx context.Context, name string, query func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(withQueryName(ctx, name), dbRetryBudget)
+	defer cancel()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = query(ctx)
+		if err == nil || !isTransientDBError(err) || attempt == dbRetryMaxAttempts {
+			return err
+		}
+
+		delay := dbRetryBaseDelay << (attempt - 1)
+		if delay > dbRetryMaxDelay {
+			delay = dbRetryMaxDelay
+		}
+		// Full jitter so pods recovering from the same failover don't retry in lockstep.
+		delay = rand.N(delay) + time.Millisecond
+
+		log.Printf("db retry query=%s attempt=%d delay=%s err=%v", name, attempt, delay, err)
+		dbRetriesTotal.WithLabelValues(name).Inc()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// isStatementTimeout reports whether Postgres cancelled the statement, which
+// with our statement_timeout means the database is overloaded or a query
+// has regressed, not that the request was bad.
+func isStatementTimeout(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "57014" // query_canceled
+}
+
+// writeDBError answers a failed database call: 503 when the statement timed
+// out or the database is unreachable, so clients and load balancers back
+// off, 500 otherwise.
+func writeDBError(w http.ResponseWriter, r *http.Request, err error) {
+	if isStatementTimeout(err) || isTransientDBError(err) {
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+	apperror.WriteError(w, r, apperror.Internal(err))
+}
+
+// isTransientDBError reports whether err is worth retrying. Constraint
+// violations and other query errors are never transient.
+func isTransientDBError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"53300", // too_many_connections
+			"57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now
+			return true
+		}
+		return pqErr.Code.Class() == "08" // connection_exception
+	}
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRetryDB(t *testing.T) {
+	quietLog(t)
+	serialization := &pq.Error{Code: "40001"}
+	for _, tt := range []struct {
+		name    string
+		errs    []error // returned by successive calls; nil after they run out
+		calls   int
+		wantErr bool
+	}{
+		{"succeeds at once", nil, 1, false},
+		{"recovers from a failover", []error{driver.ErrBadConn, &pq.Error{Code: "57P01"}}, 3, false},
+		{"gives up", []error{serialization, serialization, serialization, serialization, serialization}, dbRetryMaxAttempts, true},
+		{"unique violation isn't retried", []error{&pq.Error{Code: "23505"}}, 1, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(dbRetriesTotal.WithLabelValues("test"))
+			calls := 0
+			err := retryDB(context.Background(), "test", func(ctx context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.calls || (err != nil) != tt.wantErr {
+				t.Errorf("%d calls, err %v; want %d calls, error %v", calls, err, tt.calls, tt.wantErr)
+			}
+			if got := testutil.ToFloat64(dbRetriesTotal.WithLabelValues("test")) - before; got != float64(tt.calls-1) {
+				t.Errorf("db_query_retries_total went up by %v, want %d", got, tt.calls-1)
+			}
+		})
+	}
+}
+
+func TestRetryDBStopsWhenCancelled(t *testing.T) {
+	quietLog(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := retryDB(ctx, "test", func(ctx context.Context) error {
+		calls++
+		cancel()
+		return driver.ErrBadConn
+	})
+	if calls != 1 || err != driver.ErrBadConn {
+		t.Errorf("%d calls, err %v; want 1 call returning the query's error", calls, err)
+	}
+}
+
+// flakyUserStore fails its first GetByEmail calls as a failover would.
+type flakyUserStore struct {
+	*MemoryUserStore
+	failures int
+}
+
+func (f *flakyUserStore) GetByEmail(ctx context.Context, email string) (User, error) {
+	if f.failures > 0 {
+		f.failures--
+		return User{}, driver.ErrBadConn
+	}
+	return f.MemoryUserStore.GetByEmail(ctx, email)
+}
+
+// TestLoginRetriesLookup logs in across a brief failover, and answers 503,
+// not 401, when the database stays down.
+func TestLoginRetriesLookup(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	useFakeRefreshDB(t, s)
+	newTestUser(t, s, "a@example.com", "user")
+	store := &flakyUserStore{MemoryUserStore: s.users.(*MemoryUserStore)}
+	s.users = store
+	h := s.Handler()
+	body := `{"email":"a@example.com","password":"correct horse"}`
+
+	store.failures = 2
+	if rec := postJSON(h, "/v1/login", body); rec.Code != http.StatusOK {
+		t.Errorf("login across a failover: %d %s", rec.Code, rec.Body)
+	}
+
+	store.failures = dbRetryMaxAttempts
+	rec := postJSON(h, "/v1/login", body)
+	if env := readEnvelope(t, rec, http.StatusServiceUnavailable); env.Error.Code != "service_unavailable" {
+		t.Errorf("database down: %q, want service_unavailable", env.Error.Code)
+	}
+}
+
+// brokenUserStore's lookups fail with a query error that isn't worth
+// retrying.
+type brokenUserStore struct{ *MemoryUserStore }
+
+func (brokenUserStore) GetByEmail(context.Context, string) (User, error) {
+	return User{}, errors.New(`relation "users" does not exist`)
+}
+
+// TestLoginLookupFailure tells an unknown email apart from a failed
+// lookup: the first is a failed login, the second a server error that is
+// counted as one.
+func TestLoginLookupFailure(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	h := s.Handler()
+	body := `{"email":"nobody@example.com","password":"correct horse"}`
+	failures := func() float64 { return testutil.ToFloat64(loginFailuresTotal.WithLabelValues("unknown_user")) }
+	dbErrors := func() float64 { return testutil.ToFloat64(dbErrorsTotal.WithLabelValues("login_select_user")) }
+
+	f, e := failures(), dbErrors()
+	if env := readEnvelope(t, postJSON(h, "/v1/login", body), http.StatusUnauthorized); env.Error.Code != "invalid_credentials" {
+		t.Errorf("unknown email: %q, want invalid_credentials", env.Error.Code)
+	}
+	if failures()-f != 1 || dbErrors()-e != 0 {
+		t.Errorf("unknown email counted as %v failed logins and %v database errors, want 1 and 0", failures()-f, dbErrors()-e)
+	}
+
+	s.users = brokenUserStore{s.users.(*MemoryUserStore)}
+	f, e = failures(), dbErrors()
+	if env := readEnvelope(t, postJSON(h, "/v1/login", body), http.StatusInternalServerError); env.Error.Code != "internal_error" {
+		t.Errorf("broken lookup: %q, want internal_error", env.Error.Code)
+	}
+	if failures()-f != 0 || dbErrors()-e != 1 {
+		t.Errorf("broken lookup counted as %v failed logins and %v database errors, want 0 and 1", failures()-f, dbErrors()-e)
+	}
+}
+
+func TestWithStatementTimeout(t *testing.T) {
+	got, err := withStatementTimeout("postgres://u:p@db:5432/authdb?sslmode=disable", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := u.Query(); q.Get("statement_timeout") != "5000" || q.Get("sslmode") != "disable" {
+		t.Errorf("got %s, want statement_timeout=5000 alongside sslmode", got)
+	}
+}
+
+// timeoutUserStore's lookups are cancelled by statement_timeout.
+type timeoutUserStore struct{ *MemoryUserStore }
+
+func (timeoutUserStore) GetByEmail(context.Context, string) (User, error) {
+	return User{}, &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}
+}
+
+// TestLoginStatementTimeout answers a login whose lookup timed out with
+// 503, without retrying it.
+func TestLoginStatementTimeout(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	newTestUser(t, s, "a@example.com", "user")
+	s.users = timeoutUserStore{s.users.(*MemoryUserStore)}
+	before := testutil.ToFloat64(dbRetriesTotal.WithLabelValues("login_select_user"))
+
+	rec := postJSON(s.Handler(), "/v1/login", `{"email":"a@example.com","password":"correct horse"}`)
+	if env := readEnvelope(t, rec, http.StatusServiceUnavailable); env.Error.Code != "service_unavailable" {
+		t.Errorf("timed-out lookup: %q, want service_unavailable", env.Error.Code)
+	}
+	if got := testutil.ToFloat64(dbRetriesTotal.WithLabelValues("login_select_user")) - before; got != 0 {
+		t.Errorf("timed-out lookup retried %v times", got)
+	}
+}
+
+// TestStatementTimeoutPostgres has a real database cancel pg_sleep(10)
+// when TEST_DATABASE_URL names one, and checks the error becomes a 503.
+func TestStatementTimeoutPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	quietLog(t)
+	dsn, err := withStatementTimeout(dsn, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	start := time.Now()
+	_, err = db.ExecContext(context.Background(), "SELECT pg_sleep(10)")
+	if !isStatementTimeout(err) {
+		t.Fatalf("pg_sleep(10): %v, want a statement timeout", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("pg_sleep(10) ran for %v", d)
+	}
+	rec := httptest.NewRecorder()
+	writeDBError(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if env := readEnvelope(t, rec, http.StatusServiceUnavailable); env.Error.Code != "service_unavailable" {
+		t.Errorf("statement timeout: %q, want service_unavailable", env.Error.Code)
+	}
+}
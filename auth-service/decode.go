@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"resilient-auth-service/apperror"
+)
+
+// maxBodyBytes caps JSON request bodies read through decodeJSON.
+const maxBodyBytes = 1 << 20
+
+// decodeJSON strictly decodes the request body into dst. The body must be
+// declared application/json, be at most maxBodyBytes, hold exactly one JSON
+// value, and use only fields dst knows about. If dst is validatable its
+// validate method runs last. Failures come back as an *apperror.Error
+// naming what was wrong, ready for apperror.WriteError.
+func decodeJSON[T any](w http.ResponseWriter, r *http.Request, dst *T) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return apperror.New(http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/json")
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return decodeError(err)
+		}
+		return apperror.BadRequest("invalid_json", "Request body must contain a single JSON value")
+	}
+	if v, ok := any(dst).(validatable); ok {
+		return v.validate()
+	}
+	return nil
+}
+
+// decodeError translates a json.Decoder error into the client-facing error.
+func decodeError(err error) *apperror.Error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		maxErr    *http.MaxBytesError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperror.BadRequest("empty_body", "Request body is empty")
+
+	case errors.As(err, &syntaxErr):
+		return apperror.BadRequest("invalid_json", fmt.Sprintf("Malformed JSON at offset %d", syntaxErr.Offset))
+
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.BadRequest("invalid_json", "Malformed JSON: unexpected end of body")
+
+	case errors.As(err, &typeErr):
+		want := jsonTypeName(typeErr.Type.Kind())
+		if typeErr.Field == "" {
+			return apperror.BadRequest("invalid_json", "Request body must be "+want)
+		}
+		return apperror.Unprocessable("invalid_field_type", "Field has the wrong type").
+			WithField(typeErr.Field, "invalid_type", "must be "+want)
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for this one.
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperror.BadRequest("unknown_field", "Request body contains an unknown field").
+			WithField(field, "unknown_field", "unknown field")
+
+	case errors.As(err, &maxErr):
+		return apperror.New(http.StatusRequestEntityTooLarge, "body_too_large",
+			fmt.Sprintf("Request body must not exceed %d bytes", maxErr.Limit))
+	}
+	return apperror.BadRequest("invalid_request", "Invalid request")
+}
+
+// jsonTypeName names the JSON type a client should send for a Go kind.
+func jsonTypeName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "a " + kind.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"resilient-auth-service/apperror"
+)
+
+// decodeTarget is a request type for the decode table: validate insists
+// on a name, so the table can see it runs last.
+type decodeTarget struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+func (d *decodeTarget) validate() error {
+	var v validator
+	v.required("name", d.Name)
+	return v.err()
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int // 0 for success
+		code        string
+		field       string
+	}{
+		{"valid", "application/json", `{"name":"a","count":2,"tags":["x"]}`, 0, "", ""},
+		{"charset", "application/json; charset=utf-8", `{"name":"a"}`, 0, "", ""},
+		{"trailing whitespace", "application/json", "{\"name\":\"a\"}\n\t ", 0, "", ""},
+		{"text/plain", "text/plain", `{"name":"a"}`, http.StatusUnsupportedMediaType, "unsupported_media_type", ""},
+		{"no content type", "", `{"name":"a"}`, http.StatusUnsupportedMediaType, "unsupported_media_type", ""},
+		{"empty", "application/json", ``, http.StatusBadRequest, "empty_body", ""},
+		{"syntax", "application/json", `{"name":"a",}`, http.StatusBadRequest, "invalid_json", ""},
+		{"truncated", "application/json", `{"name":"a"`, http.StatusBadRequest, "invalid_json", ""},
+		{"not an object", "application/json", `["a"]`, http.StatusBadRequest, "invalid_json", ""},
+		{"wrong field type", "application/json", `{"name":"a","count":"2"}`, http.StatusUnprocessableEntity, "invalid_field_type", "count"},
+		{"unknown field", "application/json", `{"name":"a","admin":true}`, http.StatusBadRequest, "unknown_field", "admin"},
+		{"two values", "application/json", `{"name":"a"}{"name":"b"}`, http.StatusBadRequest, "invalid_json", ""},
+		{"trailing garbage", "application/json", `{"name":"a"} x`, http.StatusBadRequest, "invalid_json", ""},
+		{"too large", "application/json", `{"name":"` + strings.Repeat("a", maxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, "body_too_large", ""},
+		{"too large after a value", "application/json", `{"name":"a"}` + strings.Repeat(" ", maxBodyBytes), http.StatusRequestEntityTooLarge, "body_too_large", ""},
+		{"fails validation", "application/json", `{"count":1}`, http.StatusUnprocessableEntity, "validation_failed", "name"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		var dst decodeTarget
+		err := decodeJSON(httptest.NewRecorder(), r, &dst)
+
+		if tt.status == 0 {
+			if err != nil || dst.Name != "a" {
+				t.Errorf("%s: got %+v, %v", tt.name, dst, err)
+			}
+			continue
+		}
+		var e *apperror.Error
+		if !errors.As(err, &e) {
+			t.Errorf("%s: got %v, want an *apperror.Error", tt.name, err)
+			continue
+		}
+		if e.Status != tt.status || e.Code != tt.code {
+			t.Errorf("%s: got %d %s, want %d %s", tt.name, e.Status, e.Code, tt.status, tt.code)
+		}
+		if tt.field != "" && (len(e.Fields) != 1 || e.Fields[0].Field != tt.field) {
+			t.Errorf("%s: fields %+v, want one for %s", tt.name, e.Fields, tt.field)
+		}
+	}
+}
+
+// decodeSeeds are valid and known-tricky bodies for the decode fuzzers.
+var decodeSeeds = []string{
+	`{"email":"a@example.com","password":"correct horse"}`,
+	`{"email":"a@example.com","password":"correct horse","captcha_token":"x"}`,
+	``,
+	`null`,
+	`[]`,
+	`"string"`,
+	`{"email":1}`,
+	`{"email":"a@example.com"}{"email":"b@example.com"}`,
+	`{"email":"a@example.com",}`,
+	`{"email":"a@example.com","admin":true}`,
+	`{"email":"\ud800","password":"\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000"}`,
+	`{"Email":"a@example.com","PASSWORD":"correct horse"}`,
+	`{"email":"a@example.com","password":"` + strings.Repeat("x", 73) + `"}`,
+	`{"email":"a@example.com","password":{"$ne":null}}`,
+	`{"email":"a@example.com","password":"correct horse"} trailing`,
+	`{"email":"a@example.com","password":"correct horse"` + strings.Repeat(" ", 10) + `}`,
+	`{"email":"Name <a@example.com>","password":"correct horse"}`,
+	`{"email":"a@localhost","password":"correct horse"}`,
+	`{"email":"a@example.com","password":"correct horse","email":"b@example.com"}`,
+}
+
+// fuzzDecodeJSON feeds arbitrary bodies and content types to decodeJSON
+// with a T. It must never panic, and every rejection must render as a
+// client error envelope.
+func fuzzDecodeJSON[T any](f *testing.F) {
+	for _, body := range decodeSeeds {
+		f.Add(body, "application/json")
+	}
+	f.Add(decodeSeeds[0], "application/json; charset=utf-8")
+	f.Add(decodeSeeds[0], "text/plain")
+	f.Add(decodeSeeds[0], "application/json; charset")
+	f.Add(decodeSeeds[0], "")
+
+	f.Fuzz(func(t *testing.T, body, contentType string) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		var dst T
+		err := decodeJSON(w, r, &dst)
+		if err == nil {
+			return
+		}
+		var e *apperror.Error
+		if !errors.As(err, &e) {
+			t.Fatalf("decodeJSON returned %T %v, want *apperror.Error", err, err)
+		}
+		if e.Status < 400 || e.Status >= 500 {
+			t.Fatalf("decodeJSON rejected with status %d, want 4xx", e.Status)
+		}
+		rec := httptest.NewRecorder()
+		apperror.WriteError(rec, r, err)
+		readEnvelope(t, rec, e.Status)
+	})
+}
+
+func FuzzDecodeRegisterRequest(f *testing.F) { fuzzDecodeJSON[registerRequest](f) }
+
+func FuzzDecodeLoginRequest(f *testing.F) { fuzzDecodeJSON[loginRequest](f) }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	texttemplate "text/template"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"resilient-auth-service/i18n"
+	"resilient-auth-service/mailer"
+)
+
+// Each email has an HTML template, name.html, escaped by html/template,
+// and a plain-text one, name.txt, for clients that don't show HTML. The
+// English ones are in templates/; translations go in templates/<locale>/,
+// for locales that have a message catalog in locales/.
+//
+//go:embed templates
+var emailTemplateFS embed.FS
+
+// emailTemplateSet is one locale's email templates.
+type emailTemplateSet struct {
+	html *template.Template
+	text *texttemplate.Template
+}
+
+// emailTemplates holds the templates by locale; i18n.Default's are always
+// there.
+var emailTemplates = loadEmailTemplates()
+
+func loadEmailTemplates() map[language.Tag]emailTemplateSet {
+	sets := map[language.Tag]emailTemplateSet{}
+	for i, tag := range messages.Tags() {
+		dir := "templates/" + tag.String()
+		if i == 0 {
+			dir = "templates"
+		}
+		set := emailTemplateSet{html: template.New(""), text: texttemplate.New("")}
+		if files, _ := fs.Glob(emailTemplateFS, dir+"/*.html"); len(files) > 0 {
+			set.html = template.Must(set.html.ParseFS(emailTemplateFS, files...))
+		}
+		if files, _ := fs.Glob(emailTemplateFS, dir+"/*.txt"); len(files) > 0 {
+			set.text = texttemplate.Must(set.text.ParseFS(emailTemplateFS, files...))
+		}
+		sets[tag] = set
+	}
+	return sets
+}
+
+// EmailTemplateData is the data passed to every email template.
+type EmailTemplateData struct {
+	Email     string
+	Link      string
+	Code      string
+	ExpiresIn time.Duration
+
+	// Login details for security notices.
+	Country string
+	IP      string
+	Device  string
+	Time    time.Time
+
+	// BackupCodesLeft is how many unused backup codes remain.
+	BackupCodesLeft int
+
+	// OrgName is the organization an invitation is to.
+	OrgName string
+}
+
+// newEmail renders the embedded templates name.html and name.txt, e.g.
+// "verify-email", into a message to to, in the language of the request ctx
+// came from. The subject is the catalog's "email.<name>.subject", itself a
+// template over data. A locale without the templates gets the English
+// ones, and the gap is logged. Links in data should be built on cfg.AppURL.
+func newEmail(ctx context.Context, to, name string, data EmailTemplateData) (mailer.Message, error) {
+	tag := i18n.Language(ctx)
+	set := emailTemplates[tag]
+	if tag != i18n.Default && (set.html.Lookup(name+".html") == nil || set.text.Lookup(name+".txt") == nil) {
+		i18n.ReportMissing(tag, "templates/"+name)
+		set = emailTemplates[i18n.Default]
+	}
+
+	key := "email." + name + ".subject"
+	subject, err := texttemplate.New(key).Parse(localizedMessage(ctx, key))
+	if err != nil {
+		return mailer.Message{}, err
+	}
+	var subj, html, text bytes.Buffer
+	if err := subject.Execute(&subj, data); err != nil {
+		return mailer.Message{}, err
+	}
+	if err := set.html.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return mailer.Message{}, err
+	}
+	if err := set.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return mailer.Message{}, err
+	}
+	return mailer.Message{To: to, Subject: subj.String(), HTMLBody: html.String(), TextBody: text.String()}, nil
+}
+
+// emailQueueWorkers deliver from a queue of up to emailQueueSize
+// messages; a message that finds it full is dropped.
+const (
+	emailQueueSize    = 1000
+	emailQueueWorkers = 4
+)
+
+// newEmailSender picks the sender for cfg: the SES API when a region is
+// configured, SMTP when a host is, otherwise a sender that only logs. The
+// real senders retry.
+func newEmailSender(ctx context.Context, cfg Config) (mailer.Sender, error) {
+	var sender mailer.Sender
+	switch {
+	case cfg.SESRegion != "":
+		ses, err := mailer.NewSESSender(ctx, cfg.SESRegion, cfg.SMTPFrom)
+		if err != nil {
+			return nil, fmt.Errorf("ses: %w", err)
+		}
+		sender = ses
+	case cfg.SMTPHost != "":
+		sender = &mailer.SMTPSender{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			User:     cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}
+	default:
+		log.Println("neither SES_REGION nor SMTP_HOST is set, emails will be logged instead of sent")
+		return mailer.LogSender{}, nil
+	}
+
+	return &mailer.RetrySender{
+		Sender:      sender,
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"resilient-auth-service/i18n"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testEmailData fills every field a template can use, with fixed values
+// so the output is stable.
+var testEmailData = EmailTemplateData{
+	Email:           "ann@example.com",
+	Link:            "https://app.example.com/link?token=abc&x=<y>",
+	Code:            "123456",
+	ExpiresIn:       30 * time.Minute,
+	Country:         "DE",
+	IP:              "203.0.113.7",
+	Device:          "Firefox on Linux",
+	Time:            time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+	BackupCodesLeft: 3,
+	OrgName:         "Acme & Co",
+}
+
+func emailTemplateNames(t *testing.T) []string {
+	t.Helper()
+	files, err := fs.Glob(emailTemplateFS, "templates/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(path.Base(file), ".html"))
+	}
+	if len(names) == 0 {
+		t.Fatal("no email templates found")
+	}
+	return names
+}
+
+// checkGolden compares got with testdata/<file>, or rewrites it with
+// -update.
+func checkGolden(t *testing.T, file string, got []byte) {
+	t.Helper()
+	file = filepath.Join("testdata", file)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s changed; got:\n%s\nrun go test -update if that's intended", file, got)
+	}
+}
+
+// TestEmailTemplates renders every email in every locale and checks it
+// against its golden file. Each locale must have its own translation of
+// each template, not fall back to English.
+func TestEmailTemplates(t *testing.T) {
+	for _, tag := range messages.Tags() {
+		set := emailTemplates[tag]
+		for _, name := range emailTemplateNames(t) {
+			t.Run(tag.String()+"/"+name, func(t *testing.T) {
+				if set.html.Lookup(name+".html") == nil || set.text.Lookup(name+".txt") == nil {
+					t.Fatal("no translation of the template")
+				}
+
+				ctx := i18n.WithLanguage(context.Background(), tag)
+				msg, err := newEmail(ctx, testEmailData.Email, name, testEmailData)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if msg.Subject == "" || msg.HTMLBody == "" || msg.TextBody == "" {
+					t.Fatalf("empty part in %+v", msg)
+				}
+				for _, part := range []string{msg.Subject, msg.HTMLBody, msg.TextBody} {
+					if strings.Contains(part, "<no value>") {
+						t.Errorf("a template used a field EmailTemplateData doesn't have:\n%s", part)
+					}
+				}
+				if strings.Contains(msg.HTMLBody, "<y>") {
+					t.Error("the HTML body didn't escape the link")
+				}
+
+				dir := path.Join("email", tag.String())
+				checkGolden(t, path.Join(dir, name+".html"), []byte(msg.HTMLBody))
+				checkGolden(t, path.Join(dir, name+".txt"), []byte("Subject: "+msg.Subject+"\n\n"+msg.TextBody))
+			})
+		}
+	}
+}
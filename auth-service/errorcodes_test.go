@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"resilient-auth-service/flags"
+)
+
+// documentedErrorCodes reads the codes, with their statuses, that the
+// Error schema in openapi.yaml lists for clients to branch on.
+func documentedErrorCodes(t *testing.T) map[string]int {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData(openAPIYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := doc.Components.Schemas["Error"].Value.Properties["error"].Value.Properties["code"].Value.Description
+	codes := map[string]int{}
+	// "a (401, ...", "a and b (429)", "a, b and c (503; ..."
+	for _, m := range regexp.MustCompile(`((?:[a-z_]+(?:, | and ))*[a-z_]+) \((\d{3})`).FindAllStringSubmatch(desc, -1) {
+		status, _ := strconv.Atoi(m[2])
+		for _, code := range strings.FieldsFunc(strings.ReplaceAll(m[1], " and ", ","), func(r rune) bool { return r == ',' || r == ' ' }) {
+			codes[code] = status
+		}
+	}
+	if len(codes) == 0 {
+		t.Fatalf("no codes found in %q", desc)
+	}
+	return codes
+}
+
+// TestErrorCodes drives every handler branch behind a documented error
+// code and checks the status and code the client gets, and that the
+// documentation lists exactly these codes.
+func TestErrorCodes(t *testing.T) {
+	quietLog(t)
+	const login = `{"email":"a@example.com","password":"correct horse"}`
+
+	type env struct {
+		s    *Server
+		mr   *miniredis.Miniredis
+		h    http.Handler
+		user User
+	}
+	get := func(e env, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		e.h.ServeHTTP(rec, r)
+		return rec
+	}
+	session := func(t *testing.T, e env, binding string) *http.Cookie {
+		id, err := e.s.createSession(context.Background(), e.user.Email, binding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Cookie{Name: "session_id", Value: id}
+	}
+
+	cases := []struct {
+		code   string
+		status int
+		run    func(t *testing.T, e env) *httptest.ResponseRecorder
+	}{
+		{"captcha_invalid", http.StatusBadRequest, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			e.s.captchaVerifier = captchaFunc(func(context.Context, string, string) error { return errCaptchaRejected })
+			return postJSON(e.h, "/v1/register", `{"email":"b@example.com","password":"a long enough passphrase","captcha_token":"x"}`)
+		}},
+		{"passkey_ceremony_invalid", http.StatusBadRequest, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			enablePasskeys(t, e.s)
+			return finishPasskey(e.h, "/v1/webauthn/login/finish", "nope", "{}")
+		}},
+		{"passkey_invalid", http.StatusBadRequest, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			enablePasskeys(t, e.s)
+			begin := beginPasskey(t, e.h, "/v1/webauthn/login/begin")
+			return finishPasskey(e.h, "/v1/webauthn/login/finish", begin.CeremonyID, "{}")
+		}},
+		{"invalid_credentials", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return postJSON(e.h, "/v1/login", `{"email":"a@example.com","password":"wrong"}`)
+		}},
+		{"invalid_code", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			_, secret := newTOTPUser(t, e.s, "totp@example.com")
+			flow := startFlow(t, e.h, "totp@example.com")
+			code := "000000"
+			if currentTOTP(t, e.s, secret) == code {
+				code = "111111"
+			}
+			return loginStep(e.h, "/v1/login/totp", flow.FlowID, code)
+		}},
+		{"login_flow_invalid", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return loginStep(e.h, "/v1/login/totp", "nope", "123456")
+		}},
+		{"unauthenticated", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return get(e, "/v1/me/sessions")
+		}},
+		{"session_invalid", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return get(e, "/v1/me/sessions", &http.Cookie{Name: "session_id", Value: "nope"})
+		}},
+		{"session_binding_mismatch", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			e.s.cfg.TokenBindingMode = tokenBindingOptional
+			return get(e, "/v1/me/sessions", session(t, e, "fingerprint"))
+		}},
+		{"token_binding_required", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			e.s.cfg.TokenBindingMode = tokenBindingRequired
+			return postJSON(e.h, "/v1/login", login)
+		}},
+		{"token_invalid", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return get(e, "/v2/me", &http.Cookie{Name: "auth_token", Value: "not.a.jwt"})
+		}},
+		{"refresh_token_missing", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return postJSON(e.h, "/v1/refresh", "")
+		}},
+		{"refresh_token_invalid", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return postJSON(e.h, "/v1/refresh", "", &http.Cookie{Name: "refresh_token", Value: "nope"})
+		}},
+		{"refresh_token_reused", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			token, err := e.s.issueRefreshToken(context.Background(), e.user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := e.s.rotateRefreshToken(context.Background(), token); err != nil {
+				t.Fatal(err)
+			}
+			return postJSON(e.h, "/v1/refresh", "", &http.Cookie{Name: "refresh_token", Value: token})
+		}},
+		{"reauthentication_required", http.StatusUnauthorized, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			authTime := e.s.clock.Now().Add(-e.s.cfg.StepUpMaxAge - time.Minute)
+			token, err := e.s.signAccessToken(context.Background(), e.user.ID, e.user.Email, authTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return get(e, "/v1/me/export", &http.Cookie{Name: "auth_token", Value: token})
+		}},
+		{"forbidden", http.StatusForbidden, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return get(e, "/v1/admin/users", accessTokenCookie(t, e.s, e.user))
+		}},
+		{"org_required", http.StatusForbidden, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			requireOrgRole(orgRoleMember, http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/org/members", nil))
+			return rec
+		}},
+		{"totp_setup_expired", http.StatusConflict, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return postJSON(e.h, "/v1/2fa/confirm", `{"code":"123456"}`, accessTokenCookie(t, e.s, e.user))
+		}},
+		{"validation_failed", http.StatusUnprocessableEntity, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			return postJSON(e.h, "/v1/register", `{"email":"not an email","password":"a long enough passphrase"}`)
+		}},
+		{"rate_limited", http.StatusTooManyRequests, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			e.s.rateLimiter = NewRedisRateLimiter(e.s.rdb, 1, time.Minute)
+			postJSON(e.h, "/v1/login", login)
+			return postJSON(e.h, "/v1/login", login)
+		}},
+		{"export_rate_limited", http.StatusTooManyRequests, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			e.mr.Set("export_limit:"+strconv.Itoa(e.user.ID), "1")
+			return get(e, "/v1/me/export", accessTokenCookie(t, e.s, e.user))
+		}},
+		{"server_busy", http.StatusServiceUnavailable, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			saved := e.s.passwordHasher
+			e.s.passwordHasher, _ = occupiedPool(t, 0, time.Second)
+			t.Cleanup(func() { e.s.passwordHasher = saved })
+			return postJSON(e.h, "/v1/login", login)
+		}},
+		{"service_unavailable", http.StatusServiceUnavailable, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			cookie := session(t, e, "")
+			e.mr.SetError("READONLY")
+			defer e.mr.SetError("")
+			return get(e, "/v1/me/sessions", cookie)
+		}},
+		{"maintenance", http.StatusServiceUnavailable, func(t *testing.T, e env) *httptest.ResponseRecorder {
+			e.mr.Set(maintenanceKey, `{"enabled":true}`)
+			return postJSON(e.h, "/v1/login", login)
+		}},
+	}
+
+	tested := map[string]int{}
+	for _, tt := range cases {
+		tested[tt.code] = tt.status
+		t.Run(tt.code, func(t *testing.T) {
+			s, mr := newTestServer(t)
+			useFakeRefreshDB(t, s)
+			if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagDeviceTrust}); err != nil {
+				t.Fatal(err)
+			}
+			e := env{s: s, mr: mr, user: newTestUser(t, s, "a@example.com", "user")}
+			e.h = s.Handler()
+
+			rec := tt.run(t, e)
+			if got := readEnvelope(t, rec, tt.status); got.Error.Code != tt.code {
+				t.Errorf("code %q, want %q", got.Error.Code, tt.code)
+			}
+		})
+	}
+
+	documented := documentedErrorCodes(t)
+	for code, status := range documented {
+		if got, ok := tested[code]; !ok {
+			t.Errorf("%s is documented but not tested", code)
+		} else if got != status {
+			t.Errorf("%s is documented with %d but answered with %d", code, status, got)
+		}
+	}
+	for code := range tested {
+		if _, ok := documented[code]; !ok {
+			t.Errorf("%s is tested but not documented", code)
+		}
+	}
+}
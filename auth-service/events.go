@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+const sseHeartbeatInterval = 30 * time.Second
+
+// sseWriteTimeout is the write deadline the stream keeps pushing forward
+// after every write, in place of the server's WriteTimeout, so a live
+// stream stays open but a client that stops reading is still dropped.
+const sseWriteTimeout = 2 * sseHeartbeatInterval
+
+const sessionInvalidatedEvent = `{"event":"session_invalidated"}`
+
+func sessionEventsChannel(sessionID string) string {
+	return "session_events:" + sessionID
+}
+
+// publishSessionInvalidated notifies subscribers that a session has ended.
+// Failures are only logged: the session is already gone and the next
+// request will be rejected anyway, the push just makes it immediate.
+func (s *Server) publishSessionInvalidated(ctx context.Context, sessionID string) {
+	if err := s.rdb.Publish(ctx, sessionEventsChannel(sessionID), sessionInvalidatedEvent).Err(); err != nil {
+		log.Println("session event publish error:", err)
+	}
+}
+
+// sessionEventsHandler serves GET /me/events, a Server-Sent Events stream
+// for the caller's session. It forwards events published on the session's
+// channel and ends the stream after a session_invalidated event, so the
+// browser can log the user out without waiting for the TTL.
+func (s *Server) sessionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok || user.SessionID == "" {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+	sessionID := user.SessionID
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	sub := s.rdb.Subscribe(r.Context(), sessionEventsChannel(sessionID))
+	defer sub.Close()
+	// Wait for the subscription to be confirmed so an invalidation published
+	// right after we answer isn't missed.
+	if _, err := sub.Receive(r.Context()); err != nil {
+		log.Println("session events subscribe error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Event stream unavailable"))
+		return
+	}
+	// The session may have been revoked between auth and subscribing.
+	if s.rdb.Exists(r.Context(), "session:"+sessionID).Val() == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("session_invalid", "Session expired or invalid"))
+		return
+	}
+
+	// Errors are ignored: a writer without deadlines has no server
+	// WriteTimeout to escape either.
+	rc := http.NewResponseController(w)
+	flush := func() {
+		flusher.Flush()
+		rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+	w.WriteHeader(http.StatusOK)
+	flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	events := sub.Channel()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flush()
+		case msg, ok := <-events:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
+			flush()
+			if msg.Payload == sessionInvalidatedEvent {
+				return
+			}
+		}
+	}
+}
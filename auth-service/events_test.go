@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"resilient-auth-service/auth"
+)
+
+// TestSessionEvents has a browser listening on /me/events when its
+// session is revoked: it gets the event, and then the stream ends.
+func TestSessionEvents(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	sessionID, err := s.createSession(context.Background(), "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/me/events", nil)
+	r.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+	resp, err := srv.Client().Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// The headers are only sent once the subscription is confirmed, so
+	// this can't be missed.
+	s.publishSessionInvalidated(context.Background(), sessionID)
+
+	lines := bufio.NewReader(resp.Body)
+	frame, err := lines.ReadString('\n')
+	if err != nil || frame != "data: "+sessionInvalidatedEvent+"\n" {
+		t.Fatalf("got %q, %v; want the session_invalidated frame", frame, err)
+	}
+	if blank, _ := lines.ReadString('\n'); blank != "\n" {
+		t.Errorf("frame ended with %q, want a blank line", blank)
+	}
+	if rest, err := io.ReadAll(lines); err != nil || len(rest) != 0 {
+		t.Errorf("after the event: %q, %v; want the stream closed", rest, err)
+	}
+}
+
+func TestSessionEventsRevokedSession(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	sessionID, err := s.createSession(context.Background(), "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteSession(context.Background(), sessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("through the router", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/me/events", nil)
+		r.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, r)
+		readEnvelope(t, rec, http.StatusUnauthorized)
+	})
+
+	// Revoked after authMiddleware let the request in, but before the
+	// handler subscribed: the handler's own check refuses it.
+	t.Run("between auth and subscribing", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/me/events", nil)
+		r = r.WithContext(auth.SetUser(r.Context(), auth.User{Email: "a@example.com", SessionID: sessionID}))
+		rec := httptest.NewRecorder()
+		s.sessionEventsHandler(rec, r)
+		if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "session_invalid" {
+			t.Errorf("code %q, want session_invalid", env.Error.Code)
+		}
+		if rec.Header().Get("Content-Type") == "text/event-stream" {
+			t.Error("the stream was opened")
+		}
+	})
+}
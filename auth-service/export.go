@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+// exportInterval is how often a user may download their data. Building the
+// export reads their whole history, so it isn't something to allow in a
+// loop.
+const exportInterval = time.Hour
+
+// exportWriteTimeout replaces the server's WriteTimeout for the download,
+// which can run long for an old account.
+const exportWriteTimeout = 5 * time.Minute
+
+type exportUser struct {
+	ID               int            `json:"id"`
+	Email            string         `json:"email"`
+	Role             string         `json:"role"`
+	CreatedAt        time.Time      `json:"created_at"`
+	TwoFactorEnabled bool           `json:"two_factor_enabled"`
+	Profile          map[string]any `json:"profile"`
+}
+
+type exportRefreshToken struct {
+	FamilyID  string    `json:"family_id"`
+	Revoked   bool      `json:"revoked"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// meExportHandler serves GET /me/export[?format=json|csv|ndjson]:
+// everything stored about the caller as one download. The user row is
+// read up front; the histories are streamed a row at a time so a
+// long-lived account doesn't have to fit in memory. Secrets (password and
+// TOTP hashes, token hashes, raw session IDs) are left out.
+func (s *Server) meExportHandler(w http.ResponseWriter, r *http.Request) {
+	caller, ok := auth.UserFromContext(r.Context())
+	if !ok || caller.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+	userID := caller.ID
+	format, err := exportFormatFromRequest(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	ctx := r.Context()
+
+	limitKey := "export_limit:" + strconv.Itoa(userID)
+	allowed, err := s.rdb.SetNX(ctx, limitKey, 1, exportInterval).Result()
+	if err != nil {
+		log.Println("export rate limit error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+	if !allowed {
+		if ttl, err := s.rdb.TTL(ctx, limitKey).Result(); err == nil && ttl > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(ttl.Seconds()))))
+		}
+		apperror.WriteError(w, r, apperror.TooManyRequests("export_rate_limited", "An export was already requested in the last hour"))
+		return
+	}
+
+	user, err := s.loadExportUser(ctx, userID)
+	if err != nil {
+		// Nothing was sent, so don't charge the user their hourly export.
+		s.rdb.Del(ctx, limitKey)
+		log.Println("export user error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "account.exported")
+	ev.ActorID = &userID
+	s.auditor.Record(ctx, ev)
+
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	format.setHeaders(w, fmt.Sprintf("account-export-%d-%s", userID, time.Now().UTC().Format("20060102")))
+
+	// Once the body has started the status can't change; if a section
+	// fails, drop the connection so the client sees a truncated download
+	// rather than a valid-looking, incomplete file.
+	if err := s.writeExport(ctx, w, newExportStream(format, w), user); err != nil {
+		log.Println("export stream error:", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func (s *Server) loadExportUser(ctx context.Context, userID int) (exportUser, error) {
+	var (
+		u       exportUser
+		totp    sql.NullString
+		rawMeta []byte
+	)
+	err := s.withUserScope(ctx, userID, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx,
+			"SELECT id, email, role, created_at, totp_secret, metadata FROM active_users WHERE id = $1",
+			userID,
+		).Scan(&u.ID, &u.Email, &u.Role, &u.CreatedAt, &totp, &rawMeta)
+	})
+	if err != nil {
+		return u, err
+	}
+	u.TwoFactorEnabled = totp.Valid
+	if err := json.Unmarshal(rawMeta, &u.Profile); err != nil {
+		return u, err
+	}
+	return u, nil
+}
+
+func (s *Server) writeExport(ctx context.Context, w http.ResponseWriter, out exportStream, user exportUser) error {
+	flush := func() {
+		if out.flush() == nil {
+			http.NewResponseController(w).Flush()
+		}
+	}
+
+	out.field("exported_at", time.Now().UTC())
+	out.field("user", user)
+
+	sessions, err := s.userSessions(ctx, user.Email, "")
+	if err != nil {
+		return err
+	}
+	out.field("sessions", sessions)
+
+	countries, err := s.rdb.SMembers(ctx, knownLocationsKey(user.ID)).Result()
+	if err != nil {
+		return err
+	}
+	out.field("login_countries", countries)
+
+	devices, err := s.rdb.SCard(ctx, trustedDevicesKey(user.ID)).Result()
+	if err != nil {
+		return err
+	}
+	out.field("trusted_device_count", devices)
+	flush()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT family_id, revoked, created_at, expires_at FROM refresh_tokens
+		 WHERE user_id = $1 ORDER BY created_at, id`,
+		user.ID,
+	)
+	if err != nil {
+		return err
+	}
+	err = out.array("refresh_tokens", rows, func() (any, error) {
+		var t exportRefreshToken
+		err := rows.Scan(&t.FamilyID, &t.Revoked, &t.CreatedAt, &t.ExpiresAt)
+		return t, err
+	})
+	if err != nil {
+		return err
+	}
+	flush()
+
+	for _, section := range []struct{ name, cond string }{
+		{"login_history", "action LIKE 'login.%'"},
+		{"audit_events", "action NOT LIKE 'login.%'"},
+	} {
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT id, level, actor_id, action, target, ip, user_agent, request_id, metadata, created_at
+			 FROM audit_events WHERE actor_id = $1 AND `+section.cond+`
+			 ORDER BY created_at, id`,
+			user.ID,
+		)
+		if err != nil {
+			return err
+		}
+		err = out.array(section.name, rows, func() (any, error) {
+			var (
+				ev   AuditEvent
+				meta []byte
+			)
+			err := rows.Scan(&ev.ID, &ev.Level, &ev.ActorID, &ev.Action, &ev.Target, &ev.IP,
+				&ev.UserAgent, &ev.RequestID, &meta, &ev.CreatedAt)
+			if err == nil {
+				err = json.Unmarshal(meta, &ev.Metadata)
+			}
+			return ev, err
+		})
+		if err != nil {
+			return err
+		}
+		flush()
+	}
+
+	return out.close()
+}
+
+// jsonStream writes a JSON object one field at a time, so large arrays can
+// be written straight from database rows. The first write error sticks and
+// turns later writes into no-ops. Call begin first.
+type jsonStream struct {
+	w      io.Writer
+	err    error
+	fields int
+}
+
+func (s *jsonStream) write(b []byte) {
+	if s.err == nil {
+		_, s.err = s.w.Write(b)
+	}
+}
+
+func (s *jsonStream) value(v any) {
+	if s.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.write(b)
+}
+
+func (s *jsonStream) begin()       { s.write([]byte("{")) }
+func (s *jsonStream) flush() error { return s.err }
+
+func (s *jsonStream) close() error {
+	s.write([]byte("}\n"))
+	return s.err
+}
+
+func (s *jsonStream) key(name string) {
+	if s.fields > 0 {
+		s.write([]byte(","))
+	}
+	s.fields++
+	s.value(name)
+	s.write([]byte(":"))
+}
+
+func (s *jsonStream) field(name string, v any) {
+	s.key(name)
+	s.value(v)
+}
+
+// array writes rows as the array field name, calling scan once per row to
+// get each element. It closes rows.
+func (s *jsonStream) array(name string, rows *sql.Rows, scan func() (any, error)) error {
+	defer rows.Close()
+
+	s.key(name)
+	s.write([]byte("["))
+	for n := 0; rows.Next(); n++ {
+		v, err := scan()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			s.write([]byte(","))
+		}
+		s.value(v)
+		if s.err != nil {
+			return s.err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.write([]byte("]"))
+	return s.err
+}
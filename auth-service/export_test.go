@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeExportDB answers the statements behind GET /me/export for one user:
+// the user-scoped read of their row, their refresh tokens and their audit
+// events, split into logins and the rest.
+type fakeExportDB struct{ userID int }
+
+func (f *fakeExportDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeExportDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeExportDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeExportDB: prepared statements not supported")
+}
+func (f *fakeExportDB) Close() error              { return nil }
+func (f *fakeExportDB) Begin() (driver.Tx, error) { return f, nil }
+func (f *fakeExportDB) Commit() error             { return nil }
+func (f *fakeExportDB) Rollback() error           { return nil }
+
+func (f *fakeExportDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if query != "SET LOCAL ROLE authdb_app" && !strings.HasPrefix(query, "SELECT set_config('app.user_id'") {
+		return nil, fmt.Errorf("fakeExportDB: unexpected exec %q", query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (f *fakeExportDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	actor := int64(f.userID)
+	switch {
+	case strings.HasPrefix(query, "SELECT id, email, role, created_at, totp_secret, metadata FROM active_users"):
+		return &fakeRows{
+			cols: []string{"id", "email", "role", "created_at", "totp_secret", "metadata"},
+			rows: [][]driver.Value{{actor, "a@example.com", "user", created, "secret", []byte(`{"display_name":"Ann"}`)}},
+		}, nil
+	case strings.HasPrefix(query, "SELECT family_id, revoked, created_at, expires_at FROM refresh_tokens"):
+		return &fakeRows{
+			cols: []string{"family_id", "revoked", "created_at", "expires_at"},
+			rows: [][]driver.Value{{"f1", false, created, created.Add(30 * 24 * time.Hour)}},
+		}, nil
+	case strings.HasPrefix(query, "SELECT id, level, actor_id, action, target, ip, user_agent, request_id, metadata, created_at FROM audit_events"):
+		action := "password.changed"
+		if strings.Contains(query, "action LIKE 'login.%'") {
+			action = "login.success"
+		}
+		return &fakeRows{
+			cols: []string{"id", "level", "actor_id", "action", "target", "ip", "user_agent", "request_id", "metadata", "created_at"},
+			rows: [][]driver.Value{{int64(1), "info", actor, action, "", "192.0.2.1", "test", "req-1", []byte(`{}`), created}},
+		}, nil
+	}
+	return nil, fmt.Errorf("fakeExportDB: unexpected query %q", query)
+}
+
+// TestMeExport downloads a user's data: every category is in the file,
+// served as an attachment, and a second download within the hour is
+// refused until the first one's hour is up.
+func TestMeExport(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	user := newTestUser(t, s, "a@example.com", "user")
+	db := sql.OpenDB(&fakeExportDB{userID: user.ID})
+	t.Cleanup(func() { db.Close() })
+	s.db = db
+	ctx := context.Background()
+	if _, err := s.createSession(ctx, user.Email, ""); err != nil {
+		t.Fatal(err)
+	}
+	mr.SAdd(knownLocationsKey(user.ID), "DE")
+	mr.SAdd(trustedDevicesKey(user.ID), "device-1", "device-2")
+	h := s.Handler()
+
+	export := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/me/export", nil)
+		r.AddCookie(accessTokenCookie(t, s, user))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := export()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") {
+		t.Errorf("Content-Disposition %q, want an attachment", cd)
+	}
+	var got struct {
+		ExportedAt         *time.Time           `json:"exported_at"`
+		User               exportUser           `json:"user"`
+		Sessions           []sessionInfo        `json:"sessions"`
+		LoginCountries     []string             `json:"login_countries"`
+		TrustedDeviceCount int                  `json:"trusted_device_count"`
+		RefreshTokens      []exportRefreshToken `json:"refresh_tokens"`
+		LoginHistory       []AuditEvent         `json:"login_history"`
+		AuditEvents        []AuditEvent         `json:"audit_events"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	switch {
+	case got.ExportedAt == nil:
+		t.Error("no exported_at")
+	case got.User.Email != user.Email || !got.User.TwoFactorEnabled || got.User.Profile["display_name"] != "Ann":
+		t.Errorf("user %+v", got.User)
+	case len(got.Sessions) != 1:
+		t.Errorf("sessions %+v, want the one created", got.Sessions)
+	case len(got.LoginCountries) != 1 || got.LoginCountries[0] != "DE":
+		t.Errorf("login_countries %v, want [DE]", got.LoginCountries)
+	case got.TrustedDeviceCount != 2:
+		t.Errorf("trusted_device_count %d, want 2", got.TrustedDeviceCount)
+	case len(got.RefreshTokens) != 1 || got.RefreshTokens[0].FamilyID != "f1":
+		t.Errorf("refresh_tokens %+v", got.RefreshTokens)
+	case len(got.LoginHistory) != 1 || got.LoginHistory[0].Action != "login.success":
+		t.Errorf("login_history %+v", got.LoginHistory)
+	case len(got.AuditEvents) != 1 || got.AuditEvents[0].Action != "password.changed":
+		t.Errorf("audit_events %+v", got.AuditEvents)
+	}
+	for _, secret := range []string{`"secret"`, "password_hash", "totp_secret"} {
+		if strings.Contains(rec.Body.String(), secret) {
+			t.Errorf("export contains %s", secret)
+		}
+	}
+
+	mr.FastForward(exportInterval / 2)
+	rec = export()
+	if env := readEnvelope(t, rec, http.StatusTooManyRequests); env.Error.Code != "export_rate_limited" {
+		t.Errorf("second export: code %q, want export_rate_limited", env.Error.Code)
+	}
+	if ra := rec.Header().Get("Retry-After"); ra != "1800" {
+		t.Errorf("Retry-After %q, want 1800", ra)
+	}
+
+	mr.FastForward(exportInterval / 2)
+	if rec := export(); rec.Code != http.StatusOK {
+		t.Errorf("after an hour: status %d, want 200", rec.Code)
+	}
+}
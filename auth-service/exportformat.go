@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"resilient-auth-service/apperror"
+)
+
+// exportFormat is a download format, chosen with ?format=.
+type exportFormat struct {
+	name        string
+	contentType string
+}
+
+var exportFormats = map[string]exportFormat{
+	"json":   {"json", "application/json"},
+	"csv":    {"csv", "text/csv; charset=utf-8"},
+	"ndjson": {"ndjson", "application/x-ndjson"},
+}
+
+// exportFormatFromRequest returns the ?format= asked for, JSON by default.
+func exportFormatFromRequest(r *http.Request) (exportFormat, error) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "json"
+	}
+	f, ok := exportFormats[name]
+	if !ok {
+		return exportFormat{}, apperror.BadRequest("invalid_parameter", "Invalid format").
+			WithField("format", "invalid_value", "must be json, csv or ndjson")
+	}
+	return f, nil
+}
+
+// setHeaders marks the response as a download of filename, to which the
+// format's extension is added.
+func (f exportFormat) setHeaders(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", f.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, f.name))
+}
+
+// exportStream writes an export as a series of named sections, each a
+// value or an array of rows, in whatever shape the format gives them.
+// flush pushes out anything buffered; close finishes the document. Both
+// return the first error, after which further writes do nothing.
+type exportStream interface {
+	field(name string, v any)
+	array(name string, rows *sql.Rows, scan func() (any, error)) error
+	flush() error
+	close() error
+}
+
+func newExportStream(f exportFormat, w io.Writer) exportStream {
+	switch f.name {
+	case "csv":
+		return newCSVStream(w)
+	case "ndjson":
+		return &ndjsonStream{w: w}
+	}
+	s := &jsonStream{w: w}
+	s.begin()
+	return s
+}
+
+// eachElement calls fn for each element of v and reports true if v is a
+// slice; otherwise it does nothing and reports false.
+func eachElement(v any, fn func(i int, elem any)) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return false
+	}
+	for i := range rv.Len() {
+		fn(i, rv.Index(i).Interface())
+	}
+	return true
+}
+
+// ndjsonStream writes one {"section": ..., "data": ...} object per line,
+// with arrays split into a line per element, so a client can process the
+// export as it arrives.
+type ndjsonStream struct {
+	w   io.Writer
+	err error
+}
+
+func (s *ndjsonStream) line(section string, v any) {
+	if s.err != nil {
+		return
+	}
+	b, err := json.Marshal(struct {
+		Section string `json:"section"`
+		Data    any    `json:"data"`
+	}{section, v})
+	if err != nil {
+		s.err = err
+		return
+	}
+	_, s.err = s.w.Write(append(b, '\n'))
+}
+
+func (s *ndjsonStream) field(name string, v any) {
+	if !eachElement(v, func(_ int, elem any) { s.line(name, elem) }) {
+		s.line(name, v)
+	}
+}
+
+func (s *ndjsonStream) array(name string, rows *sql.Rows, scan func() (any, error)) error {
+	defer rows.Close()
+	for rows.Next() {
+		v, err := scan()
+		if err != nil {
+			return err
+		}
+		s.line(name, v)
+		if s.err != nil {
+			return s.err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func (s *ndjsonStream) flush() error { return s.err }
+func (s *ndjsonStream) close() error { return s.err }
+
+// csvStream writes each section as a table of its own: a header of
+// "section" and the section's columns, then one line per value, or per
+// element of an array section, starting with the section name. A struct
+// gets a column per JSON field, in declaration order, so the user's fields
+// sit side by side as in a spreadsheet; anything else gets one "value"
+// column. Tables are separated by a blank line, which CSV readers skip,
+// so they must allow a varying number of fields per record.
+type csvStream struct {
+	w   *csv.Writer
+	err error
+	// section is the table being written, and columns its header; nil
+	// columns means the one "value" column.
+	section string
+	columns []string
+}
+
+func newCSVStream(w io.Writer) *csvStream {
+	return &csvStream{w: csv.NewWriter(w)}
+}
+
+func (s *csvStream) write(record []string) {
+	if s.err == nil {
+		s.err = s.w.Write(record)
+	}
+}
+
+// record writes v as a line of section's table, starting the table if v
+// is its first line. v goes through JSON first, so its fields are named
+// and formatted as in the JSON export.
+func (s *csvStream) record(section string, v any) {
+	if s.err != nil {
+		return
+	}
+	if section != s.section {
+		if s.section != "" {
+			s.write(nil)
+		}
+		s.section = section
+		s.columns = csvColumns(v)
+		if s.columns == nil {
+			s.write([]string{"section", "value"})
+		} else {
+			s.write(append([]string{"section"}, s.columns...))
+		}
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		s.err = err
+		return
+	}
+
+	obj, ok := generic.(map[string]any)
+	if !ok || s.columns == nil {
+		s.write([]string{section, csvCell(generic)})
+		return
+	}
+	line := []string{section}
+	for _, c := range s.columns {
+		line = append(line, csvCell(obj[c]))
+	}
+	s.write(line)
+}
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// csvColumns returns the JSON field names of v's struct type, including
+// omitempty ones so every line of a table has the same columns, or nil if
+// v isn't a struct or marshals itself, like time.Time.
+func csvColumns(v any) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct ||
+		t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return nil
+	}
+	var cols []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		cols = append(cols, name)
+	}
+	return cols
+}
+
+func (s *csvStream) field(name string, v any) {
+	if !eachElement(v, func(_ int, elem any) { s.record(name, elem) }) {
+		s.record(name, v)
+	}
+}
+
+func (s *csvStream) array(name string, rows *sql.Rows, scan func() (any, error)) error {
+	defer rows.Close()
+	for rows.Next() {
+		v, err := scan()
+		if err != nil {
+			return err
+		}
+		s.record(name, v)
+		if s.err != nil {
+			return s.err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func (s *csvStream) flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	s.w.Flush()
+	s.err = s.w.Error()
+	return s.err
+}
+
+func (s *csvStream) close() error { return s.flush() }
+
+// csvCell formats a decoded JSON value for a CSV cell: strings as they
+// are, nested objects and arrays as JSON. Strings a spreadsheet would
+// read as a formula get a leading quote, since exports hold text users
+// chose, like user agents and profile fields.
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
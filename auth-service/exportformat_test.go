@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	testExportedAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	testExportUser = exportUser{
+		ID:        42,
+		Email:     "a@example.com",
+		Role:      "user",
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Profile: map[string]any{
+			"display_name": `Ann "the admin", Jr.`,
+			"bio":          "line one\nline two",
+			"website":      "=HYPERLINK(\"http://evil.example\")",
+		},
+	}
+	testExportSessions = []sessionInfo{
+		{ID: "s1", Current: true, ExpiresAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: "s2", ExpiresAt: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	testExportTokens = []exportRefreshToken{
+		{FamilyID: "f1", Revoked: true, CreatedAt: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), ExpiresAt: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{FamilyID: "f1", CreatedAt: time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC), ExpiresAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+)
+
+// writeTestExport writes the same sections as writeExport, from fixed
+// values rather than the database.
+func writeTestExport(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	out := newExportStream(exportFormats[format], &buf)
+	out.field("exported_at", testExportedAt)
+	out.field("user", testExportUser)
+	out.field("sessions", testExportSessions)
+	out.field("login_countries", []string{"DE", "FR"})
+	out.field("trusted_device_count", int64(1))
+
+	var rows [][]driver.Value
+	for _, rt := range testExportTokens {
+		rows = append(rows, []driver.Value{rt.FamilyID, rt.Revoked, rt.CreatedAt, rt.ExpiresAt})
+	}
+	r := queryRows(t, []string{"family_id", "revoked", "created_at", "expires_at"}, rows...)
+	err := out.array("refresh_tokens", r, func() (any, error) {
+		var rt exportRefreshToken
+		err := r.Scan(&rt.FamilyID, &rt.Revoked, &rt.CreatedAt, &rt.ExpiresAt)
+		return rt, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := out.close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportJSON(t *testing.T) {
+	var got struct {
+		ExportedAt         time.Time            `json:"exported_at"`
+		User               exportUser           `json:"user"`
+		Sessions           []sessionInfo        `json:"sessions"`
+		LoginCountries     []string             `json:"login_countries"`
+		TrustedDeviceCount int                  `json:"trusted_device_count"`
+		RefreshTokens      []exportRefreshToken `json:"refresh_tokens"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(writeTestExport(t, "json")))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if dec.More() {
+		t.Error("more than one JSON document")
+	}
+
+	if !got.ExportedAt.Equal(testExportedAt) || got.TrustedDeviceCount != 1 {
+		t.Errorf("scalars: got %v, %d", got.ExportedAt, got.TrustedDeviceCount)
+	}
+	if !reflect.DeepEqual(got.User, testExportUser) {
+		t.Errorf("user: got %+v, want %+v", got.User, testExportUser)
+	}
+	if !reflect.DeepEqual(got.Sessions, testExportSessions) {
+		t.Errorf("sessions: got %+v", got.Sessions)
+	}
+	if !reflect.DeepEqual(got.LoginCountries, []string{"DE", "FR"}) {
+		t.Errorf("login_countries: got %v", got.LoginCountries)
+	}
+	if !reflect.DeepEqual(got.RefreshTokens, testExportTokens) {
+		t.Errorf("refresh_tokens: got %+v", got.RefreshTokens)
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	dec := json.NewDecoder(bytes.NewReader(writeTestExport(t, "ndjson")))
+	dec.DisallowUnknownFields()
+	counts := map[string]int{}
+	var order []string
+	for {
+		var line struct {
+			Section string          `json:"section"`
+			Data    json.RawMessage `json:"data"`
+		}
+		err := dec.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counts[line.Section] == 0 {
+			order = append(order, line.Section)
+		}
+		counts[line.Section]++
+
+		if line.Section == "user" {
+			var u exportUser
+			if err := json.Unmarshal(line.Data, &u); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(u, testExportUser) {
+				t.Errorf("user: got %+v", u)
+			}
+		}
+	}
+
+	wantOrder := []string{"exported_at", "user", "sessions", "login_countries", "trusted_device_count", "refresh_tokens"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("sections %v, want %v", order, wantOrder)
+	}
+	wantCounts := map[string]int{"exported_at": 1, "user": 1, "sessions": 2, "login_countries": 2, "trusted_device_count": 1, "refresh_tokens": 2}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("lines per section %v, want %v", counts, wantCounts)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	r := csv.NewReader(bytes.NewReader(writeTestExport(t, "csv")))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Split the file back into its tables.
+	type table struct {
+		header []string
+		rows   [][]string
+	}
+	tables := map[string]*table{}
+	var current []string
+	for _, rec := range records {
+		if rec[0] == "section" {
+			current = rec
+			continue
+		}
+		if current == nil || len(rec) != len(current) {
+			t.Fatalf("record %q doesn't match header %q", rec, current)
+		}
+		tb := tables[rec[0]]
+		if tb == nil {
+			tb = &table{header: current}
+			tables[rec[0]] = tb
+		}
+		tb.rows = append(tb.rows, rec)
+	}
+
+	user := tables["user"]
+	if user == nil {
+		t.Fatal("no user table")
+	}
+	wantHeader := []string{"section", "id", "email", "role", "created_at", "two_factor_enabled", "profile"}
+	if !reflect.DeepEqual(user.header, wantHeader) {
+		t.Errorf("user header %q, want %q", user.header, wantHeader)
+	}
+	if len(user.rows) != 1 {
+		t.Fatalf("user rows %q, want one", user.rows)
+	}
+	row := user.rows[0]
+	if row[1] != "42" || row[2] != "a@example.com" || row[3] != "user" ||
+		row[4] != "2025-06-01T12:00:00Z" || row[5] != "false" {
+		t.Errorf("user row %q", row)
+	}
+	var profile map[string]any
+	if err := json.Unmarshal([]byte(row[6]), &profile); err != nil {
+		t.Fatalf("profile cell %q: %v", row[6], err)
+	}
+	if !reflect.DeepEqual(profile, testExportUser.Profile) {
+		t.Errorf("profile %v, want %v", profile, testExportUser.Profile)
+	}
+
+	sessions := tables["sessions"]
+	if sessions == nil || !reflect.DeepEqual(sessions.header, []string{"section", "id", "current", "expires_at"}) ||
+		len(sessions.rows) != 2 || sessions.rows[0][1] != "s1" || sessions.rows[0][2] != "true" {
+		t.Errorf("sessions table %+v", sessions)
+	}
+	countries := tables["login_countries"]
+	if countries == nil || !reflect.DeepEqual(countries.header, []string{"section", "value"}) ||
+		!reflect.DeepEqual(countries.rows, [][]string{{"login_countries", "DE"}, {"login_countries", "FR"}}) {
+		t.Errorf("login_countries table %+v", countries)
+	}
+	if tb := tables["exported_at"]; tb == nil || tb.rows[0][1] != "2026-01-02T03:04:05Z" {
+		t.Errorf("exported_at table %+v", tb)
+	}
+	tokens := tables["refresh_tokens"]
+	if tokens == nil || !reflect.DeepEqual(tokens.header, []string{"section", "family_id", "revoked", "created_at", "expires_at"}) ||
+		len(tokens.rows) != 2 || tokens.rows[0][2] != "true" {
+		t.Errorf("refresh_tokens table %+v", tokens)
+	}
+}
+
+// Strings a spreadsheet would evaluate are defused; everything else is
+// written as is.
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{"plain", "plain"},
+		{"=1+1", "'=1+1"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tx", "'\tx"},
+		{"", ""},
+		{nil, ""},
+		{json.Number("12"), "12"},
+		{true, "true"},
+		{map[string]any{"a": "b"}, `{"a":"b"}`},
+		{[]any{"x", json.Number("1")}, `["x",1]`},
+	}
+	for _, tt := range tests {
+		if got := csvCell(tt.in); got != tt.want {
+			t.Errorf("csvCell(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExportFormatFromRequest(t *testing.T) {
+	tests := []struct {
+		query, contentType, ext string
+		wantErr                 bool
+	}{
+		{"", "application/json", "json", false},
+		{"?format=json", "application/json", "json", false},
+		{"?format=csv", "text/csv; charset=utf-8", "csv", false},
+		{"?format=ndjson", "application/x-ndjson", "ndjson", false},
+		{"?format=xml", "", "", true},
+	}
+	for _, tt := range tests {
+		f, err := exportFormatFromRequest(httptest.NewRequest(http.MethodGet, "/me/export"+tt.query, nil))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err %v", tt.query, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		rec := httptest.NewRecorder()
+		f.setHeaders(rec, "export")
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%q: Content-Type %q, want %q", tt.query, got, tt.contentType)
+		}
+		if got, want := rec.Header().Get("Content-Disposition"), `attachment; filename="export.`+tt.ext+`"`; got != want {
+			t.Errorf("%q: Content-Disposition %q, want %q", tt.query, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"resilient-auth-service/flags"
+
+	"resilient-auth-service/apperror"
+)
+
+// Feature flags guarding login methods that are still rolling out.
+const (
+	flagTOTPLogin   = "totp_login"
+	flagDeviceTrust = "device_trust"
+	flagPasskeys    = "webauthn"
+)
+
+// requireFlag answers 404 while the named feature is off for everyone, so a
+// disabled flow looks like it doesn't exist.
+func (s *Server) requireFlag(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.featureFlags.IsEnabled(r.Context(), name) {
+			apperror.WriteError(w, r, apperror.NotFound("not_found", "Not found"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminListFlagsHandler serves GET /admin/flags.
+func (s *Server) adminListFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	all, err := s.featureFlags.List(r.Context())
+	if err != nil {
+		log.Println("admin list flags error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Flags unavailable"))
+		return
+	}
+
+	// By name, so the order doesn't change from one request to the next.
+	list := make([]flags.Flag, 0, len(all))
+	for _, f := range all {
+		list = append(list, f)
+	}
+	slices.SortFunc(list, func(a, b flags.Flag) int { return strings.Compare(a.Name, b.Name) })
+	writeJSON(w, http.StatusOK, map[string]any{"flags": list})
+}
+
+type flagRequest struct {
+	Enabled    bool `json:"enabled"`
+	Percentage *int `json:"percentage"`
+}
+
+func (req flagRequest) validate() error {
+	var v validator
+	if req.Percentage != nil {
+		v.between("percentage", *req.Percentage, 0, 100)
+	}
+	return v.err()
+}
+
+// adminSetFlagHandler serves PUT /admin/flags/{name}. Percentage defaults
+// to 100.
+func (s *Server) adminSetFlagHandler(w http.ResponseWriter, r *http.Request) {
+	var req flagRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	f := flags.Flag{Name: r.PathValue("name"), Enabled: req.Enabled, Percentage: 100}
+	if req.Percentage != nil {
+		f.Percentage = *req.Percentage
+	}
+	if err := f.Validate(); err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_flag", err.Error()))
+		return
+	}
+
+	all, err := s.featureFlags.List(r.Context())
+	if err == nil {
+		err = s.featureFlags.Set(r.Context(), f)
+	}
+	if err != nil {
+		log.Println("admin set flag error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Flags unavailable"))
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "admin.flag_update")
+	ev.Level = "warning"
+	ev.Target = "flag:" + f.Name
+	ev.Metadata = map[string]any{"before": all[f.Name], "after": f}
+	s.auditor.Record(r.Context(), ev)
+
+	writeJSON(w, http.StatusOK, f)
+}
@@ -0,0 +1,90 @@
+// Package flags gates features at runtime, with optional percentage
+// rollouts bucketed on user ID.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Flag is the state of one feature. Percentage only matters when Enabled:
+// 100 turns the feature on for everyone, lower values for a stable subset
+// of users.
+type Flag struct {
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+	Percentage int    `json:"percentage"`
+}
+
+// Flags answers whether a feature is on.
+type Flags interface {
+	// IsEnabled reports whether the feature is on for anyone at all, e.g.
+	// whether its endpoints should exist.
+	IsEnabled(ctx context.Context, name string) bool
+	// IsEnabledFor reports whether the feature is on for this user, applying
+	// any percentage rollout.
+	IsEnabledFor(ctx context.Context, name string, userID int) bool
+}
+
+func (f Flag) enabled() bool {
+	return f.Enabled && f.Percentage > 0
+}
+
+func (f Flag) enabledFor(userID int) bool {
+	return f.Enabled && Bucket(f.Name, userID) < f.Percentage
+}
+
+// Bucket maps a user to 0..99 for the named flag. It is deterministic, so a
+// user stays in or out of a rollout across requests and replicas, and
+// salted with the flag name so the same users aren't first for every flag.
+func Bucket(name string, userID int) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + strconv.Itoa(userID)))
+	return int(h.Sum32() % 100)
+}
+
+// Validate checks that f is well formed.
+func (f Flag) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("flag name is required")
+	}
+	if f.Percentage < 0 || f.Percentage > 100 {
+		return fmt.Errorf("flag %s: percentage must be between 0 and 100", f.Name)
+	}
+	return nil
+}
+
+// Parse reads a static flag list such as "magic_link=on,webauthn=25%,beta=off".
+func Parse(spec string) (map[string]Flag, error) {
+	flags := map[string]Flag{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("flag %q: want name=on|off|N%%", item)
+		}
+		f := Flag{Name: strings.TrimSpace(name)}
+		switch value = strings.TrimSpace(value); value {
+		case "on", "true":
+			f.Enabled, f.Percentage = true, 100
+		case "off", "false":
+		default:
+			p, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			if err != nil {
+				return nil, fmt.Errorf("flag %q: want name=on|off|N%%", item)
+			}
+			f.Enabled, f.Percentage = true, p
+		}
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+		flags[f.Name] = f
+	}
+	return flags, nil
+}
@@ -0,0 +1,125 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+// Buckets are pinned: changing the hash would move users in and out of
+// every running rollout.
+func TestBucketStable(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int
+		want   int
+	}{
+		{"webauthn", 1, 70},
+		{"webauthn", 42, 17},
+		{"magic_link", 42, 11},
+		{"magic_link", 1000, 80},
+	}
+	for _, tt := range tests {
+		for range 3 {
+			if got := Bucket(tt.name, tt.userID); got != tt.want {
+				t.Errorf("Bucket(%q, %d) = %d, want %d", tt.name, tt.userID, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestBucketDistribution(t *testing.T) {
+	const users = 20000
+	counts := make([]int, 100)
+	same := 0
+	for id := range users {
+		b := Bucket("webauthn", id)
+		if b < 0 || b > 99 {
+			t.Fatalf("Bucket(webauthn, %d) = %d, outside 0..99", id, b)
+		}
+		counts[b]++
+		if Bucket("magic_link", id) == b {
+			same++
+		}
+	}
+	// 200 expected per bucket; this is many standard deviations wide.
+	for b, n := range counts {
+		if n < 100 || n > 300 {
+			t.Errorf("bucket %d holds %d of %d users", b, n, users)
+		}
+	}
+	// Salting by name: about 1 in 100 users share a bucket across flags.
+	if same > users/20 {
+		t.Errorf("%d of %d users have the same bucket for two flags", same, users)
+	}
+}
+
+// A user in a rollout stays in as the percentage grows, and the share of
+// users in tracks the percentage.
+func TestPercentageRollout(t *testing.T) {
+	const users = 10000
+	for _, pct := range []int{0, 1, 10, 25, 50, 99, 100} {
+		f := Flag{Name: "webauthn", Enabled: true, Percentage: pct}
+		wider := Flag{Name: "webauthn", Enabled: true, Percentage: min(pct+10, 100)}
+		in := 0
+		for id := range users {
+			if !f.enabledFor(id) {
+				continue
+			}
+			in++
+			if !wider.enabledFor(id) {
+				t.Fatalf("user %d in at %d%% but out at %d%%", id, pct, wider.Percentage)
+			}
+		}
+		if want := users * pct / 100; in < want-users/50 || in > want+users/50 {
+			t.Errorf("%d%%: %d of %d users in, want about %d", pct, in, users, want)
+		}
+		if pct == 100 && in != users {
+			t.Errorf("100%%: %d of %d users in, want all", in, users)
+		}
+	}
+
+	off := Flag{Name: "webauthn", Percentage: 100}
+	for id := range 100 {
+		if off.enabledFor(id) {
+			t.Fatalf("disabled flag on for user %d", id)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	flags, err := Parse("magic_link=on, webauthn=25%, beta=off, zero=0%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStatic(flags)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"magic_link", true},
+		{"webauthn", true},
+		{"beta", false},
+		{"zero", false},
+		{"unknown", false},
+	}
+	for _, tt := range tests {
+		if got := s.IsEnabled(ctx, tt.name); got != tt.want {
+			t.Errorf("IsEnabled(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	for id := range 200 {
+		if got, want := s.IsEnabledFor(ctx, "webauthn", id), Bucket("webauthn", id) < 25; got != want {
+			t.Fatalf("IsEnabledFor(webauthn, %d) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"magic_link", "webauthn=lots", "webauthn=101%", "webauthn=-1%", "=on"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) accepted", spec)
+		}
+	}
+}
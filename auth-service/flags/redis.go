@@ -0,0 +1,107 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKey = "feature_flags"
+
+// Redis stores flags in a Redis hash so admins can change them at runtime.
+// Flags not set in Redis fall back to the static defaults. Reads are served
+// from a local copy refreshed at most every ttl, so checking a flag on a
+// hot path costs no round trip and a change reaches every replica within
+// ttl.
+type Redis struct {
+	rdb      *redis.Client
+	defaults *Static
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	cached    map[string]Flag
+	fetchedAt time.Time
+}
+
+func NewRedis(rdb *redis.Client, defaults *Static, ttl time.Duration) *Redis {
+	return &Redis{rdb: rdb, defaults: defaults, ttl: ttl}
+}
+
+func (r *Redis) IsEnabled(ctx context.Context, name string) bool {
+	return r.get(ctx)[name].enabled()
+}
+
+func (r *Redis) IsEnabledFor(ctx context.Context, name string, userID int) bool {
+	return r.get(ctx)[name].enabledFor(userID)
+}
+
+// List returns every known flag, runtime overrides applied.
+func (r *Redis) List(ctx context.Context) (map[string]Flag, error) {
+	return r.load(ctx)
+}
+
+// Set stores f and drops the local cache so this replica sees it at once.
+func (r *Redis) Set(ctx context.Context, f Flag) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := r.rdb.HSet(ctx, redisKey, f.Name, data).Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.fetchedAt = time.Time{}
+	r.mu.Unlock()
+	return nil
+}
+
+// get returns the cached flags, refreshing them once the TTL has passed.
+// If Redis is unreachable the last known flags (or the defaults) are used.
+func (r *Redis) get(ctx context.Context) map[string]Flag {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cached != nil && time.Since(r.fetchedAt) < r.ttl {
+		return r.cached
+	}
+
+	flags, err := r.load(ctx)
+	if err != nil {
+		log.Println("feature flags refresh error:", err)
+		if r.cached == nil {
+			r.cached = r.defaults.All()
+		}
+		// Don't hammer Redis while it's down; retry after another TTL.
+		r.fetchedAt = time.Now()
+		return r.cached
+	}
+	r.cached, r.fetchedAt = flags, time.Now()
+	return r.cached
+}
+
+func (r *Redis) load(ctx context.Context) (map[string]Flag, error) {
+	stored, err := r.rdb.HGetAll(ctx, redisKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := r.defaults.All()
+	for name, data := range stored {
+		var f Flag
+		if err := json.Unmarshal([]byte(data), &f); err != nil {
+			log.Printf("feature flag %s: ignoring invalid value: %v", name, err)
+			continue
+		}
+		f.Name = name
+		flags[name] = f
+	}
+	return flags, nil
+}
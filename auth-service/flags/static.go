@@ -0,0 +1,30 @@
+package flags
+
+import "context"
+
+// Static serves a fixed set of flags, typically parsed from configuration.
+// Unknown flags are off.
+type Static struct {
+	flags map[string]Flag
+}
+
+func NewStatic(flags map[string]Flag) *Static {
+	return &Static{flags: flags}
+}
+
+func (s *Static) IsEnabled(ctx context.Context, name string) bool {
+	return s.flags[name].enabled()
+}
+
+func (s *Static) IsEnabledFor(ctx context.Context, name string, userID int) bool {
+	return s.flags[name].enabledFor(userID)
+}
+
+// All returns a copy of the configured flags.
+func (s *Static) All() map[string]Flag {
+	out := make(map[string]Flag, len(s.flags))
+	for k, v := range s.flags {
+		out[k] = v
+	}
+	return out
+}
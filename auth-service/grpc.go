@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"resilient-auth-service/authpb"
+)
+
+var grpcHandledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_server_handled_total",
+	Help: "gRPC calls completed, by method and status code.",
+}, []string{"method", "code"})
+
+var grpcHandlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "grpc_server_handling_seconds",
+	Help:    "gRPC call latency by method.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method"})
+
+// authServer implements authpb.AuthService on the same Postgres and Redis
+// clients, and the same session helpers, as the HTTP handlers. Handlers
+// pass the call's context to every store call, so a caller's deadline
+// bounds the queries made for it.
+type authServer struct {
+	authpb.UnimplementedAuthServiceServer
+	s *Server
+}
+
+func (a authServer) ValidateSession(ctx context.Context, req *authpb.ValidateSessionRequest) (*authpb.ValidateSessionResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	email, _, err := a.s.lookupSession(ctx, req.GetSessionId(), false)
+	if err == redis.Nil {
+		return nil, status.Error(codes.Unauthenticated, "session expired or invalid")
+	}
+	if err != nil {
+		return nil, grpcStoreError("session lookup", err)
+	}
+	ttl, err := a.s.rdb.PTTL(ctx, "session:"+req.GetSessionId()).Result()
+	if err != nil {
+		return nil, grpcStoreError("session ttl", err)
+	}
+	if ttl <= 0 {
+		// Expired between the two reads.
+		return nil, status.Error(codes.Unauthenticated, "session expired or invalid")
+	}
+
+	resp := &authpb.ValidateSessionResponse{
+		Email:     email,
+		ExpiresAt: timestamppb.New(a.s.clock.Now().Add(ttl)),
+	}
+	user, err := a.s.users.GetByEmail(ctx, email)
+	if errors.Is(err, ErrNotFound) {
+		// The user was deleted but the session lingered.
+		return nil, status.Error(codes.Unauthenticated, "session expired or invalid")
+	}
+	if err != nil {
+		return nil, grpcStoreError("session user lookup", err)
+	}
+	resp.UserId, resp.Role = int64(user.ID), user.Role
+	return resp, nil
+}
+
+func (a authServer) GetUser(ctx context.Context, req *authpb.GetUserRequest) (*authpb.User, error) {
+	user, err := a.s.users.GetByID(ctx, int(req.GetId()))
+	if errors.Is(err, ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, grpcStoreError("get user", err)
+	}
+	return &authpb.User{
+		Id:        int64(user.ID),
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}, nil
+}
+
+func (a authServer) RevokeSession(ctx context.Context, req *authpb.RevokeSessionRequest) (*authpb.RevokeSessionResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if err := a.s.DeleteSession(ctx, req.GetSessionId()); err != nil {
+		return nil, grpcStoreError("revoke session", err)
+	}
+
+	caller, _ := ctx.Value(grpcCallerKey).(string)
+	a.s.auditor.Record(ctx, AuditEvent{
+		Action:   "session.revoked_by_service",
+		Target:   "session:" + sessionPublicID(req.GetSessionId()),
+		Metadata: map[string]any{"service": caller},
+	})
+	return &authpb.RevokeSessionResponse{}, nil
+}
+
+// grpcStoreError logs a failed database or Redis call and converts it to a
+// status: the caller's own deadline or cancellation is reported as such,
+// a statement timeout as UNAVAILABLE so clients back off, and anything
+// else as INTERNAL without details.
+func grpcStoreError(op string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+	log.Printf("grpc %s error: %v", op, err)
+	if isStatementTimeout(err) {
+		return status.Error(codes.Unavailable, "service temporarily unavailable")
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+const grpcCallerKey contextKey = "grpcCaller"
+
+// grpcAuthInterceptor admits callers that either presented a client
+// certificate our CA verified (identified by its common name) or sent
+// "authorization: Bearer <token>" with one of cfg.GRPCServiceTokens
+// (identified by the token's name). The caller's name goes into the
+// context for logging and auditing.
+func (s *Server) grpcAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	caller, ok := s.grpcCaller(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid service credentials")
+	}
+	return handler(context.WithValue(ctx, grpcCallerKey, caller), req)
+}
+
+func (s *Server) grpcCaller(ctx context.Context) (string, bool) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName, true
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(v, "Bearer ")
+		if !ok {
+			continue
+		}
+		// Check every token so timing doesn't reveal which one matched.
+		caller := ""
+		for name, want := range s.cfg.GRPCServiceTokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+				caller = name
+			}
+		}
+		if caller != "" {
+			return caller, true
+		}
+	}
+	return "", false
+}
+
+// grpcMetricsInterceptor runs outermost so rejected calls are counted too.
+func grpcMetricsInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	grpcHandlingSeconds.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+	grpcHandledTotal.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+	return resp, err
+}
+
+func grpcLoggingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	caller, _ := ctx.Value(grpcCallerKey).(string)
+	log.Printf("grpc method=%s caller=%s code=%s duration=%s",
+		info.FullMethod, caller, status.Code(err), time.Since(start).Round(time.Microsecond))
+	return resp, err
+}
+
+// grpcRecoverInterceptor turns a handler panic into INTERNAL, like
+// recoverMiddleware does for HTTP.
+func grpcRecoverInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panicsTotal.Inc()
+			log.Printf("grpc panic in %s: %v", info.FullMethod, rec)
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// newGRPCServer builds the internal gRPC server. Logging runs inside auth so
+// it can name the caller.
+func (s *Server) newGRPCServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			grpcMetricsInterceptor,
+			grpcRecoverInterceptor,
+			s.grpcAuthInterceptor,
+			grpcLoggingInterceptor,
+		),
+	}
+	if s.cfg.GRPCTLSCertFile != "" {
+		creds, err := s.grpcTLSCredentials()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	srv := grpc.NewServer(opts...)
+	authpb.RegisterAuthServiceServer(srv, authServer{s: s})
+	return srv, nil
+}
+
+// grpcTLSCredentials serves TLS with the configured certificate. With a
+// client CA, callers may present certificates signed by it instead of a
+// token; it doesn't require one, so token callers still get in.
+func (s *Server) grpcTLSCredentials() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(s.cfg.GRPCTLSCertFile, s.cfg.GRPCTLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading gRPC certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if s.cfg.GRPCClientCAFile != "" {
+		pem, err := os.ReadFile(s.cfg.GRPCClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading gRPC client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("gRPC client CA file holds no certificates")
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// serveGRPC starts the gRPC server on cfg.GRPCAddr in the background.
+func (s *Server) serveGRPC(srv *grpc.Server) {
+	lis, err := net.Listen("tcp", s.cfg.GRPCAddr)
+	if err != nil {
+		log.Fatal("gRPC listen error:", err)
+	}
+	go func() {
+		log.Println("gRPC service running on", s.cfg.GRPCAddr)
+		if err := srv.Serve(lis); err != nil {
+			log.Fatal("gRPC server error:", err)
+		}
+	}()
+}
+
+// stopGRPC lets in-flight calls finish, cutting them off when ctx ends.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("gRPC shutdown timed out; closing connections")
+		s.Stop()
+	}
+}
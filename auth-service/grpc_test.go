@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"resilient-auth-service/authpb"
+)
+
+// dialGRPC serves s's gRPC API over an in-memory listener and returns a
+// client for it.
+func dialGRPC(t *testing.T, s *Server) authpb.AuthServiceClient {
+	t.Helper()
+	srv, err := s.newGRPCServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return authpb.NewAuthServiceClient(conn)
+}
+
+// blockingUserStore answers GetByID only when the caller gives up, and
+// then reports on done whether its context had a deadline.
+type blockingUserStore struct {
+	*MemoryUserStore
+	done chan bool
+}
+
+func (b blockingUserStore) GetByID(ctx context.Context, id int) (User, error) {
+	_, hasDeadline := ctx.Deadline()
+	<-ctx.Done()
+	b.done <- hasDeadline
+	return User{}, ctx.Err()
+}
+
+func TestGRPCAuthService(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	s.cfg.GRPCServiceTokens = map[string]string{"billing": "billing-token"}
+	user := newTestUser(t, s, "a@example.com", "admin")
+	sessionID, err := s.createSession(context.Background(), user.Email, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := dialGRPC(t, s)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer billing-token")
+
+	code := func(err error) codes.Code { return status.Code(err) }
+
+	t.Run("unauthenticated caller", func(t *testing.T) {
+		method := authpb.AuthService_GetUser_FullMethodName
+		before := testutil.ToFloat64(grpcHandledTotal.WithLabelValues(method, codes.Unauthenticated.String()))
+		for _, ctx := range []context.Context{
+			context.Background(),
+			metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong"),
+		} {
+			if _, err := client.GetUser(ctx, &authpb.GetUserRequest{Id: int64(user.ID)}); code(err) != codes.Unauthenticated {
+				t.Errorf("GetUser: %v, want Unauthenticated", err)
+			}
+		}
+		if got := testutil.ToFloat64(grpcHandledTotal.WithLabelValues(method, codes.Unauthenticated.String())); got != before+2 {
+			t.Errorf("grpc_server_handled_total rose by %v, want 2", got-before)
+		}
+	})
+
+	t.Run("ValidateSession", func(t *testing.T) {
+		resp, err := client.ValidateSession(ctx, &authpb.ValidateSessionRequest{SessionId: sessionID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetUserId() != int64(user.ID) || resp.GetEmail() != user.Email || resp.GetRole() != "admin" {
+			t.Errorf("got %+v", resp)
+		}
+		if exp := resp.GetExpiresAt().AsTime(); !exp.After(s.clock.Now()) {
+			t.Errorf("expires_at %v is not in the future", exp)
+		}
+		if _, err := client.ValidateSession(ctx, &authpb.ValidateSessionRequest{SessionId: "nope"}); code(err) != codes.Unauthenticated {
+			t.Errorf("unknown session: %v, want Unauthenticated", err)
+		}
+		if _, err := client.ValidateSession(ctx, &authpb.ValidateSessionRequest{}); code(err) != codes.InvalidArgument {
+			t.Errorf("no session: %v, want InvalidArgument", err)
+		}
+	})
+
+	t.Run("GetUser", func(t *testing.T) {
+		got, err := client.GetUser(ctx, &authpb.GetUserRequest{Id: int64(user.ID)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetEmail() != user.Email || got.GetRole() != "admin" {
+			t.Errorf("got %+v", got)
+		}
+		if _, err := client.GetUser(ctx, &authpb.GetUserRequest{Id: 999}); code(err) != codes.NotFound {
+			t.Errorf("unknown user: %v, want NotFound", err)
+		}
+	})
+
+	t.Run("deadline reaches the store", func(t *testing.T) {
+		saved := s.users
+		store := blockingUserStore{saved.(*MemoryUserStore), make(chan bool, 1)}
+		s.users = store
+		defer func() { s.users = saved }()
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if _, err := client.GetUser(ctx, &authpb.GetUserRequest{Id: int64(user.ID)}); code(err) != codes.DeadlineExceeded {
+			t.Errorf("GetUser: %v, want DeadlineExceeded", err)
+		}
+		select {
+		case hasDeadline := <-store.done:
+			if !hasDeadline {
+				t.Error("the store's context had no deadline")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("the store call outlived the client's deadline")
+		}
+	})
+
+	t.Run("RevokeSession", func(t *testing.T) {
+		if _, err := client.RevokeSession(ctx, &authpb.RevokeSessionRequest{SessionId: sessionID}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.ValidateSession(ctx, &authpb.ValidateSessionRequest{SessionId: sessionID}); code(err) != codes.Unauthenticated {
+			t.Errorf("revoked session: %v, want Unauthenticated", err)
+		}
+	})
+}
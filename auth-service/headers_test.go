@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSecurityHeaders checks the headers each class of route gets, over
+// HTTP and HTTPS, and that each one can be turned off.
+func TestSecurityHeaders(t *testing.T) {
+	quietLog(t)
+	baseline := map[string]string{
+		"X-Content-Type-Options":  testConfig.HeaderContentTypeOptions,
+		"X-Frame-Options":         testConfig.HeaderFrameOptions,
+		"Referrer-Policy":         testConfig.HeaderReferrerPolicy,
+		"Content-Security-Policy": testConfig.HeaderCSP,
+	}
+	for _, tt := range []struct {
+		name      string
+		method    string
+		target    string
+		configure func(*Config)
+		// want are headers beyond the baseline; "" means absent.
+		want map[string]string
+	}{
+		{"auth flow", http.MethodPost, "http://example.com/v1/login", nil, map[string]string{
+			"Cache-Control": "no-store", "Strict-Transport-Security": "",
+		}},
+		{"auth flow over HTTPS", http.MethodPost, "https://example.com/v1/login", nil, map[string]string{
+			"Cache-Control": "no-store", "Strict-Transport-Security": testConfig.HeaderHSTS,
+		}},
+		{"authenticated", http.MethodGet, "http://example.com/v1/me", nil, map[string]string{
+			"Cache-Control": "no-store",
+		}},
+		{"probe", http.MethodGet, "http://example.com/livez", nil, map[string]string{
+			"Cache-Control": "",
+		}},
+		{"HSTS forced behind a proxy", http.MethodGet, "http://example.com/livez", func(c *Config) { c.ForceHSTS = true }, map[string]string{
+			"Strict-Transport-Security": testConfig.HeaderHSTS,
+		}},
+		{"headers turned off", http.MethodGet, "https://example.com/v1/me", func(c *Config) {
+			c.HeaderHSTS, c.HeaderCSP, c.HeaderFrameOptions, c.HeaderCacheControl = "", "", "", ""
+		}, map[string]string{
+			"Strict-Transport-Security": "", "Content-Security-Policy": "", "X-Frame-Options": "", "Cache-Control": "",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestServer(t)
+			if tt.configure != nil {
+				tt.configure(&s.cfg)
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			want := map[string]string{}
+			for k, v := range baseline {
+				want[k] = v
+			}
+			for k, v := range tt.want {
+				want[k] = v
+			}
+			for name, value := range want {
+				if got := rec.Header().Get(name); got != value {
+					t.Errorf("%s: %q, want %q", name, got, value)
+				}
+			}
+		})
+	}
+}
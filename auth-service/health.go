@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+// livezHandler serves GET /livez, the liveness probe. It checks no
+// dependencies: a database or Redis outage shouldn't get every pod
+// restarted.
+func livezHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyzHandler serves GET /readyz, the readiness probe: 503 while the
+// database is unreachable. Redis is left out on purpose; without it the
+// service runs degraded rather than down, and failing readiness on every
+// replica at once would also take out the routes that still work.
+func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		apperror.WriteError(w, r, apperror.Unavailable("not_ready", "Database unavailable"))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	status := map[string]any{
+		"service":         "up",
+		"service_version": version,
+	}
+
+	// Check DB
+	if err := s.db.PingContext(ctx); err != nil {
+		status["database"] = "down"
+	} else {
+		status["database"] = "up"
+		if schema, _, err := schemaVersion(ctx, s.db); err == nil {
+			status["schema_version"] = schema
+		}
+	}
+
+	// Check Redis
+	if err := s.rdb.Ping(ctx).Err(); err != nil {
+		status["redis"] = "down"
+		if n := redisConnectAttempts.Load(); n > 0 {
+			status["redis_connect_attempts"] = n
+		}
+	} else {
+		status["redis"] = "up"
+	}
+
+	if s.syntheticMonitor != nil {
+		if res := s.syntheticMonitor.Last(); res != nil {
+			status["self_test"] = res
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestProbesNotRateLimited sends a kubelet's worth of probes from one
+// address: none is limited, while the public routes from that address
+// still are.
+func TestProbesNotRateLimited(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	// /readyz pings the database.
+	useFakeRefreshDB(t, s)
+	h := s.Handler()
+
+	get := func(path string) int {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = "10.0.0.1:41000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	for _, path := range []string{"/livez", "/readyz", "/health", "/version", "/metrics"} {
+		for i := range 100 {
+			if code := get(path); code != http.StatusOK {
+				t.Fatalf("%s: request %d got %d, want 200", path, i+1, code)
+			}
+		}
+	}
+
+	for i := range s.cfg.RateLimit {
+		if code := get("/.well-known/jwks.json"); code != http.StatusOK {
+			t.Fatalf("jwks: request %d got %d, want 200", i+1, code)
+		}
+	}
+	if code := get("/.well-known/jwks.json"); code != http.StatusTooManyRequests {
+		t.Errorf("jwks past the limit: %d, want 429", code)
+	}
+}
@@ -0,0 +1,154 @@
+// Package i18n picks a language for each request from its Accept-Language
+// header and looks up messages in per-locale catalogs.
+//
+// A catalog is a directory of JSON files, one per locale, named by BCP 47
+// tag (en.json, de.json, ...), each a flat object of message key to text.
+// English is the reference: every key must be in en.json, and a locale
+// that lacks one falls back to English.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// Default is the language used when a client asks for none the catalog
+// has, and the one every other locale falls back to.
+var Default = language.English
+
+// Catalog holds the messages for every supported locale.
+type Catalog struct {
+	// tags has Default first, so the matcher falls back to it.
+	tags     []language.Tag
+	matcher  language.Matcher
+	messages map[language.Tag]map[string]string
+}
+
+// Load reads the catalog in dir of fsys. It fails if en.json is missing or
+// another locale has a key en.json doesn't, which is most likely a typo.
+func Load(fsys fs.FS, dir string) (*Catalog, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{tags: []language.Tag{Default}, messages: map[language.Tag]map[string]string{}}
+	for _, file := range files {
+		tag, err := language.Parse(strings.TrimSuffix(path.Base(file), ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]string
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		c.messages[tag] = m
+		if tag != Default {
+			c.tags = append(c.tags, tag)
+		}
+	}
+
+	ref, ok := c.messages[Default]
+	if !ok {
+		return nil, fmt.Errorf("%s: no %s.json", dir, Default)
+	}
+	for tag, m := range c.messages {
+		for key := range m {
+			if _, ok := ref[key]; !ok {
+				return nil, fmt.Errorf("%s/%s.json: key %q not in %s.json", dir, tag, key, Default)
+			}
+		}
+	}
+	c.matcher = language.NewMatcher(c.tags)
+	return c, nil
+}
+
+// MustLoad is Load for package-level catalogs; it panics on error.
+func MustLoad(fsys fs.FS, dir string) *Catalog {
+	c, err := Load(fsys, dir)
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	return c
+}
+
+// Tags returns the supported locales, Default first.
+func (c *Catalog) Tags() []language.Tag {
+	return slices.Clone(c.tags)
+}
+
+// Match returns the supported locale that best fits an Accept-Language
+// header, or Default if none does or the header doesn't parse.
+func (c *Catalog) Match(acceptLanguage string) language.Tag {
+	prefs, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(prefs) == 0 {
+		return Default
+	}
+	_, i, _ := c.matcher.Match(prefs...)
+	return c.tags[i]
+}
+
+// Message returns the text for key in tag's catalog. When tag doesn't have
+// it, the miss is logged (once per locale and key) and ok is false; the
+// caller falls back, to English or a message of its own.
+func (c *Catalog) Message(tag language.Tag, key string) (msg string, ok bool) {
+	msg, ok = c.messages[tag][key]
+	if !ok {
+		ReportMissing(tag, key)
+	}
+	return msg, ok
+}
+
+// reported holds the "tag key" pairs ReportMissing has logged.
+var reported sync.Map
+
+// ReportMissing logs that tag has no message for key, the first time
+// only, so a gap shows up in the logs without flooding them. Message calls
+// it; callers with translations outside a catalog, e.g. templates, call it
+// themselves.
+func ReportMissing(tag language.Tag, key string) {
+	if _, seen := reported.LoadOrStore(tag.String()+" "+key, true); !seen {
+		log.Printf("i18n: missing %s message %q", tag, key)
+	}
+}
+
+type ctxKey struct{}
+
+// WithLanguage returns a copy of ctx carrying tag.
+func WithLanguage(ctx context.Context, tag language.Tag) context.Context {
+	return context.WithValue(ctx, ctxKey{}, tag)
+}
+
+// Language returns the locale Middleware picked for the request ctx
+// belongs to, or Default. Contexts derived from it, including ones
+// detached with context.WithoutCancel for background work, keep it.
+func Language(ctx context.Context) language.Tag {
+	if tag, ok := ctx.Value(ctxKey{}).(language.Tag); ok {
+		return tag
+	}
+	return Default
+}
+
+// Middleware picks each request's locale from its Accept-Language header
+// and stores it in the request context for Language.
+func (c *Catalog) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		tag := c.Match(r.Header.Get("Accept-Language"))
+		next.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), tag)))
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+const (
+	// idempotencyTTL is how long a completed response can be replayed.
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLockTTL bounds the pending marker, so a request that dies
+	// mid-flight only blocks its key briefly rather than for a day.
+	idempotencyLockTTL = time.Minute
+	maxIdempotencyKey  = 255
+)
+
+// idempotencyRecord is what is stored under idempotency:<path>:<key>.
+// Pending records are the lock held while the first request runs.
+type idempotencyRecord struct {
+	Pending     bool                `json:"pending,omitempty"`
+	Fingerprint string              `json:"fingerprint"`
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// idempotencyMiddleware makes an unsafe endpoint safe to retry: a request
+// carrying an Idempotency-Key runs once, and retries with the same key and
+// body get the stored response (marked Idempotent-Replayed) instead of, say,
+// a 409 for the user they just registered. The same key with a different
+// body is a 409 idempotency_conflict. SET NX on a pending marker makes sure
+// concurrent first attempts don't both run.
+//
+// 5xx responses aren't stored, so the client can retry them for real.
+// Set-Cookie is never stored; don't use this on endpoints that log in.
+// If Redis is down requests run without the guarantee.
+func (s *Server) idempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			apperror.WriteError(w, r, apperror.BadRequest("invalid_idempotency_key", "Idempotency-Key is too long"))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			apperror.WriteError(w, r, decodeError(err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// A retry may come through another API version or the legacy
+		// alias; it is still the same request.
+		path := unversionedPath(r.URL.Path)
+		sum := sha256.Sum256(append([]byte(r.Method+" "+path+"\n"), body...))
+		fingerprint := hex.EncodeToString(sum[:])
+		redisKey := "idempotency:" + path + ":" + key
+
+		pending, _ := json.Marshal(idempotencyRecord{Pending: true, Fingerprint: fingerprint})
+		acquired, err := s.rdb.SetNX(r.Context(), redisKey, pending, idempotencyLockTTL).Result()
+		if err != nil {
+			log.Println("idempotency lock error:", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !acquired {
+			s.replayIdempotent(w, r, redisKey, fingerprint)
+			return
+		}
+
+		rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= 500 {
+			s.rdb.Del(r.Context(), redisKey)
+			return
+		}
+		header := rec.Header().Clone()
+		header.Del("Set-Cookie")
+		done, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      rec.status,
+			Header:      header,
+			Body:        rec.body.Bytes(),
+		})
+		if err := s.rdb.Set(r.Context(), redisKey, done, idempotencyTTL).Err(); err != nil {
+			log.Println("idempotency store error:", err)
+		}
+	})
+}
+
+// replayIdempotent answers a request whose key is already taken.
+func (s *Server) replayIdempotent(w http.ResponseWriter, r *http.Request, redisKey, fingerprint string) {
+	raw, err := s.rdb.Get(r.Context(), redisKey).Bytes()
+	var stored idempotencyRecord
+	if err == nil {
+		err = json.Unmarshal(raw, &stored)
+	}
+	if err != nil {
+		// Includes the record expiring between SET NX and GET.
+		log.Println("idempotency read error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+
+	switch {
+	case stored.Fingerprint != fingerprint:
+		apperror.WriteError(w, r, apperror.Conflict("idempotency_conflict", "Idempotency-Key was already used with a different request"))
+	case stored.Pending:
+		w.Header().Set("Retry-After", "1")
+		apperror.WriteError(w, r, apperror.Conflict("idempotency_in_progress", "A request with this Idempotency-Key is still in progress"))
+	default:
+		for k, v := range stored.Header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(stored.Status)
+		w.Write(stored.Body)
+	}
+}
+
+// idempotencyRecorder passes the response through while keeping a copy.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *idempotencyRecorder) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *idempotencyRecorder) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingHandler answers status, 201 by default, with a response
+// numbered by how many times it has run, and a cookie that must not be
+// replayed.
+type countingHandler struct {
+	runs atomic.Int32
+	// release, if set, holds every run until it is closed.
+	release chan struct{}
+	status  int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := h.runs.Add(1)
+	if h.release != nil {
+		<-h.release
+	}
+	status := h.status
+	if status == 0 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("X-Run", strconv.Itoa(int(n)))
+	http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "secret"})
+	w.WriteHeader(status)
+	w.Write([]byte(`{"run":` + strconv.Itoa(int(n)) + `}`))
+}
+
+func idempotentPost(h http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	s, _ := newTestServer(t)
+	next := &countingHandler{}
+	h := s.idempotencyMiddleware(next)
+
+	first := idempotentPost(h, "/v1/register", "k1", `{"email":"a@example.com"}`)
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first: %d %v", first.Code, first.Header())
+	}
+
+	// The same request through another version and the legacy alias.
+	for _, path := range []string{"/v1/register", "/v2/register", "/register"} {
+		rec := idempotentPost(h, path, "k1", `{"email":"a@example.com"}`)
+		if rec.Code != first.Code || rec.Body.String() != first.Body.String() || rec.Header().Get("X-Run") != "1" {
+			t.Errorf("%s: got %d %s, want the first response", path, rec.Code, rec.Body)
+		}
+		if rec.Header().Get("Idempotent-Replayed") != "true" {
+			t.Errorf("%s: not marked as a replay", path)
+		}
+		if rec.Header().Get("Set-Cookie") != "" {
+			t.Errorf("%s: a cookie was replayed", path)
+		}
+	}
+	if n := next.runs.Load(); n != 1 {
+		t.Errorf("the handler ran %d times, want 1", n)
+	}
+
+	// Keys are per endpoint.
+	if rec := idempotentPost(h, "/v1/orgs", "k1", `{"email":"a@example.com"}`); rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("a key was replayed across endpoints")
+	}
+}
+
+func TestIdempotencyConflict(t *testing.T) {
+	s, _ := newTestServer(t)
+	next := &countingHandler{}
+	h := s.idempotencyMiddleware(next)
+
+	idempotentPost(h, "/v1/register", "k1", `{"email":"a@example.com"}`)
+	rec := idempotentPost(h, "/v1/register", "k1", `{"email":"b@example.com"}`)
+	if env := readEnvelope(t, rec, http.StatusConflict); env.Error.Code != "idempotency_conflict" {
+		t.Errorf("code %q, want idempotency_conflict", env.Error.Code)
+	}
+	if n := next.runs.Load(); n != 1 {
+		t.Errorf("the handler ran %d times, want 1", n)
+	}
+}
+
+// Concurrent first attempts: one runs, the rest are told to retry rather
+// than running alongside it.
+func TestIdempotencyConcurrentDuplicates(t *testing.T) {
+	s, _ := newTestServer(t)
+	next := &countingHandler{release: make(chan struct{})}
+	h := s.idempotencyMiddleware(next)
+
+	const clients = 8
+	results := make(chan *httptest.ResponseRecorder, clients)
+	var wg sync.WaitGroup
+	for range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- idempotentPost(h, "/v1/register", "k1", `{"email":"a@example.com"}`)
+		}()
+	}
+	// All but the one holding the lock answer without waiting for it.
+	for range clients - 1 {
+		rec := <-results
+		if env := readEnvelope(t, rec, http.StatusConflict); env.Error.Code != "idempotency_in_progress" {
+			t.Errorf("code %q, want idempotency_in_progress", env.Error.Code)
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Error("no Retry-After on an in-progress conflict")
+		}
+	}
+	close(next.release)
+	wg.Wait()
+	if rec := <-results; rec.Code != http.StatusCreated {
+		t.Errorf("the request that ran: %d %s", rec.Code, rec.Body)
+	}
+	if n := next.runs.Load(); n != 1 {
+		t.Errorf("the handler ran %d times, want 1", n)
+	}
+
+	if rec := idempotentPost(h, "/v1/register", "k1", `{"email":"a@example.com"}`); rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("a retry after it finished: %d %v, want a replay", rec.Code, rec.Header())
+	}
+}
+
+// A 5xx isn't stored: the retry runs for real.
+func TestIdempotencyServerErrorNotStored(t *testing.T) {
+	s, _ := newTestServer(t)
+	next := &countingHandler{status: http.StatusServiceUnavailable}
+	h := s.idempotencyMiddleware(next)
+
+	idempotentPost(h, "/v1/register", "k1", `{}`)
+	next.status = http.StatusCreated
+	rec := idempotentPost(h, "/v1/register", "k1", `{}`)
+	if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" || next.runs.Load() != 2 {
+		t.Errorf("retry after a 503: %d %v, %d runs", rec.Code, rec.Header(), next.runs.Load())
+	}
+}
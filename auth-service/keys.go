@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/tokens"
+)
+
+const (
+	// keyRefreshInterval bounds how long a replica keeps signing with a key
+	// another replica has rotated out.
+	keyRefreshInterval = time.Minute
+	// keyMissReloadInterval rate-limits the reload triggered by a token
+	// whose kid isn't in the cached set, so a flood of forged kids can't
+	// turn into a flood of queries.
+	keyMissReloadInterval = 5 * time.Second
+	// jwksMaxAge is how long verifiers may cache the JWKS. A new key is
+	// published for at least this long before it signs anything, so no
+	// cached copy can be missing the kid of a token it's handed.
+	jwksMaxAge = 5 * time.Minute
+	// keyRetention is how long a retired key stays trusted: it may go on
+	// signing for jwksMaxAge, plus a refresh, after it's retired, and the
+	// last tokens it signs last accessTokenTTL.
+	keyRetention = accessTokenTTL + jwksMaxAge + keyRefreshInterval
+)
+
+// KeyStore caches the access-token KeySet held in signing_keys. Rotation
+// happens on one replica; the others pick the new key up on their next
+// refresh, or sooner when they're handed a token it signed. A new key only
+// signs once it has been published for jwksMaxAge. Retired keys are dropped
+// once every token they signed has expired.
+type KeyStore struct {
+	db *sql.DB
+
+	mu       sync.RWMutex
+	set      *tokens.KeySet
+	loadedAt time.Time
+
+	// reloading lets one caller reload while the rest carry on with the
+	// cached set.
+	reloading sync.Mutex
+}
+
+func NewKeyStore(db *sql.DB) *KeyStore {
+	return &KeyStore{db: db}
+}
+
+// Load reads the key set, first creating a key if there is no active one,
+// as on a fresh database. Replicas starting together may race to do that;
+// the one-active index lets only one insert win.
+func (s *KeyStore) Load(ctx context.Context) error {
+	set, err := s.read(ctx)
+	if errors.Is(err, errNoActiveKey) {
+		if err := s.insertFirst(ctx); err != nil {
+			return err
+		}
+		set, err = s.read(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.set = set
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+var errNoActiveKey = errors.New("no active signing key")
+
+func (s *KeyStore) read(ctx context.Context) (*tokens.KeySet, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kid, private_key, active, created_at FROM signing_keys
+		WHERE retired_at IS NULL OR retired_at > $1
+		ORDER BY created_at`,
+		time.Now().Add(-keyRetention),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		keys   []tokens.SigningKey
+		active bool
+	)
+	for rows.Next() {
+		var (
+			k   tokens.SigningKey
+			pem string
+		)
+		if err := rows.Scan(&k.ID, &pem, &k.Active, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		if k.Key, err = tokens.ParsePrivateKey(pem); err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", k.ID, err)
+		}
+		active = active || k.Active
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, errNoActiveKey
+	}
+	pickSigner(keys, time.Now())
+	return tokens.NewKeySet(keys)
+}
+
+// pickSigner marks which of keys, ordered oldest first, signs new tokens.
+// The key made active by the last rotation signs once it has been
+// published for jwksMaxAge; until then the newest key that has been, the
+// one it replaced, carries on. A fresh database's first key signs at once,
+// there being nothing else.
+func pickSigner(keys []tokens.SigningKey, now time.Time) {
+	signer, active := -1, -1
+	for i, k := range keys {
+		if k.Active {
+			active = i
+		}
+		if now.Sub(k.CreatedAt) >= jwksMaxAge {
+			signer = i
+		}
+	}
+	if signer == -1 {
+		signer = active
+	}
+	for i := range keys {
+		keys[i].Active = i == signer
+	}
+}
+
+func (s *KeyStore) insertFirst(ctx context.Context) error {
+	k, pem, err := generateSigningKey()
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO signing_keys (kid, private_key) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		k.ID, pem,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		log.Printf("Created signing key kid=%s", k.ID)
+	}
+	return nil
+}
+
+func generateSigningKey() (tokens.SigningKey, string, error) {
+	k, err := tokens.GenerateSigningKey()
+	if err != nil {
+		return tokens.SigningKey{}, "", err
+	}
+	pem, err := k.MarshalPrivateKey()
+	return k, pem, err
+}
+
+// Current returns the cached set, reloading it first if it's older than
+// keyRefreshInterval. A failed reload is logged and the cached set used.
+func (s *KeyStore) Current(ctx context.Context) *tokens.KeySet {
+	s.mu.RLock()
+	set, age := s.set, time.Since(s.loadedAt)
+	s.mu.RUnlock()
+
+	if age >= keyRefreshInterval {
+		set = s.reload(ctx, keyRefreshInterval)
+	}
+	return set
+}
+
+// Lookup returns a set holding kid if there is one, reloading when the
+// cached set doesn't and hasn't been reloaded in keyMissReloadInterval.
+// The returned set is always usable; callers check Has themselves.
+func (s *KeyStore) Lookup(ctx context.Context, kid string) *tokens.KeySet {
+	set := s.Current(ctx)
+	if set.Has(kid) {
+		return set
+	}
+	return s.reload(ctx, keyMissReloadInterval)
+}
+
+// reload loads the set if it's at least minAge old. If another caller is
+// already reloading, it returns the cached set rather than wait.
+func (s *KeyStore) reload(ctx context.Context, minAge time.Duration) *tokens.KeySet {
+	if s.reloading.TryLock() {
+		defer s.reloading.Unlock()
+
+		s.mu.RLock()
+		stale := time.Since(s.loadedAt) >= minAge
+		s.mu.RUnlock()
+		if stale {
+			if err := s.Load(ctx); err != nil {
+				log.Println("signing key reload error:", err)
+			}
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set
+}
+
+// Rotate makes a new key active and retires the current one, returning
+// both kids. The retired key goes on signing until the new one has been
+// published for jwksMaxAge. Keys retired more than keyRetention ago are
+// deleted, since nothing they signed is still valid.
+func (s *KeyStore) Rotate(ctx context.Context) (kid, previous string, err error) {
+	k, pem, err := generateSigningKey()
+	if err != nil {
+		return "", "", err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer tx.Rollback()
+
+	// Serialises concurrent rotations: the second then sees the first's
+	// key as the one to retire instead of colliding with it.
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE signing_keys IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return "", "", err
+	}
+	err = tx.QueryRowContext(ctx,
+		"UPDATE signing_keys SET active = false, retired_at = now() WHERE active RETURNING kid",
+	).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", "", err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO signing_keys (kid, private_key) VALUES ($1, $2)", k.ID, pem,
+	); err != nil {
+		return "", "", err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM signing_keys WHERE retired_at < $1", time.Now().Add(-keyRetention),
+	); err != nil {
+		return "", "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", "", err
+	}
+
+	if err := s.Load(ctx); err != nil {
+		log.Println("signing key reload error:", err)
+	}
+	return k.ID, previous, nil
+}
+
+// jwksHandler serves GET /.well-known/jwks.json: the public half of every
+// key a valid access token may be signed with, including a newly rotated
+// key that doesn't sign yet. Verifiers may cache it for jwksMaxAge, since
+// no key signs before it has been here that long, but should still refetch
+// it when they see a kid they don't know.
+func (s *Server) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	jwks, err := s.accessKeys.Current(r.Context()).JWKS()
+	if err != nil {
+		log.Println("jwks error:", err)
+		apperror.WriteError(w, r, apperror.Internal(err))
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(jwksMaxAge.Seconds())))
+	writeJSON(w, http.StatusOK, jwks)
+}
+
+// adminRotateKeysHandler serves POST /admin/keys/rotate. Tokens signed by
+// the old key stay valid until they expire; new ones are signed by the new
+// key once it has been published for jwksMaxAge, as each replica refreshes
+// its set.
+func (s *Server) adminRotateKeysHandler(w http.ResponseWriter, r *http.Request) {
+	kid, previous, err := s.accessKeys.Rotate(r.Context())
+	if err != nil {
+		log.Println("signing key rotation error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "admin.keys_rotate")
+	ev.Level = "warning"
+	ev.Target = "signing_key:" + kid
+	ev.Metadata = map[string]any{"kid": kid, "previous_kid": previous}
+	s.auditor.Record(r.Context(), ev)
+
+	writeJSON(w, http.StatusCreated, map[string]string{"kid": kid, "previous_kid": previous})
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"resilient-auth-service/tokens"
+)
+
+// fakeKeyDB is a database/sql driver holding an in-memory signing_keys
+// table. It understands exactly the statements keys.go runs, and fails
+// loudly on anything else.
+type fakeKeyDB struct {
+	keys []*fakeSigningKey
+}
+
+type fakeSigningKey struct {
+	kid       string
+	pem       string
+	active    bool
+	createdAt time.Time
+	retiredAt time.Time
+}
+
+func (f *fakeKeyDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeKeyDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeKeyDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeKeyDB: prepared statements not supported")
+}
+func (f *fakeKeyDB) Close() error              { return nil }
+func (f *fakeKeyDB) Begin() (driver.Tx, error) { return f, nil }
+func (f *fakeKeyDB) Commit() error             { return nil }
+func (f *fakeKeyDB) Rollback() error           { return nil }
+
+func (f *fakeKeyDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case query == "LOCK TABLE signing_keys IN SHARE ROW EXCLUSIVE MODE":
+	case strings.HasPrefix(query, "INSERT INTO signing_keys (kid, private_key) VALUES ($1, $2)"):
+		f.keys = append(f.keys, &fakeSigningKey{
+			kid: args[0].Value.(string), pem: args[1].Value.(string), active: true, createdAt: time.Now(),
+		})
+	case query == "DELETE FROM signing_keys WHERE retired_at < $1":
+		before := args[0].Value.(time.Time)
+		var kept []*fakeSigningKey
+		for _, k := range f.keys {
+			if k.retiredAt.IsZero() || !k.retiredAt.Before(before) {
+				kept = append(kept, k)
+			}
+		}
+		f.keys = kept
+	default:
+		return nil, fmt.Errorf("fakeKeyDB: unexpected exec %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (f *fakeKeyDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	switch query {
+	case "UPDATE signing_keys SET active = false, retired_at = now() WHERE active RETURNING kid":
+		rows := &fakeRows{cols: []string{"kid"}}
+		for _, k := range f.keys {
+			if k.active {
+				k.active, k.retiredAt = false, time.Now()
+				rows.rows = append(rows.rows, []driver.Value{k.kid})
+			}
+		}
+		return rows, nil
+	case "SELECT kid, private_key, active, created_at FROM signing_keys WHERE retired_at IS NULL OR retired_at > $1 ORDER BY created_at":
+		after := args[0].Value.(time.Time)
+		rows := &fakeRows{cols: []string{"kid", "private_key", "active", "created_at"}}
+		for _, k := range f.keys {
+			if k.retiredAt.IsZero() || k.retiredAt.After(after) {
+				rows.rows = append(rows.rows, []driver.Value{k.kid, k.pem, k.active, k.createdAt})
+			}
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("fakeKeyDB: unexpected query %q", query)
+}
+
+func (f *fakeKeyDB) byKid(kid string) *fakeSigningKey {
+	for _, k := range f.keys {
+		if k.kid == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// age moves every key's timestamps d into the past, as if d had passed.
+func (f *fakeKeyDB) age(d time.Duration) {
+	for _, k := range f.keys {
+		k.createdAt = k.createdAt.Add(-d)
+		if !k.retiredAt.IsZero() {
+			k.retiredAt = k.retiredAt.Add(-d)
+		}
+	}
+}
+
+func TestPickSigner(t *testing.T) {
+	now := time.Now()
+	key := func(id string, age time.Duration, active bool) tokens.SigningKey {
+		return tokens.SigningKey{ID: id, CreatedAt: now.Add(-age), Active: active}
+	}
+	tests := []struct {
+		name string
+		keys []tokens.SigningKey
+		want string
+	}{
+		{"first key signs at once", []tokens.SigningKey{key("a", 0, true)}, "a"},
+		{"published long enough", []tokens.SigningKey{key("a", time.Hour, false), key("b", jwksMaxAge, true)}, "b"},
+		{"just rotated", []tokens.SigningKey{key("a", time.Hour, false), key("b", time.Second, true)}, "a"},
+		{"rotated twice quickly", []tokens.SigningKey{
+			key("a", time.Hour, false), key("b", 2*time.Minute, false), key("c", time.Minute, true),
+		}, "a"},
+		{"rotated twice slowly", []tokens.SigningKey{
+			key("a", time.Hour, false), key("b", 10*time.Minute, false), key("c", time.Minute, true),
+		}, "b"},
+	}
+	for _, tt := range tests {
+		pickSigner(tt.keys, now)
+		var signers []string
+		for _, k := range tt.keys {
+			if k.Active {
+				signers = append(signers, k.ID)
+			}
+		}
+		if len(signers) != 1 || signers[0] != tt.want {
+			t.Errorf("%s: signers %v, want [%s]", tt.name, signers, tt.want)
+		}
+	}
+}
+
+// TestKeyRotation rotates from key A to key B. B is published at once but
+// only signs once verifiers' cached JWKS must have it, and A's tokens stay
+// valid throughout.
+func TestKeyRotation(t *testing.T) {
+	quietLog(t)
+	fake := &fakeKeyDB{}
+	store := NewKeyStore(sql.OpenDB(fake))
+	t.Cleanup(func() { store.db.Close() })
+	s := &Server{cfg: testConfig, clock: newFakeClock(), accessKeys: store, claimsEnricher: NoOpClaimsEnricher{}}
+	ctx := context.Background()
+
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	fake.age(time.Hour)
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	kidA := store.Current(ctx).ActiveID()
+
+	tokenA, err := s.signAccessToken(ctx, 42, "a@example.com", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify := func(raw string) (string, error) {
+		token, err := jwt.Parse(raw, s.accessTokenKeyfunc(ctx), jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}))
+		if err != nil {
+			return "", err
+		}
+		return token.Header["kid"].(string), nil
+	}
+
+	kidB, previous, err := store.Rotate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous != kidA || kidB == kidA {
+		t.Fatalf("rotated to %s from %s, want from %s", kidB, previous, kidA)
+	}
+
+	// Just after rotation: B is served, A still signs.
+	rec := httptest.NewRecorder()
+	s.jwksHandler(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	var jwks tokens.JWKS
+	if err := json.NewDecoder(rec.Body).Decode(&jwks); err != nil {
+		t.Fatal(err)
+	}
+	served := map[string]bool{}
+	for _, k := range jwks.Keys {
+		served[k.KeyID] = true
+	}
+	if !served[kidA] || !served[kidB] {
+		t.Errorf("JWKS serves %v, want both %s and %s", served, kidA, kidB)
+	}
+	if got, want := rec.Header().Get("Cache-Control"), fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())); got != want {
+		t.Errorf("Cache-Control %q, want %q", got, want)
+	}
+	if got := store.Current(ctx).ActiveID(); got != kidA {
+		t.Errorf("signing with %s straight after rotation, want %s until %s is published", got, kidA, kidB)
+	}
+	if kid, err := verify(tokenA); err != nil || kid != kidA {
+		t.Errorf("A's token after rotation: kid %s, %v", kid, err)
+	}
+
+	// Once every cached JWKS has B, B signs, and A's tokens still verify.
+	fake.age(jwksMaxAge)
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tokenB, err := s.signAccessToken(ctx, 42, "a@example.com", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid, err := verify(tokenB); err != nil || kid != kidB {
+		t.Errorf("new token: kid %s, %v; want signed by %s", kid, err, kidB)
+	}
+	if kid, err := verify(tokenA); err != nil || kid != kidA {
+		t.Errorf("A's token once B signs: kid %s, %v", kid, err)
+	}
+
+	// A is dropped only once its last tokens have expired.
+	fake.age(accessTokenTTL)
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !store.Current(ctx).Has(kidA) {
+		t.Error("A dropped while tokens it signed may still be valid")
+	}
+	fake.age(keyRetention - accessTokenTTL)
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if store.Current(ctx).Has(kidA) || fake.byKid(kidA) == nil {
+		t.Error("A should be out of the set, and deleted only at the next rotation")
+	}
+}
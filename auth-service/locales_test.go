@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"resilient-auth-service/i18n"
+)
+
+// apperrorCodeArg is the position of the code argument of each apperror
+// constructor that takes one.
+var apperrorCodeArg = map[string]int{
+	"New":             1,
+	"BadRequest":      0,
+	"Unauthorized":    0,
+	"Forbidden":       0,
+	"NotFound":        0,
+	"Conflict":        0,
+	"Unprocessable":   0,
+	"TooManyRequests": 0,
+	"Unavailable":     0,
+}
+
+// sourceMessageKeys returns every catalog key the module's code can ask
+// for: the literal codes passed to apperror constructors, and "field."
+// plus the literal codes passed to WithField and the validator's add.
+func sourceMessageKeys(t *testing.T) []string {
+	t.Helper()
+	keys := map[string]bool{"internal_error": true}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && file != "." && (d.Name() == "testdata" || d.Name() == "client" || d.Name() == "cmd") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			literal := func(i int) (string, bool) {
+				if i >= len(call.Args) {
+					return "", false
+				}
+				lit, ok := call.Args[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return "", false
+				}
+				s, err := strconv.Unquote(lit.Value)
+				return s, err == nil
+			}
+
+			pkg, _ := sel.X.(*ast.Ident)
+			switch {
+			case pkg != nil && pkg.Name == "apperror":
+				if i, ok := apperrorCodeArg[sel.Sel.Name]; ok {
+					if code, ok := literal(i); ok {
+						keys[code] = true
+					}
+				}
+			case sel.Sel.Name == "WithField" || sel.Sel.Name == "add" && len(call.Args) == 3:
+				if code, ok := literal(1); ok {
+					keys["field."+code] = true
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out []string
+	for k := range keys {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// TestMessageCatalogComplete checks every locale has a message for every
+// key: each error and field code the code can produce, the subject of
+// every email template, and everything else in the English catalog.
+func TestMessageCatalogComplete(t *testing.T) {
+	quietLog(t)
+	want := sourceMessageKeys(t)
+	if len(want) < 20 {
+		t.Fatalf("found only %d codes in the source; is the scan broken?", len(want))
+	}
+
+	templates, err := fs.Glob(emailTemplateFS, "templates/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range templates {
+		want = append(want, "email."+strings.TrimSuffix(path.Base(file), ".html")+".subject")
+	}
+
+	b, err := fs.ReadFile(localeFS, "locales/"+i18n.Default.String()+".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var en map[string]string
+	if err := json.Unmarshal(b, &en); err != nil {
+		t.Fatal(err)
+	}
+	for key := range en {
+		want = append(want, key)
+	}
+	slices.Sort(want)
+	want = slices.Compact(want)
+
+	for _, tag := range messages.Tags() {
+		for _, key := range want {
+			if msg, ok := messages.Message(tag, key); !ok || strings.TrimSpace(msg) == "" {
+				t.Errorf("%s: no message for %q", tag, key)
+			}
+		}
+	}
+}
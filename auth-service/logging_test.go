@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// hijackRecorder is a recorder that can hand over a connection and copy
+// with ReadFrom, like the server's own writer.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+	readFrom bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func (h *hijackRecorder) ReadFrom(src io.Reader) (int64, error) {
+	h.readFrom = true
+	return io.Copy(h.ResponseRecorder, src)
+}
+
+func newRecordingWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := newRecordingWriter(rec)
+
+	var w http.ResponseWriter = rw
+	f, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatal("responseWriter isn't an http.Flusher")
+	}
+	f.Flush()
+	if !rec.Flushed {
+		t.Error("Flush didn't reach the underlying writer")
+	}
+	if err := http.NewResponseController(rw).Flush(); err != nil {
+		t.Errorf("ResponseController.Flush: %v", err)
+	}
+	if rw.statusCode != http.StatusOK || !rw.wroteHeader {
+		t.Errorf("status %d, wroteHeader %v after a flush", rw.statusCode, rw.wroteHeader)
+	}
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	rw := newRecordingWriter(rec)
+
+	var w http.ResponseWriter = rw
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		t.Fatal("responseWriter isn't an http.Hijacker")
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if !rec.hijacked {
+		t.Error("Hijack didn't reach the underlying writer")
+	}
+	if rw.statusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status %d after a hijack, want 101", rw.statusCode)
+	}
+
+	// A writer that can't hijack says so rather than pretending.
+	_, _, err = newRecordingWriter(httptest.NewRecorder()).Hijack()
+	if !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("got %v, want http.ErrNotSupported", err)
+	}
+}
+
+func TestResponseWriterReadFrom(t *testing.T) {
+	body := strings.Repeat("x", 5000)
+	// Hide strings.Reader's WriteTo, which io.Copy would prefer.
+	src := func() io.Reader { return struct{ io.Reader }{strings.NewReader(body)} }
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	rw := newRecordingWriter(rec)
+	n, err := io.Copy(rw, src())
+	if err != nil || n != int64(len(body)) {
+		t.Fatalf("copied %d, %v", n, err)
+	}
+	if !rec.readFrom {
+		t.Error("io.Copy didn't use the underlying ReadFrom")
+	}
+	if rw.bytes != int64(len(body)) || rec.Body.String() != body {
+		t.Errorf("counted %d bytes, wrote %d", rw.bytes, rec.Body.Len())
+	}
+
+	// Without one underneath, the copy still works and is counted.
+	plain := httptest.NewRecorder()
+	rw = newRecordingWriter(plain)
+	if _, err := io.Copy(rw, src()); err != nil {
+		t.Fatal(err)
+	}
+	if rw.bytes != int64(len(body)) || plain.Body.String() != body {
+		t.Errorf("counted %d bytes, wrote %d", rw.bytes, plain.Body.Len())
+	}
+}
+
+func TestResponseWriterInformational(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		want  int
+	}{
+		{"early hints then created", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusEarlyHints)
+			w.WriteHeader(http.StatusCreated)
+		}, http.StatusCreated},
+		{"early hints then body", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusEarlyHints)
+			w.Write([]byte("ok"))
+		}, http.StatusOK},
+		{"continue then not found", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusContinue)
+			w.WriteHeader(http.StatusNotFound)
+		}, http.StatusNotFound},
+		{"second final status ignored", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusAccepted)
+			w.WriteHeader(http.StatusInternalServerError)
+		}, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		rw := newRecordingWriter(httptest.NewRecorder())
+		tt.write(rw)
+		if rw.statusCode != tt.want {
+			t.Errorf("%s: recorded %d, want %d", tt.name, rw.statusCode, tt.want)
+		}
+	}
+
+	// Only informational, then nothing: nothing final was recorded yet.
+	rw := newRecordingWriter(httptest.NewRecorder())
+	rw.WriteHeader(http.StatusEarlyHints)
+	if rw.wroteHeader {
+		t.Error("a 1xx counted as the final status")
+	}
+}
+
+// TestAccessLogMasksPathTokens checks that a token in the path, like an
+// invitation link's, doesn't reach the access log.
+func TestAccessLogMasksPathTokens(t *testing.T) {
+	var buf strings.Builder
+	saved := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(saved) })
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux := http.NewServeMux()
+	mux.Handle("GET /v1/invitations/{token}", loggingMiddleware(ok))
+	mux.Handle("GET /v1/org/invitations/{id}", loggingMiddleware(ok))
+	for _, path := range []string{"/v1/invitations/s3cr3t-t0ken", "/v1/org/invitations/42"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	got := buf.String()
+	if strings.Contains(got, "s3cr3t-t0ken") || !strings.Contains(got, "path=/v1/invitations/"+redacted+" ") {
+		t.Errorf("token not masked:\n%s", got)
+	}
+	if !strings.Contains(got, "path=/v1/org/invitations/42 ") {
+		t.Errorf("other path values masked:\n%s", got)
+	}
+}
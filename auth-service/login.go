@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password secret `json:"password"`
+}
+
+// validate doesn't apply the full password policy: that applies when a
+// password is set, and a login with a policy-violating password should
+// just fail.
+func (req loginRequest) validate() error {
+	var v validator
+	v.required("email", req.Email)
+	v.passwordAttempt("password", string(req.Password))
+	return v.err()
+}
+
+func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	// Fail before the password check rather than after a TOTP prompt.
+	if _, ok := s.loginTokenBinding(w, r); !ok {
+		return
+	}
+
+	var user User
+	err := retryDB(r.Context(), "login_select_user", func(ctx context.Context) error {
+		var err error
+		user, err = s.users.GetByEmail(ctx, req.Email)
+		return err
+	})
+	userID := user.ID
+	if r.Context().Err() != nil {
+		// The client went away; don't record that as a failed login.
+		return
+	}
+	if errors.Is(err, ErrNotFound) {
+		err := s.passwordHasher.CompareDummy(r.Context(), string(req.Password))
+		if r.Context().Err() != nil {
+			return
+		}
+		if errors.Is(err, errBcryptBusy) {
+			writeServerBusy(w, r)
+			return
+		}
+
+		loginFailuresTotal.WithLabelValues("unknown_user").Inc()
+		ev := s.loginAuditEvent(r, "login.failure", map[string]any{"email": req.Email, "reason": "unknown_user"})
+		s.auditor.Record(r.Context(), ev)
+
+		apperror.WriteError(w, r, apperror.Unauthorized("invalid_credentials", "Invalid credentials"))
+		return
+	}
+	if err != nil {
+		// An outage must not look like everyone mistyping their password.
+		dbErrorsTotal.WithLabelValues("login_select_user").Inc()
+		log.Printf("login lookup error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	err = s.passwordHasher.ComparePassword(r.Context(), []byte(user.PasswordHash), string(req.Password))
+	if err != nil && r.Context().Err() != nil {
+		// The client gave up while waiting for a worker.
+		return
+	}
+	if errors.Is(err, errBcryptBusy) {
+		writeServerBusy(w, r)
+		return
+	}
+	if errors.Is(err, errInvalidPasswordHash) {
+		log.Printf("login refused: user %d has an invalid password hash", userID)
+	}
+	if err != nil {
+		loginFailuresTotal.WithLabelValues("wrong_password").Inc()
+		ev := s.loginAuditEvent(r, "login.failure", map[string]any{"email": req.Email, "reason": "wrong_password"})
+		ev.ActorID = &userID
+		s.auditor.Record(r.Context(), ev)
+
+		apperror.WriteError(w, r, apperror.Unauthorized("invalid_credentials", "Invalid credentials"))
+		return
+	}
+
+	if s.passwordHasher.NeedsRehash([]byte(user.PasswordHash)) {
+		s.rehashPassword(r.Context(), userID, user.PasswordHash, string(req.Password))
+	}
+	s.startLogin(w, r, userID, req.Email, user.TOTPSecret != "")
+}
+
+// passwordRehashTimeout bounds a background re-hash, queueing included.
+const passwordRehashTimeout = 30 * time.Second
+
+// rehashPassword replaces oldHash with a hash by the current algorithm, in
+// the background so the login doesn't wait for a second hash. This is
+// how hashes migrate after PASSWORD_HASH_ALGORITHM or BCRYPT_COST
+// changes: one login at a time, since the password is only known then.
+// The update only applies if the hash is still oldHash, so it can't undo
+// a password change made meanwhile. Failures are only logged; the next
+// login tries again.
+func (s *Server) rehashPassword(ctx context.Context, userID int, oldHash, password string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), passwordRehashTimeout)
+		defer cancel()
+
+		hash, err := s.passwordHasher.HashPassword(ctx, password)
+		if err != nil {
+			log.Printf("password rehash error user_id=%d err=%v", userID, err)
+			return
+		}
+		err = s.users.UpdatePassword(ctx, userID, oldHash, string(hash))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.Printf("password rehash update error user_id=%d err=%v", userID, err)
+		}
+	}()
+}
+
+// setAuthCookies sends the JWT and the refresh token as cookies. The refresh
+// token is only ever sent back to the refresh endpoint.
+func (s *Server) setAuthCookies(w http.ResponseWriter, accessToken, refreshToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    accessToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   s.cfg.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	for _, path := range refreshCookiePaths {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "refresh_token",
+			Value:    refreshToken,
+			Path:     path,
+			MaxAge:   int(refreshTokenTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   s.cfg.CookieSecure,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
+
+// refreshCookiePaths are where the refresh token cookie is sent: each
+// version's endpoint and, until the legacy routes are removed, its
+// unversioned alias.
+var refreshCookiePaths = apiPaths("/refresh")
+
+func clearAuthCookies(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: "auth_token", Path: "/", MaxAge: -1})
+	for _, path := range refreshCookiePaths {
+		http.SetCookie(w, &http.Cookie{Name: "refresh_token", Path: path, MaxAge: -1})
+	}
+	clearSessionCookie(w)
+}
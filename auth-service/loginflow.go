@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/apperror"
+)
+
+const (
+	authFlowTTL     = 5 * time.Minute
+	deviceCookieTTL = 365 * 24 * time.Hour
+
+	stepTOTP         = "totp"
+	stepDeviceVerify = "device_verify"
+)
+
+// AuthFlow is a login in progress: the password has been checked but more
+// factors are due before a session is created. It lives in Redis under
+// auth_flow:<id> for authFlowTTL and is single-use: every step takes it
+// with GETDEL and only puts it back if the login moves on to another step,
+// so a failed or replayed step ends the flow.
+type AuthFlow struct {
+	ID       string `json:"id"`
+	UserID   int    `json:"user_id"`
+	Email    string `json:"email"`
+	NextStep string `json:"next_step"`
+
+	// NeedDevice is set when the device isn't trusted yet and the
+	// device_verify step still has to run after TOTP.
+	NeedDevice bool `json:"need_device"`
+	// TrustDevice marks the current device trusted once the flow completes.
+	TrustDevice    bool   `json:"trust_device"`
+	DeviceCodeHash string `json:"device_code_hash,omitempty"`
+}
+
+type authFlowResponse struct {
+	FlowID    string `json:"flow_id"`
+	NextStep  string `json:"next_step"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+func authFlowKey(id string) string {
+	return "auth_flow:" + id
+}
+
+func (s *Server) saveAuthFlow(ctx context.Context, flow *AuthFlow) error {
+	data, err := json.Marshal(flow)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, authFlowKey(flow.ID), data, authFlowTTL).Err()
+}
+
+// takeAuthFlow removes and returns the flow, or (nil, nil) if it doesn't
+// exist or has expired.
+func (s *Server) takeAuthFlow(ctx context.Context, id string) (*AuthFlow, error) {
+	data, err := s.rdb.GetDel(ctx, authFlowKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var flow AuthFlow
+	if err := json.Unmarshal(data, &flow); err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// startLogin is called once the password has been verified. It completes
+// the login straight away if no further factor is needed, otherwise it
+// starts an AuthFlow and tells the client which step comes next.
+func (s *Server) startLogin(w http.ResponseWriter, r *http.Request, userID int, email string, totpEnabled bool) {
+	known, trusted, err := s.deviceStatus(r, userID)
+	if err != nil {
+		log.Println("device trust lookup error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	deviceCheck := s.featureFlags.IsEnabledFor(r.Context(), flagDeviceTrust, userID)
+	totpEnabled = totpEnabled && s.featureFlags.IsEnabledFor(r.Context(), flagTOTPLogin, userID)
+
+	flow := &AuthFlow{
+		UserID: userID,
+		Email:  email,
+		// A user's first device is trusted on first use; after that, new
+		// devices have to be confirmed by email.
+		NeedDevice:  deviceCheck && known && !trusted,
+		TrustDevice: deviceCheck && (!known || !trusted),
+	}
+
+	if !totpEnabled && !flow.NeedDevice {
+		s.completeLogin(w, r, flow)
+		return
+	}
+
+	if flow.ID, err = newUUID(); err != nil {
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+	if totpEnabled {
+		flow.NextStep = stepTOTP
+	} else if err := s.beginDeviceVerify(r.Context(), flow); err != nil {
+		log.Println("device verification error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	s.advanceAuthFlow(w, r, flow)
+}
+
+func (s *Server) advanceAuthFlow(w http.ResponseWriter, r *http.Request, flow *AuthFlow) {
+	if err := s.saveAuthFlow(r.Context(), flow); err != nil {
+		log.Println("auth flow save error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+	writeJSON(w, http.StatusOK, authFlowResponse{
+		FlowID:    flow.ID,
+		NextStep:  flow.NextStep,
+		ExpiresIn: int(authFlowTTL.Seconds()),
+	})
+}
+
+type loginStepRequest struct {
+	FlowID secret `json:"flow_id"`
+	Code   secret `json:"code"`
+	// BackupCode may replace Code in the TOTP step.
+	BackupCode secret `json:"backup_code"`
+}
+
+func (req loginStepRequest) validate() error {
+	var v validator
+	v.required("flow_id", string(req.FlowID))
+	if req.BackupCode == "" {
+		v.required("code", string(req.Code))
+	}
+	return v.err()
+}
+
+// loadLoginStep decodes a step request and takes its flow, answering the
+// request itself if either fails.
+func (s *Server) loadLoginStep(w http.ResponseWriter, r *http.Request, step string) (*AuthFlow, loginStepRequest, bool) {
+	var req loginStepRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return nil, req, false
+	}
+
+	flow, err := s.takeAuthFlow(r.Context(), string(req.FlowID))
+	if err != nil {
+		log.Println("auth flow load error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return nil, req, false
+	}
+	if flow == nil || flow.NextStep != step {
+		apperror.WriteError(w, r, apperror.Unauthorized("login_flow_invalid", "Login flow expired or invalid"))
+		return nil, req, false
+	}
+	return flow, req, true
+}
+
+func (s *Server) failLoginStep(w http.ResponseWriter, r *http.Request, flow *AuthFlow, reason string) {
+	loginFailuresTotal.WithLabelValues(reason).Inc()
+	ev := s.loginAuditEvent(r, "login.failure", map[string]any{"email": flow.Email, "reason": reason})
+	ev.ActorID = &flow.UserID
+	s.auditor.Record(r.Context(), ev)
+
+	apperror.WriteError(w, r, apperror.Unauthorized("invalid_code", "Invalid code"))
+}
+
+// loginTOTPHandler serves POST /login/totp: {"flow_id": "...", "code": "123456"},
+// or {"flow_id": "...", "backup_code": "..."} from a user who has lost
+// their TOTP device.
+func (s *Server) loginTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	flow, req, ok := s.loadLoginStep(w, r, stepTOTP)
+	if !ok {
+		return
+	}
+
+	if req.BackupCode != "" {
+		ok = s.passBackupCode(w, r, flow, string(req.BackupCode))
+	} else {
+		ok = s.passTOTP(w, r, flow, string(req.Code))
+	}
+	if !ok {
+		return
+	}
+
+	if flow.NeedDevice {
+		if err := s.beginDeviceVerify(r.Context(), flow); err != nil {
+			log.Println("device verification error:", err)
+			apperror.WriteError(w, r, apperror.Internal(nil))
+			return
+		}
+		s.advanceAuthFlow(w, r, flow)
+		return
+	}
+	s.completeLogin(w, r, flow)
+}
+
+// passTOTP checks a TOTP code for the TOTP step. It reports whether the
+// login may carry on; otherwise it has answered the request.
+func (s *Server) passTOTP(w http.ResponseWriter, r *http.Request, flow *AuthFlow, code string) bool {
+	user, err := s.users.GetByID(r.Context(), flow.UserID)
+	if err != nil {
+		log.Println("totp secret lookup error:", err)
+		writeDBError(w, r, err)
+		return false
+	}
+
+	counter, valid := verifyTOTP(user.TOTPSecret, code, s.clock.Now())
+	if valid {
+		valid, err = s.claimTOTPStep(r.Context(), flow.UserID, counter)
+		if err != nil {
+			log.Println("totp replay check error:", err)
+			apperror.WriteError(w, r, apperror.Internal(nil))
+			return false
+		}
+	}
+	if !valid {
+		s.failLoginStep(w, r, flow, "invalid_totp")
+		return false
+	}
+	return true
+}
+
+// loginDeviceTrustHandler serves POST /login/device-trust with the code
+// emailed by beginDeviceVerify.
+func (s *Server) loginDeviceTrustHandler(w http.ResponseWriter, r *http.Request) {
+	flow, req, ok := s.loadLoginStep(w, r, stepDeviceVerify)
+	if !ok {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(req.Code))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(flow.DeviceCodeHash)) != 1 {
+		s.failLoginStep(w, r, flow, "invalid_device_code")
+		return
+	}
+
+	flow.NeedDevice = false
+	s.completeLogin(w, r, flow)
+}
+
+// beginDeviceVerify moves flow to the device_verify step and emails the user
+// a one-time code.
+func (s *Server) beginDeviceVerify(ctx context.Context, flow *AuthFlow) error {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	sum := sha256.Sum256([]byte(code))
+	flow.DeviceCodeHash = hex.EncodeToString(sum[:])
+	flow.NextStep = stepDeviceVerify
+
+	msg, err := newEmail(ctx, flow.Email, "device-verify", EmailTemplateData{
+		Email:     flow.Email,
+		Code:      code,
+		ExpiresIn: authFlowTTL,
+	})
+	if err != nil {
+		return err
+	}
+	return s.emailSender.Send(ctx, msg)
+}
+
+// deviceStatus reports whether the user has any trusted devices yet and
+// whether the request's device_id cookie is one of them.
+func (s *Server) deviceStatus(r *http.Request, userID int) (known, trusted bool, err error) {
+	key := trustedDevicesKey(userID)
+	n, err := s.rdb.SCard(r.Context(), key).Result()
+	if err != nil || n == 0 {
+		return false, false, err
+	}
+	cookie, err := r.Cookie("device_id")
+	if err != nil {
+		return true, false, nil
+	}
+	trusted, err = s.rdb.SIsMember(r.Context(), key, hashDeviceID(cookie.Value)).Result()
+	return true, trusted, err
+}
+
+func trustedDevicesKey(userID int) string {
+	return "trusted_devices:" + strconv.Itoa(userID)
+}
+
+func hashDeviceID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// trustDevice adds the request's device to the user's trusted set and
+// returns its ID, for setDeviceCookie. A device without a device_id
+// cookie is given a new ID.
+func (s *Server) trustDevice(r *http.Request, userID int) (string, error) {
+	deviceID := ""
+	if c, err := r.Cookie("device_id"); err == nil {
+		deviceID = c.Value
+	} else {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		deviceID = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	key := trustedDevicesKey(userID)
+	pipe := s.rdb.TxPipeline()
+	pipe.SAdd(r.Context(), key, hashDeviceID(deviceID))
+	pipe.Expire(r.Context(), key, deviceCookieTTL)
+	if _, err := pipe.Exec(r.Context()); err != nil {
+		return "", err
+	}
+	return deviceID, nil
+}
+
+// setDeviceCookie sends the device_id cookie, scoped to the login
+// endpoints under each version and the legacy alias.
+func (s *Server) setDeviceCookie(w http.ResponseWriter, deviceID string) {
+	for _, path := range apiPaths("/login") {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "device_id",
+			Value:    deviceID,
+			Path:     path,
+			MaxAge:   int(deviceCookieTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   s.cfg.CookieSecure,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
+
+// completeLogin issues tokens and the session once every factor has passed.
+// Cookies are only set once all of them exist: a login that fails part
+// way answers with an error and no cookies, and the refresh token it
+// already issued is revoked, so nothing half-logged-in is left behind.
+func (s *Server) completeLogin(w http.ResponseWriter, r *http.Request, flow *AuthFlow) {
+	binding, ok := s.loginTokenBinding(w, r)
+	if !ok {
+		return
+	}
+
+	tokenString, err := s.signAccessToken(r.Context(), flow.UserID, flow.Email, s.clock.Now())
+	if err != nil {
+		log.Println("access token sign error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	refreshToken, err := s.issueRefreshToken(r.Context(), flow.UserID)
+	if err != nil {
+		log.Println("refresh token issue error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	sessionID, err := s.createSession(r.Context(), flow.Email, binding)
+	if err != nil {
+		log.Println("session create error:", err)
+		if err := s.revokeRefreshToken(context.WithoutCancel(r.Context()), refreshToken); err != nil {
+			log.Println("refresh token revoke error:", err)
+		}
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	deviceID := ""
+	if flow.TrustDevice {
+		if deviceID, err = s.trustDevice(r, flow.UserID); err != nil {
+			// Not fatal: the device will just be asked to verify next time.
+			log.Println("trust device error:", err)
+		}
+	}
+
+	s.setAuthCookies(w, tokenString, refreshToken)
+	s.setSessionCookie(w, sessionID)
+	if deviceID != "" {
+		s.setDeviceCookie(w, deviceID)
+	}
+
+	ev := s.loginAuditEvent(r, "login.success", nil)
+	ev.ActorID = &flow.UserID
+	s.auditor.Record(r.Context(), ev)
+
+	s.checkLoginLocation(r, flow.UserID, flow.Email, sessionID)
+
+	w.Write([]byte("Logged in"))
+}
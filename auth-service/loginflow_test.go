@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postJSON sends body to path through h with the given cookies.
+func postJSON(h http.Handler, path, body string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+// newTOTPUser creates a user with password "correct horse" and TOTP
+// turned on, and returns it with its secret.
+func newTOTPUser(t *testing.T, s *Server, email string) (User, string) {
+	t.Helper()
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := newTestUser(t, s, email, "user")
+	updateTestUser(s, user.ID, func(u *User) { u.TOTPSecret = secret })
+	user.TOTPSecret = secret
+	return user, secret
+}
+
+// currentTOTP is the code an authenticator app would show at s's time.
+func currentTOTP(t *testing.T, s *Server, secret string) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return totpCode(key, uint64(s.clock.Now().Unix())/uint64(totpStep.Seconds()))
+}
+
+// startFlow logs in with the password and returns the flow the login
+// continues in.
+func startFlow(t *testing.T, h http.Handler, email string) authFlowResponse {
+	t.Helper()
+	rec := postJSON(h, "/v1/login", `{"email":"`+email+`","password":"correct horse"}`)
+	var flow authFlowResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &flow) != nil || flow.FlowID == "" {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body)
+	}
+	if len(rec.Result().Cookies()) != 0 {
+		t.Errorf("a login waiting for a second factor set cookies: %v", rec.Result().Cookies())
+	}
+	return flow
+}
+
+func loginStep(h http.Handler, path, flowID, code string) *httptest.ResponseRecorder {
+	return postJSON(h, path, `{"flow_id":"`+flowID+`","code":"`+code+`"}`)
+}
+
+func cookieNames(rec *httptest.ResponseRecorder) map[string]bool {
+	names := map[string]bool{}
+	for _, c := range rec.Result().Cookies() {
+		names[c.Name] = true
+	}
+	return names
+}
+
+var sixDigits = regexp.MustCompile(`\b\d{6}\b`)
+
+// TestLoginFlowSteps walks a login through both extra steps: TOTP, then
+// confirming a new device with the emailed code. Only the last step logs
+// in.
+func TestLoginFlowSteps(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	refresh := useFakeRefreshDB(t, s)
+	h := s.Handler()
+	user, secret := newTOTPUser(t, s, "a@example.com")
+	// Some other device is trusted already, so this one must be confirmed.
+	mr.SAdd(trustedDevicesKey(user.ID), hashDeviceID("laptop"))
+
+	// Well away from the real time, so only a code for the fake clock's
+	// time is accepted.
+	s.clock.(*fakeClock).Advance(10 * time.Minute)
+
+	flow := startFlow(t, h, "a@example.com")
+	if flow.NextStep != stepTOTP || flow.ExpiresIn != int(authFlowTTL.Seconds()) {
+		t.Fatalf("after the password: %+v", flow)
+	}
+
+	rec := loginStep(h, "/v1/login/totp", flow.FlowID, currentTOTP(t, s, secret))
+	var next authFlowResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &next) != nil {
+		t.Fatalf("totp step: %d %s", rec.Code, rec.Body)
+	}
+	if next.FlowID != flow.FlowID || next.NextStep != stepDeviceVerify {
+		t.Fatalf("after totp: %+v, want the same flow at %s", next, stepDeviceVerify)
+	}
+	if len(rec.Result().Cookies()) != 0 {
+		t.Errorf("the totp step set cookies before the login completed")
+	}
+
+	sent := s.emailSender.(*fakeEmailSender).messages()
+	if len(sent) != 1 || sent[0].To != "a@example.com" {
+		t.Fatalf("emails sent: %+v", sent)
+	}
+	code := sixDigits.FindString(sent[0].TextBody)
+	if code == "" {
+		t.Fatalf("no code in %q", sent[0].TextBody)
+	}
+
+	rec = loginStep(h, "/v1/login/device-trust", flow.FlowID, code)
+	if rec.Code != http.StatusOK || rec.Body.String() != "Logged in" {
+		t.Fatalf("device step: %d %s", rec.Code, rec.Body)
+	}
+	names := cookieNames(rec)
+	for _, name := range []string{"auth_token", "refresh_token", "session_id", "device_id"} {
+		if !names[name] {
+			t.Errorf("no %s cookie after the login", name)
+		}
+	}
+	if len(refresh.tokens) != 1 || refresh.tokens[0].userID != user.ID {
+		t.Errorf("refresh tokens issued: %+v", refresh.tokens)
+	}
+	if mr.Exists(authFlowKey(flow.FlowID)) {
+		t.Error("the flow outlived the login")
+	}
+}
+
+// Every step takes its flow with GETDEL, so a flow can't be used twice:
+// not after a wrong code, not after it succeeded, and not at a step it
+// isn't at.
+func TestLoginFlowSingleUse(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	useFakeRefreshDB(t, s)
+	h := s.Handler()
+	_, secret := newTOTPUser(t, s, "a@example.com")
+
+	invalidFlow := func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+		if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "login_flow_invalid" {
+			t.Errorf("code %q, want login_flow_invalid", env.Error.Code)
+		}
+	}
+
+	t.Run("after a wrong code", func(t *testing.T) {
+		flow := startFlow(t, h, "a@example.com")
+		if env := readEnvelope(t, loginStep(h, "/v1/login/totp", flow.FlowID, "000000"), http.StatusUnauthorized); env.Error.Code != "invalid_code" {
+			t.Errorf("wrong code: %q, want invalid_code", env.Error.Code)
+		}
+		invalidFlow(t, loginStep(h, "/v1/login/totp", flow.FlowID, currentTOTP(t, s, secret)))
+	})
+
+	t.Run("replayed after success", func(t *testing.T) {
+		s.clock.(*fakeClock).Advance(totpStep)
+		flow := startFlow(t, h, "a@example.com")
+		body := `{"flow_id":"` + flow.FlowID + `","code":"` + currentTOTP(t, s, secret) + `"}`
+		if rec := postJSON(h, "/v1/login/totp", body); rec.Code != http.StatusOK {
+			t.Fatalf("first use: %d %s", rec.Code, rec.Body)
+		}
+		rec := postJSON(h, "/v1/login/totp", body)
+		invalidFlow(t, rec)
+		if len(rec.Result().Cookies()) != 0 {
+			t.Error("the replay was given cookies")
+		}
+	})
+
+	t.Run("at the wrong step", func(t *testing.T) {
+		s.clock.(*fakeClock).Advance(totpStep)
+		flow := startFlow(t, h, "a@example.com")
+		invalidFlow(t, loginStep(h, "/v1/login/device-trust", flow.FlowID, "123456"))
+		invalidFlow(t, loginStep(h, "/v1/login/totp", flow.FlowID, currentTOTP(t, s, secret)))
+	})
+}
+
+// A flow lasts authFlowTTL, which Redis enforces.
+func TestLoginFlowExpiry(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	useFakeRefreshDB(t, s)
+	h := s.Handler()
+	_, secret := newTOTPUser(t, s, "a@example.com")
+
+	flow := startFlow(t, h, "a@example.com")
+	mr.FastForward(authFlowTTL - time.Second)
+	if rec := loginStep(h, "/v1/login/totp", flow.FlowID, currentTOTP(t, s, secret)); rec.Code != http.StatusOK {
+		t.Fatalf("a second before expiry: %d %s", rec.Code, rec.Body)
+	}
+
+	s.clock.(*fakeClock).Advance(totpStep)
+	flow = startFlow(t, h, "a@example.com")
+	mr.FastForward(authFlowTTL + time.Second)
+	rec := loginStep(h, "/v1/login/totp", flow.FlowID, currentTOTP(t, s, secret))
+	if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "login_flow_invalid" {
+		t.Errorf("after expiry: %q, want login_flow_invalid", env.Error.Code)
+	}
+}
+
+// A login that fails after issuing its refresh token sets no cookies and
+// revokes the token, rather than leaving the client half logged in.
+func TestCompleteLoginFailureSetsNoCookies(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	refresh := useFakeRefreshDB(t, s)
+
+	// Redis goes down between the last step and the session write.
+	mr.SetError("READONLY You can't write against a read only replica.")
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/v1/login/totp", nil)
+	s.completeLogin(rec, r, &AuthFlow{UserID: 42, Email: "a@example.com", TrustDevice: true})
+
+	readEnvelope(t, rec, http.StatusInternalServerError)
+	if cookies := rec.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("a failed login set cookies: %v", cookies)
+	}
+	if len(refresh.tokens) != 1 || !refresh.tokens[0].revoked {
+		t.Errorf("the refresh token issued by the failed login is live: %+v", refresh.tokens)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+const (
+	loginHistoryDefaultLimit = 10
+	loginHistoryMaxLimit     = 100
+	// loginHistoryRateLimit is requests per user per minute, on top of the
+	// per-IP limit.
+	loginHistoryRateLimit = 10
+)
+
+type loginHistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	IP        string    `json:"ip"`
+	Country   string    `json:"country"`
+	City      string    `json:"city"`
+	Browser   string    `json:"browser"`
+	OS        string    `json:"os"`
+	Success   bool      `json:"success"`
+}
+
+// loginHistoryHandler serves GET /v1/me/login-history?limit=, the
+// caller's latest successful and failed logins, newest first, read from
+// the audit log. Country and city are "" for logins made before they were
+// recorded, or when the edge doesn't resolve them (GEO_COUNTRY_HEADER,
+// GEO_CITY_HEADER); browser and os are "" for clients parseUserAgent
+// doesn't know.
+func (s *Server) loginHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	caller, ok := auth.UserFromContext(r.Context())
+	if !ok || caller.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+
+	limit := loginHistoryDefaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			apperror.WriteError(w, r, apperror.BadRequest("invalid_parameter", "Invalid limit").
+				WithField("limit", "invalid_value", "must be a positive integer"))
+			return
+		}
+		limit = min(n, loginHistoryMaxLimit)
+	}
+
+	allowed, err := s.loginHistoryLimiter.Allow(r.Context(), "login_history:"+strconv.Itoa(caller.ID))
+	if err != nil {
+		log.Println("login history rate limit error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+	if !allowed {
+		rateLimitedTotal.Inc()
+		apperror.WriteError(w, r, apperror.TooManyRequests("rate_limited", "Too many requests"))
+		return
+	}
+
+	// The action list must match audit_events_login_idx's predicate for
+	// the planner to use it.
+	rows, err := s.db.QueryContext(r.Context(),
+		`SELECT created_at, ip, user_agent, action = 'login.success',
+		        COALESCE(metadata->>'country', ''), COALESCE(metadata->>'city', '')
+		 FROM audit_events
+		 WHERE actor_id = $1 AND action IN ('login.success', 'login.failure')
+		 ORDER BY created_at DESC, id DESC LIMIT $2`,
+		caller.ID, limit,
+	)
+	if err != nil {
+		log.Println("login history error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	entries := []loginHistoryEntry{}
+	for rows.Next() {
+		var (
+			e  loginHistoryEntry
+			ua string
+		)
+		if err := rows.Scan(&e.Timestamp, &e.IP, &ua, &e.Success, &e.Country, &e.City); err != nil {
+			writeDBError(w, r, err)
+			return
+		}
+		e.Browser, e.OS = parseUserAgent(ua)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"logins": entries})
+}
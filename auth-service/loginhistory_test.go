@@ -0,0 +1,258 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"resilient-auth-service/flags"
+)
+
+const (
+	firefoxOnLinux = "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+	safariOnIPhone = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+)
+
+// auditLogDB is a database/sql driver that keeps the audit events the
+// Auditor inserts and answers the login history query from them, newest
+// first as ORDER BY created_at DESC, id DESC would.
+type auditLogDB struct {
+	mu     sync.Mutex
+	events []auditLogRow
+}
+
+type auditLogRow struct {
+	id       int
+	actorID  driver.Value
+	action   string
+	ip       string
+	ua       string
+	metadata map[string]string
+	created  time.Time
+}
+
+func (f *auditLogDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *auditLogDB) Driver() driver.Driver                        { return nil }
+func (f *auditLogDB) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (f *auditLogDB) Close() error                                 { return nil }
+func (f *auditLogDB) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (f *auditLogDB) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.events)
+}
+
+func (f *auditLogDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO audit_events"):
+		ev := auditLogRow{id: len(f.events) + 1, actorID: args[1].Value, action: args[2].Value.(string),
+			ip: args[4].Value.(string), ua: args[5].Value.(string), created: args[8].Value.(time.Time)}
+		if err := json.Unmarshal(args[7].Value.([]byte), &ev.metadata); err != nil {
+			return nil, err
+		}
+		f.events = append(f.events, ev)
+		return &fakeRows{cols: []string{"id"}, rows: [][]driver.Value{{int64(ev.id)}}}, nil
+
+	case strings.HasPrefix(query, "SELECT created_at, ip, user_agent, action = 'login.success'"):
+		var matched []auditLogRow
+		for _, ev := range f.events {
+			if ev.actorID == args[0].Value && (ev.action == "login.success" || ev.action == "login.failure") {
+				matched = append(matched, ev)
+			}
+		}
+		slices.SortFunc(matched, func(a, b auditLogRow) int {
+			return cmp.Or(b.created.Compare(a.created), cmp.Compare(b.id, a.id))
+		})
+		rows := &fakeRows{cols: []string{"created_at", "ip", "user_agent", "success", "country", "city"}}
+		for _, ev := range matched[:min(len(matched), int(args[1].Value.(int64)))] {
+			rows.rows = append(rows.rows, []driver.Value{ev.created, ev.ip, ev.ua, ev.action == "login.success",
+				ev.metadata["country"], ev.metadata["city"]})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("auditLogDB: unexpected query %q", query)
+}
+
+// useAuditLogDB gives s an Auditor that writes to an auditLogDB, and
+// returns it with a database on it to read the events back from.
+func useAuditLogDB(t *testing.T, s *Server) (*auditLogDB, *sql.DB) {
+	t.Helper()
+	s.auditor.Close(context.Background())
+	fake := &auditLogDB{}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+	s.auditor = NewAuditor(db, s.rdb, 64)
+	return fake, db
+}
+
+func getLoginHistory(t *testing.T, h http.Handler, cookie *http.Cookie, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/v1/me/login-history"+query, nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+// TestLoginHistory has two users log in, and fail to, from different
+// browsers and places, then reads each one's history back: their own
+// logins only, newest first, with where they came from. Each user may
+// read it loginHistoryRateLimit times a minute.
+func TestLoginHistory(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	s.cfg.GeoCountryHeader = "CF-IPCountry"
+	s.cfg.GeoCityHeader = "CF-IPCity"
+	_, edge, _ := net.ParseCIDR("192.0.2.0/24") // httptest's RemoteAddr
+	s.cfg.TrustedProxies = []net.IPNet{*edge}
+	// Only the per-user limit is under test, not the per-IP one.
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1000, time.Minute)
+	useFakeRefreshDB(t, s)
+	audit, auditDB := useAuditLogDB(t, s)
+	if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagDeviceTrust}); err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+	alice := newTestUser(t, s, "alice@example.com", "user")
+	bob := newTestUser(t, s, "bob@example.com", "user")
+
+	login := func(email, password, ua, country, city string) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/v1/login",
+			strings.NewReader(fmt.Sprintf(`{"email":%q,"password":%q}`, email, password)))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("User-Agent", ua)
+		r.Header.Set("CF-IPCountry", country)
+		r.Header.Set("CF-IPCity", city)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	login("alice@example.com", "correct horse", firefoxOnLinux, "DE", "Berlin")
+	login("alice@example.com", "wrong", safariOnIPhone, "FR", "Paris")
+	login("bob@example.com", "correct horse", firefoxOnLinux, "US", "Boston")
+	login("nobody@example.com", "wrong", firefoxOnLinux, "US", "Boston")
+	login("alice@example.com", "correct horse", safariOnIPhone, "XX", "")
+	eventually(t, "the audit events", func() bool { return audit.count() == 5 })
+	s.db = auditDB
+
+	aliceCookie := accessTokenCookie(t, s, alice)
+	var history struct{ Logins []loginHistoryEntry }
+	rec := getLoginHistory(t, h, aliceCookie, "")
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &history) != nil {
+		t.Fatalf("history: %d %s", rec.Code, rec.Body)
+	}
+	want := []loginHistoryEntry{
+		{IP: "192.0.2.1", Browser: "Safari", OS: "iOS", Success: true},
+		{IP: "192.0.2.1", Country: "FR", City: "Paris", Browser: "Safari", OS: "iOS"},
+		{IP: "192.0.2.1", Country: "DE", City: "Berlin", Browser: "Firefox", OS: "Linux", Success: true},
+	}
+	if len(history.Logins) != len(want) {
+		t.Fatalf("history %+v, want %d entries", history.Logins, len(want))
+	}
+	for i, got := range history.Logins {
+		if i > 0 && got.Timestamp.After(history.Logins[i-1].Timestamp) {
+			t.Errorf("entry %d is newer than the one before it", i)
+		}
+		got.Timestamp = time.Time{}
+		if got != want[i] {
+			t.Errorf("entry %d: %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	history.Logins = nil
+	if rec := getLoginHistory(t, h, aliceCookie, "?limit=1"); json.Unmarshal(rec.Body.Bytes(), &history) != nil ||
+		len(history.Logins) != 1 || history.Logins[0].OS != "iOS" {
+		t.Errorf("limit=1: %d %s", rec.Code, rec.Body)
+	}
+	for _, limit := range []string{"0", "-1", "x"} {
+		if env := readEnvelope(t, getLoginHistory(t, h, aliceCookie, "?limit="+limit), http.StatusBadRequest); env.Error.Code != "invalid_parameter" {
+			t.Errorf("limit=%s: code %q", limit, env.Error.Code)
+		}
+	}
+	if rec := getLoginHistory(t, h, nil, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token: %d", rec.Code)
+	}
+
+	// Two requests so far; the limit is per user.
+	for i := 2; i < loginHistoryRateLimit; i++ {
+		if rec := getLoginHistory(t, h, aliceCookie, ""); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: %d %s", i+1, rec.Code, rec.Body)
+		}
+	}
+	if env := readEnvelope(t, getLoginHistory(t, h, aliceCookie, ""), http.StatusTooManyRequests); env.Error.Code != "rate_limited" {
+		t.Errorf("over the limit: code %q", env.Error.Code)
+	}
+	history.Logins = nil
+	if rec := getLoginHistory(t, h, accessTokenCookie(t, s, bob), ""); json.Unmarshal(rec.Body.Bytes(), &history) != nil ||
+		len(history.Logins) != 1 || history.Logins[0].City != "Boston" {
+		t.Errorf("bob's history: %d %s", rec.Code, rec.Body)
+	}
+}
+
+// TestLoginHistoryPostgres seeds audit_events out of order when
+// TEST_DATABASE_URL names a database, and checks that the query returns
+// the caller's logins newest first. It empties audit_events, so point it
+// at a scratch database.
+func TestLoginHistoryPostgres(t *testing.T) {
+	db := openTestDatabase(t)
+	quietLog(t)
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "TRUNCATE audit_events"); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestServer(t)
+	s.db = db
+	user := newTestUser(t, s, "a@example.com", "user")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, ev := range []struct {
+		actor   int
+		action  string
+		ago     time.Duration
+		country string
+	}{
+		{user.ID, "login.success", 3 * time.Hour, "DE"},
+		{user.ID, "login.failure", time.Hour, "FR"},
+		{user.ID, "user.password_change", 30 * time.Minute, "GB"},
+		{user.ID + 1, "login.success", 10 * time.Minute, "US"},
+		{user.ID, "login.success", 2 * time.Hour, "JP"},
+	} {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO audit_events (actor_id, action, ip, metadata, created_at) VALUES ($1, $2, '192.0.2.1', $3, $4)`,
+			ev.actor, ev.action, fmt.Sprintf(`{"country":%q}`, ev.country), now.Add(-ev.ago))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var history struct{ Logins []loginHistoryEntry }
+	rec := getLoginHistory(t, s.Handler(), accessTokenCookie(t, s, user), "")
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &history) != nil {
+		t.Fatalf("history: %d %s", rec.Code, rec.Body)
+	}
+	var got []string
+	for _, e := range history.Logins {
+		got = append(got, fmt.Sprintf("%s %v %s", e.Country, e.Success, e.Timestamp.Sub(now)))
+	}
+	want := []string{"FR false -1h0m0s", "JP true -2h0m0s", "DE true -3h0m0s"}
+	if !slices.Equal(got, want) {
+		t.Errorf("history %v, want %v", got, want)
+	}
+}
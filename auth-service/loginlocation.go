@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+
+	"resilient-auth-service/apperror"
+)
+
+const (
+	// newLocationAlertInterval caps new-location emails per user, so a
+	// traveller (or an attacker hopping VPN exits) can't flood the inbox.
+	newLocationAlertInterval = time.Hour
+	newLocationEmailTimeout  = 30 * time.Second
+)
+
+// geoCountry returns the ISO 3166 country code the edge resolved for the
+// request, from cfg.GeoCountryHeader (e.g. Cloudflare's CF-IPCountry), or ""
+// when unknown. Like X-Forwarded-For, the header is only believed from a
+// trusted proxy.
+func (s *Server) geoCountry(r *http.Request) string {
+	code := strings.ToUpper(s.trustedGeoHeader(r, s.cfg.GeoCountryHeader))
+	if len(code) != 2 || code == "XX" || code == "T1" { // unknown, Tor
+		return ""
+	}
+	return code
+}
+
+// geoCity returns the city the edge resolved for the request, from
+// cfg.GeoCityHeader, or "" when unknown.
+func (s *Server) geoCity(r *http.Request) string {
+	return s.trustedGeoHeader(r, s.cfg.GeoCityHeader)
+}
+
+// trustedGeoHeader returns header's value if the request came through a
+// trusted proxy, and "" otherwise or if header is "".
+func (s *Server) trustedGeoHeader(r *http.Request, header string) string {
+	if header == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !ipTrusted(net.ParseIP(host), s.cfg.TrustedProxies) {
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get(header))
+}
+
+// loginAuditEvent is auditEventFromRequest for login.success and
+// login.failure, with the client's location in the metadata when the edge
+// resolved one, for GET /me/login-history.
+func (s *Server) loginAuditEvent(r *http.Request, action string, metadata map[string]any) AuditEvent {
+	ev := s.auditEventFromRequest(r, action)
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	if country := s.geoCountry(r); country != "" {
+		metadata["country"] = country
+	}
+	if city := s.geoCity(r); city != "" {
+		metadata["city"] = city
+	}
+	ev.Metadata = metadata
+	return ev
+}
+
+// countryName turns "DE" into "Germany", falling back to the code.
+func countryName(code string) string {
+	region, err := language.ParseRegion(code)
+	if err != nil {
+		return code
+	}
+	if name := display.English.Regions().Name(region); name != "" {
+		return name
+	}
+	return code
+}
+
+func knownLocationsKey(userID int) string {
+	return "known_locations:" + strconv.Itoa(userID)
+}
+
+// checkLoginLocation runs after a successful login. The first time a user
+// logs in from a country outside known_locations:<userID> it records the
+// country, audits login.new_location and emails the user in the background.
+// The very first country on record is just remembered: without history
+// there is nothing to compare it with.
+func (s *Server) checkLoginLocation(r *http.Request, userID int, email, sessionID string) {
+	country := s.geoCountry(r)
+	if country == "" {
+		return
+	}
+	ctx := r.Context()
+	key := knownLocationsKey(userID)
+
+	pipe := s.rdb.TxPipeline()
+	added := pipe.SAdd(ctx, key, country)
+	known := pipe.SCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Println("known locations error:", err)
+		return
+	}
+	if added.Val() == 0 || known.Val() == 1 {
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "login.new_location")
+	ev.Level = "warning"
+	ev.ActorID = &userID
+	ev.Metadata = map[string]any{"country": country}
+	s.auditor.Record(ctx, ev)
+
+	alertKey := "new_location_alert:" + strconv.Itoa(userID)
+	first, err := s.rdb.SetNX(ctx, alertKey, 1, newLocationAlertInterval).Result()
+	if err != nil || !first {
+		return
+	}
+
+	token, err := newRefreshToken()
+	if err != nil {
+		log.Println("revoke token error:", err)
+		return
+	}
+	if err := s.rdb.Set(ctx, "session_revoke:"+hashRefreshToken(token), sessionID, s.cfg.SessionTTL).Err(); err != nil {
+		log.Println("revoke token error:", err)
+		return
+	}
+
+	data := EmailTemplateData{
+		Email:   email,
+		Link:    s.cfg.AppURL + "/revoke-session?token=" + url.QueryEscape(token),
+		Country: countryName(country),
+		IP:      ev.IP,
+		Device:  r.UserAgent(),
+		Time:    time.Now().UTC(),
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), newLocationEmailTimeout)
+		defer cancel()
+		if err := s.sendNewLocationEmail(ctx, data); err != nil {
+			log.Println("new location email error:", err)
+		}
+	}()
+}
+
+func (s *Server) sendNewLocationEmail(ctx context.Context, data EmailTemplateData) error {
+	msg, err := newEmail(ctx, data.Email, "new-location", data)
+	if err != nil {
+		return err
+	}
+	return s.emailSender.Send(ctx, msg)
+}
+
+type revokeSessionRequest struct {
+	Token secret `json:"token"`
+}
+
+func (req revokeSessionRequest) validate() error {
+	var v validator
+	v.required("token", string(req.Token))
+	return v.err()
+}
+
+// revokeSessionHandler serves POST /v1/sessions/revoke, which the page
+// linked from the new-location email calls. The token names exactly one
+// session and works once.
+func (s *Server) revokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	var req revokeSessionRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	sessionID, err := s.rdb.GetDel(r.Context(), "session_revoke:"+hashRefreshToken(string(req.Token))).Result()
+	if err == redis.Nil {
+		apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid or expired token"))
+		return
+	}
+	if err != nil {
+		log.Println("session revoke error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+	if err := s.DeleteSession(r.Context(), sessionID); err != nil {
+		log.Println("session revoke error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	s.auditor.Record(r.Context(), s.auditEventFromRequest(r, "session.revoked_from_email"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"resilient-auth-service/flags"
+)
+
+// TestNewLocationAlert logs one user in from Germany, France, the US and,
+// an hour later, Japan. The first country is just remembered; every later
+// new one is audited, and emailed at most once an hour, with a link that
+// revokes the session.
+func TestNewLocationAlert(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	s.cfg.GeoCountryHeader = "CF-IPCountry"
+	_, edge, _ := net.ParseCIDR("192.0.2.0/24") // httptest's RemoteAddr
+	s.cfg.TrustedProxies = []net.IPNet{*edge}
+	useFakeRefreshDB(t, s)
+	useAuditDB(t, s)
+	newTestUser(t, s, "a@example.com", "user")
+	// Each login would otherwise be a new device to verify first.
+	if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagDeviceTrust}); err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+	emails := s.emailSender.(*fakeEmailSender)
+
+	sub := s.rdb.Subscribe(context.Background(), auditLiveChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(country string) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(`{"email":"a@example.com","password":"correct horse"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("CF-IPCountry", country)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("login from %s: %d %s", country, rec.Code, rec.Body)
+		}
+	}
+	waitForEmails := func(n int) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); len(emails.messages()) < n; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d emails sent, want %d", len(emails.messages()), n)
+			}
+		}
+	}
+
+	login("DE")
+	login("DE")
+	login("FR")
+	waitForEmails(1)
+	login("US")
+	mr.FastForward(newLocationAlertInterval)
+	login("JP")
+	waitForEmails(2)
+
+	var countries []string
+	for len(countries) < 3 {
+		select {
+		case msg := <-sub.Channel():
+			var ev AuditEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				t.Fatal(err)
+			}
+			if ev.Action == "login.new_location" {
+				countries = append(countries, ev.Metadata["country"].(string))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("new_location events for %v, want FR, US and JP", countries)
+		}
+	}
+	slices.Sort(countries)
+	if !slices.Equal(countries, []string{"FR", "JP", "US"}) {
+		t.Errorf("new_location events for %v, want FR, US and JP", countries)
+	}
+
+	sent := emails.messages()
+	if len(sent) != 2 || sent[0].Subject != "New login from France" || sent[1].Subject != "New login from Japan" {
+		t.Fatalf("emails %+v, want one for France and one for Japan", sent)
+	}
+	token := regexp.MustCompile(`revoke-session\?token=([^"&]+)`).FindStringSubmatch(sent[0].HTMLBody)
+	if token == nil || !strings.Contains(sent[0].HTMLBody, "192.0.2.1") {
+		t.Fatalf("email lacks the IP or a revoke link:\n%s", sent[0].HTMLBody)
+	}
+	for _, want := range []int{http.StatusNoContent, http.StatusUnauthorized} {
+		if rec := postJSON(h, "/v1/sessions/revoke", `{"token":"`+token[1]+`"}`); rec.Code != want {
+			t.Errorf("revoke: %d %s, want %d", rec.Code, rec.Body, want)
+		}
+	}
+}
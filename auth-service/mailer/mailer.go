@@ -0,0 +1,84 @@
+// Package mailer delivers the service's transactional email. A Sender
+// sends one Message: SMTPSender through an SMTP relay, SESSender through
+// the Amazon SES API and LogSender nowhere, for development. RetrySender
+// retries a failing Sender and Queue delivers in the background, so
+// callers never wait on a mail server.
+//
+// Rendering is the caller's job: a Message arrives with its subject and
+// bodies final.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Message is a single outgoing email. TextBody is optional; with it, the
+// email is sent as multipart/alternative.
+type Message struct {
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Sender delivers email. Implementations must honour ctx cancellation.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// traceHeaders returns ctx's trace context and baggage (traceparent,
+// baggage), which senders add to the message headers so mail pipelines
+// that understand them can join the trace.
+func traceHeaders(ctx context.Context) propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// RetrySender retries a failing Sender with exponential backoff.
+type RetrySender struct {
+	Sender      Sender
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+func (s *RetrySender) Send(ctx context.Context, msg Message) error {
+	var err error
+	delay := s.BaseDelay
+
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		err = s.Sender.Send(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		if attempt == s.MaxAttempts {
+			break
+		}
+
+		log.Printf("email send failed attempt=%d to=%s err=%v", attempt, msg.To, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return fmt.Errorf("email send failed after %d attempts: %w", s.MaxAttempts, err)
+}
+
+// LogSender is used when no mail server is configured. The body is not
+// logged because it usually carries a one-time link.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("email (not sent) to=%s subject=%q", msg.To, msg.Subject)
+	return nil
+}
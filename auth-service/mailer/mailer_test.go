@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+)
+
+func quietLog(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+}
+
+// fakeSender fails the first f.failures sends, then records messages.
+type fakeSender struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	sent     []Message
+}
+
+var errUnavailable = errors.New("421 service not available")
+
+func (f *fakeSender) Send(ctx context.Context, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return errUnavailable
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func (f *fakeSender) messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.sent...)
+}
+
+func TestRetrySender(t *testing.T) {
+	quietLog(t)
+	msg := Message{To: "a@example.com", Subject: "Hi"}
+
+	t.Run("recovers", func(t *testing.T) {
+		f := &fakeSender{failures: 2}
+		s := &RetrySender{Sender: f, MaxAttempts: 3, BaseDelay: time.Millisecond}
+		if err := s.Send(context.Background(), msg); err != nil {
+			t.Fatal(err)
+		}
+		if f.calls != 3 || len(f.messages()) != 1 {
+			t.Errorf("%d calls, %d sent; want 3, 1", f.calls, len(f.messages()))
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		f := &fakeSender{failures: 5}
+		s := &RetrySender{Sender: f, MaxAttempts: 3, BaseDelay: time.Millisecond}
+		if err := s.Send(context.Background(), msg); !errors.Is(err, errUnavailable) {
+			t.Errorf("got %v, want the last error wrapped", err)
+		}
+		if f.calls != 3 {
+			t.Errorf("%d calls, want 3", f.calls)
+		}
+	})
+
+	t.Run("cancelled between attempts", func(t *testing.T) {
+		f := &fakeSender{failures: 5}
+		s := &RetrySender{Sender: f, MaxAttempts: 3, BaseDelay: time.Hour}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := s.Send(ctx, msg); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want the deadline", err)
+		}
+		if f.calls != 1 {
+			t.Errorf("%d calls, want 1", f.calls)
+		}
+	})
+}
+
+func TestQueue(t *testing.T) {
+	quietLog(t)
+	f := &fakeSender{}
+	q := NewQueue(f, 2, 10)
+	for range 5 {
+		if err := q.Send(context.Background(), Message{To: "a@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(f.messages()); got != 5 {
+		t.Errorf("Close returned with %d of 5 messages delivered", got)
+	}
+	if err := q.Send(context.Background(), Message{To: "a@example.com"}); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Send after Close: got %v, want ErrQueueClosed", err)
+	}
+}
+
+// The caller's cancellation doesn't reach a queued message, which is
+// delivered after the request that sent it has ended.
+func TestQueueOutlivesRequest(t *testing.T) {
+	quietLog(t)
+	f := &fakeSender{}
+	q := NewQueue(f, 1, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := q.Send(ctx, Message{To: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	q.Close(context.Background())
+	if got := len(f.messages()); got != 1 {
+		t.Errorf("%d messages delivered, want 1", got)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	quietLog(t)
+	q := NewQueue(&fakeSender{}, 0, 1)
+	if err := q.Send(context.Background(), Message{To: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Send(context.Background(), Message{To: "b@example.com"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("got %v, want ErrQueueFull", err)
+	}
+	q.Close(context.Background())
+}
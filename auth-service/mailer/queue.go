@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// deliveryTimeout bounds one message's delivery, retries included.
+const deliveryTimeout = 2 * time.Minute
+
+var deliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "email_deliveries_total",
+	Help: "Emails by outcome: sent, dead_letter (failed after retries) or dropped (queue full or closed).",
+}, []string{"result"})
+
+// Errors from Queue.Send, which mean the message was dropped.
+var (
+	ErrQueueFull   = errors.New("mailer: queue full")
+	ErrQueueClosed = errors.New("mailer: queue closed")
+)
+
+type queuedMessage struct {
+	// ctx carries the sender's values, e.g. trace context for the
+	// message headers, but not its deadline or cancellation.
+	ctx context.Context
+	msg Message
+}
+
+// Queue is a Sender that hands messages to a few workers and
+// returns at once, so a slow or failing mail server holds up neither
+// requests nor the goroutines that send from them. Retries are the wrapped
+// sender's job (RetrySender); a message that still fails is
+// dead-lettered: logged without its body, which usually carries a
+// one-time link, and counted in email_deliveries_total.
+type Queue struct {
+	sender Sender
+	queue  chan queuedMessage
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewQueue(sender Sender, workers, queueSize int) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		sender: sender,
+		queue:  make(chan queuedMessage, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for range workers {
+		q.wg.Add(1)
+		go q.run()
+	}
+	return q
+}
+
+// Send queues msg. It fails only if the queue is full or closed, and the
+// message is then dropped.
+func (q *Queue) Send(ctx context.Context, msg Message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		deliveriesTotal.WithLabelValues("dropped").Inc()
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.queue <- queuedMessage{ctx: context.WithoutCancel(ctx), msg: msg}:
+		return nil
+	default:
+		deliveriesTotal.WithLabelValues("dropped").Inc()
+		log.Printf("email queue full, dropped to=%s subject=%q", msg.To, msg.Subject)
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting messages and waits for the queued ones to be
+// delivered. When ctx expires first, deliveries in progress are cancelled
+// and the rest dead-lettered.
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) run() {
+	defer q.wg.Done()
+	for item := range q.queue {
+		q.deliver(item)
+	}
+}
+
+func (q *Queue) deliver(item queuedMessage) {
+	ctx, cancel := context.WithTimeout(item.ctx, deliveryTimeout)
+	defer cancel()
+	stop := context.AfterFunc(q.ctx, cancel)
+	defer stop()
+
+	if err := q.sender.Send(ctx, item.msg); err != nil {
+		deliveriesTotal.WithLabelValues("dead_letter").Inc()
+		log.Printf("email dead-lettered to=%s subject=%q err=%v", item.msg.To, item.msg.Subject, err)
+		return
+	}
+	deliveriesTotal.WithLabelValues("sent").Inc()
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// SESClient is the part of the SES v2 API SESSender uses; *sesv2.Client
+// implements it.
+type SESClient interface {
+	SendEmail(ctx context.Context, in *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error)
+}
+
+// SESSender sends mail through the Amazon SES API. From must be an
+// identity verified in SES.
+type SESSender struct {
+	Client SESClient
+	From   string
+}
+
+// NewSESSender returns a sender for SES in region, with credentials from
+// the default AWS chain: environment, shared config or the instance role.
+func NewSESSender(ctx context.Context, region, from string) (*SESSender, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, err
+	}
+	return &SESSender{Client: sesv2.NewFromConfig(cfg), From: from}, nil
+}
+
+func (s *SESSender) Send(ctx context.Context, msg Message) error {
+	content := &types.Message{
+		Subject: utf8Content(msg.Subject),
+		Body:    &types.Body{Html: utf8Content(msg.HTMLBody)},
+	}
+	if msg.TextBody != "" {
+		content.Body.Text = utf8Content(msg.TextBody)
+	}
+	for k, v := range traceHeaders(ctx) {
+		content.Headers = append(content.Headers, types.MessageHeader{Name: aws.String(k), Value: aws.String(v)})
+	}
+
+	_, err := s.Client.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(s.From),
+		Destination:      &types.Destination{ToAddresses: []string{msg.To}},
+		Content:          &types.EmailContent{Simple: content},
+	})
+	return err
+}
+
+func utf8Content(s string) *types.Content {
+	return &types.Content{Data: aws.String(s), Charset: aws.String("UTF-8")}
+}
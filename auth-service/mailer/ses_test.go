@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+)
+
+// fakeSESClient records the requests it gets instead of calling AWS.
+type fakeSESClient struct {
+	err error
+	in  []*sesv2.SendEmailInput
+}
+
+func (f *fakeSESClient) SendEmail(ctx context.Context, in *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error) {
+	f.in = append(f.in, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sesv2.SendEmailOutput{MessageId: aws.String("m-1")}, nil
+}
+
+func TestSESSender(t *testing.T) {
+	client := &fakeSESClient{}
+	s := &SESSender{Client: client, From: "no-reply@example.com"}
+	msg := Message{To: "a@example.com", Subject: "Reset your password", HTMLBody: "<p>Reset</p>", TextBody: "Reset"}
+	if err := s.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(client.in) != 1 {
+		t.Fatalf("%d requests, want 1", len(client.in))
+	}
+	in := client.in[0]
+	if got := aws.ToString(in.FromEmailAddress); got != "no-reply@example.com" {
+		t.Errorf("from %q", got)
+	}
+	if in.Destination == nil || len(in.Destination.ToAddresses) != 1 || in.Destination.ToAddresses[0] != "a@example.com" {
+		t.Errorf("destination %+v", in.Destination)
+	}
+	simple := in.Content.Simple
+	if simple == nil || in.Content.Raw != nil || in.Content.Template != nil {
+		t.Fatalf("content %+v, want a simple message", in.Content)
+	}
+	for name, c := range map[string]struct{ got, want string }{
+		"subject": {aws.ToString(simple.Subject.Data), msg.Subject},
+		"html":    {aws.ToString(simple.Body.Html.Data), msg.HTMLBody},
+		"text":    {aws.ToString(simple.Body.Text.Data), msg.TextBody},
+		"charset": {aws.ToString(simple.Body.Html.Charset), "UTF-8"},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s %q, want %q", name, c.got, c.want)
+		}
+	}
+}
+
+func TestSESSenderHTMLOnly(t *testing.T) {
+	client := &fakeSESClient{}
+	s := &SESSender{Client: client, From: "no-reply@example.com"}
+	if err := s.Send(context.Background(), Message{To: "a@example.com", Subject: "Hi", HTMLBody: "<p>Hi</p>"}); err != nil {
+		t.Fatal(err)
+	}
+	if body := client.in[0].Content.Simple.Body; body.Text != nil {
+		t.Errorf("an empty text part was sent: %q", aws.ToString(body.Text.Data))
+	}
+}
+
+func TestSESSenderError(t *testing.T) {
+	errThrottled := errors.New("throttled")
+	s := &SESSender{Client: &fakeSESClient{err: errThrottled}, From: "no-reply@example.com"}
+	if err := s.Send(context.Background(), Message{To: "a@example.com"}); !errors.Is(err, errThrottled) {
+		t.Errorf("got %v, want the client's error", err)
+	}
+}
@@ -0,0 +1,191 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer speaks just enough SMTP for net/smtp's client, over an
+// in-memory connection, and records what it was sent.
+type fakeSMTPServer struct {
+	rejectRcpt bool
+
+	auth string
+	from string
+	rcpt string
+	data []byte
+	done chan struct{}
+}
+
+// sender returns an SMTPSender that dials f. The host is localhost, the
+// one name net/smtp sends PLAIN credentials to without TLS.
+func (f *fakeSMTPServer) sender() *SMTPSender {
+	f.done = make(chan struct{})
+	return &SMTPSender{
+		Host:     "localhost",
+		Port:     587,
+		User:     "mailer",
+		Password: "secret",
+		From:     "no-reply@example.com",
+		Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go f.serve(server)
+			return client, nil
+		},
+	}
+}
+
+func (f *fakeSMTPServer) serve(conn net.Conn) {
+	defer close(f.done)
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch verb {
+		case "EHLO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			f.auth = arg
+			tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			f.from = arg
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			if f.rejectRcpt {
+				tp.PrintfLine("550 no such user")
+				continue
+			}
+			f.rcpt = arg
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			f.data, _ = tp.ReadDotBytes()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPSenderMultipart(t *testing.T) {
+	var f fakeSMTPServer
+	// Longer than SMTP's 998-byte line limit.
+	long := strings.Repeat("0123456789", 150)
+	msg := Message{
+		To:       "a@example.com",
+		Subject:  "Vérifiez votre adresse",
+		HTMLBody: `<p><a href="https://app.example.com/verify?token=x&amp;y=1">Verify</a></p>`,
+		TextBody: "Verify: https://app.example.com/verify?token=x&y=1\n" + long,
+	}
+	if err := f.sender().Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	<-f.done
+
+	wantAuth := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00mailer\x00secret"))
+	if f.auth != wantAuth {
+		t.Errorf("AUTH %q, want %q", f.auth, wantAuth)
+	}
+	if f.from != "FROM:<no-reply@example.com>" || f.rcpt != "TO:<a@example.com>" {
+		t.Errorf("envelope MAIL %q RCPT %q", f.from, f.rcpt)
+	}
+	for line := range strings.Lines(string(f.data)) {
+		if len(line) > 998 {
+			t.Fatalf("a %d-byte line", len(line))
+		}
+	}
+
+	m, err := mail.ReadMessage(bytes.NewReader(f.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Header.Get("From"); got != "no-reply@example.com" {
+		t.Errorf("From %q", got)
+	}
+	if got := m.Header.Get("To"); got != "a@example.com" {
+		t.Errorf("To %q", got)
+	}
+	if got, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject")); err != nil || got != msg.Subject {
+		t.Errorf("Subject %q, %v; want %q", got, err, msg.Subject)
+	}
+	if _, err := m.Header.Date(); err != nil {
+		t.Errorf("Date: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type %q, %v", m.Header.Get("Content-Type"), err)
+	}
+
+	// multipart.Reader undoes the quoted-printable encoding.
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", msg.TextBody},
+		{"text/html; charset=UTF-8", msg.HTMLBody},
+	} {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("part Content-Type %q, want %q", got, want.contentType)
+		}
+		if string(body) != want.body {
+			t.Errorf("%s part:\n%s\nwant:\n%s", want.contentType, body, want.body)
+		}
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("a third part: %v", err)
+	}
+}
+
+func TestSMTPSenderHTMLOnly(t *testing.T) {
+	var f fakeSMTPServer
+	msg := Message{To: "a@example.com", Subject: "Hi", HTMLBody: "<p>Hello</p>"}
+	if err := f.sender().Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	<-f.done
+
+	m, err := mail.ReadMessage(bytes.NewReader(f.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Header.Get("Content-Type"); got != "text/html; charset=UTF-8" {
+		t.Errorf("Content-Type %q", got)
+	}
+	// DATA ends with the line break net/smtp adds before the final dot.
+	if body, _ := io.ReadAll(m.Body); string(body) != msg.HTMLBody+"\n" {
+		t.Errorf("body %q", body)
+	}
+}
+
+func TestSMTPSenderRejected(t *testing.T) {
+	f := fakeSMTPServer{rejectRcpt: true}
+	err := f.sender().Send(context.Background(), Message{To: "nobody@example.com", HTMLBody: "x"})
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("got %v, want the server's 550", err)
+	}
+	<-f.done
+	if f.data != nil {
+		t.Error("the message was sent to a rejected recipient")
+	}
+}
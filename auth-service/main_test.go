@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/flags"
+	"resilient-auth-service/mailer"
+	"resilient-auth-service/tokens"
+)
+
+// testConfig is the development default, as a bare go run . would start
+// with; newTestServer hands each test its own copy.
+var testConfig Config
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	testConfig = loadConfig()
+	log.SetOutput(os.Stderr)
+	apperror.RequestID = requestIDFromContext
+	apperror.Localize = localizeError
+	os.Exit(m.Run())
+}
+
+// quietLog discards log output for the rest of the test.
+func quietLog(t testing.TB) {
+	t.Helper()
+	saved := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(saved) })
+}
+
+// newTestServer returns a Server with the test config, a fakeClock, an
+// in-process Redis, users in a MemoryUserStore and a database that is
+// down (see errDB); tests swap in a fake driver where they need other
+// rows. Audit events and emails are kept in
+// memory, passwords are hashed at bcrypt's minimum cost, and a fresh
+// signing key is loaded. Everything is stopped when the test ends.
+func newTestServer(t testing.TB) (*Server, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	s := &Server{
+		cfg:            testConfig,
+		db:             errDB(),
+		rdb:            rdb,
+		clock:          newFakeClock(),
+		users:          NewMemoryUserStore(),
+		accessKeys:     newTestKeyStore(t),
+		passwordHasher: NewBcryptWorkerPool(2, BcryptHasher{Cost: bcrypt.MinCost}, 100, time.Second),
+		emailSender:    &fakeEmailSender{},
+		claimsEnricher: NoOpClaimsEnricher{},
+		featureFlags:   flags.NewRedis(rdb, flags.NewStatic(testConfig.FeatureFlags), testConfig.FlagsCacheTTL),
+		passkeys:       NewMemoryPasskeyStore(),
+	}
+	webAuthn, err := newWebAuthn(testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.webAuthn = webAuthn
+	s.auditor = NewAuditor(s.db, rdb, 64)
+	s.webhooks = NewWebhookDispatcher(s.db, 64)
+	limiter := NewTokenBucketLimiter(s.clock, s.cfg.RateLimit, s.cfg.RateLimitWindow, time.Minute)
+	s.rateLimiter = NewFallbackRateLimiter(NewRedisRateLimiter(rdb, s.cfg.RateLimit, s.cfg.RateLimitWindow), limiter)
+	s.loginHistoryLimiter = NewFallbackRateLimiter(NewRedisRateLimiter(rdb, loginHistoryRateLimit, time.Minute), limiter)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.auditor.Close(ctx)
+		s.webhooks.Close(ctx)
+		s.passwordHasher.Close()
+		limiter.Close()
+	})
+	return s, mr
+}
+
+// fakeClock is a Clock that stands still until Advance moves it. It
+// starts at the real time, so times it gives out look current to Redis.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock { return &fakeClock{now: time.Now()} }
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// fakeEmailSender keeps the messages it is asked to send.
+type fakeEmailSender struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (f *fakeEmailSender) Send(ctx context.Context, msg mailer.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+// messages returns what has been sent so far.
+func (f *fakeEmailSender) messages() []mailer.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.sent)
+}
+
+// newTestUser creates a user with the password "correct horse" and the
+// given role, "user" or "admin".
+func newTestUser(t testing.TB, s *Server, email, role string) User {
+	t.Helper()
+	ctx := context.Background()
+	hash, err := s.passwordHasher.HashPassword(ctx, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.users.Create(ctx, email, string(hash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role != user.Role {
+		updateTestUser(s, user.ID, func(u *User) { u.Role = role })
+		user.Role = role
+	}
+	return user
+}
+
+// updateTestUser changes fields of a user in s's MemoryUserStore that
+// UserStore has no method for, such as the role.
+func updateTestUser(s *Server, id int, update func(*User)) {
+	store := s.users.(*MemoryUserStore)
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	update(&store.users[id].User)
+}
+
+// accessTokenCookie is the auth_token cookie a login by user would set.
+func accessTokenCookie(t testing.TB, s *Server, user User) *http.Cookie {
+	t.Helper()
+	token, err := s.signAccessToken(context.Background(), user.ID, user.Email, s.clock.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Cookie{Name: "auth_token", Value: token}
+}
+
+var errDBDown = errors.New("test database is down")
+
+type downConnector struct{}
+
+func (downConnector) Connect(context.Context) (driver.Conn, error) { return nil, errDBDown }
+func (downConnector) Driver() driver.Driver                        { return nil }
+
+// errDB returns a database whose every query fails with errDBDown, for
+// code that should never reach the database or should survive it being
+// down.
+func errDB() *sql.DB { return sql.OpenDB(downConnector{}) }
+
+// fakeRows are canned result rows for a fake driver's queries.
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// staticRowsDB answers every query with the same rows.
+type staticRowsDB struct{ rows fakeRows }
+
+func (d *staticRowsDB) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *staticRowsDB) Driver() driver.Driver                        { return nil }
+func (d *staticRowsDB) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (d *staticRowsDB) Close() error                                 { return nil }
+func (d *staticRowsDB) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (d *staticRowsDB) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	rows := d.rows
+	return &rows, nil
+}
+
+// queryRows returns *sql.Rows holding rows under cols, for code that
+// consumes a result set.
+func queryRows(t testing.TB, cols []string, rows ...[]driver.Value) *sql.Rows {
+	t.Helper()
+	conn := sql.OpenDB(&staticRowsDB{fakeRows{cols: cols, rows: rows}})
+	t.Cleanup(func() { conn.Close() })
+	r, err := conn.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// newTestKeyStore returns an access-token KeyStore holding one fresh key.
+// Its database is down, so a reload keeps the cached set.
+func newTestKeyStore(t testing.TB) *KeyStore {
+	t.Helper()
+	k, err := tokens.GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.Active = true
+	set, err := tokens.NewKeySet([]tokens.SigningKey{k})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &KeyStore{db: errDB(), set: set, loadedAt: time.Now()}
+}
+
+// testEnvelope is the JSON error envelope as a client sees it.
+type testEnvelope struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Fields  []struct {
+			Field   string `json:"field"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"fields"`
+	} `json:"error"`
+	RequestID string `json:"request_id"`
+}
+
+// readEnvelope checks rec holds a well-formed error envelope with the
+// given status and returns it.
+func readEnvelope(t testing.TB, rec *httptest.ResponseRecorder, status int) testEnvelope {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status %d, want %d; body %s", rec.Code, status, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type %q, want application/json", ct)
+	}
+	var env testEnvelope
+	dec := json.NewDecoder(rec.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if env.Error.Code == "" || env.Error.Message == "" {
+		t.Fatalf("envelope without code or message: %+v", env)
+	}
+	return env
+}
+
+// TestHTTPServerSlowHeaders is a slowloris client: it sends its headers a
+// byte at a time and never finishes. The server must hang up once
+// ReadHeaderTimeout has passed, however steadily the bytes arrive.
+func TestHTTPServerSlowHeaders(t *testing.T) {
+	cfg := testConfig
+	cfg.HTTPReadHeaderTimeout = 300 * time.Millisecond
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newHTTPServer(cfg, ln.Addr().String(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.ErrorLog = log.New(io.Discard, "", 0)
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+
+	// A complete request is answered.
+	resp, err := http.Get("http://" + ln.Addr().String() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		header := "GET / HTTP/1.1\r\nHost: localhost\r\nX-Slow: " + strings.Repeat("a", 1000)
+		for i := 0; i < len(header); i++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+			if _, err := conn.Write([]byte{header[i]}); err != nil {
+				return
+			}
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b, err := io.ReadAll(conn)
+	elapsed := time.Since(start)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		t.Fatalf("the connection was still open after %s", elapsed)
+	}
+	if len(b) > 0 && !strings.HasPrefix(string(b), "HTTP/1.1 408") {
+		t.Errorf("got %q, want the connection closed or a 408", b)
+	}
+	if elapsed < cfg.HTTPReadHeaderTimeout || elapsed > cfg.HTTPReadHeaderTimeout+2*time.Second {
+		t.Errorf("closed after %s, want soon after %s", elapsed, cfg.HTTPReadHeaderTimeout)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+
+	"resilient-auth-service/apperror"
+)
+
+const (
+	maintenanceKey        = "maintenance"
+	maintenanceCacheTTL   = time.Second
+	maintenanceRetryAfter = time.Minute
+)
+
+// maintenanceState is stored as JSON under maintenanceKey. The key's TTL,
+// if any, is the auto-expiry; Until mirrors it for Retry-After.
+type maintenanceState struct {
+	Enabled bool      `json:"enabled"`
+	Message string    `json:"message,omitempty"`
+	Until   time.Time `json:"until,omitzero"`
+}
+
+// maintenanceCache is the copy of maintenanceState each replica serves
+// from between reads.
+type maintenanceCache struct {
+	mu        sync.Mutex
+	state     maintenanceState
+	fetchedAt time.Time
+	fetch     singleflight.Group
+}
+
+// currentMaintenance returns the maintenance state, read from Redis at most
+// once per maintenanceCacheTTL. The lock only guards the cached copy; a
+// stale read waits on a single shared fetch, so a slow Redis holds up only
+// the requests that need fresh state, not every one. If Redis can't be read
+// the last known state is kept.
+func (s *Server) currentMaintenance(r *http.Request) maintenanceState {
+	c := &s.maintenance
+	c.mu.Lock()
+	st, fresh := c.state, time.Since(c.fetchedAt) < maintenanceCacheTTL
+	c.mu.Unlock()
+	if fresh {
+		return st
+	}
+
+	// The fetch is shared, so one caller going away mustn't cancel it for
+	// the rest.
+	ctx := context.WithoutCancel(r.Context())
+	v, _, _ := c.fetch.Do(maintenanceKey, func() (any, error) {
+		return s.fetchMaintenance(ctx), nil
+	})
+	return v.(maintenanceState)
+}
+
+// fetchMaintenance reads the state from Redis into maintenanceCache and
+// returns the cached state.
+func (s *Server) fetchMaintenance(ctx context.Context) maintenanceState {
+	started := time.Now()
+	data, err := s.rdb.Get(ctx, maintenanceKey).Bytes()
+
+	c := &s.maintenance
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// An admin update landed while we were reading; it's newer than what
+	// we got.
+	if c.fetchedAt.After(started) {
+		return c.state
+	}
+	c.fetchedAt = started
+
+	switch {
+	case err == redis.Nil:
+		c.state = maintenanceState{}
+	case err != nil:
+		log.Println("maintenance flag read error:", err)
+	default:
+		var st maintenanceState
+		if err := json.Unmarshal(data, &st); err != nil {
+			log.Println("maintenance flag decode error:", err)
+			break
+		}
+		c.state = st
+	}
+	return c.state
+}
+
+// maintenanceExempt lists what keeps working during maintenance: reads,
+// logging out or revoking a session, refreshing an existing session, and
+// the admin endpoints (including the one that turns maintenance off).
+func maintenanceExempt(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	path := unversionedPath(r.URL.Path)
+	return path == "/logout" || path == "/refresh" || path == "/sessions/revoke" ||
+		strings.HasPrefix(path, "/admin/")
+}
+
+// maintenanceMiddleware rejects mutating requests with 503 while
+// maintenance mode is on, so registrations and logins stop but existing
+// sessions stay usable.
+func (s *Server) maintenanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maintenanceExempt(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		st := s.currentMaintenance(r)
+		if !st.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := maintenanceRetryAfter
+		if !st.Until.IsZero() {
+			retryAfter = max(time.Until(st.Until), time.Second)
+		}
+		// An operator's own message goes out as written; only the default
+		// is translated.
+		e := apperror.Unavailable("maintenance", "The service is undergoing maintenance; please try again later.")
+		if st.Message != "" {
+			e.Message, e.Verbatim = st.Message, true
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		apperror.WriteError(w, r, e)
+	})
+}
+
+// adminGetMaintenanceHandler serves GET /admin/maintenance.
+func (s *Server) adminGetMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.currentMaintenance(r))
+}
+
+type maintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+	// ExpiresIn turns maintenance off automatically after this many
+	// seconds; 0 means until turned off.
+	ExpiresIn int `json:"expires_in"`
+}
+
+// maxMaintenanceMessage keeps the banner message to something a client can
+// reasonably display.
+const maxMaintenanceMessage = 500
+
+func (req maintenanceRequest) validate() error {
+	var v validator
+	v.length("message", req.Message, 0, maxMaintenanceMessage)
+	if req.ExpiresIn < 0 {
+		v.add("expires_in", "out_of_range", "must not be negative")
+	}
+	return v.err()
+}
+
+// adminSetMaintenanceHandler serves PUT /admin/maintenance.
+func (s *Server) adminSetMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	st := maintenanceState{Enabled: req.Enabled, Message: req.Message}
+	var err error
+	if !req.Enabled {
+		err = s.rdb.Del(r.Context(), maintenanceKey).Err()
+	} else {
+		ttl := time.Duration(req.ExpiresIn) * time.Second
+		if ttl > 0 {
+			st.Until = time.Now().Add(ttl).UTC()
+		}
+		data, _ := json.Marshal(st)
+		err = s.rdb.Set(r.Context(), maintenanceKey, data, ttl).Err()
+	}
+	if err != nil {
+		log.Println("maintenance flag write error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Maintenance flag unavailable"))
+		return
+	}
+
+	// Apply it on this replica at once; the others follow within a second.
+	s.maintenance.mu.Lock()
+	s.maintenance.state, s.maintenance.fetchedAt = st, time.Now()
+	s.maintenance.mu.Unlock()
+
+	ev := s.auditEventFromRequest(r, "admin.maintenance_update")
+	ev.Level = "warning"
+	ev.Metadata = map[string]any{"enabled": st.Enabled, "message": st.Message, "expires_in": req.ExpiresIn}
+	s.auditor.Record(r.Context(), ev)
+
+	writeJSON(w, http.StatusOK, st)
+}
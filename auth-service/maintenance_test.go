@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCurrentMaintenance(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if st := s.currentMaintenance(req); st.Enabled {
+		t.Fatalf("unset key: got %+v, want disabled", st)
+	}
+
+	mr.Set(maintenanceKey, `{"enabled":true,"message":"upgrading"}`)
+	if st := s.currentMaintenance(req); st.Enabled {
+		t.Error("the cached state should hold for maintenanceCacheTTL")
+	}
+	s.maintenance.fetchedAt = time.Time{}
+	if st := s.currentMaintenance(req); !st.Enabled || st.Message != "upgrading" {
+		t.Fatalf("got %+v, want enabled with the message", st)
+	}
+
+	mr.Close()
+	s.maintenance.fetchedAt = time.Time{}
+	if st := s.currentMaintenance(req); !st.Enabled {
+		t.Error("with Redis down, the last known state should be kept")
+	}
+}
+
+// TestCurrentMaintenanceSlowRedis checks a hung Redis read doesn't hold
+// the cache lock, and that an admin update made meanwhile isn't
+// overwritten when the read finally returns.
+func TestCurrentMaintenanceSlowRedis(t *testing.T) {
+	quietLog(t)
+
+	// A Redis that accepts connections and never answers.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	s := &Server{rdb: redis.NewClient(&redis.Options{Addr: ln.Addr().String(), MaxRetries: -1, ReadTimeout: time.Minute})}
+	t.Cleanup(func() { s.rdb.Close() })
+
+	done := make(chan maintenanceState)
+	go func() { done <- s.currentMaintenance(httptest.NewRequest(http.MethodGet, "/", nil)) }()
+
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fetch never reached Redis")
+	}
+
+	c := &s.maintenance
+	if !c.mu.TryLock() {
+		t.Fatal("the cache lock is held while waiting on Redis")
+	}
+	c.state, c.fetchedAt = maintenanceState{Enabled: true, Message: "admin"}, time.Now()
+	c.mu.Unlock()
+
+	conn.Close()
+	select {
+	case st := <-done:
+		if !st.Enabled || st.Message != "admin" {
+			t.Errorf("got %+v, want the admin update", st)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fetch didn't return after Redis hung up")
+	}
+}
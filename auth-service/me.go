@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+// meResponse is the v2 body of GET /me. Roles is a list for the same reason
+// as the roles claim (see RoleClaimsEnricher).
+type meResponse struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	Roles     []string  `json:"roles"`
+}
+
+// meHandler serves GET /me. v1 answers with a plain-text greeting; v2 with
+// the user as JSON.
+func (s *Server) meHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Identity comes from middleware, not cookies
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+
+	if apiVersionFromContext(r.Context()) < 2 {
+		w.Write([]byte("Hello " + user.Email))
+		return
+	}
+
+	u, err := s.users.GetByID(r.Context(), user.ID)
+	if errors.Is(err, ErrNotFound) {
+		// Deleted since the token was issued.
+		apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid or expired token"))
+		return
+	}
+	if err != nil {
+		log.Println("me lookup error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, meResponse{ID: user.ID, Email: user.Email, CreatedAt: u.CreatedAt, Roles: []string{u.Role}})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryUserStore is a UserStore in a map, for tests. It keeps the same
+// rules as PostgresUserStore, which the conformance suite in
+// store_test.go holds both to, but has no row-level security and writes
+// no outbox events.
+type MemoryUserStore struct {
+	mu     sync.Mutex
+	users  map[int]*memoryUser
+	nextID int
+}
+
+type memoryUser struct {
+	User
+	deleted bool
+}
+
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{users: map[int]*memoryUser{}}
+}
+
+func (m *MemoryUserStore) Create(ctx context.Context, email, passwordHash string) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if u.Email == email {
+			return User{}, ErrDuplicateEmail
+		}
+	}
+	m.nextID++
+	u := &memoryUser{User: User{
+		ID:           m.nextID,
+		Email:        email,
+		PasswordHash: passwordHash,
+		Role:         "user",
+		CreatedAt:    time.Now().UTC(),
+	}}
+	m.users[u.ID] = u
+	return u.User, nil
+}
+
+func (m *MemoryUserStore) GetByEmail(ctx context.Context, email string) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if u.Email == email && !u.deleted {
+			return u.User, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (m *MemoryUserStore) GetByID(ctx context.Context, id int) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok || u.deleted {
+		return User{}, ErrNotFound
+	}
+	return u.User, nil
+}
+
+func (m *MemoryUserStore) UpdatePassword(ctx context.Context, id int, oldHash, newHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok || u.deleted || u.PasswordHash != oldHash {
+		return ErrNotFound
+	}
+	u.PasswordHash = newHash
+	return nil
+}
+
+func (m *MemoryUserStore) UpdateEmail(ctx context.Context, id int, email string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok || u.deleted {
+		return ErrNotFound
+	}
+	for _, other := range m.users {
+		if other.Email == email && other != u {
+			return ErrDuplicateEmail
+		}
+	}
+	u.Email = email
+	return nil
+}
+
+func (m *MemoryUserStore) SetStatus(ctx context.Context, id int, status UserStatus) error {
+	if status != UserActive && status != UserDeleted {
+		return fmt.Errorf("unknown user status %q", status)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	u.deleted = status == UserDeleted
+	return nil
+}
+
+func (m *MemoryUserStore) List(ctx context.Context, after *UserKey, limit int) ([]User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var users []User
+	for _, u := range m.users {
+		if u.deleted || after != nil && !userKeyLess(*after, userKey(u.User)) {
+			continue
+		}
+		users = append(users, u.User)
+	}
+	slices.SortFunc(users, func(a, b User) int {
+		switch {
+		case userKeyLess(userKey(a), userKey(b)):
+			return -1
+		case userKeyLess(userKey(b), userKey(a)):
+			return 1
+		}
+		return 0
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func userKey(u User) UserKey { return UserKey{CreatedAt: u.CreatedAt, ID: u.ID} }
+
+func userKeyLess(a, b UserKey) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
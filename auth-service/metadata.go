@@ -0,0 +1,197 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+const maxMetadataBytes = 4096
+
+var errMetadataConflict = errors.New("metadata modified concurrently")
+
+// mergePatch applies an RFC 7396 JSON Merge Patch to target in place: null
+// removes a key, objects merge recursively, anything else replaces.
+func mergePatch(target, patch map[string]any) map[string]any {
+	if target == nil {
+		target = map[string]any{}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+		if vm, ok := v.(map[string]any); ok {
+			tm, _ := target[k].(map[string]any)
+			target[k] = mergePatch(tm, vm)
+			continue
+		}
+		target[k] = v
+	}
+	return target
+}
+
+type metadataResponse struct {
+	Metadata        map[string]any `json:"metadata"`
+	MetadataVersion int            `json:"metadata_version"`
+}
+
+// meMetadataHandler serves PUT /me. The body is a JSON Merge Patch applied to
+// the user's metadata. Updates use optimistic locking on metadata_version: a
+// client may pin the version it read with If-Match, and a concurrent write
+// between our read and update is reported as 409 rather than lost.
+func (s *Server) meMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok || user.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+	userID := user.ID
+
+	var patch map[string]any
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 2*maxMetadataBytes)).Decode(&patch); err != nil || patch == nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_request", "Request body must be a JSON object"))
+		return
+	}
+
+	expectedVersion := -1
+	if v := r.Header.Get("If-Match"); v != "" {
+		n, err := strconv.Atoi(strings.Trim(v, `"`))
+		if err != nil {
+			apperror.WriteError(w, r, apperror.BadRequest("invalid_parameter", "Invalid If-Match"))
+			return
+		}
+		expectedVersion = n
+	}
+
+	var resp metadataResponse
+	tooLarge := false
+
+	err := s.withUserScope(r.Context(), userID, func(tx *sql.Tx) error {
+		var raw []byte
+		err := tx.QueryRowContext(r.Context(),
+			"SELECT metadata, metadata_version FROM active_users WHERE id = $1",
+			userID,
+		).Scan(&raw, &resp.MetadataVersion)
+		if err != nil {
+			return err
+		}
+		if expectedVersion >= 0 && expectedVersion != resp.MetadataVersion {
+			return errMetadataConflict
+		}
+
+		var current map[string]any
+		if err := json.Unmarshal(raw, &current); err != nil {
+			return err
+		}
+		resp.Metadata = mergePatch(current, patch)
+
+		merged, err := json.Marshal(resp.Metadata)
+		if err != nil {
+			return err
+		}
+		if len(merged) > maxMetadataBytes {
+			tooLarge = true
+			return nil
+		}
+
+		res, err := tx.ExecContext(r.Context(),
+			`UPDATE active_users SET metadata = $1, metadata_version = metadata_version + 1
+			 WHERE id = $2 AND metadata_version = $3`,
+			merged, userID, resp.MetadataVersion,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errMetadataConflict
+		}
+		resp.MetadataVersion++
+		return writeOutbox(r.Context(), tx, "user:"+strconv.Itoa(userID), "user.metadata_updated",
+			map[string]any{"user_id": userID, "metadata_version": resp.MetadataVersion})
+	})
+
+	switch {
+	case tooLarge:
+		apperror.WriteError(w, r, apperror.Unprocessable("metadata_too_large", "Metadata exceeds 4 KB"))
+		return
+	case errors.Is(err, errMetadataConflict):
+		apperror.WriteError(w, r, apperror.Conflict("version_conflict", "Metadata was modified concurrently; re-read and retry"))
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		apperror.WriteError(w, r, apperror.NotFound("user_not_found", "User not found"))
+		return
+	case err != nil:
+		log.Println("metadata update error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "user.metadata_update")
+	ev.Target = "user:" + strconv.Itoa(userID)
+	s.auditor.Record(r.Context(), ev)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(resp.MetadataVersion)))
+	json.NewEncoder(w).Encode(resp)
+}
+
+type adminUserDetail struct {
+	adminUser
+	Metadata        map[string]any `json:"metadata,omitempty"`
+	MetadataVersion *int           `json:"metadata_version,omitempty"`
+}
+
+// adminUserHandler serves GET /admin/users/{id}[?include=metadata].
+func (s *Server) adminUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid user id"))
+		return
+	}
+	includeMetadata := r.URL.Query().Get("include") == "metadata"
+
+	var (
+		u       adminUserDetail
+		raw     []byte
+		version int
+	)
+	err = s.withAdminScope(r.Context(), func(tx *sql.Tx) error {
+		return tx.QueryRowContext(r.Context(),
+			"SELECT id, email, role, created_at, metadata, metadata_version FROM active_users WHERE id = $1",
+			id,
+		).Scan(&u.ID, &u.Email, &u.Role, &u.CreatedAt, &raw, &version)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, apperror.NotFound("user_not_found", "User not found"))
+		return
+	}
+	if err != nil {
+		log.Println("admin get user error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "admin.user_view")
+	ev.Target = "user:" + strconv.Itoa(id)
+	s.auditor.Record(r.Context(), ev)
+
+	if includeMetadata {
+		if err := json.Unmarshal(raw, &u.Metadata); err != nil {
+			log.Println("admin get user metadata error:", err)
+			writeDBError(w, r, err)
+			return
+		}
+		u.MetadataVersion = &version
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(u)
+}
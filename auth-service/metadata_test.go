@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeMetadataDB is a database/sql driver holding one user's metadata
+// column. It understands the statements meMetadataHandler runs inside a
+// user scope; beforeUpdate, when set, runs between the read and the write,
+// where a concurrent request would land.
+type fakeMetadataDB struct {
+	metadata     []byte
+	version      int64
+	beforeUpdate func(*fakeMetadataDB)
+}
+
+func (f *fakeMetadataDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeMetadataDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeMetadataDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeMetadataDB: prepared statements not supported")
+}
+func (f *fakeMetadataDB) Close() error              { return nil }
+func (f *fakeMetadataDB) Begin() (driver.Tx, error) { return f, nil }
+func (f *fakeMetadataDB) Commit() error             { return nil }
+func (f *fakeMetadataDB) Rollback() error           { return nil }
+
+func (f *fakeMetadataDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "SET LOCAL ROLE"), strings.HasPrefix(query, "SELECT set_config"),
+		strings.HasPrefix(query, "INSERT INTO outbox_events"):
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "UPDATE active_users SET metadata = $1"):
+		if f.beforeUpdate != nil {
+			f.beforeUpdate(f)
+			f.beforeUpdate = nil
+		}
+		if args[2].Value.(int64) != f.version {
+			return driver.RowsAffected(0), nil
+		}
+		f.metadata = args[0].Value.([]byte)
+		f.version++
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("fakeMetadataDB: unexpected exec %q", query)
+}
+
+func (f *fakeMetadataDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if query != "SELECT metadata, metadata_version FROM active_users WHERE id = $1" {
+		return nil, fmt.Errorf("fakeMetadataDB: unexpected query %q", query)
+	}
+	return &fakeRows{
+		cols: []string{"metadata", "metadata_version"},
+		rows: [][]driver.Value{{f.metadata, f.version}},
+	}, nil
+}
+
+func TestMeMetadata(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	fake := &fakeMetadataDB{metadata: []byte(`{"theme":"dark","lang":"en"}`), version: 3}
+	s.db = sql.OpenDB(fake)
+	s.db.SetMaxOpenConns(1)
+	t.Cleanup(func() { s.db.Close() })
+	h := s.Handler()
+	cookie := accessTokenCookie(t, s, newTestUser(t, s, "a@example.com", "user"))
+
+	put := func(body, ifMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPut, "/v1/me", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		r.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	stored := func() string { return string(fake.metadata) }
+
+	for _, tt := range []struct {
+		name, patch, want string
+		version           int
+	}{
+		{"add a field", `{"tz":"UTC"}`, `{"lang":"en","theme":"dark","tz":"UTC"}`, 4},
+		{"update a field", `{"theme":"light"}`, `{"lang":"en","theme":"light","tz":"UTC"}`, 5},
+		{"remove a field", `{"lang":null}`, `{"theme":"light","tz":"UTC"}`, 6},
+		{"merge an object", `{"ui":{"compact":true}}`, `{"theme":"light","tz":"UTC","ui":{"compact":true}}`, 7},
+	} {
+		rec := put(tt.patch, "")
+		var resp metadataResponse
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil {
+			t.Fatalf("%s: %d %s", tt.name, rec.Code, rec.Body)
+		}
+		if stored() != tt.want || resp.MetadataVersion != tt.version {
+			t.Errorf("%s: stored %s at version %d, want %s at %d", tt.name, stored(), resp.MetadataVersion, tt.want, tt.version)
+		}
+		if etag := rec.Header().Get("ETag"); etag != fmt.Sprintf(`"%d"`, tt.version) {
+			t.Errorf("%s: ETag %s", tt.name, etag)
+		}
+	}
+
+	before := stored()
+	big := fmt.Sprintf(`{"notes":%q}`, strings.Repeat("x", maxMetadataBytes))
+	if env := readEnvelope(t, put(big, ""), http.StatusUnprocessableEntity); env.Error.Code != "metadata_too_large" {
+		t.Errorf("over 4 KB: %q, want metadata_too_large", env.Error.Code)
+	}
+	if stored() != before {
+		t.Errorf("metadata over 4 KB was stored: %s", stored())
+	}
+
+	// A client pinning a stale version is refused before anything is written.
+	if env := readEnvelope(t, put(`{"tz":"CET"}`, `"6"`), http.StatusConflict); env.Error.Code != "version_conflict" {
+		t.Errorf("stale If-Match: %q, want version_conflict", env.Error.Code)
+	}
+
+	// Another request writes between this one's read and its update.
+	fake.beforeUpdate = func(f *fakeMetadataDB) {
+		f.metadata, f.version = []byte(`{"theme":"blue"}`), f.version+1
+	}
+	if env := readEnvelope(t, put(`{"tz":"CET"}`, ""), http.StatusConflict); env.Error.Code != "version_conflict" {
+		t.Errorf("concurrent update: %q, want version_conflict", env.Error.Code)
+	}
+	if stored() != `{"theme":"blue"}` || fake.version != 8 {
+		t.Errorf("concurrent update overwrote the other write: %s at version %d", stored(), fake.version)
+	}
+}
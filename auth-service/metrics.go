@@ -0,0 +1,60 @@
+package main
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var dbRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "db_query_retries_total",
+	Help: "Retries of idempotent database queries after a transient error.",
+}, []string{"query"})
+
+var panicsTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "http_handler_panics_total",
+	Help: "Panics recovered from HTTP handlers.",
+})
+
+var buildInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "build_info",
+	Help: "Always 1; labels identify the running build.",
+}, []string{"version", "git_sha", "go_version"})
+
+var sessionLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "session_lookups_total",
+	Help: "Session cookie lookups by result: hit, miss (no such session) or error (Redis failed).",
+}, []string{"result"})
+
+var sessionStoreDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "session_store_duration_seconds",
+	Help:    "Latency of Redis session operations: create, lookup, delete or revoke_user.",
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+}, []string{"op"})
+
+// observeSessionStore is deferred with the operation's start time.
+func observeSessionStore(op string, start time.Time) {
+	sessionStoreDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+}
+
+var dbErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "db_query_errors_total",
+	Help: "Database failures (not missing rows) that failed a request, after any retries.",
+}, []string{"query"})
+
+var loginFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "login_failures_total",
+	Help: "Failed login attempts by reason, as in the login.failure audit event.",
+}, []string{"reason"})
+
+var rateLimitedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "rate_limited_requests_total",
+	Help: "Requests refused with 429 by the per-IP rate limit.",
+})
+
+var httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_request_duration_seconds",
+	Help:    "Latency of client API requests by route pattern, e.g. \"POST /v1/login\".",
+	Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"route"})
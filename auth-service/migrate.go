@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// migrations are applied in order and never edited once released; add a new
+// entry instead. The first two use IF NOT EXISTS because they predate the
+// migrator and may already exist in deployed databases.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create_users",
+		sql: `
+		CREATE TABLE IF NOT EXISTS users (
+			id SERIAL PRIMARY KEY,
+			email TEXT UNIQUE NOT NULL,
+			password_hash TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);`,
+	},
+	{
+		version: 2,
+		name:    "create_refresh_tokens",
+		// Refresh tokens are grouped into families: every rotation keeps the
+		// family_id so a replayed token can revoke the whole chain at once.
+		sql: `
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id SERIAL PRIMARY KEY,
+			user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash TEXT UNIQUE NOT NULL,
+			family_id UUID NOT NULL,
+			revoked BOOLEAN NOT NULL DEFAULT false,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family_id);`,
+	},
+	{
+		version: 3,
+		name:    "users_row_level_security",
+		// See "Security model" in the README. authdb_app only ever sees the
+		// row whose id matches app.user_id; authdb_admin bypasses RLS.
+		sql: `
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authdb_app') THEN
+				CREATE ROLE authdb_app NOLOGIN;
+			END IF;
+			IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authdb_admin') THEN
+				CREATE ROLE authdb_admin NOLOGIN BYPASSRLS;
+			END IF;
+		END
+		$$;
+
+		GRANT SELECT, INSERT, UPDATE ON users, refresh_tokens TO authdb_app;
+		GRANT USAGE ON SEQUENCE users_id_seq, refresh_tokens_id_seq TO authdb_app;
+		GRANT SELECT, INSERT, UPDATE, DELETE ON users, refresh_tokens TO authdb_admin;
+		GRANT USAGE ON SEQUENCE users_id_seq, refresh_tokens_id_seq TO authdb_admin;
+		GRANT authdb_app, authdb_admin TO CURRENT_USER;
+
+		ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+
+		CREATE POLICY users_self ON users
+			FOR ALL TO authdb_app
+			USING (id = NULLIF(current_setting('app.user_id', true), '')::int)
+			WITH CHECK (id = NULLIF(current_setting('app.user_id', true), '')::int);`,
+	},
+	{
+		version: 4,
+		name:    "users_role_and_keyset_index",
+		sql: `
+		ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';
+		CREATE INDEX users_created_at_id_idx ON users (created_at, id);`,
+	},
+	{
+		version: 5,
+		name:    "users_metadata",
+		sql: `
+		ALTER TABLE users
+			ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}',
+			ADD COLUMN metadata_version INT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 6,
+		name:    "create_audit_events",
+		// actor_id is deliberately not a foreign key: audit rows must
+		// outlive the users they describe.
+		sql: `
+		CREATE TABLE audit_events (
+			id BIGSERIAL PRIMARY KEY,
+			level TEXT NOT NULL DEFAULT 'info',
+			actor_id INT,
+			action TEXT NOT NULL,
+			target TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			request_id TEXT NOT NULL DEFAULT '',
+			metadata JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+		CREATE INDEX audit_events_actor_idx ON audit_events (actor_id, created_at);
+		CREATE INDEX audit_events_action_idx ON audit_events (action, created_at);`,
+	},
+	{
+		version: 7,
+		name:    "create_webhooks",
+		// An empty events array subscribes to every event type.
+		sql: `
+		CREATE TABLE webhooks (
+			id SERIAL PRIMARY KEY,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			events TEXT[] NOT NULL DEFAULT '{}',
+			active BOOLEAN NOT NULL DEFAULT true,
+			failing BOOLEAN NOT NULL DEFAULT false,
+			consecutive_failures INT NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE webhook_deliveries (
+			id BIGSERIAL PRIMARY KEY,
+			webhook_id INT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+			event_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			payload JSONB NOT NULL,
+			attempt INT NOT NULL,
+			status_code INT,
+			success BOOLEAN NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id);`,
+	},
+	{
+		version: 8,
+		name:    "create_outbox_events",
+		// Rows are written in the same transaction as the mutation they
+		// describe. aggregate_id is the partition key ("user:42"); id order
+		// within an aggregate is publish order.
+		sql: `
+		CREATE TABLE outbox_events (
+			id BIGSERIAL PRIMARY KEY,
+			aggregate_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			payload JSONB NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			sent_at TIMESTAMP
+		);
+		CREATE INDEX outbox_events_pending_idx ON outbox_events (aggregate_id, id) WHERE sent_at IS NULL;
+		CREATE INDEX outbox_events_sent_at_idx ON outbox_events (sent_at) WHERE sent_at IS NOT NULL;
+		GRANT INSERT ON outbox_events TO authdb_app, authdb_admin;
+		GRANT USAGE ON SEQUENCE outbox_events_id_seq TO authdb_app, authdb_admin;`,
+	},
+	{
+		version: 9,
+		name:    "users_totp_secret",
+		// Base32 TOTP secret; NULL means two-factor login is off.
+		sql: `ALTER TABLE users ADD COLUMN totp_secret TEXT;`,
+	},
+	{
+		version: 10,
+		name:    "users_soft_delete",
+		// The application reads and updates users only through
+		// active_users, so a deleted row is invisible everywhere without
+		// each query having to remember the filter. security_invoker keeps
+		// the users_self policy applying to authdb_app. The view's column
+		// list is fixed when it is created: a migration that adds a column
+		// to users must recreate it. Deleted users keep their email
+		// reserved, since users_email_key still covers them.
+		sql: `
+		ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+		CREATE VIEW active_users WITH (security_invoker = true) AS
+			SELECT * FROM users WHERE deleted_at IS NULL;
+		GRANT SELECT, UPDATE ON active_users TO authdb_app, authdb_admin;`,
+	},
+	{
+		version: 11,
+		name:    "users_not_blank",
+		// NOT VALID applies the checks to new and updated rows only, so the
+		// migration can't fail on rows from before validation existed;
+		// checkUserRows reports those at startup. Once they're fixed, run
+		// ALTER TABLE users VALIDATE CONSTRAINT for both.
+		sql: `
+		ALTER TABLE users
+			ADD CONSTRAINT users_email_not_blank CHECK (btrim(email) <> '') NOT VALID,
+			ADD CONSTRAINT users_password_hash_not_blank CHECK (password_hash <> '') NOT VALID;`,
+	},
+	{
+		version: 12,
+		name:    "create_signing_keys",
+		// Access-token signing keys, shared so every replica signs and
+		// verifies with the same set. private_key is PKCS#8 PEM; nothing is
+		// granted to authdb_app or authdb_admin, so request-scoped queries
+		// can't read it. The partial index allows one active key at a time.
+		sql: `
+		CREATE TABLE signing_keys (
+			kid TEXT PRIMARY KEY,
+			private_key TEXT NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			retired_at TIMESTAMP
+		);
+		CREATE UNIQUE INDEX signing_keys_one_active_idx ON signing_keys ((true)) WHERE active;`,
+	},
+	{
+		version: 13,
+		name:    "create_totp_backup_codes",
+		// bcrypt hashes of the one-time codes that stand in for a lost TOTP
+		// device. Like users, rows are limited to the app.user_id user for
+		// authdb_app.
+		sql: `
+		CREATE TABLE totp_backup_codes (
+			id SERIAL PRIMARY KEY,
+			user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			code_hash TEXT NOT NULL,
+			used_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX totp_backup_codes_user_idx ON totp_backup_codes (user_id);
+		GRANT SELECT, INSERT, UPDATE, DELETE ON totp_backup_codes TO authdb_app, authdb_admin;
+		GRANT USAGE ON SEQUENCE totp_backup_codes_id_seq TO authdb_app, authdb_admin;
+
+		ALTER TABLE totp_backup_codes ENABLE ROW LEVEL SECURITY;
+
+		CREATE POLICY totp_backup_codes_self ON totp_backup_codes
+			FOR ALL TO authdb_app
+			USING (user_id = NULLIF(current_setting('app.user_id', true), '')::int)
+			WITH CHECK (user_id = NULLIF(current_setting('app.user_id', true), '')::int);`,
+	},
+	{
+		version: 14,
+		name:    "create_organizations",
+		// Memberships and invitations relate users to each other, so they
+		// can't be limited to one user's rows like users are; the org
+		// handlers check the caller's role instead. Nothing is granted to
+		// authdb_app. Invitations are by email so that inviting doesn't
+		// reveal whether the address has an account; one per email per
+		// org, a new invitation replacing the old.
+		sql: `
+		CREATE TABLE organizations (
+			id SERIAL PRIMARY KEY,
+			name TEXT NOT NULL CHECK (btrim(name) <> ''),
+			created_by INT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE org_memberships (
+			org_id INT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (org_id, user_id)
+		);
+		CREATE INDEX org_memberships_user_idx ON org_memberships (user_id);
+		CREATE TABLE org_invitations (
+			org_id INT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			email TEXT NOT NULL,
+			role TEXT NOT NULL CHECK (role IN ('admin', 'member')),
+			invited_by INT REFERENCES users(id) ON DELETE SET NULL,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (org_id, email)
+		);
+		CREATE INDEX org_invitations_email_idx ON org_invitations (email);`,
+	},
+	{
+		version: 15,
+		name:    "add_org_invitation_tokens",
+		// Invitations are kept once used or revoked, for their status.
+		// Only a hash of the token is stored, like refresh tokens;
+		// invitations made before this have none and can still be
+		// accepted from GET /me/org-invitations. One pending invitation
+		// per email per org, a new invitation replacing the old.
+		sql: `
+		ALTER TABLE org_invitations DROP CONSTRAINT org_invitations_pkey;
+		ALTER TABLE org_invitations
+			ADD COLUMN id SERIAL PRIMARY KEY,
+			ADD COLUMN token_hash TEXT UNIQUE,
+			ADD COLUMN accepted_by INT REFERENCES users(id) ON DELETE SET NULL,
+			ADD COLUMN accepted_at TIMESTAMP,
+			ADD COLUMN revoked_at TIMESTAMP;
+		CREATE UNIQUE INDEX org_invitations_pending_idx ON org_invitations (org_id, email)
+			WHERE accepted_at IS NULL AND revoked_at IS NULL;`,
+	},
+	{
+		version: 16,
+		name:    "index_audit_events_logins",
+		// For GET /me/login-history. The predicate lists the actions
+		// rather than LIKE 'login.%': the planner can only use a partial
+		// index whose predicate the query's WHERE implies, and it can't
+		// prove that of LIKE from an IN list.
+		sql: `
+		CREATE INDEX audit_events_login_idx ON audit_events (actor_id, created_at DESC)
+			WHERE action IN ('login.success', 'login.failure');`,
+	},
+	{
+		version: 17,
+		name:    "create_webauthn_credentials",
+		// Passkeys. credential is go-webauthn's whole Credential as JSON;
+		// credential_id and user_handle are copied out of it to be unique
+		// and looked up. Limited to the app.user_id user like
+		// totp_backup_codes.
+		sql: `
+		CREATE TABLE webauthn_credentials (
+			id SERIAL PRIMARY KEY,
+			user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			credential_id BYTEA NOT NULL UNIQUE,
+			user_handle BYTEA NOT NULL,
+			credential JSONB NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_used_at TIMESTAMP
+		);
+		CREATE INDEX webauthn_credentials_user_idx ON webauthn_credentials (user_id);
+		GRANT SELECT, INSERT, UPDATE, DELETE ON webauthn_credentials TO authdb_app, authdb_admin;
+		GRANT USAGE ON SEQUENCE webauthn_credentials_id_seq TO authdb_app, authdb_admin;
+
+		ALTER TABLE webauthn_credentials ENABLE ROW LEVEL SECURITY;
+
+		CREATE POLICY webauthn_credentials_self ON webauthn_credentials
+			FOR ALL TO authdb_app
+			USING (user_id = NULLIF(current_setting('app.user_id', true), '')::int)
+			WITH CHECK (user_id = NULLIF(current_setting('app.user_id', true), '')::int);`,
+	},
+}
+
+// Migrator applies pending migrations and records them in schema_migrations.
+type Migrator struct {
+	db *sql.DB
+}
+
+// Up is safe to call from several replicas at once: only the holder of the
+// migration lock applies anything, and the others re-read the version once
+// they get the lock and find nothing left to do.
+func (m *Migrator) Up(ctx context.Context) error {
+	lock, err := acquireMigratorLock(ctx, m.db)
+	if err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer lock.Release()
+
+	conn := lock.conn
+
+	_, err = conn.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current int
+	err = conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for _, mig := range migrations {
+		if mig.version <= current {
+			continue
+		}
+		if err := apply(ctx, conn, mig); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", mig.version, mig.name, err)
+		}
+		log.Printf("Applied migration version=%d name=%s", mig.version, mig.name)
+	}
+
+	return nil
+}
+
+func apply(ctx context.Context, conn *sql.Conn, mig migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, mig.sql); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+		mig.version, mig.name,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const migrationLockID = 1234567
+
+// MigratorLock is a session-level Postgres advisory lock held on a dedicated
+// connection. If the process dies while holding it, Postgres releases it
+// when the connection drops, so a crashed pod can't wedge later rollouts.
+type MigratorLock struct {
+	conn *sql.Conn
+}
+
+func acquireMigratorLock(ctx context.Context, db *sql.DB) (*MigratorLock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blocks until any other migrating replica is done.
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &MigratorLock{conn: conn}, nil
+}
+
+// Release unlocks and returns the connection to the pool. It uses its own
+// context so the lock is released even if the caller's was cancelled.
+func (l *MigratorLock) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+		log.Println("migration lock release error:", err)
+	}
+	l.conn.Close()
+}
+
+// schemaVersion reports the newest applied migration. During a rolling
+// update pods running different builds share one database, so this is what
+// ops compare against each pod's service version.
+func schemaVersion(ctx context.Context, db *sql.DB) (int, time.Time, error) {
+	var (
+		version   int
+		appliedAt time.Time
+	)
+	err := db.QueryRowContext(ctx,
+		"SELECT version, applied_at FROM schema_migrations ORDER BY version DESC LIMIT 1",
+	).Scan(&version, &appliedAt)
+	return version, appliedAt, err
+}
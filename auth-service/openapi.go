@@ -0,0 +1,64 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIYAML is the API contract. It is written by hand alongside the
+// handlers; keep it in step when adding or changing an endpoint.
+// TestOpenAPICompliance checks responses against it, and the client
+// package is generated from it.
+//
+//go:embed openapi.yaml
+var openAPIYAML []byte
+
+// openAPIJSON converts the embedded spec once, at startup, so a broken spec
+// fails the deploy rather than the first request.
+func openAPIJSON() ([]byte, error) {
+	var spec any
+	if err := yaml.Unmarshal(openAPIYAML, &spec); err != nil {
+		return nil, fmt.Errorf("parse openapi.yaml: %w", err)
+	}
+	return json.Marshal(spec)
+}
+
+// openAPIHandler serves GET /openapi.json.
+func openAPIHandler(spec []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// apiDocsCSP loosens the default CSP just enough for Redoc, which is loaded
+// from its CDN and renders with inline styles and a blob: worker.
+const apiDocsCSP = "default-src 'none'; script-src https://cdn.redoc.ly; " +
+	"style-src 'unsafe-inline' https://fonts.googleapis.com; font-src https://fonts.gstatic.com; " +
+	"img-src 'self' data: https://cdn.redoc.ly; connect-src 'self'; worker-src blob:"
+
+const apiDocsPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>resilient-auth-service API</title>
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<redoc spec-url="/openapi.json"></redoc>
+<script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// apiDocsHandler serves GET /docs, a Redoc page for the spec. Only mounted
+// with cfg.EnableAPIDocs.
+func apiDocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", apiDocsCSP)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(apiDocsPage))
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// loadOpenAPI parses and validates the embedded spec and returns a router
+// over its operations.
+func loadOpenAPI(t *testing.T) routers.Router {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData(openAPIYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("openapi.yaml: %v", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+// TestOpenAPICompliance sends requests through the router and checks each
+// request and response against openapi.yaml: the status must be listed
+// for the operation, and the headers and body must match what is listed
+// for it.
+func TestOpenAPICompliance(t *testing.T) {
+	quietLog(t)
+	router := loadOpenAPI(t)
+	s, _ := newTestServer(t)
+	useFakeRefreshDB(t, s)
+	// Every case comes from the same address.
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1000, time.Minute)
+	h := s.Handler()
+
+	user := newTestUser(t, s, "a@example.com", "user")
+	admin := newTestUser(t, s, "admin@example.com", "admin")
+	newTOTPUser(t, s, "totp@example.com")
+	userCookie := accessTokenCookie(t, s, user)
+	adminCookie := accessTokenCookie(t, s, admin)
+
+	for _, tt := range []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		cookie      *http.Cookie
+		status      int
+	}{
+		{"liveness", "GET", "/livez", "", "", nil, http.StatusOK},
+		{"version", "GET", "/version", "", "", nil, http.StatusOK},
+		{"signing keys", "GET", "/.well-known/jwks.json", "", "", nil, http.StatusOK},
+		{"register", "POST", "/v1/register", "application/json", `{"email":"new@example.com","password":"correct horse"}`, nil, http.StatusCreated},
+		{"register with a bad email", "POST", "/v1/register", "application/json", `{"email":"nope","password":"correct horse"}`, nil, http.StatusUnprocessableEntity},
+		{"register as a form", "POST", "/v1/register", "application/x-www-form-urlencoded", `email=new@example.com`, nil, http.StatusUnsupportedMediaType},
+		{"register with malformed JSON", "POST", "/v1/register", "application/json", `{"email":`, nil, http.StatusBadRequest},
+		{"login", "POST", "/v1/login", "application/json", `{"email":"a@example.com","password":"correct horse"}`, nil, http.StatusOK},
+		{"login with TOTP", "POST", "/v1/login", "application/json", `{"email":"totp@example.com","password":"correct horse"}`, nil, http.StatusOK},
+		{"login with a wrong password", "POST", "/v1/login", "application/json", `{"email":"a@example.com","password":"wrong"}`, nil, http.StatusUnauthorized},
+		{"a used-up login flow", "POST", "/v1/login/totp", "application/json", `{"flow_id":"gone","code":"123456"}`, nil, http.StatusUnauthorized},
+		{"passkey login while the flag is off", "POST", "/v1/webauthn/login/begin", "", "", nil, http.StatusNotFound},
+		{"refresh without a token", "POST", "/v1/refresh", "", "", nil, http.StatusUnauthorized},
+		{"v1 me", "GET", "/v1/me", "", "", userCookie, http.StatusOK},
+		{"v2 me", "GET", "/v2/me", "", "", userCookie, http.StatusOK},
+		{"v2 me, logged out", "GET", "/v2/me", "", "", nil, http.StatusUnauthorized},
+		{"admin users as a user", "GET", "/v1/admin/users", "", "", userCookie, http.StatusForbidden},
+		{"flags", "GET", "/v1/admin/flags", "", "", adminCookie, http.StatusOK},
+		{"maintenance", "GET", "/v1/admin/maintenance", "", "", adminCookie, http.StatusOK},
+		{"maintenance on", "PUT", "/v1/admin/maintenance", "application/json", `{"enabled":false,"message":"Back soon"}`, adminCookie, http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+			route, params, err := router.FindRoute(r)
+			if err != nil {
+				t.Fatalf("%s %s isn't in the spec: %v", tt.method, tt.path, err)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			// The request went out already; validate a copy of it too, so a
+			// case that only passes by sending what the spec forbids fails.
+			in := &openapi3filter.RequestValidationInput{
+				Request:    r.Clone(context.Background()),
+				PathParams: params,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			in.Request.Body = io.NopCloser(strings.NewReader(tt.body))
+			if rec.Code < 400 {
+				if err := openapi3filter.ValidateRequest(context.Background(), in); err != nil {
+					t.Errorf("request: %v", err)
+				}
+			}
+
+			out := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: in,
+				Status:                 rec.Code,
+				Header:                 rec.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+			}
+			if err := openapi3filter.ValidateResponse(context.Background(), out); err != nil {
+				t.Errorf("response: %v", err)
+			}
+		})
+	}
+}
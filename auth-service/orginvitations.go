@@ -0,0 +1,589 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+	"resilient-auth-service/pagination"
+)
+
+// Invitation statuses, as computed by orgInvitationStatusSQL.
+const (
+	orgInvitationPending  = "pending"
+	orgInvitationAccepted = "accepted"
+	orgInvitationRevoked  = "revoked"
+)
+
+// orgInvitationStatusSQL is the status of the org_invitations row i.
+const orgInvitationStatusSQL = `CASE
+	WHEN i.accepted_at IS NOT NULL THEN 'accepted'
+	WHEN i.revoked_at IS NOT NULL THEN 'revoked'
+	WHEN i.expires_at <= now() THEN 'expired'
+	ELSE 'pending' END`
+
+// orgInvitationNotFound is the answer for a token or invitation that
+// doesn't exist, was used or revoked, or has expired, so the response
+// doesn't tell them apart.
+func orgInvitationNotFound() *apperror.Error {
+	return apperror.NotFound("invitation_not_found", "Invitation not found or expired")
+}
+
+type inviteOrgMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+func (req inviteOrgMemberRequest) validate() error {
+	var v validator
+	v.email("email", req.Email)
+	if req.Role != orgRoleAdmin && req.Role != orgRoleMember {
+		v.add("role", "invalid_value", "must be admin or member")
+	}
+	return v.err()
+}
+
+// sentOrgInvitation is an invitation as the inviting organization sees it.
+type sentOrgInvitation struct {
+	ID         int        `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  *int       `json:"invited_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// inviteOrgMemberHandler serves POST /v1/org/invitations. It answers the
+// same whether or not the email has an account, and emails the address a
+// single-use link to accept with, logged in or by signing up. A pending
+// invitation to the same email is replaced, so its link stops working.
+// Only owners may invite admins.
+func (s *Server) inviteOrgMemberHandler(w http.ResponseWriter, r *http.Request) {
+	var req inviteOrgMemberRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	user, _ := auth.UserFromContext(r.Context())
+	org, _ := auth.OrgFromContext(r.Context())
+	if req.Role == orgRoleAdmin && org.Role != orgRoleOwner {
+		apperror.WriteError(w, r, apperror.Forbidden("forbidden", "Only owners can invite admins"))
+		return
+	}
+
+	// Invitation tokens are made and stored like refresh tokens: only a
+	// hash is kept.
+	token, err := newRefreshToken()
+	if err != nil {
+		log.Println("org invitation token error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	inv := sentOrgInvitation{
+		Email:     req.Email,
+		Role:      req.Role,
+		Status:    orgInvitationPending,
+		InvitedBy: &user.ID,
+		ExpiresAt: time.Now().UTC().Add(orgInvitationTTL),
+	}
+	var orgName string
+	err = s.db.QueryRowContext(r.Context(),
+		`INSERT INTO org_invitations AS i (org_id, email, role, invited_by, expires_at, token_hash)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (org_id, email) WHERE accepted_at IS NULL AND revoked_at IS NULL DO UPDATE
+		 SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by, expires_at = EXCLUDED.expires_at,
+		     token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP
+		 RETURNING i.id, i.created_at, (SELECT name FROM organizations WHERE id = $1)`,
+		org.ID, req.Email, req.Role, user.ID, inv.ExpiresAt, hashRefreshToken(token),
+	).Scan(&inv.ID, &inv.CreatedAt, &orgName)
+	if err != nil {
+		log.Println("org invitation error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "org.member_invite")
+	ev.ActorID = &user.ID
+	ev.Target = "org:" + strconv.Itoa(org.ID)
+	ev.Metadata = map[string]any{"invitation_id": inv.ID, "email": req.Email, "role": req.Role}
+	s.auditor.Record(r.Context(), ev)
+
+	s.sendOrgInvitationEmail(r.Context(), req.Email, orgName, token)
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+// sendOrgInvitationEmail emails email the link to accept an invitation to
+// orgName, in the background.
+func (s *Server) sendOrgInvitationEmail(ctx context.Context, email, orgName, token string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), orgInvitationEmailTimeout)
+		defer cancel()
+
+		msg, err := newEmail(ctx, email, "org-invitation", EmailTemplateData{
+			Email:     email,
+			Link:      s.cfg.AppURL + "/invitations?token=" + url.QueryEscape(token),
+			OrgName:   orgName,
+			ExpiresIn: orgInvitationTTL,
+		})
+		if err == nil {
+			err = s.emailSender.Send(ctx, msg)
+		}
+		if err != nil {
+			log.Println("org invitation email error:", err)
+		}
+	}()
+}
+
+type orgInvitationKey struct {
+	ID int `json:"id"`
+}
+
+var orgInvitationsKeyset = pagination.Keyset{Columns: []string{"i.id"}, Desc: true}
+
+// listSentOrgInvitationsHandler serves GET
+// /v1/org/invitations?limit=&cursor=, the active organization's
+// invitations with their status, newest first.
+func (s *Server) listSentOrgInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := pagination.Limit(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	after, hasCursor, err := pagination.FromRequest[orgInvitationKey](r, s.cfg.CursorSecret)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	org, _ := auth.OrgFromContext(r.Context())
+
+	query := `SELECT i.id, i.email, i.role, ` + orgInvitationStatusSQL + `, i.invited_by,
+		i.created_at, i.expires_at, i.accepted_at, i.revoked_at
+		FROM org_invitations i WHERE i.org_id = $1`
+	args := []any{org.ID}
+	if hasCursor {
+		query += " AND " + orgInvitationsKeyset.After(2)
+		args = append(args, after.ID)
+	}
+	args = append(args, limit+1)
+	query += " ORDER BY " + orgInvitationsKeyset.OrderBy() + " LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := s.db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		log.Println("list org invitations error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var invitations []sentOrgInvitation
+	for rows.Next() {
+		var inv sentOrgInvitation
+		err := rows.Scan(&inv.ID, &inv.Email, &inv.Role, &inv.Status, &inv.InvitedBy,
+			&inv.CreatedAt, &inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt)
+		if err != nil {
+			writeDBError(w, r, err)
+			return
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pagination.NewList(invitations, limit, func(inv sentOrgInvitation) string {
+		return pagination.Encode(orgInvitationKey{ID: inv.ID}, s.cfg.CursorSecret)
+	}))
+}
+
+// revokeOrgInvitationHandler serves DELETE /v1/org/invitations/{id}, which
+// stops a pending or expired invitation's link from working. Like
+// inviting, only owners may revoke invitations of admins.
+func (s *Server) revokeOrgInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid invitation id"))
+		return
+	}
+	user, _ := auth.UserFromContext(r.Context())
+	org, _ := auth.OrgFromContext(r.Context())
+
+	err = s.withTx(r.Context(), func(tx *sql.Tx) error {
+		var role, status string
+		err := tx.QueryRowContext(r.Context(),
+			`SELECT i.role, `+orgInvitationStatusSQL+` FROM org_invitations i
+			 WHERE i.id = $1 AND i.org_id = $2 FOR UPDATE`,
+			id, org.ID,
+		).Scan(&role, &status)
+		if err != nil {
+			return err
+		}
+		if role == orgRoleAdmin && org.Role != orgRoleOwner {
+			return apperror.Forbidden("forbidden", "Only owners can revoke invitations of admins")
+		}
+		if status == orgInvitationAccepted || status == orgInvitationRevoked {
+			return apperror.Conflict("invitation_closed", "Invitation was already "+status)
+		}
+		_, err = tx.ExecContext(r.Context(),
+			"UPDATE org_invitations SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+		return err
+	})
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		apperror.WriteError(w, r, appErr)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, orgInvitationNotFound())
+		return
+	}
+	if err != nil {
+		log.Println("revoke org invitation error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "org.invitation_revoke")
+	ev.ActorID = &user.ID
+	ev.Target = "org:" + strconv.Itoa(org.ID)
+	ev.Metadata = map[string]any{"invitation_id": id}
+	s.auditor.Record(r.Context(), ev)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pendingOrgInvitation is an invitation that can still be accepted.
+type pendingOrgInvitation struct {
+	ID      int
+	OrgID   int
+	OrgName string
+	Email   string
+	Role    string
+	Expires time.Time
+}
+
+// rowQuerier is a *sql.DB or *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// findOrgInvitation returns the pending invitation with token. With lock,
+// q must be a transaction, and the invitation stays locked until it ends,
+// so only one accept can use it: a second one waits, then finds it no
+// longer pending.
+func findOrgInvitation(ctx context.Context, q rowQuerier, token string, lock bool) (pendingOrgInvitation, error) {
+	query := `SELECT i.id, i.org_id, o.name, i.email, i.role, i.expires_at
+		FROM org_invitations i JOIN organizations o ON o.id = i.org_id
+		WHERE i.token_hash = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL
+		AND i.expires_at > now()`
+	if lock {
+		query += " FOR UPDATE OF i"
+	}
+	var inv pendingOrgInvitation
+	err := q.QueryRowContext(ctx, query, hashRefreshToken(token)).
+		Scan(&inv.ID, &inv.OrgID, &inv.OrgName, &inv.Email, &inv.Role, &inv.Expires)
+	return inv, err
+}
+
+// joinOrgByInvitation adds userID to inv's organization with inv's role
+// and marks inv accepted. A user already in the organization keeps their
+// current role.
+func joinOrgByInvitation(ctx context.Context, tx *sql.Tx, inv pendingOrgInvitation, userID int) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO org_memberships (org_id, user_id, role) VALUES ($1, $2, $3)
+		 ON CONFLICT (org_id, user_id) DO NOTHING`,
+		inv.OrgID, userID, inv.Role,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE org_invitations SET accepted_at = CURRENT_TIMESTAMP, accepted_by = $2 WHERE id = $1",
+		inv.ID, userID,
+	)
+	return err
+}
+
+type orgInvitationPreview struct {
+	OrgID     int       `json:"org_id"`
+	OrgName   string    `json:"org_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// previewOrgInvitationHandler serves GET /v1/invitations/{token}, for the
+// page the invitation email links to. Anyone with the link may see it; it
+// doesn't say whether the email has an account.
+func (s *Server) previewOrgInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	inv, err := findOrgInvitation(r.Context(), s.db, r.PathValue("token"), false)
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, orgInvitationNotFound())
+		return
+	}
+	if err != nil {
+		log.Println("preview org invitation error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, orgInvitationPreview{
+		OrgID:     inv.OrgID,
+		OrgName:   inv.OrgName,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		ExpiresAt: inv.Expires,
+	})
+}
+
+// orgInvitationAcceptHandler is the chain for POST
+// /v1/invitations/{token}/accept. A request with a session cookie accepts
+// as the session's user, through orgHandler; one without signs up the
+// invited email, through publicHandler. A client whose cookie turns out
+// to be stale gets 401 session_invalid, with the cookie cleared, and can
+// retry.
+func (s *Server) orgInvitationAcceptHandler() http.Handler {
+	loggedIn := s.orgHandler("", s.acceptOrgInvitationTokenHandler)
+	signUp := s.publicHandler(http.HandlerFunc(s.signUpWithOrgInvitationHandler))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session_id"); err == nil {
+			loggedIn.ServeHTTP(w, r)
+			return
+		}
+		signUp.ServeHTTP(w, r)
+	})
+}
+
+// acceptOrgInvitationTokenHandler accepts an invitation for the logged-in
+// caller, whose email must be the one invited. The match is exact, as it
+// is everywhere else users.email is compared.
+func (s *Server) acceptOrgInvitationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+
+	var inv pendingOrgInvitation
+	err := s.withTx(r.Context(), func(tx *sql.Tx) error {
+		var err error
+		inv, err = findOrgInvitation(r.Context(), tx, r.PathValue("token"), true)
+		if err != nil {
+			return err
+		}
+		if inv.Email != user.Email {
+			return apperror.Forbidden("invitation_email_mismatch", "This invitation is for another email address")
+		}
+		return joinOrgByInvitation(r.Context(), tx, inv, user.ID)
+	})
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		apperror.WriteError(w, r, appErr)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, orgInvitationNotFound())
+		return
+	}
+	if err != nil {
+		log.Println("accept org invitation error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	s.recordOrgJoin(r, user.ID, inv)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type signUpWithOrgInvitationRequest struct {
+	Password secret `json:"password"`
+}
+
+func (req signUpWithOrgInvitationRequest) validate() error {
+	var v validator
+	v.password("password", string(req.Password))
+	return v.err()
+}
+
+// signUpWithOrgInvitationHandler creates an account for the invited email
+// with the given password and adds it to the organization, in one
+// transaction: a failure leaves neither the account nor the membership,
+// and the invitation pending. The link proves the email, so there's no
+// captcha. An email that already has an account gets 409 account_exists,
+// to log in and accept; only the link's holder learns that. Like
+// registration, it doesn't log in.
+func (s *Server) signUpWithOrgInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var req signUpWithOrgInvitationRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	token := r.PathValue("token")
+
+	// Check the token before hashing, so made-up tokens can't tie up the
+	// hashing workers.
+	_, err := findOrgInvitation(r.Context(), s.db, token, false)
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, orgInvitationNotFound())
+		return
+	}
+	if err != nil {
+		log.Println("org invitation lookup error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	hash, err := s.passwordHasher.HashPassword(r.Context(), string(req.Password))
+	if errors.Is(err, errBcryptBusy) {
+		writeServerBusy(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("org invitation sign-up hash error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	var (
+		inv    pendingOrgInvitation
+		userID int
+	)
+	err = s.withTx(r.Context(), func(tx *sql.Tx) error {
+		var err error
+		inv, err = findOrgInvitation(r.Context(), tx, token, true)
+		if err != nil {
+			return err
+		}
+		user, err := insertUser(r.Context(), tx, inv.Email, string(hash))
+		if errors.Is(err, ErrDuplicateEmail) {
+			return apperror.Conflict("account_exists", "An account with this email exists; log in to accept the invitation")
+		}
+		if err != nil {
+			return err
+		}
+		userID = user.ID
+		return joinOrgByInvitation(r.Context(), tx, inv, userID)
+	})
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		apperror.WriteError(w, r, appErr)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, orgInvitationNotFound())
+		return
+	}
+	if err != nil {
+		log.Printf("org invitation sign-up error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "user.register")
+	ev.ActorID = &userID
+	ev.Target = "user:" + strconv.Itoa(userID)
+	ev.Metadata = map[string]any{"org_invitation_id": inv.ID}
+	s.auditor.Record(r.Context(), ev)
+	s.recordOrgJoin(r, userID, inv)
+
+	s.webhooks.Enqueue(r.Context(), "user.registered", map[string]any{"user_id": userID, "email": inv.Email})
+
+	s.sendRegistrationEmail(r.Context(), inv.Email, true)
+	writeJSON(w, http.StatusCreated, map[string]any{"user_id": userID, "org_id": inv.OrgID, "role": inv.Role})
+}
+
+func (s *Server) recordOrgJoin(r *http.Request, userID int, inv pendingOrgInvitation) {
+	ev := s.auditEventFromRequest(r, "org.member_join")
+	ev.ActorID = &userID
+	ev.Target = "org:" + strconv.Itoa(inv.OrgID)
+	ev.Metadata = map[string]any{"invitation_id": inv.ID, "role": inv.Role}
+	s.auditor.Record(r.Context(), ev)
+}
+
+type orgInvitation struct {
+	OrgID     int       `json:"org_id"`
+	OrgName   string    `json:"org_name"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// listOrgInvitationsHandler serves GET /v1/me/org-invitations, the
+// pending invitations to the caller's email.
+func (s *Server) listOrgInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+
+	rows, err := s.db.QueryContext(r.Context(),
+		`SELECT i.org_id, o.name, i.role, i.expires_at FROM org_invitations i
+		 JOIN organizations o ON o.id = i.org_id
+		 WHERE i.email = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL
+		 AND i.expires_at > now() ORDER BY i.org_id`,
+		user.Email,
+	)
+	if err != nil {
+		log.Println("list org invitations error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	invitations := []orgInvitation{}
+	for rows.Next() {
+		var inv orgInvitation
+		if err := rows.Scan(&inv.OrgID, &inv.OrgName, &inv.Role, &inv.ExpiresAt); err != nil {
+			writeDBError(w, r, err)
+			return
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"invitations": invitations})
+}
+
+// acceptOrgInvitationHandler serves POST
+// /v1/me/org-invitations/{org_id}/accept, which accepts the pending
+// invitation to the caller's email without its link.
+func (s *Server) acceptOrgInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.Atoi(r.PathValue("org_id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid organization id"))
+		return
+	}
+	user, _ := auth.UserFromContext(r.Context())
+
+	inv := pendingOrgInvitation{OrgID: orgID}
+	err = s.withTx(r.Context(), func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(r.Context(),
+			`SELECT id, role FROM org_invitations
+			 WHERE org_id = $1 AND email = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+			 AND expires_at > now() FOR UPDATE`,
+			orgID, user.Email,
+		).Scan(&inv.ID, &inv.Role)
+		if err != nil {
+			return err
+		}
+		return joinOrgByInvitation(r.Context(), tx, inv, user.ID)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, orgInvitationNotFound())
+		return
+	}
+	if err != nil {
+		log.Println("accept org invitation error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	s.recordOrgJoin(r, user.ID, inv)
+	w.WriteHeader(http.StatusNoContent)
+}
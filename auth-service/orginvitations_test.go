@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"resilient-auth-service/pagination"
+)
+
+var invitationLink = regexp.MustCompile(`/invitations\?token=([\w-]+)`)
+
+// orgInvitationsServer returns a server with a fake organization database,
+// and an organization, Acme, that owner has switched to.
+func orgInvitationsServer(t *testing.T) (*Server, http.Handler, *fakeOrgDB, orgClient, organization) {
+	t.Helper()
+	quietLog(t)
+	s, _ := newTestServer(t)
+	db := useFakeOrgDB(t, s)
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1000, time.Minute)
+	h := s.Handler()
+	owner := newOrgClient(t, s, db, "owner@example.com")
+	rec := owner.do(h, http.MethodPost, "/v1/orgs", `{"name":"Acme"}`)
+	var org organization
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &org) != nil {
+		t.Fatalf("create org: %d %s", rec.Code, rec.Body)
+	}
+	owner.switchTo(t, h, org.ID)
+	return s, h, db, owner, org
+}
+
+// invite has c invite email with role, and returns the invitation and the
+// token from the email it sends.
+func invite(t *testing.T, s *Server, h http.Handler, c orgClient, email, role string) (sentOrgInvitation, string) {
+	t.Helper()
+	emails := s.emailSender.(*fakeEmailSender)
+	sentTo := func() []string {
+		var bodies []string
+		for _, m := range emails.messages() {
+			if m.To == email {
+				bodies = append(bodies, m.TextBody)
+			}
+		}
+		return bodies
+	}
+	before := len(sentTo())
+
+	rec := c.do(h, http.MethodPost, "/v1/org/invitations", fmt.Sprintf(`{"email":%q,"role":%q}`, email, role))
+	var inv sentOrgInvitation
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &inv) != nil {
+		t.Fatalf("inviting %s: %d %s", email, rec.Code, rec.Body)
+	}
+	var bodies []string
+	eventually(t, "the invitation email to "+email, func() bool { bodies = sentTo(); return len(bodies) > before })
+	m := invitationLink.FindStringSubmatch(bodies[len(bodies)-1])
+	if m == nil {
+		t.Fatalf("no invitation link in %q", bodies[len(bodies)-1])
+	}
+	return inv, m[1]
+}
+
+func previewInvitation(h http.Handler, token string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/invitations/"+token, nil))
+	return rec
+}
+
+// TestOrgInvitations sends, replaces, revokes and lists invitations, and
+// checks which of their links still preview.
+func TestOrgInvitations(t *testing.T) {
+	s, h, db, owner, org := orgInvitationsServer(t)
+	admin := newOrgClient(t, s, db, "admin@example.com")
+	member := newOrgClient(t, s, db, "member@example.com")
+	db.addMember(org.ID, admin.ID, orgRoleAdmin)
+	db.addMember(org.ID, member.ID, orgRoleMember)
+	admin.switchTo(t, h, org.ID)
+	member.switchTo(t, h, org.ID)
+
+	for _, tt := range []struct {
+		name   string
+		c      orgClient
+		body   string
+		status int
+		code   string
+	}{
+		{"member inviting", member, `{"email":"x@example.com","role":"member"}`, http.StatusForbidden, "forbidden"},
+		{"admin inviting an admin", admin, `{"email":"x@example.com","role":"admin"}`, http.StatusForbidden, "forbidden"},
+		{"inviting an owner", owner, `{"email":"x@example.com","role":"owner"}`, http.StatusUnprocessableEntity, "validation_failed"},
+		{"bad email", admin, `{"email":"x","role":"member"}`, http.StatusUnprocessableEntity, "validation_failed"},
+	} {
+		rec := tt.c.do(h, http.MethodPost, "/v1/org/invitations", tt.body)
+		if env := readEnvelope(t, rec, tt.status); env.Error.Code != tt.code {
+			t.Errorf("%s: code %q, want %s", tt.name, env.Error.Code, tt.code)
+		}
+	}
+
+	inv, first := invite(t, s, h, admin, "new@example.com", orgRoleMember)
+	if inv.Status != orgInvitationPending || inv.InvitedBy == nil || *inv.InvitedBy != admin.ID {
+		t.Errorf("invitation %+v", inv)
+	}
+	var preview orgInvitationPreview
+	if rec := previewInvitation(h, first); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &preview) != nil {
+		t.Fatalf("preview: %d %s", rec.Code, rec.Body)
+	}
+	if preview.OrgID != org.ID || preview.OrgName != "Acme" || preview.Email != "new@example.com" || preview.Role != orgRoleMember {
+		t.Errorf("preview %+v", preview)
+	}
+
+	// Inviting the same email again replaces the invitation and its link.
+	again, second := invite(t, s, h, admin, "new@example.com", orgRoleMember)
+	if again.ID != inv.ID || second == first {
+		t.Errorf("re-invited as %d with the same token: %v", again.ID, second == first)
+	}
+	if env := readEnvelope(t, previewInvitation(h, first), http.StatusNotFound); env.Error.Code != "invitation_not_found" {
+		t.Errorf("replaced link: code %q", env.Error.Code)
+	}
+	if rec := previewInvitation(h, second); rec.Code != http.StatusOK {
+		t.Errorf("new link: %d", rec.Code)
+	}
+
+	boss, _ := invite(t, s, h, owner, "boss@example.com", orgRoleAdmin)
+	gone, goneToken := invite(t, s, h, admin, "gone@example.com", orgRoleMember)
+	revoke := func(c orgClient, id string) *httptest.ResponseRecorder {
+		return c.do(h, http.MethodDelete, "/v1/org/invitations/"+id, "")
+	}
+	if rec := revoke(admin, strconv.Itoa(gone.ID)); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke: %d %s", rec.Code, rec.Body)
+	}
+	if env := readEnvelope(t, previewInvitation(h, goneToken), http.StatusNotFound); env.Error.Code != "invitation_not_found" {
+		t.Errorf("revoked link: code %q", env.Error.Code)
+	}
+	for _, tt := range []struct {
+		name   string
+		rec    *httptest.ResponseRecorder
+		status int
+		code   string
+	}{
+		{"revoking twice", revoke(admin, strconv.Itoa(gone.ID)), http.StatusConflict, "invitation_closed"},
+		{"admin revoking an admin's invitation", revoke(admin, strconv.Itoa(boss.ID)), http.StatusForbidden, "forbidden"},
+		{"member revoking", revoke(member, strconv.Itoa(inv.ID)), http.StatusForbidden, "forbidden"},
+		{"unknown invitation", revoke(admin, "999"), http.StatusNotFound, "invitation_not_found"},
+		{"bad id", revoke(admin, "x"), http.StatusBadRequest, "invalid_id"},
+	} {
+		if env := readEnvelope(t, tt.rec, tt.status); env.Error.Code != tt.code {
+			t.Errorf("%s: code %q, want %s", tt.name, env.Error.Code, tt.code)
+		}
+	}
+
+	db.mu.Lock()
+	db.invitation(inv.ID).expires = time.Now().Add(-time.Minute)
+	db.mu.Unlock()
+	if env := readEnvelope(t, previewInvitation(h, second), http.StatusNotFound); env.Error.Code != "invitation_not_found" {
+		t.Errorf("expired link: code %q", env.Error.Code)
+	}
+
+	// The list is newest first, with each invitation's status.
+	var statuses []string
+	path := "/v1/org/invitations?limit=2"
+	for {
+		var page pagination.ListResponse[sentOrgInvitation]
+		rec := admin.do(h, http.MethodGet, path, "")
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &page) != nil {
+			t.Fatalf("list: %d %s", rec.Code, rec.Body)
+		}
+		for _, inv := range page.Items {
+			statuses = append(statuses, inv.Email+" "+inv.Status)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		path = "/v1/org/invitations?limit=2&cursor=" + *page.NextCursor
+	}
+	want := fmt.Sprint([]string{"gone@example.com revoked", "boss@example.com pending", "new@example.com expired"})
+	if got := fmt.Sprint(statuses); got != want {
+		t.Errorf("listed %s, want %s", got, want)
+	}
+}
+
+// TestAcceptOrgInvitation accepts invitations each way: signing up through
+// the link, logging in and following it, and from the list of one's own
+// invitations. Each can be used once, and only by the invited email.
+func TestAcceptOrgInvitation(t *testing.T) {
+	s, h, db, owner, org := orgInvitationsServer(t)
+	existing := newOrgClient(t, s, db, "existing@example.com")
+	other := newOrgClient(t, s, db, "other@example.com")
+	accept := func(token string) string { return "/v1/invitations/" + token + "/accept" }
+
+	// Signing up.
+	_, token := invite(t, s, h, owner, "new@example.com", orgRoleMember)
+	if env := readEnvelope(t, postJSON(h, accept(token), `{"password":"short"}`), http.StatusUnprocessableEntity); env.Error.Code != "validation_failed" {
+		t.Errorf("short password: code %q", env.Error.Code)
+	}
+	rec := postJSON(h, accept(token), `{"password":"correct horse"}`)
+	var joined struct {
+		UserID int    `json:"user_id"`
+		OrgID  int    `json:"org_id"`
+		Role   string `json:"role"`
+	}
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &joined) != nil {
+		t.Fatalf("sign-up: %d %s", rec.Code, rec.Body)
+	}
+	if joined.UserID != db.users["new@example.com"] || joined.OrgID != org.ID || joined.Role != orgRoleMember {
+		t.Errorf("joined %+v", joined)
+	}
+	if role := db.members[[2]int{org.ID, joined.UserID}]; role != orgRoleMember {
+		t.Errorf("new user's role %q", role)
+	}
+	if env := readEnvelope(t, postJSON(h, accept(token), `{"password":"correct horse"}`), http.StatusNotFound); env.Error.Code != "invitation_not_found" {
+		t.Errorf("signing up twice: code %q", env.Error.Code)
+	}
+
+	// Logging in.
+	_, token = invite(t, s, h, owner, existing.Email, orgRoleMember)
+	if env := readEnvelope(t, postJSON(h, accept(token), `{"password":"correct horse"}`), http.StatusConflict); env.Error.Code != "account_exists" {
+		t.Errorf("signing up as an existing account: code %q", env.Error.Code)
+	}
+	if rec := previewInvitation(h, token); rec.Code != http.StatusOK {
+		t.Errorf("invitation not pending after a failed sign-up: %d", rec.Code)
+	}
+	if env := readEnvelope(t, other.do(h, http.MethodPost, accept(token), ""), http.StatusForbidden); env.Error.Code != "invitation_email_mismatch" {
+		t.Errorf("another user accepting: code %q", env.Error.Code)
+	}
+	stale := orgClient{existing.User, "stale"}
+	if env := readEnvelope(t, stale.do(h, http.MethodPost, accept(token), ""), http.StatusUnauthorized); env.Error.Code != "session_invalid" {
+		t.Errorf("stale session: code %q", env.Error.Code)
+	}
+	if rec := existing.do(h, http.MethodPost, accept(token), ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("accept: %d %s", rec.Code, rec.Body)
+	}
+	if role := db.members[[2]int{org.ID, existing.ID}]; role != orgRoleMember {
+		t.Errorf("existing user's role %q", role)
+	}
+	if env := readEnvelope(t, existing.do(h, http.MethodPost, accept(token), ""), http.StatusNotFound); env.Error.Code != "invitation_not_found" {
+		t.Errorf("accepting twice: code %q", env.Error.Code)
+	}
+
+	// From the list, without the link.
+	invite(t, s, h, owner, other.Email, orgRoleAdmin)
+	var list struct{ Invitations []orgInvitation }
+	if rec := other.do(h, http.MethodGet, "/v1/me/org-invitations", ""); json.Unmarshal(rec.Body.Bytes(), &list) != nil ||
+		len(list.Invitations) != 1 || list.Invitations[0].OrgName != "Acme" || list.Invitations[0].Role != orgRoleAdmin {
+		t.Fatalf("my invitations: %d %s", rec.Code, rec.Body)
+	}
+	acceptMine := "/v1/me/org-invitations/" + strconv.Itoa(org.ID) + "/accept"
+	if rec := other.do(h, http.MethodPost, acceptMine, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("accept mine: %d %s", rec.Code, rec.Body)
+	}
+	if role := db.members[[2]int{org.ID, other.ID}]; role != orgRoleAdmin {
+		t.Errorf("role from the list %q", role)
+	}
+	if rec := other.do(h, http.MethodGet, "/v1/me/org-invitations", ""); rec.Body.String() != `{"invitations":[]}`+"\n" {
+		t.Errorf("my invitations after accepting: %s", rec.Body)
+	}
+	if env := readEnvelope(t, other.do(h, http.MethodPost, acceptMine, ""), http.StatusNotFound); env.Error.Code != "invitation_not_found" {
+		t.Errorf("accepting mine twice: code %q", env.Error.Code)
+	}
+
+	// A member invited again keeps their role.
+	_, token = invite(t, s, h, owner, other.Email, orgRoleMember)
+	if rec := other.do(h, http.MethodPost, accept(token), ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("accept as a member: %d %s", rec.Code, rec.Body)
+	}
+	if role := db.members[[2]int{org.ID, other.ID}]; role != orgRoleAdmin {
+		t.Errorf("member's role became %q", role)
+	}
+}
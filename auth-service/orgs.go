@@ -0,0 +1,506 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+	"resilient-auth-service/pagination"
+)
+
+// Organization roles. An owner can do everything an admin can, and an
+// admin everything a member can.
+const (
+	orgRoleOwner  = "owner"
+	orgRoleAdmin  = "admin"
+	orgRoleMember = "member"
+)
+
+var orgRoleRank = map[string]int{orgRoleMember: 1, orgRoleAdmin: 2, orgRoleOwner: 3}
+
+const (
+	orgNameMaxLen             = 100
+	orgInvitationTTL          = 7 * 24 * time.Hour
+	orgInvitationEmailTimeout = 30 * time.Second
+)
+
+// sessionOrgKey holds the ID of the session's active organization. It is
+// set with the session's remaining TTL and deleted with the session.
+func sessionOrgKey(sessionID string) string {
+	return "session_org:" + sessionID
+}
+
+// orgSessionsKey is the set of sessions that have made orgID active, so
+// deleting the organization can clear it from them. Like user_sessions, it
+// outlives sessions that expire on their own.
+func orgSessionsKey(orgID int) string {
+	return "org_sessions:" + strconv.Itoa(orgID)
+}
+
+// clearActiveOrgScript deletes a session's active organization only if it
+// is still ARGV[1], so clearing a stale one can't undo a switch to another
+// organization made meanwhile.
+var clearActiveOrgScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// clearActiveOrg removes orgID as the active organization of those of
+// sessionIDs that have it. The script is sent in full: Run's fallback from
+// EVALSHA to EVAL on NOSCRIPT doesn't work inside a pipeline, so it would
+// fail whenever Redis hasn't cached the script yet.
+func (s *Server) clearActiveOrg(ctx context.Context, orgID int, sessionIDs ...string) error {
+	pipe := s.rdb.Pipeline()
+	for _, id := range sessionIDs {
+		clearActiveOrgScript.Eval(ctx, pipe, []string{sessionOrgKey(id)}, strconv.Itoa(orgID))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// orgHandler is the chain for the organization endpoints, authenticated
+// by the Redis session. role is the least role the caller needs in the
+// session's active organization; "" doesn't require one.
+func (s *Server) orgHandler(role string, h http.HandlerFunc) http.Handler {
+	return recoverMiddleware(s.securityHeadersMiddleware(s.noStoreMiddleware(s.corsMiddleware(
+		s.authMiddleware(s.orgMiddleware(s.rateLimitMiddleware(loggingMiddleware(requireOrgRole(role, h)))))),
+	)))
+}
+
+// orgMiddleware must run after authMiddleware. It adds the user's ID, which
+// a session doesn't carry, and the session's active organization with the
+// user's role in it, for auth.OrgFromContext. Like requireAdmin, it reads
+// the membership from the database on every request, so a removal, role
+// change or deletion takes effect immediately; a session left pointing at
+// an organization the user no longer belongs to has it cleared.
+func (s *Server) orgMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+			return
+		}
+
+		var activeOrg sql.NullInt64
+		orgID, err := s.rdb.Get(r.Context(), sessionOrgKey(user.SessionID)).Int()
+		switch {
+		case err == nil:
+			activeOrg = sql.NullInt64{Int64: int64(orgID), Valid: true}
+		case err != redis.Nil:
+			log.Printf("active org lookup error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+			w.Header().Set("Retry-After", "1")
+			apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+			return
+		}
+
+		var role sql.NullString
+		err = s.db.QueryRowContext(r.Context(),
+			`SELECT u.id, m.role FROM active_users u
+			 LEFT JOIN org_memberships m ON m.user_id = u.id AND m.org_id = $2
+			 WHERE u.email = $1`,
+			user.Email, activeOrg,
+		).Scan(&user.ID, &role)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted since the session was created.
+			apperror.WriteError(w, r, apperror.Unauthorized("session_invalid", "Session expired or invalid"))
+			return
+		}
+		if err != nil {
+			log.Println("org membership lookup error:", err)
+			writeDBError(w, r, err)
+			return
+		}
+
+		ctx := auth.SetUser(r.Context(), user)
+		if role.Valid {
+			ctx = auth.SetOrg(ctx, auth.Org{ID: orgID, Role: role.String})
+		} else if activeOrg.Valid {
+			if err := s.clearActiveOrg(r.Context(), orgID, user.SessionID); err != nil {
+				log.Println("active org clear error:", err)
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireOrgRole must run after orgMiddleware. Without an active
+// organization the request gets 403 org_required; with a role below role,
+// 403 forbidden.
+func requireOrgRole(role string, next http.Handler) http.Handler {
+	if role == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		org, ok := auth.OrgFromContext(r.Context())
+		if !ok {
+			apperror.WriteError(w, r, apperror.Forbidden("org_required", "Switch to an organization first"))
+			return
+		}
+		if orgRoleRank[org.Role] < orgRoleRank[role] {
+			apperror.WriteError(w, r, apperror.Forbidden("forbidden", "Forbidden"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type createOrgRequest struct {
+	Name string `json:"name"`
+}
+
+func (req createOrgRequest) validate() error {
+	var v validator
+	if v.required("name", req.Name) {
+		v.length("name", req.Name, 0, orgNameMaxLen)
+	}
+	return v.err()
+}
+
+// createOrgHandler serves POST /v1/orgs. The caller becomes the new
+// organization's owner; it isn't made active.
+func (s *Server) createOrgHandler(w http.ResponseWriter, r *http.Request) {
+	var req createOrgRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	user, _ := auth.UserFromContext(r.Context())
+
+	org := organization{Name: strings.TrimSpace(req.Name), Role: orgRoleOwner}
+	err := s.withTx(r.Context(), func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(r.Context(),
+			"INSERT INTO organizations (name, created_by) VALUES ($1, $2) RETURNING id, created_at",
+			org.Name, user.ID,
+		).Scan(&org.ID, &org.CreatedAt)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(r.Context(),
+			"INSERT INTO org_memberships (org_id, user_id, role) VALUES ($1, $2, $3)",
+			org.ID, user.ID, orgRoleOwner,
+		)
+		return err
+	})
+	if err != nil {
+		log.Println("create org error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "org.create")
+	ev.ActorID = &user.ID
+	ev.Target = "org:" + strconv.Itoa(org.ID)
+	s.auditor.Record(r.Context(), ev)
+
+	writeJSON(w, http.StatusCreated, org)
+}
+
+// listOrgsHandler serves GET /v1/orgs, the caller's organizations.
+func (s *Server) listOrgsHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	active, _ := auth.OrgFromContext(r.Context())
+
+	rows, err := s.db.QueryContext(r.Context(),
+		`SELECT o.id, o.name, m.role, o.created_at FROM org_memberships m
+		 JOIN organizations o ON o.id = m.org_id
+		 WHERE m.user_id = $1 ORDER BY o.id`,
+		user.ID,
+	)
+	if err != nil {
+		log.Println("list orgs error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	orgs := []organization{}
+	for rows.Next() {
+		var o organization
+		if err := rows.Scan(&o.ID, &o.Name, &o.Role, &o.CreatedAt); err != nil {
+			writeDBError(w, r, err)
+			return
+		}
+		o.Active = o.ID == active.ID
+		orgs = append(orgs, o)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"organizations": orgs})
+}
+
+type switchOrgRequest struct {
+	// OrgID is the organization to act in; null leaves none active.
+	OrgID *int `json:"org_id"`
+}
+
+// switchOrgHandler serves PUT /v1/me/org, which sets the organization the
+// session acts in. It applies to this session only, for the rest of its
+// life.
+func (s *Server) switchOrgHandler(w http.ResponseWriter, r *http.Request) {
+	var req switchOrgRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	user, _ := auth.UserFromContext(r.Context())
+
+	if req.OrgID == nil {
+		if err := s.rdb.Del(r.Context(), sessionOrgKey(user.SessionID)).Err(); err != nil {
+			log.Println("switch org error:", err)
+			apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	orgID := *req.OrgID
+	var role string
+	err := s.db.QueryRowContext(r.Context(),
+		"SELECT role FROM org_memberships WHERE org_id = $1 AND user_id = $2",
+		orgID, user.ID,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Organizations the caller isn't in are indistinguishable from
+		// ones that don't exist.
+		apperror.WriteError(w, r, apperror.NotFound("org_not_found", "Organization not found"))
+		return
+	}
+	if err != nil {
+		log.Println("switch org lookup error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ttl, err := s.rdb.PTTL(r.Context(), "session:"+user.SessionID).Result()
+	if err == nil && ttl <= 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("session_invalid", "Session expired or invalid"))
+		return
+	}
+	if err == nil {
+		pipe := s.rdb.TxPipeline()
+		pipe.Set(r.Context(), sessionOrgKey(user.SessionID), orgID, ttl)
+		pipe.SAdd(r.Context(), orgSessionsKey(orgID), user.SessionID)
+		pipe.Expire(r.Context(), orgSessionsKey(orgID), s.cfg.SessionTTL)
+		_, err = pipe.Exec(r.Context())
+	}
+	if err != nil {
+		log.Println("switch org error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"org_id": orgID, "role": role})
+}
+
+type orgMember struct {
+	UserID    int       `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type orgMemberKey struct {
+	UserID int `json:"user_id"`
+}
+
+var orgMembersKeyset = pagination.Keyset{Columns: []string{"m.user_id"}}
+
+// listOrgMembersHandler serves GET /v1/org/members?limit=&cursor=, the
+// members of the active organization.
+func (s *Server) listOrgMembersHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := pagination.Limit(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	after, hasCursor, err := pagination.FromRequest[orgMemberKey](r, s.cfg.CursorSecret)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	org, _ := auth.OrgFromContext(r.Context())
+
+	query := `SELECT m.user_id, u.email, m.role, m.created_at FROM org_memberships m
+		JOIN active_users u ON u.id = m.user_id WHERE m.org_id = $1`
+	args := []any{org.ID}
+	if hasCursor {
+		query += " AND " + orgMembersKeyset.After(2)
+		args = append(args, after.UserID)
+	}
+	args = append(args, limit+1)
+	query += " ORDER BY " + orgMembersKeyset.OrderBy() + " LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := s.db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		log.Println("list org members error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var members []orgMember
+	for rows.Next() {
+		var m orgMember
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+			writeDBError(w, r, err)
+			return
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pagination.NewList(members, limit, func(m orgMember) string {
+		return pagination.Encode(orgMemberKey{UserID: m.UserID}, s.cfg.CursorSecret)
+	}))
+}
+
+// removeOrgMemberHandler serves DELETE /v1/org/members/{user_id}. Admins
+// can remove members; only owners can remove admins and owners, and the
+// last owner can't be removed. Members can remove themselves, to leave.
+func (s *Server) removeOrgMemberHandler(w http.ResponseWriter, r *http.Request) {
+	targetID, err := strconv.Atoi(r.PathValue("user_id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid user id"))
+		return
+	}
+	user, _ := auth.UserFromContext(r.Context())
+	org, _ := auth.OrgFromContext(r.Context())
+
+	var email string
+	err = s.withTx(r.Context(), func(tx *sql.Tx) error {
+		// Lock the organization's owners so two owners can't remove each
+		// other at once and leave it with none.
+		rows, err := tx.QueryContext(r.Context(),
+			"SELECT user_id FROM org_memberships WHERE org_id = $1 AND role = $2 FOR UPDATE",
+			org.ID, orgRoleOwner,
+		)
+		if err != nil {
+			return err
+		}
+		owners := 0
+		for rows.Next() {
+			owners++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		var role string
+		err = tx.QueryRowContext(r.Context(),
+			`SELECT m.role, u.email FROM org_memberships m JOIN users u ON u.id = m.user_id
+			 WHERE m.org_id = $1 AND m.user_id = $2`,
+			org.ID, targetID,
+		).Scan(&role, &email)
+		if err != nil {
+			return err
+		}
+		switch {
+		case targetID == user.ID:
+		case orgRoleRank[org.Role] < orgRoleRank[orgRoleAdmin]:
+			return apperror.Forbidden("forbidden", "Forbidden")
+		case role != orgRoleMember && org.Role != orgRoleOwner:
+			return apperror.Forbidden("forbidden", "Only owners can remove admins and owners")
+		}
+		if role == orgRoleOwner && owners <= 1 {
+			return apperror.Conflict("last_owner", "An organization must keep at least one owner")
+		}
+
+		_, err = tx.ExecContext(r.Context(),
+			"DELETE FROM org_memberships WHERE org_id = $1 AND user_id = $2",
+			org.ID, targetID,
+		)
+		return err
+	})
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		apperror.WriteError(w, r, appErr)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, apperror.NotFound("member_not_found", "Member not found"))
+		return
+	}
+	if err != nil {
+		log.Println("remove org member error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	// orgMiddleware would clear these on their next request anyway.
+	if ids, err := s.rdb.SMembers(r.Context(), "user_sessions:"+email).Result(); err == nil {
+		err = s.clearActiveOrg(r.Context(), org.ID, ids...)
+	}
+	if err != nil {
+		log.Println("remove org member session error:", err)
+	}
+
+	ev := s.auditEventFromRequest(r, "org.member_remove")
+	ev.ActorID = &user.ID
+	ev.Target = "org:" + strconv.Itoa(org.ID)
+	ev.Metadata = map[string]any{"user_id": targetID}
+	s.auditor.Record(r.Context(), ev)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteOrgHandler serves DELETE /v1/org, which deletes the active
+// organization with its memberships and invitations, and clears it from
+// every session that has it active.
+func (s *Server) deleteOrgHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	org, _ := auth.OrgFromContext(r.Context())
+
+	_, err := s.db.ExecContext(r.Context(), "DELETE FROM organizations WHERE id = $1", org.ID)
+	if err != nil {
+		log.Println("delete org error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	// Failures leave sessions pointing at an organization that no longer
+	// exists, which orgMiddleware ignores and clears.
+	key := orgSessionsKey(org.ID)
+	if ids, err := s.rdb.SMembers(r.Context(), key).Result(); err == nil {
+		if err = s.clearActiveOrg(r.Context(), org.ID, ids...); err == nil {
+			err = s.rdb.Del(r.Context(), key).Err()
+		}
+	}
+	if err != nil {
+		log.Println("delete org session error:", err)
+	}
+
+	ev := s.auditEventFromRequest(r, "org.delete")
+	ev.Level = "warning"
+	ev.ActorID = &user.ID
+	ev.Target = "org:" + strconv.Itoa(org.ID)
+	s.auditor.Record(r.Context(), ev)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"resilient-auth-service/auth"
+	"resilient-auth-service/pagination"
+)
+
+// fakeOrgDB is a database/sql driver holding in-memory users,
+// organizations, memberships and invitations. Like fakeRefreshDB it understands exactly
+// the statements the organization handlers run and fails loudly on
+// anything else.
+type fakeOrgDB struct {
+	mu      sync.Mutex
+	users   map[string]int // email → ID, the active_users view
+	orgs    map[int]string // ID → name
+	members map[[2]int]string
+	nextOrg int
+	// invitations[i] has ID i+1.
+	invitations []*fakeOrgInvitation
+}
+
+type fakeOrgInvitation struct {
+	orgID     int
+	email     string
+	role      string
+	tokenHash string
+	expires   time.Time
+	accepted  bool
+	revoked   bool
+}
+
+// status is what orgInvitationStatusSQL computes.
+func (inv *fakeOrgInvitation) status() string {
+	switch {
+	case inv.accepted:
+		return orgInvitationAccepted
+	case inv.revoked:
+		return orgInvitationRevoked
+	case !inv.expires.After(time.Now()):
+		return "expired"
+	}
+	return orgInvitationPending
+}
+
+// invitation returns the invitation with id, or nil.
+func (f *fakeOrgDB) invitation(id int) *fakeOrgInvitation {
+	if id < 1 || id > len(f.invitations) {
+		return nil
+	}
+	return f.invitations[id-1]
+}
+
+func (f *fakeOrgDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeOrgDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeOrgDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeOrgDB: prepared statements not supported")
+}
+func (f *fakeOrgDB) Close() error              { return nil }
+func (f *fakeOrgDB) Begin() (driver.Tx, error) { return f, nil }
+
+// Statements apply immediately; the handlers only roll back before
+// writing anything.
+func (f *fakeOrgDB) Commit() error   { return nil }
+func (f *fakeOrgDB) Rollback() error { return nil }
+
+// addMember puts userID in orgID with role.
+func (f *fakeOrgDB) addMember(orgID, userID int, role string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.members[[2]int{orgID, userID}] = role
+}
+
+func (f *fakeOrgDB) emailOf(userID int) string {
+	for email, id := range f.users {
+		if id == userID {
+			return email
+		}
+	}
+	return ""
+}
+
+func (f *fakeOrgDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	arg := func(i int) int { return int(args[i].Value.(int64)) }
+	switch query {
+	case "INSERT INTO org_memberships (org_id, user_id, role) VALUES ($1, $2, $3)":
+		f.members[[2]int{arg(0), arg(1)}] = args[2].Value.(string)
+	case "INSERT INTO org_memberships (org_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (org_id, user_id) DO NOTHING":
+		if _, ok := f.members[[2]int{arg(0), arg(1)}]; !ok {
+			f.members[[2]int{arg(0), arg(1)}] = args[2].Value.(string)
+		}
+	case "UPDATE org_invitations SET accepted_at = CURRENT_TIMESTAMP, accepted_by = $2 WHERE id = $1":
+		f.invitation(arg(0)).accepted = true
+	case "UPDATE org_invitations SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1":
+		f.invitation(arg(0)).revoked = true
+	case "INSERT INTO outbox_events (aggregate_id, event_type, payload) VALUES ($1, $2, $3)":
+	case "DELETE FROM org_memberships WHERE org_id = $1 AND user_id = $2":
+		delete(f.members, [2]int{arg(0), arg(1)})
+	case "DELETE FROM organizations WHERE id = $1":
+		delete(f.orgs, arg(0))
+		for k := range f.members {
+			if k[0] == arg(0) {
+				delete(f.members, k)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("fakeOrgDB: unexpected exec %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (f *fakeOrgDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	arg := func(i int) int { return int(args[i].Value.(int64)) }
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	switch {
+	case strings.HasPrefix(query, "SELECT u.id, m.role FROM active_users u LEFT JOIN org_memberships m"):
+		rows := &fakeRows{cols: []string{"id", "role"}}
+		if id, ok := f.users[args[0].Value.(string)]; ok {
+			var role driver.Value
+			if orgID, ok := args[1].Value.(int64); ok {
+				if r, ok := f.members[[2]int{int(orgID), id}]; ok {
+					role = r
+				}
+			}
+			rows.rows = append(rows.rows, []driver.Value{int64(id), role})
+		}
+		return rows, nil
+
+	case query == "INSERT INTO organizations (name, created_by) VALUES ($1, $2) RETURNING id, created_at":
+		f.nextOrg++
+		f.orgs[f.nextOrg] = args[0].Value.(string)
+		return &fakeRows{cols: []string{"id", "created_at"}, rows: [][]driver.Value{{int64(f.nextOrg), created}}}, nil
+
+	case strings.HasPrefix(query, "SELECT o.id, o.name, m.role, o.created_at FROM org_memberships m"):
+		rows := &fakeRows{cols: []string{"id", "name", "role", "created_at"}}
+		for id := 1; id <= f.nextOrg; id++ {
+			if role, ok := f.members[[2]int{id, arg(0)}]; ok {
+				rows.rows = append(rows.rows, []driver.Value{int64(id), f.orgs[id], role, created})
+			}
+		}
+		return rows, nil
+
+	case query == "SELECT role FROM org_memberships WHERE org_id = $1 AND user_id = $2":
+		rows := &fakeRows{cols: []string{"role"}}
+		if role, ok := f.members[[2]int{arg(0), arg(1)}]; ok {
+			rows.rows = append(rows.rows, []driver.Value{role})
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "SELECT m.user_id, u.email, m.role, m.created_at FROM org_memberships m"):
+		after, limit := 0, arg(len(args)-1)
+		if len(args) == 3 {
+			after = arg(1)
+		}
+		var ids []int
+		for k := range f.members {
+			if k[0] == arg(0) && k[1] > after {
+				ids = append(ids, k[1])
+			}
+		}
+		slices.Sort(ids)
+		rows := &fakeRows{cols: []string{"user_id", "email", "role", "created_at"}}
+		for _, id := range ids[:min(limit, len(ids))] {
+			rows.rows = append(rows.rows, []driver.Value{int64(id), f.emailOf(id), f.members[[2]int{arg(0), id}], created})
+		}
+		return rows, nil
+
+	case query == "SELECT user_id FROM org_memberships WHERE org_id = $1 AND role = $2 FOR UPDATE":
+		rows := &fakeRows{cols: []string{"user_id"}}
+		for k, role := range f.members {
+			if k[0] == arg(0) && role == args[1].Value.(string) {
+				rows.rows = append(rows.rows, []driver.Value{int64(k[1])})
+			}
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "SELECT m.role, u.email FROM org_memberships m JOIN users u"):
+		rows := &fakeRows{cols: []string{"role", "email"}}
+		if role, ok := f.members[[2]int{arg(0), arg(1)}]; ok {
+			rows.rows = append(rows.rows, []driver.Value{role, f.emailOf(arg(1))})
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "INSERT INTO org_invitations AS i"):
+		inv := &fakeOrgInvitation{orgID: arg(0), email: args[1].Value.(string), role: args[2].Value.(string),
+			expires: args[4].Value.(time.Time), tokenHash: args[5].Value.(string)}
+		id := 0
+		for i, old := range f.invitations {
+			if old.orgID == inv.orgID && old.email == inv.email && !old.accepted && !old.revoked {
+				f.invitations[i], id = inv, i+1
+			}
+		}
+		if id == 0 {
+			f.invitations = append(f.invitations, inv)
+			id = len(f.invitations)
+		}
+		return &fakeRows{cols: []string{"id", "created_at", "name"}, rows: [][]driver.Value{{int64(id), created, f.orgs[inv.orgID]}}}, nil
+
+	case strings.HasPrefix(query, "SELECT i.id, i.email, i.role, CASE"):
+		before, limit := len(f.invitations)+1, arg(len(args)-1)
+		if len(args) == 3 {
+			before = arg(1)
+		}
+		rows := &fakeRows{cols: []string{"id", "email", "role", "status", "invited_by", "created_at", "expires_at", "accepted_at", "revoked_at"}}
+		for id := before - 1; id >= 1 && len(rows.rows) < limit; id-- {
+			if inv := f.invitation(id); inv.orgID == arg(0) {
+				rows.rows = append(rows.rows, []driver.Value{int64(id), inv.email, inv.role, inv.status(), nil, created, inv.expires, nil, nil})
+			}
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "SELECT i.role, CASE"):
+		rows := &fakeRows{cols: []string{"role", "status"}}
+		if inv := f.invitation(arg(0)); inv != nil && inv.orgID == arg(1) {
+			rows.rows = append(rows.rows, []driver.Value{inv.role, inv.status()})
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "SELECT i.id, i.org_id, o.name, i.email, i.role, i.expires_at FROM org_invitations i"):
+		rows := &fakeRows{cols: []string{"id", "org_id", "name", "email", "role", "expires_at"}}
+		for i, inv := range f.invitations {
+			if inv.tokenHash == args[0].Value.(string) && inv.status() == orgInvitationPending {
+				rows.rows = append(rows.rows, []driver.Value{int64(i + 1), int64(inv.orgID), f.orgs[inv.orgID], inv.email, inv.role, inv.expires})
+			}
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "SELECT i.org_id, o.name, i.role, i.expires_at FROM org_invitations i"):
+		rows := &fakeRows{cols: []string{"org_id", "name", "role", "expires_at"}}
+		for _, inv := range f.invitations {
+			if inv.email == args[0].Value.(string) && inv.status() == orgInvitationPending {
+				rows.rows = append(rows.rows, []driver.Value{int64(inv.orgID), f.orgs[inv.orgID], inv.role, inv.expires})
+			}
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "SELECT id, role FROM org_invitations WHERE org_id = $1 AND email = $2"):
+		rows := &fakeRows{cols: []string{"id", "role"}}
+		for i, inv := range f.invitations {
+			if inv.orgID == arg(0) && inv.email == args[1].Value.(string) && inv.status() == orgInvitationPending {
+				rows.rows = append(rows.rows, []driver.Value{int64(i + 1), inv.role})
+			}
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, "INSERT INTO users (email, password_hash) VALUES ($1, $2) RETURNING"):
+		email := args[0].Value.(string)
+		if _, ok := f.users[email]; ok {
+			return nil, &pq.Error{Code: "23505", Constraint: "users_email_key"}
+		}
+		id := 1000 + len(f.users)
+		f.users[email] = id
+		return &fakeRows{cols: []string{"id", "email", "password_hash", "role", "totp_secret", "created_at"},
+			rows: [][]driver.Value{{int64(id), email, args[1].Value, "user", "", created}}}, nil
+	}
+	return nil, fmt.Errorf("fakeOrgDB: unexpected query %q", query)
+}
+
+// useFakeOrgDB gives s a fake database with organization tables.
+func useFakeOrgDB(t testing.TB, s *Server) *fakeOrgDB {
+	t.Helper()
+	fake := &fakeOrgDB{users: map[string]int{}, orgs: map[int]string{}, members: map[[2]int]string{}}
+	s.db = sql.OpenDB(fake)
+	t.Cleanup(func() { s.db.Close() })
+	return fake
+}
+
+// orgClient is a user with a session, for calling the organization
+// endpoints.
+type orgClient struct {
+	User
+	sessionID string
+}
+
+func newOrgClient(t *testing.T, s *Server, db *fakeOrgDB, email string) orgClient {
+	t.Helper()
+	user := newTestUser(t, s, email, "user")
+	db.mu.Lock()
+	db.users[email] = user.ID
+	db.mu.Unlock()
+	id, err := s.createSession(context.Background(), email, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return orgClient{user, id}
+}
+
+func (c orgClient) do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.AddCookie(&http.Cookie{Name: "session_id", Value: c.sessionID})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func (c orgClient) switchTo(t *testing.T, h http.Handler, orgID int) {
+	t.Helper()
+	if rec := c.do(h, http.MethodPut, "/v1/me/org", fmt.Sprintf(`{"org_id":%d}`, orgID)); rec.Code != http.StatusOK {
+		t.Fatalf("%s switching to %d: %d %s", c.Email, orgID, rec.Code, rec.Body)
+	}
+}
+
+// TestOrganizations walks an organization through its life: created,
+// switched to, listed, members removed under the role rules, and deleted
+// with its active-org claims cleared from live sessions.
+func TestOrganizations(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	db := useFakeOrgDB(t, s)
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1000, time.Minute)
+	h := s.Handler()
+	owner := newOrgClient(t, s, db, "owner@example.com")
+	admin := newOrgClient(t, s, db, "admin@example.com")
+	member := newOrgClient(t, s, db, "member@example.com")
+	outsider := newOrgClient(t, s, db, "outsider@example.com")
+
+	if env := readEnvelope(t, owner.do(h, http.MethodPost, "/v1/orgs", `{"name":" "}`), http.StatusUnprocessableEntity); env.Error.Code != "validation_failed" {
+		t.Errorf("blank name: code %q", env.Error.Code)
+	}
+	rec := owner.do(h, http.MethodPost, "/v1/orgs", `{"name":" Acme "}`)
+	var org organization
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &org) != nil {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body)
+	}
+	if org.Name != "Acme" || org.Role != orgRoleOwner || org.Active {
+		t.Errorf("created %+v", org)
+	}
+	db.addMember(org.ID, admin.ID, orgRoleAdmin)
+	db.addMember(org.ID, member.ID, orgRoleMember)
+
+	if env := readEnvelope(t, owner.do(h, http.MethodGet, "/v1/org/members", ""), http.StatusForbidden); env.Error.Code != "org_required" {
+		t.Errorf("members before switching: code %q, want org_required", env.Error.Code)
+	}
+	if env := readEnvelope(t, outsider.do(h, http.MethodPut, "/v1/me/org", fmt.Sprintf(`{"org_id":%d}`, org.ID)), http.StatusNotFound); env.Error.Code != "org_not_found" {
+		t.Errorf("outsider switching: code %q, want org_not_found", env.Error.Code)
+	}
+	for _, c := range []orgClient{owner, admin, member} {
+		c.switchTo(t, h, org.ID)
+	}
+	if got, _ := mr.Get(sessionOrgKey(owner.sessionID)); got != strconv.Itoa(org.ID) {
+		t.Errorf("session_org %q", got)
+	}
+	if ttl, sessionTTL := mr.TTL(sessionOrgKey(owner.sessionID)), mr.TTL("session:"+owner.sessionID); ttl != sessionTTL {
+		t.Errorf("session_org TTL %v, session TTL %v", ttl, sessionTTL)
+	}
+	if ok, _ := mr.SIsMember(orgSessionsKey(org.ID), owner.sessionID); !ok {
+		t.Error("session not in org_sessions")
+	}
+
+	var orgs struct{ Organizations []organization }
+	if rec := owner.do(h, http.MethodGet, "/v1/orgs", ""); json.Unmarshal(rec.Body.Bytes(), &orgs) != nil ||
+		len(orgs.Organizations) != 1 || !orgs.Organizations[0].Active || orgs.Organizations[0].Role != orgRoleOwner {
+		t.Errorf("list orgs: %d %s", rec.Code, rec.Body)
+	}
+
+	var page pagination.ListResponse[orgMember]
+	if rec := member.do(h, http.MethodGet, "/v1/org/members?limit=2", ""); json.Unmarshal(rec.Body.Bytes(), &page) != nil ||
+		len(page.Items) != 2 || page.Items[0].Email != "owner@example.com" || page.NextCursor == nil {
+		t.Fatalf("members page 1: %d %s", rec.Code, rec.Body)
+	}
+	cursor := *page.NextCursor
+	page = pagination.ListResponse[orgMember]{}
+	if rec := member.do(h, http.MethodGet, "/v1/org/members?limit=2&cursor="+cursor, ""); json.Unmarshal(rec.Body.Bytes(), &page) != nil ||
+		len(page.Items) != 1 || page.Items[0].Role != orgRoleMember || page.NextCursor != nil {
+		t.Errorf("members page 2: %d %s", rec.Code, rec.Body)
+	}
+
+	remove := func(c orgClient, target User) *httptest.ResponseRecorder {
+		return c.do(h, http.MethodDelete, "/v1/org/members/"+strconv.Itoa(target.ID), "")
+	}
+	for _, tt := range []struct {
+		name   string
+		rec    *httptest.ResponseRecorder
+		status int
+		code   string
+	}{
+		{"member deleting the org", member.do(h, http.MethodDelete, "/v1/org", ""), http.StatusForbidden, "forbidden"},
+		{"member removing an admin", remove(member, admin.User), http.StatusForbidden, "forbidden"},
+		{"admin removing the owner", remove(admin, owner.User), http.StatusForbidden, "forbidden"},
+		{"last owner leaving", remove(owner, owner.User), http.StatusConflict, "last_owner"},
+		{"removing a non-member", remove(owner, outsider.User), http.StatusNotFound, "member_not_found"},
+	} {
+		if env := readEnvelope(t, tt.rec, tt.status); env.Error.Code != tt.code {
+			t.Errorf("%s: code %q, want %s", tt.name, env.Error.Code, tt.code)
+		}
+	}
+
+	if rec := remove(admin, member.User); rec.Code != http.StatusNoContent {
+		t.Fatalf("admin removing a member: %d %s", rec.Code, rec.Body)
+	}
+	if mr.Exists(sessionOrgKey(member.sessionID)) {
+		t.Error("removed member's session still has the org active")
+	}
+	if env := readEnvelope(t, member.do(h, http.MethodGet, "/v1/org/members", ""), http.StatusForbidden); env.Error.Code != "org_required" {
+		t.Errorf("removed member: code %q, want org_required", env.Error.Code)
+	}
+	if rec := remove(admin, admin.User); rec.Code != http.StatusNoContent {
+		t.Errorf("admin leaving: %d %s", rec.Code, rec.Body)
+	}
+
+	// A session left pointing at an organization the user isn't in has it
+	// cleared on its next request.
+	mr.Set(sessionOrgKey(outsider.sessionID), strconv.Itoa(org.ID))
+	if env := readEnvelope(t, outsider.do(h, http.MethodGet, "/v1/org/members", ""), http.StatusForbidden); env.Error.Code != "org_required" {
+		t.Errorf("stale active org: code %q, want org_required", env.Error.Code)
+	}
+	if mr.Exists(sessionOrgKey(outsider.sessionID)) {
+		t.Error("stale active org not cleared")
+	}
+
+	second := owner.do(h, http.MethodPost, "/v1/orgs", `{"name":"Other"}`)
+	var other organization
+	if err := json.Unmarshal(second.Body.Bytes(), &other); err != nil {
+		t.Fatal(err)
+	}
+	otherSession, err := s.createSession(context.Background(), owner.Email, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	(orgClient{owner.User, otherSession}).switchTo(t, h, other.ID)
+
+	if rec := owner.do(h, http.MethodDelete, "/v1/org", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: %d %s", rec.Code, rec.Body)
+	}
+	for k := range db.members {
+		if k[0] == org.ID {
+			t.Errorf("membership %v outlived the org", k)
+		}
+	}
+	if mr.Exists(sessionOrgKey(owner.sessionID)) || mr.Exists(orgSessionsKey(org.ID)) {
+		t.Error("deleted org still active in a session")
+	}
+	if got, _ := mr.Get(sessionOrgKey(otherSession)); got != strconv.Itoa(other.ID) {
+		t.Errorf("another org's session cleared: %q", got)
+	}
+
+	if rec := owner.do(h, http.MethodPut, "/v1/me/org", `{"org_id":null}`); rec.Code != http.StatusNoContent {
+		t.Errorf("switching to none: %d", rec.Code)
+	}
+}
+
+// TestRequireOrgRole checks the role ranks: each role can do what the ones
+// below it can.
+func TestRequireOrgRole(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	for _, need := range []string{orgRoleMember, orgRoleAdmin, orgRoleOwner} {
+		for _, have := range []string{orgRoleMember, orgRoleAdmin, orgRoleOwner} {
+			r := httptest.NewRequest(http.MethodGet, "/v1/org/members", nil)
+			r = r.WithContext(auth.SetOrg(r.Context(), auth.Org{ID: 1, Role: have}))
+			rec := httptest.NewRecorder()
+			requireOrgRole(need, ok).ServeHTTP(rec, r)
+			if want := orgRoleRank[have] >= orgRoleRank[need]; (rec.Code == http.StatusNoContent) != want {
+				t.Errorf("%s needing %s: %d", have, need, rec.Code)
+			}
+		}
+	}
+}
+
+// TestClearActiveOrg clears an organization from sessions on a fresh
+// Redis, where the script isn't cached, and leaves sessions that have
+// since switched to another organization alone.
+func TestClearActiveOrg(t *testing.T) {
+	s, mr := newTestServer(t)
+	mr.Set(sessionOrgKey("a"), "1")
+	mr.Set(sessionOrgKey("b"), "2")
+	if err := s.clearActiveOrg(context.Background(), 1, "a", "b", "none"); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(sessionOrgKey("a")) {
+		t.Error("a still has org 1 active")
+	}
+	if got, _ := mr.Get(sessionOrgKey("b")); got != "2" {
+		t.Errorf("b's active org %q, want 2", got)
+	}
+}
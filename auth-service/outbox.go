@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	outboxBatchSize     = 100 // aggregates per relay pass, and rows per aggregate
+	outboxPruneInterval = time.Hour
+)
+
+// OutboxEvent is one row of outbox_events and, JSON-encoded, the message
+// published to the broker. ID is unique and increasing, so consumers can
+// use it to drop the duplicates at-least-once delivery allows.
+type OutboxEvent struct {
+	ID          int64           `json:"id"`
+	AggregateID string          `json:"aggregate_id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"data"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// writeOutbox records an event in tx, so it is published if and only if the
+// mutation it describes commits. Synthetic checks publish nothing.
+func writeOutbox(ctx context.Context, tx *sql.Tx, aggregateID, eventType string, payload any) error {
+	if isSynthetic(ctx) {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO outbox_events (aggregate_id, event_type, payload) VALUES ($1, $2, $3)",
+		aggregateID, eventType, data,
+	)
+	return err
+}
+
+// OutboxRelay publishes pending outbox rows in the background. Several
+// instances may run against the same database: each aggregate is claimed
+// with a transaction-scoped advisory lock, so only one relay publishes a
+// given user's events at a time and they go out in id order.
+type OutboxRelay struct {
+	db        *sql.DB
+	pub       Publisher
+	interval  time.Duration
+	retention time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewOutboxRelay(db *sql.DB, pub Publisher, interval, retention time.Duration) *OutboxRelay {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &OutboxRelay{
+		db:        db,
+		pub:       pub,
+		interval:  interval,
+		retention: retention,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Close stops the relay after its current pass and closes the publisher.
+// Unsent rows stay in the table for the next instance to pick up.
+func (r *OutboxRelay) Close(ctx context.Context) error {
+	r.cancel()
+	select {
+	case <-r.done:
+		return r.pub.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *OutboxRelay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	var lastPrune time.Time
+
+	for {
+		if err := r.relay(); err != nil && r.ctx.Err() == nil {
+			log.Println("outbox relay error:", err)
+		}
+		if time.Since(lastPrune) >= outboxPruneInterval {
+			if err := r.prune(); err != nil && r.ctx.Err() == nil {
+				log.Println("outbox prune error:", err)
+			}
+			lastPrune = time.Now()
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay makes one pass over the aggregates with pending events, oldest first.
+func (r *OutboxRelay) relay() error {
+	rows, err := r.db.QueryContext(r.ctx,
+		`SELECT aggregate_id FROM outbox_events WHERE sent_at IS NULL
+		 GROUP BY aggregate_id ORDER BY min(id) LIMIT $1`,
+		outboxBatchSize,
+	)
+	if err != nil {
+		return err
+	}
+	var aggregates []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		aggregates = append(aggregates, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range aggregates {
+		if r.ctx.Err() != nil {
+			return nil
+		}
+		if err := r.relayAggregate(id); err != nil {
+			log.Printf("outbox relay aggregate=%s err=%v", id, err)
+		}
+	}
+	return nil
+}
+
+// relayAggregate publishes one aggregate's pending events in order, stopping
+// at the first failure so a later event never overtakes an earlier one.
+func (r *OutboxRelay) relayAggregate(aggregateID string) error {
+	tx, err := r.db.BeginTx(r.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED alone would let a second relay skip a locked row and
+	// publish the one after it; the advisory lock keeps the aggregate whole.
+	var locked bool
+	err = tx.QueryRowContext(r.ctx,
+		"SELECT pg_try_advisory_xact_lock(hashtext('outbox:' || $1))", aggregateID,
+	).Scan(&locked)
+	if err != nil || !locked {
+		return err
+	}
+
+	rows, err := tx.QueryContext(r.ctx,
+		`SELECT id, aggregate_id, event_type, payload, created_at FROM outbox_events
+		 WHERE aggregate_id = $1 AND sent_at IS NULL
+		 ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED`,
+		aggregateID, outboxBatchSize,
+	)
+	if err != nil {
+		return err
+	}
+	var events []OutboxEvent
+	for rows.Next() {
+		var ev OutboxEvent
+		if err := rows.Scan(&ev.ID, &ev.AggregateID, &ev.Type, &ev.Payload, &ev.CreatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		events = append(events, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var sent []int64
+	var pubErr error
+	for _, ev := range events {
+		if pubErr = r.pub.Publish(r.ctx, ev); pubErr != nil {
+			break
+		}
+		sent = append(sent, ev.ID)
+	}
+
+	if len(sent) > 0 {
+		// If this update or the commit fails the events are published again
+		// on the next pass; consumers dedupe on id.
+		_, err = tx.ExecContext(r.ctx,
+			"UPDATE outbox_events SET sent_at = CURRENT_TIMESTAMP WHERE id = ANY($1)",
+			pq.Array(sent),
+		)
+		if err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return pubErr
+}
+
+func (r *OutboxRelay) prune() error {
+	res, err := r.db.ExecContext(r.ctx,
+		"DELETE FROM outbox_events WHERE sent_at < $1",
+		time.Now().UTC().Add(-r.retention),
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("outbox pruned %d sent events", n)
+	}
+	return nil
+}
+
+// replayOutbox marks events created at or after from as unsent, so the relay
+// publishes them again. An empty eventType matches every type. Only events
+// still within the retention window can be replayed.
+func replayOutbox(ctx context.Context, db *sql.DB, from time.Time, eventType string) (int64, error) {
+	res, err := db.ExecContext(ctx,
+		`UPDATE outbox_events SET sent_at = NULL
+		 WHERE created_at >= $1 AND ($2 = '' OR event_type = $2) AND sent_at IS NOT NULL`,
+		from.UTC(), eventType,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// outboxCommand implements "outbox replay -from <RFC 3339> [-type <event>]".
+func outboxCommand(db *sql.DB, args []string) error {
+	if len(args) == 0 || args[0] != "replay" {
+		return fmt.Errorf("usage: %s outbox replay -from <RFC 3339> [-type <event>]", os.Args[0])
+	}
+
+	fs := flag.NewFlagSet("outbox replay", flag.ContinueOnError)
+	fromStr := fs.String("from", "", "replay events created at or after this time (RFC 3339)")
+	eventType := fs.String("type", "", "only replay events of this type")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	from, err := time.Parse(time.RFC3339, *fromStr)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+
+	n, err := replayOutbox(context.Background(), db, from, *eventType)
+	if err != nil {
+		return err
+	}
+	log.Printf("outbox replay: %d events queued for republishing", n)
+	return nil
+}
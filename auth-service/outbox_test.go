@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOutboxDB is a database/sql driver holding an in-memory outbox_events
+// table. It understands the statements writeOutbox and one relay pass run;
+// every advisory lock is granted.
+type fakeOutboxDB struct {
+	mu     sync.Mutex
+	events []*fakeOutboxEvent
+}
+
+type fakeOutboxEvent struct {
+	OutboxEvent
+	sent bool
+}
+
+func (f *fakeOutboxDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeOutboxDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeOutboxDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeOutboxDB: prepared statements not supported")
+}
+func (f *fakeOutboxDB) Close() error              { return nil }
+func (f *fakeOutboxDB) Begin() (driver.Tx, error) { return f, nil }
+func (f *fakeOutboxDB) Commit() error             { return nil }
+func (f *fakeOutboxDB) Rollback() error           { return nil }
+
+func (f *fakeOutboxDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO outbox_events"):
+		f.events = append(f.events, &fakeOutboxEvent{OutboxEvent: OutboxEvent{
+			ID:          int64(len(f.events) + 1),
+			AggregateID: args[0].Value.(string),
+			Type:        args[1].Value.(string),
+			Payload:     args[2].Value.([]byte),
+			CreatedAt:   time.Now(),
+		}})
+	case query == "UPDATE outbox_events SET sent_at = CURRENT_TIMESTAMP WHERE id = ANY($1)":
+		for _, s := range strings.Split(strings.Trim(args[0].Value.(string), "{}"), ",") {
+			id, _ := strconv.ParseInt(s, 10, 64)
+			f.events[id-1].sent = true
+		}
+	default:
+		return nil, fmt.Errorf("fakeOutboxDB: unexpected exec %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (f *fakeOutboxDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "SELECT aggregate_id FROM outbox_events WHERE sent_at IS NULL"):
+		rows := &fakeRows{cols: []string{"aggregate_id"}}
+		var seen []string
+		for _, ev := range f.events {
+			if !ev.sent && !slices.Contains(seen, ev.AggregateID) {
+				seen = append(seen, ev.AggregateID)
+				rows.rows = append(rows.rows, []driver.Value{ev.AggregateID})
+			}
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT pg_try_advisory_xact_lock"):
+		return &fakeRows{cols: []string{"locked"}, rows: [][]driver.Value{{true}}}, nil
+	case strings.HasPrefix(query, "SELECT id, aggregate_id, event_type, payload, created_at FROM outbox_events"):
+		rows := &fakeRows{cols: []string{"id", "aggregate_id", "event_type", "payload", "created_at"}}
+		for _, ev := range f.events {
+			if !ev.sent && ev.AggregateID == args[0].Value.(string) {
+				rows.rows = append(rows.rows, []driver.Value{ev.ID, ev.AggregateID, ev.Type, []byte(ev.Payload), ev.CreatedAt})
+			}
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("fakeOutboxDB: unexpected query %q", query)
+}
+
+// failOncePublisher fails the first attempt to publish event failID.
+type failOncePublisher struct {
+	MemoryPublisher
+	failID int64
+}
+
+func (p *failOncePublisher) Publish(ctx context.Context, ev OutboxEvent) error {
+	if ev.ID == p.failID {
+		p.failID = 0
+		return errors.New("broker unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, ev)
+}
+
+// publishedIDs groups the ids of events by aggregate, in publish order.
+func publishedIDs(events []OutboxEvent) map[string][]int64 {
+	ids := map[string][]int64{}
+	for _, ev := range events {
+		ids[ev.AggregateID] = append(ids[ev.AggregateID], ev.ID)
+	}
+	return ids
+}
+
+// TestOutboxRelayOrder has the broker fail in the middle of one user's
+// events: the relay holds back that user's later events until the failed
+// one is published, and carries on with other users meanwhile.
+func TestOutboxRelayOrder(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	fake := &fakeOutboxDB{}
+	db := sql.OpenDB(fake)
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	// Events 1, 3 and 5 are user 1's; 2 and 4 are user 2's.
+	for i := 1; i <= 5; i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeOutbox(ctx, tx, fmt.Sprintf("user:%d", 2-i%2), "user.updated", map[string]int{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pub := &failOncePublisher{failID: 3}
+	relay := &OutboxRelay{db: db, pub: pub, ctx: ctx}
+	if err := relay.relay(); err != nil {
+		t.Fatal(err)
+	}
+	got := publishedIDs(pub.Events())
+	if !slices.Equal(got["user:1"], []int64{1}) || !slices.Equal(got["user:2"], []int64{2, 4}) {
+		t.Fatalf("after a failed publish: %v, want user:1 stopped at 1 and user:2 done", got)
+	}
+
+	if err := relay.relay(); err != nil {
+		t.Fatal(err)
+	}
+	got = publishedIDs(pub.Events())
+	if !slices.Equal(got["user:1"], []int64{1, 3, 5}) || !slices.Equal(got["user:2"], []int64{2, 4}) {
+		t.Errorf("after the retry: %v, want each user's events once and in order", got)
+	}
+	for _, ev := range fake.events {
+		if !ev.sent {
+			t.Errorf("event %d not marked sent", ev.ID)
+		}
+	}
+}
+
+// TestOutboxRelayPostgres runs two relays against a real database when
+// TEST_DATABASE_URL names one: every event is published exactly once,
+// each user's in order, and a replay publishes them again. It migrates
+// the database and empties outbox_events, so point it at a scratch
+// database.
+func TestOutboxRelayPostgres(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	quietLog(t)
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	if err := (&Migrator{db: db}).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "TRUNCATE outbox_events RESTART IDENTITY"); err != nil {
+		t.Fatal(err)
+	}
+	const users, perUser = 5, 20
+	for i := range users * perUser {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeOutbox(ctx, tx, fmt.Sprintf("user:%d", i%users), "user.updated", map[string]int{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitForRelays := func() []OutboxEvent {
+		pubs := []*MemoryPublisher{{}, {}}
+		var relays []*OutboxRelay
+		for _, pub := range pubs {
+			relays = append(relays, NewOutboxRelay(db, pub, 10*time.Millisecond, time.Hour))
+		}
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			var pending int
+			if err := db.QueryRowContext(ctx, "SELECT count(*) FROM outbox_events WHERE sent_at IS NULL").Scan(&pending); err != nil {
+				t.Fatal(err)
+			}
+			if pending == 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%d events still pending", pending)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		var all []OutboxEvent
+		for i, r := range relays {
+			if err := r.Close(ctx); err != nil {
+				t.Fatal(err)
+			}
+			events := pubs[i].Events()
+			for agg, ids := range publishedIDs(events) {
+				if !slices.IsSorted(ids) {
+					t.Errorf("relay %d published %s out of order: %v", i, agg, ids)
+				}
+			}
+			all = append(all, events...)
+		}
+		return all
+	}
+	checkOnce := func(events []OutboxEvent) {
+		t.Helper()
+		seen := map[int64]bool{}
+		for _, ev := range events {
+			if seen[ev.ID] {
+				t.Errorf("event %d published twice", ev.ID)
+			}
+			seen[ev.ID] = true
+		}
+		if len(seen) != users*perUser {
+			t.Errorf("%d events published, want %d", len(seen), users*perUser)
+		}
+	}
+
+	checkOnce(waitForRelays())
+
+	n, err := replayOutbox(ctx, db, time.Time{}, "user.updated")
+	if err != nil || n != users*perUser {
+		t.Fatalf("replay: %d, %v", n, err)
+	}
+	checkOnce(waitForRelays())
+}
@@ -0,0 +1,137 @@
+package pagination
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+type testKey struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+var testSecret = []byte("cursor-test-secret")
+
+// Any key survives a round trip through Encode and Decode. Timestamps
+// span 1970-2106 at microsecond precision, as Postgres stores them.
+func TestCursorRoundTrip(t *testing.T) {
+	prop := func(id int64, secs, micros uint32) bool {
+		createdAt := time.Unix(int64(secs), int64(micros%1e6)*1e3).UTC()
+		key := testKey{CreatedAt: createdAt, ID: id}
+		got, err := Decode[testKey](Encode(key, testSecret), testSecret)
+		return err == nil && got.ID == key.ID && got.CreatedAt.Equal(key.CreatedAt)
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// resign rebuilds a cursor from raw bytes with a valid HMAC, so a test can
+// change one thing and leave the signature good.
+func resign(body []byte) string {
+	return base64.RawURLEncoding.EncodeToString(append(body, mac(body, testSecret)...))
+}
+
+func TestDecodeRejects(t *testing.T) {
+	key := testKey{CreatedAt: time.Unix(1_700_000_000, 0).UTC(), ID: 7}
+	valid := Encode(key, testSecret)
+	raw, err := base64.RawURLEncoding.DecodeString(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := raw[:len(raw)-sha256.Size]
+	flip := func(i int) string {
+		b := append([]byte(nil), raw...)
+		b[i] ^= 1
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	tests := []struct {
+		name, cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString(raw)},
+		{"too short for an HMAC", base64.RawURLEncoding.EncodeToString(raw[:sha256.Size])},
+		{"tampered HMAC", flip(len(raw) - 1)},
+		{"tampered payload", flip(len(raw) - sha256.Size - 2)},
+		{"tampered version", flip(0)},
+		{"wrong version, validly signed", resign(append([]byte{cursorVersion + 1}, body[1:]...))},
+		{"no version, validly signed", resign(append([]byte(nil), body[1:]...))},
+		{"other secret", Encode(key, []byte("other secret"))},
+		{"not a key, validly signed", Encode([]int{1, 2}, testSecret)},
+	}
+	for _, tt := range tests {
+		if got, err := Decode[testKey](tt.cursor, testSecret); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: got %+v, %v; want ErrInvalidCursor", tt.name, got, err)
+		}
+	}
+
+	if got, err := Decode[testKey](valid, testSecret); err != nil || got.ID != key.ID {
+		t.Errorf("the untouched cursor: got %+v, %v", got, err)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	valid := Encode(testKey{ID: 7}, testSecret)
+	tests := []struct {
+		query   string
+		wantOK  bool
+		wantErr bool
+	}{
+		{"", false, false},
+		{"?cursor=", false, false},
+		{"?cursor=" + valid, true, false},
+		{"?cursor=" + valid[:len(valid)-2], false, true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/items"+tt.query, nil)
+		key, ok, err := FromRequest[testKey](r, testSecret)
+		if ok != tt.wantOK || (err != nil) != tt.wantErr {
+			t.Errorf("%q: ok %v, err %v", tt.query, ok, err)
+			continue
+		}
+		if ok && key.ID != 7 {
+			t.Errorf("%q: key %+v", tt.query, key)
+		}
+		var appErr *apperror.Error
+		if err != nil && (!errors.As(err, &appErr) || appErr.Status != http.StatusBadRequest || appErr.Code != "invalid_cursor") {
+			t.Errorf("%q: got %v, want 400 invalid_cursor", tt.query, err)
+		}
+	}
+}
+
+// FuzzDecode feeds arbitrary cursors to Decode. Only cursors we signed
+// decode; anything else is ErrInvalidCursor, never a panic.
+func FuzzDecode(f *testing.F) {
+	signed := testKey{CreatedAt: time.Unix(1_700_000_000, 0).UTC(), ID: 7}
+	valid := Encode(signed, testSecret)
+	f.Add(valid)
+	f.Add("")
+	f.Add("AA")
+	f.Add(valid[:len(valid)-1])
+	f.Add("=" + valid)
+	f.Add(Encode(testKey{ID: 7}, []byte("other secret")))
+	f.Add(Encode(map[string]any{"id": "7"}, testSecret))
+
+	f.Fuzz(func(t *testing.T, cursor string) {
+		key, err := Decode[testKey](cursor, testSecret)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("got %v, want ErrInvalidCursor", err)
+			}
+			return
+		}
+		if key.ID != signed.ID || !key.CreatedAt.Equal(signed.CreatedAt) {
+			t.Fatalf("decoded %q, which we never signed, to %+v", cursor, key)
+		}
+	})
+}
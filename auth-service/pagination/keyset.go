@@ -0,0 +1,45 @@
+package pagination
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Keyset describes a query's sort order so the same columns drive ORDER BY
+// and the condition that resumes after a cursor. The columns must together
+// be unique (end with the primary key) or rows sharing a sort key get
+// skipped between pages. Column names are written into the SQL verbatim,
+// so they must be constants, never client input.
+type Keyset struct {
+	Columns []string
+	// Desc sorts newest/highest first.
+	Desc bool
+}
+
+// OrderBy returns the ORDER BY list, e.g. "created_at, id".
+func (k Keyset) OrderBy() string {
+	if !k.Desc {
+		return strings.Join(k.Columns, ", ")
+	}
+	cols := make([]string, len(k.Columns))
+	for i, c := range k.Columns {
+		cols[i] = c + " DESC"
+	}
+	return strings.Join(cols, ", ")
+}
+
+// After returns the condition selecting rows after the cursor row, using
+// placeholders $firstArg onwards, e.g. "(created_at, id) > ($1, $2)". The
+// caller appends the key values as args in column order. A row comparison
+// rather than ORs of columns lets Postgres use a composite index.
+func (k Keyset) After(firstArg int) string {
+	params := make([]string, len(k.Columns))
+	for i := range k.Columns {
+		params[i] = "$" + strconv.Itoa(firstArg+i)
+	}
+	op := " > "
+	if k.Desc {
+		op = " < "
+	}
+	return "(" + strings.Join(k.Columns, ", ") + ")" + op + "(" + strings.Join(params, ", ") + ")"
+}
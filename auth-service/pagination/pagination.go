@@ -0,0 +1,54 @@
+// Package pagination implements keyset pagination for list endpoints: page
+// size parsing, opaque signed cursors, the SQL to resume after a cursor and
+// the {items, next_cursor} response envelope every list shares.
+package pagination
+
+import (
+	"net/http"
+	"strconv"
+
+	"resilient-auth-service/apperror"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ListResponse is the body of every paginated list. NextCursor is null on
+// the last page; otherwise passing it back as ?cursor= gets the next one.
+type ListResponse[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// Limit reads ?limit=, defaulting to DefaultLimit and clamping to MaxLimit.
+// Anything that isn't a positive integer is a 400.
+func Limit(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return DefaultLimit, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, apperror.BadRequest("invalid_parameter", "Invalid limit").
+			WithField("limit", "invalid_value", "must be a positive integer")
+	}
+	return min(n, MaxLimit), nil
+}
+
+// NewList builds the response for a page fetched with LIMIT limit+1: the
+// extra row, if present, only tells us there's a next page and is dropped.
+// cursor is called on the last item kept to build next_cursor.
+func NewList[T any](items []T, limit int, cursor func(T) string) ListResponse[T] {
+	resp := ListResponse[T]{Items: items}
+	if resp.Items == nil {
+		resp.Items = []T{}
+	}
+	if len(items) > limit {
+		resp.Items = items[:limit]
+		next := cursor(resp.Items[limit-1])
+		resp.NextCursor = &next
+	}
+	return resp
+}
@@ -0,0 +1,76 @@
+package pagination
+
+import (
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"resilient-auth-service/apperror"
+)
+
+func TestLimit(t *testing.T) {
+	for _, tt := range []struct {
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{"", DefaultLimit, false},
+		{"?limit=1", 1, false},
+		{"?limit=100", 100, false},
+		{"?limit=101", MaxLimit, false},
+		{"?limit=0", 0, true},
+		{"?limit=-5", 0, true},
+		{"?limit=ten", 0, true},
+	} {
+		got, err := Limit(httptest.NewRequest("GET", "/"+tt.query, nil))
+		var appErr *apperror.Error
+		if tt.wantErr != (err != nil) || got != tt.want || (err != nil && !errors.As(err, &appErr)) {
+			t.Errorf("%q: %d, %v; want %d, error %v", tt.query, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewList(t *testing.T) {
+	cursor := func(n int) string { return strconv.Itoa(n) }
+	for _, tt := range []struct {
+		name  string
+		items []int
+		want  []int
+		next  string // "" for none
+	}{
+		{"empty", nil, []int{}, ""},
+		{"last page", []int{1, 2}, []int{1, 2}, ""},
+		{"full last page", []int{1, 2, 3}, []int{1, 2, 3}, ""},
+		{"more to come", []int{1, 2, 3, 4}, []int{1, 2, 3}, "3"},
+	} {
+		got := NewList(tt.items, 3, cursor)
+		next := ""
+		if got.NextCursor != nil {
+			next = *got.NextCursor
+		}
+		if !slices.Equal(got.Items, tt.want) || got.Items == nil || next != tt.next {
+			t.Errorf("%s: items %v, next %q; want %v, %q", tt.name, got.Items, next, tt.want, tt.next)
+		}
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	for _, tt := range []struct {
+		k              Keyset
+		firstArg       int
+		orderBy, after string
+	}{
+		{Keyset{Columns: []string{"id"}}, 1, "id", "(id) > ($1)"},
+		{Keyset{Columns: []string{"created_at", "id"}}, 3, "created_at, id", "(created_at, id) > ($3, $4)"},
+		{Keyset{Columns: []string{"created_at", "id"}, Desc: true}, 3, "created_at DESC, id DESC", "(created_at, id) < ($3, $4)"},
+	} {
+		if got := tt.k.OrderBy(); got != tt.orderBy {
+			t.Errorf("%+v OrderBy: %q, want %q", tt.k, got, tt.orderBy)
+		}
+		if got := tt.k.After(tt.firstArg); got != tt.after {
+			t.Errorf("%+v After(%d): %q, want %q", tt.k, tt.firstArg, got, tt.after)
+		}
+	}
+}
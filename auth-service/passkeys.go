@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+// passkeyCeremonyTTL is how long a registration or login ceremony can wait
+// for the authenticator; it is also the timeout the browser is given.
+const passkeyCeremonyTTL = 5 * time.Minute
+
+// newWebAuthn configures the relying party from cfg.AppURL: passkeys are
+// bound to the frontend's host name, and only its origin may use them.
+func newWebAuthn(cfg Config) (*webauthn.WebAuthn, error) {
+	u, err := url.Parse(cfg.AppURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("passkeys: APP_URL %q has no host name", cfg.AppURL)
+	}
+	return webauthn.New(&webauthn.Config{
+		RPID:          u.Hostname(),
+		RPDisplayName: u.Hostname(),
+		RPOrigins:     []string{u.Scheme + "://" + u.Host},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL},
+		},
+	})
+}
+
+// passkeyUserHandle is the WebAuthn user handle for a user: their ID. The
+// authenticator hands it back at a discoverable login, which is how the
+// login finds whose credential it is.
+func passkeyUserHandle(userID int) []byte {
+	return []byte(strconv.Itoa(userID))
+}
+
+// passkeyUser is a User as go-webauthn sees one.
+type passkeyUser struct {
+	User
+	credentials []webauthn.Credential
+}
+
+func (u passkeyUser) WebAuthnID() []byte                         { return passkeyUserHandle(u.ID) }
+func (u passkeyUser) WebAuthnName() string                       { return u.Email }
+func (u passkeyUser) WebAuthnDisplayName() string                { return u.Email }
+func (u passkeyUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+// passkeyCeremony is what a begin handler keeps for its finish handler.
+// UserID is 0 for a login, which doesn't know the user yet.
+type passkeyCeremony struct {
+	UserID  int                  `json:"user_id"`
+	Session webauthn.SessionData `json:"session"`
+}
+
+func passkeyCeremonyKey(id string) string {
+	return "webauthn_ceremony:" + id
+}
+
+func (s *Server) savePasskeyCeremony(r *http.Request, ceremony passkeyCeremony) (string, error) {
+	id, err := newUUID()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(ceremony)
+	if err != nil {
+		return "", err
+	}
+	return id, s.rdb.Set(r.Context(), passkeyCeremonyKey(id), data, passkeyCeremonyTTL).Err()
+}
+
+// takePasskeyCeremony removes and returns a ceremony, so each can be
+// finished once, or (nil, nil) if it doesn't exist or has expired.
+func (s *Server) takePasskeyCeremony(r *http.Request, id string) (*passkeyCeremony, error) {
+	data, err := s.rdb.GetDel(r.Context(), passkeyCeremonyKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ceremony passkeyCeremony
+	if err := json.Unmarshal(data, &ceremony); err != nil {
+		return nil, err
+	}
+	return &ceremony, nil
+}
+
+type passkeyBeginResponse struct {
+	CeremonyID string `json:"ceremony_id"`
+	// Options is passed to navigator.credentials.create or .get.
+	Options any `json:"options"`
+}
+
+type passkeyFinishRequest struct {
+	CeremonyID secret `json:"ceremony_id"`
+	// Credential is the PublicKeyCredential the browser returned, as JSON.
+	Credential json.RawMessage `json:"credential"`
+}
+
+func (req passkeyFinishRequest) validate() error {
+	var v validator
+	v.required("ceremony_id", string(req.CeremonyID))
+	v.required("credential", string(req.Credential))
+	return v.err()
+}
+
+// loadPasskeyFinish decodes a finish request and takes its ceremony,
+// answering the request itself if either fails.
+func (s *Server) loadPasskeyFinish(w http.ResponseWriter, r *http.Request) (*passkeyCeremony, passkeyFinishRequest, bool) {
+	var req passkeyFinishRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return nil, req, false
+	}
+	ceremony, err := s.takePasskeyCeremony(r, string(req.CeremonyID))
+	if err != nil {
+		log.Println("passkey ceremony load error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return nil, req, false
+	}
+	if ceremony == nil {
+		apperror.WriteError(w, r, apperror.BadRequest("passkey_ceremony_invalid", "Passkey ceremony expired or invalid"))
+		return nil, req, false
+	}
+	return ceremony, req, true
+}
+
+// loadPasskeyUser returns a user with their credentials.
+func (s *Server) loadPasskeyUser(r *http.Request, userID int) (passkeyUser, error) {
+	user, err := s.users.GetByID(r.Context(), userID)
+	if err != nil {
+		return passkeyUser{}, err
+	}
+	creds, err := s.passkeys.List(r.Context(), userID)
+	if err != nil {
+		return passkeyUser{}, err
+	}
+	return passkeyUser{User: user, credentials: creds}, nil
+}
+
+// passkeyRegisterBeginHandler serves POST /webauthn/register/begin. The
+// passkey must be discoverable and verify the user, since it is all a
+// passkey login asks for; credentials the user already has are excluded
+// so the same authenticator isn't registered twice.
+func (s *Server) passkeyRegisterBeginHandler(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := auth.UserFromContext(r.Context())
+	if !ok || authUser.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+	user, err := s.loadPasskeyUser(r, authUser.ID)
+	if errors.Is(err, ErrNotFound) {
+		apperror.WriteError(w, r, apperror.NotFound("user_not_found", "User not found"))
+		return
+	}
+	if err != nil {
+		log.Println("passkey register error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	options, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		}),
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		log.Println("passkey register error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+	id, err := s.savePasskeyCeremony(r, passkeyCeremony{UserID: user.ID, Session: *session})
+	if err != nil {
+		log.Println("passkey ceremony save error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+	writeJSON(w, http.StatusOK, passkeyBeginResponse{CeremonyID: id, Options: options})
+}
+
+// passkeyRegisterFinishHandler serves POST /webauthn/register/finish:
+// {"ceremony_id": "...", "credential": {...}}. The ceremony must have
+// been begun by the same user.
+func (s *Server) passkeyRegisterFinishHandler(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := auth.UserFromContext(r.Context())
+	if !ok || authUser.ID == 0 {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+	ceremony, req, ok := s.loadPasskeyFinish(w, r)
+	if !ok {
+		return
+	}
+	if ceremony.UserID != authUser.ID {
+		apperror.WriteError(w, r, apperror.BadRequest("passkey_ceremony_invalid", "Passkey ceremony expired or invalid"))
+		return
+	}
+	user, err := s.loadPasskeyUser(r, authUser.ID)
+	if errors.Is(err, ErrNotFound) {
+		apperror.WriteError(w, r, apperror.NotFound("user_not_found", "User not found"))
+		return
+	}
+	if err != nil {
+		log.Println("passkey register error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("passkey_invalid", "Passkey response is invalid"))
+		return
+	}
+	cred, err := s.webAuthn.CreateCredential(user, ceremony.Session, parsed)
+	if err != nil {
+		log.Println("passkey registration refused:", err)
+		apperror.WriteError(w, r, apperror.BadRequest("passkey_invalid", "Passkey response is invalid"))
+		return
+	}
+	if err := s.passkeys.Add(r.Context(), user.ID, *cred); err != nil {
+		log.Println("passkey store error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "user.passkey_register")
+	ev.Level = "warning"
+	ev.ActorID = &user.ID
+	s.auditor.Record(r.Context(), ev)
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// passkeyLoginBeginHandler serves POST /webauthn/login/begin. The login
+// is discoverable: no email is asked for, and the browser offers whichever
+// passkeys it holds for this site.
+func (s *Server) passkeyLoginBeginHandler(w http.ResponseWriter, r *http.Request) {
+	options, session, err := s.webAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		log.Println("passkey login error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+	id, err := s.savePasskeyCeremony(r, passkeyCeremony{Session: *session})
+	if err != nil {
+		log.Println("passkey ceremony save error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+	writeJSON(w, http.StatusOK, passkeyBeginResponse{CeremonyID: id, Options: options})
+}
+
+// passkeyLoginFinishHandler serves POST /webauthn/login/finish. A passkey
+// proves possession and, since user verification is required, the PIN or
+// biometric that unlocks it, so the login completes without the TOTP and
+// device steps a password login goes through.
+func (s *Server) passkeyLoginFinishHandler(w http.ResponseWriter, r *http.Request) {
+	ceremony, req, ok := s.loadPasskeyFinish(w, r)
+	if !ok {
+		return
+	}
+	if ceremony.UserID != 0 {
+		apperror.WriteError(w, r, apperror.BadRequest("passkey_ceremony_invalid", "Passkey ceremony expired or invalid"))
+		return
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("passkey_invalid", "Passkey response is invalid"))
+		return
+	}
+
+	// go-webauthn wraps whatever the lookup returns, so it is kept here to
+	// tell an outage from an unknown passkey.
+	var (
+		user      passkeyUser
+		lookupErr error
+	)
+	cred, err := s.webAuthn.ValidateDiscoverableLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		userID, err := strconv.Atoi(string(userHandle))
+		if err != nil {
+			lookupErr = ErrNotFound
+			return nil, lookupErr
+		}
+		user, lookupErr = s.loadPasskeyUser(r, userID)
+		return user, lookupErr
+	}, ceremony.Session, parsed)
+	if lookupErr != nil && !errors.Is(lookupErr, ErrNotFound) {
+		log.Println("passkey login lookup error:", lookupErr)
+		writeDBError(w, r, lookupErr)
+		return
+	}
+	if err == nil && cred.Authenticator.CloneWarning {
+		err = errors.New("sign count went backwards; the authenticator may be cloned")
+	}
+	if err != nil {
+		s.failPasskeyLogin(w, r, user, err)
+		return
+	}
+
+	if err := s.passkeys.Update(r.Context(), user.ID, *cred); err != nil {
+		// Only the sign count and flags are lost; the next login still
+		// checks against the stored ones.
+		log.Println("passkey update error:", err)
+	}
+	s.completeLogin(w, r, &AuthFlow{UserID: user.ID, Email: user.Email})
+}
+
+func (s *Server) failPasskeyLogin(w http.ResponseWriter, r *http.Request, user passkeyUser, err error) {
+	log.Println("passkey login refused:", err)
+	loginFailuresTotal.WithLabelValues("passkey").Inc()
+	ev := s.loginAuditEvent(r, "login.failure", map[string]any{"reason": "passkey"})
+	if user.ID != 0 {
+		ev.ActorID = &user.ID
+		ev.Metadata["email"] = user.Email
+	}
+	s.auditor.Record(r.Context(), ev)
+
+	apperror.WriteError(w, r, apperror.Unauthorized("invalid_credentials", "Invalid credentials"))
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+
+	"resilient-auth-service/flags"
+)
+
+// softAuthenticator is a passkey authenticator in software: one ES256
+// credential for one user, answering the options the begin handlers send
+// the way a browser and a platform authenticator would.
+type softAuthenticator struct {
+	t      *testing.T
+	origin string
+	rpID   string
+	key    *ecdsa.PrivateKey
+	credID []byte
+	handle []byte
+	count  uint32
+}
+
+func newSoftAuthenticator(t *testing.T, appURL string) *softAuthenticator {
+	t.Helper()
+	u, err := url.Parse(appURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credID := make([]byte, 16)
+	rand.Read(credID)
+	return &softAuthenticator{t: t, origin: u.Scheme + "://" + u.Host, rpID: u.Hostname(), key: key, credID: credID}
+}
+
+var b64 = base64.RawURLEncoding
+
+// clientData is the clientDataJSON for a ceremony of the given type.
+func (a *softAuthenticator) clientData(typ string, options json.RawMessage) []byte {
+	var opts struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+		} `json:"publicKey"`
+	}
+	if err := json.Unmarshal(options, &opts); err != nil || opts.PublicKey.Challenge == "" {
+		a.t.Fatalf("options %s: %v", options, err)
+	}
+	data, _ := json.Marshal(map[string]any{"type": typ, "challenge": opts.PublicKey.Challenge, "origin": a.origin})
+	return data
+}
+
+// authData is the authenticator data, with the credential attached at
+// registration. The flags say the user was present and verified.
+func (a *softAuthenticator) authData(attest bool) []byte {
+	rpHash := sha256.Sum256([]byte(a.rpID))
+	data := append([]byte{}, rpHash[:]...)
+	flags := byte(0x01 | 0x04)
+	if attest {
+		flags |= 0x40
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.count)
+	if !attest {
+		return data
+	}
+	coseKey, err := webauthncbor.Marshal(map[int]any{
+		1: 2, 3: -7, -1: 1,
+		-2: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		-3: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	data = append(data, make([]byte, 16)...) // AAGUID
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credID)))
+	data = append(data, a.credID...)
+	return append(data, coseKey...)
+}
+
+// register answers navigator.credentials.create.
+func (a *softAuthenticator) register(options json.RawMessage) string {
+	var opts struct {
+		PublicKey struct {
+			User struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"publicKey"`
+	}
+	json.Unmarshal(options, &opts)
+	handle, err := b64.DecodeString(opts.PublicKey.User.ID)
+	if err != nil {
+		a.t.Fatalf("user handle %q: %v", opts.PublicKey.User.ID, err)
+	}
+	a.handle = handle
+
+	attestation, err := webauthncbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": a.authData(true)})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	cred, _ := json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credID),
+		"rawId": b64.EncodeToString(a.credID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64.EncodeToString(a.clientData("webauthn.create", options)),
+			"attestationObject": b64.EncodeToString(attestation),
+		},
+	})
+	return string(cred)
+}
+
+// login answers navigator.credentials.get, returning the user handle as a
+// discoverable credential does.
+func (a *softAuthenticator) login(options json.RawMessage) string {
+	a.count++
+	authData := a.authData(false)
+	clientData := a.clientData("webauthn.get", options)
+	clientHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	cred, _ := json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credID),
+		"rawId": b64.EncodeToString(a.credID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64.EncodeToString(clientData),
+			"authenticatorData": b64.EncodeToString(authData),
+			"signature":         b64.EncodeToString(sig),
+			"userHandle":        b64.EncodeToString(a.handle),
+		},
+	})
+	return string(cred)
+}
+
+type passkeyBegin struct {
+	CeremonyID string          `json:"ceremony_id"`
+	Options    json.RawMessage `json:"options"`
+}
+
+func beginPasskey(t *testing.T, h http.Handler, path string, cookies ...*http.Cookie) passkeyBegin {
+	t.Helper()
+	rec := postJSON(h, path, "", cookies...)
+	var begin passkeyBegin
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &begin) != nil || begin.CeremonyID == "" {
+		t.Fatalf("%s: %d %s", path, rec.Code, rec.Body)
+	}
+	return begin
+}
+
+func finishPasskey(h http.Handler, path, ceremonyID, credential string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]any{"ceremony_id": ceremonyID, "credential": json.RawMessage(credential)})
+	return postJSON(h, path, string(body), cookies...)
+}
+
+func enablePasskeys(t *testing.T, s *Server) {
+	t.Helper()
+	if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagPasskeys, Enabled: true, Percentage: 100}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPasskeys registers a passkey and logs in with it, without an email
+// address, as a browser would.
+func TestPasskeys(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	enablePasskeys(t, s)
+	useFakeRefreshDB(t, s)
+	h := s.Handler()
+	user := newTestUser(t, s, "a@example.com", "user")
+	cookie := accessTokenCookie(t, s, user)
+	authn := newSoftAuthenticator(t, s.cfg.AppURL)
+
+	begin := beginPasskey(t, h, "/v1/webauthn/register/begin", cookie)
+	cred := authn.register(begin.Options)
+	if rec := finishPasskey(h, "/v1/webauthn/register/finish", begin.CeremonyID, cred, cookie); rec.Code != http.StatusCreated {
+		t.Fatalf("register: %d %s", rec.Code, rec.Body)
+	}
+	stored, _ := s.passkeys.List(context.Background(), user.ID)
+	if len(stored) != 1 {
+		t.Fatalf("%d passkeys stored, want 1", len(stored))
+	}
+
+	// The ceremony is single use.
+	rec := finishPasskey(h, "/v1/webauthn/register/finish", begin.CeremonyID, cred, cookie)
+	if env := readEnvelope(t, rec, http.StatusBadRequest); env.Error.Code != "passkey_ceremony_invalid" {
+		t.Errorf("replayed registration: %q, want passkey_ceremony_invalid", env.Error.Code)
+	}
+
+	// The same authenticator isn't offered for registration again.
+	again := beginPasskey(t, h, "/v1/webauthn/register/begin", cookie)
+	var opts struct {
+		PublicKey struct {
+			ExcludeCredentials []struct {
+				ID string `json:"id"`
+			} `json:"excludeCredentials"`
+		} `json:"publicKey"`
+	}
+	json.Unmarshal(again.Options, &opts)
+	if len(opts.PublicKey.ExcludeCredentials) != 1 || opts.PublicKey.ExcludeCredentials[0].ID != b64.EncodeToString(authn.credID) {
+		t.Errorf("excluded credentials %+v, want the registered one", opts.PublicKey.ExcludeCredentials)
+	}
+
+	login := beginPasskey(t, h, "/v1/webauthn/login/begin")
+	assertion := authn.login(login.Options)
+	rec = finishPasskey(h, "/v1/webauthn/login/finish", login.CeremonyID, assertion)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body)
+	}
+	if names := cookieNames(rec); !names["auth_token"] || !names["refresh_token"] || !names["session_id"] {
+		t.Errorf("login set cookies %v", names)
+	}
+	stored, _ = s.passkeys.List(context.Background(), user.ID)
+	if stored[0].Authenticator.SignCount != authn.count {
+		t.Errorf("stored sign count %d, want %d", stored[0].Authenticator.SignCount, authn.count)
+	}
+
+	rec = finishPasskey(h, "/v1/webauthn/login/finish", login.CeremonyID, assertion)
+	if env := readEnvelope(t, rec, http.StatusBadRequest); env.Error.Code != "passkey_ceremony_invalid" {
+		t.Errorf("replayed login: %q, want passkey_ceremony_invalid", env.Error.Code)
+	}
+}
+
+func TestPasskeyLoginRefused(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	enablePasskeys(t, s)
+	useFakeRefreshDB(t, s)
+	h := s.Handler()
+	user := newTestUser(t, s, "a@example.com", "user")
+	cookie := accessTokenCookie(t, s, user)
+	authn := newSoftAuthenticator(t, s.cfg.AppURL)
+	begin := beginPasskey(t, h, "/v1/webauthn/register/begin", cookie)
+	if rec := finishPasskey(h, "/v1/webauthn/register/finish", begin.CeremonyID, authn.register(begin.Options), cookie); rec.Code != http.StatusCreated {
+		t.Fatalf("register: %d %s", rec.Code, rec.Body)
+	}
+
+	for _, tt := range []struct {
+		name string
+		// loginFirst logs in once before tampering.
+		loginFirst bool
+		tamper     func(a *softAuthenticator)
+	}{
+		{"unknown user handle", false, func(a *softAuthenticator) { a.handle = []byte("999") }},
+		{"another key", false, func(a *softAuthenticator) { a.key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader) }},
+		// A counter that doesn't move on suggests a cloned authenticator.
+		{"replayed sign count", true, func(a *softAuthenticator) { a.count = 0 }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			a := *authn
+			a.t = t
+			if tt.loginFirst {
+				login := beginPasskey(t, h, "/v1/webauthn/login/begin")
+				if rec := finishPasskey(h, "/v1/webauthn/login/finish", login.CeremonyID, a.login(login.Options)); rec.Code != http.StatusOK {
+					t.Fatalf("first login: %d %s", rec.Code, rec.Body)
+				}
+			}
+			tt.tamper(&a)
+			login := beginPasskey(t, h, "/v1/webauthn/login/begin")
+			rec := finishPasskey(h, "/v1/webauthn/login/finish", login.CeremonyID, a.login(login.Options))
+			if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "invalid_credentials" {
+				t.Errorf("code %q, want invalid_credentials", env.Error.Code)
+			}
+			if len(rec.Result().Cookies()) != 0 {
+				t.Error("a refused login set cookies")
+			}
+		})
+	}
+}
+
+// TestPasskeysFlagOff checks the endpoints don't exist until the webauthn
+// flag is turned on.
+func TestPasskeysFlagOff(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	h := s.Handler()
+	cookie := accessTokenCookie(t, s, newTestUser(t, s, "a@example.com", "user"))
+	for _, path := range []string{"/v1/webauthn/register/begin", "/v1/webauthn/login/begin"} {
+		if rec := postJSON(h, path, "", cookie); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: %d, want 404", path, rec.Code)
+		}
+	}
+}
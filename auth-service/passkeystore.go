@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// PasskeyStore keeps users' WebAuthn credentials. Each is stored whole,
+// as go-webauthn describes it, so its sign count and flags survive from
+// one login to the next.
+type PasskeyStore interface {
+	Add(ctx context.Context, userID int, cred webauthn.Credential) error
+	List(ctx context.Context, userID int) ([]webauthn.Credential, error)
+	// Update replaces a credential after a login, returning ErrNotFound
+	// if the user has no credential with its ID.
+	Update(ctx context.Context, userID int, cred webauthn.Credential) error
+}
+
+// PostgresPasskeyStore is the PasskeyStore on webauthn_credentials. Every
+// method runs in the user's row-level security scope; a passkey login
+// learns whose credentials to load from the user handle the
+// authenticator returns.
+type PostgresPasskeyStore struct {
+	db *sql.DB
+}
+
+func NewPostgresPasskeyStore(db *sql.DB) *PostgresPasskeyStore {
+	return &PostgresPasskeyStore{db: db}
+}
+
+func (p *PostgresPasskeyStore) Add(ctx context.Context, userID int, cred webauthn.Credential) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	return userScope(ctx, p.db, userID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO webauthn_credentials (user_id, credential_id, user_handle, credential)
+			 VALUES ($1, $2, $3, $4)`,
+			userID, cred.ID, passkeyUserHandle(userID), data)
+		return err
+	})
+}
+
+func (p *PostgresPasskeyStore) List(ctx context.Context, userID int) ([]webauthn.Credential, error) {
+	var creds []webauthn.Credential
+	err := userScope(ctx, p.db, userID, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			"SELECT credential FROM webauthn_credentials WHERE user_id = $1 ORDER BY id", userID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var data []byte
+			if err := rows.Scan(&data); err != nil {
+				return err
+			}
+			var cred webauthn.Credential
+			if err := json.Unmarshal(data, &cred); err != nil {
+				return err
+			}
+			creds = append(creds, cred)
+		}
+		return rows.Err()
+	})
+	return creds, err
+}
+
+func (p *PostgresPasskeyStore) Update(ctx context.Context, userID int, cred webauthn.Credential) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	return userScope(ctx, p.db, userID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE webauthn_credentials SET credential = $1, last_used_at = now()
+			 WHERE user_id = $2 AND credential_id = $3`,
+			data, userID, cred.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// MemoryPasskeyStore is a PasskeyStore in a map, for tests.
+type MemoryPasskeyStore struct {
+	mu    sync.Mutex
+	creds map[int][]webauthn.Credential
+}
+
+func NewMemoryPasskeyStore() *MemoryPasskeyStore {
+	return &MemoryPasskeyStore{creds: map[int][]webauthn.Credential{}}
+}
+
+func (m *MemoryPasskeyStore) Add(ctx context.Context, userID int, cred webauthn.Credential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.creds[userID] = append(m.creds[userID], cred)
+	return nil
+}
+
+func (m *MemoryPasskeyStore) List(ctx context.Context, userID int) ([]webauthn.Credential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.creds[userID]), nil
+}
+
+func (m *MemoryPasskeyStore) Update(ctx context.Context, userID int, cred webauthn.Credential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := slices.IndexFunc(m.creds[userID], func(c webauthn.Credential) bool { return bytes.Equal(c.ID, cred.ID) })
+	if i < 0 {
+		return ErrNotFound
+	}
+	m.creds[userID][i] = cred
+	return nil
+}
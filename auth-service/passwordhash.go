@@ -0,0 +1,210 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Password hashing algorithms, for cfg.PasswordHashAlgorithm.
+const (
+	hashBcrypt   = "bcrypt"
+	hashArgon2ID = "argon2id"
+	hashScrypt   = "scrypt"
+)
+
+// errPasswordMismatch means the password doesn't match the hash.
+var errPasswordMismatch = errors.New("password does not match")
+
+// Hasher is one password hashing algorithm with its parameters. Hashes
+// are self-describing: each starts with $<algorithm>$ and carries its
+// parameters, so ParseHasher can always find the Hasher that verifies
+// one, whatever the current algorithm is.
+//
+// Hashers are comparable; a stored hash whose Hasher differs from the
+// current one is due to be re-hashed.
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify returns nil on a match and errPasswordMismatch otherwise.
+	Verify(password, hash string) error
+}
+
+// newHasher returns the Hasher for new hashes named by
+// PASSWORD_HASH_ALGORITHM.
+func newHasher(cfg Config) (Hasher, error) {
+	switch cfg.PasswordHashAlgorithm {
+	case hashBcrypt:
+		return BcryptHasher{Cost: cfg.BcryptCost}, nil
+	case hashArgon2ID:
+		return defaultArgon2ID, nil
+	case hashScrypt:
+		return defaultScrypt, nil
+	}
+	return nil, errors.New("unknown PASSWORD_HASH_ALGORITHM " + cfg.PasswordHashAlgorithm)
+}
+
+// ParseHasher returns the Hasher that made hash, with the parameters it
+// was made with. An empty, unknown or malformed hash is
+// errInvalidPasswordHash.
+func ParseHasher(hash string) (Hasher, error) {
+	switch {
+	case strings.HasPrefix(hash, "$2"):
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return nil, errInvalidPasswordHash
+		}
+		return BcryptHasher{Cost: cost}, nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		h, _, _, err := parseArgon2ID(hash)
+		return h, err
+	case strings.HasPrefix(hash, "$scrypt$"):
+		h, _, _, err := parseScrypt(hash)
+		return h, err
+	}
+	return nil, errInvalidPasswordHash
+}
+
+// BcryptHasher makes bcrypt hashes ($2a$...).
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hash), err
+}
+
+func (h BcryptHasher) Verify(password, hash string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return errPasswordMismatch
+	}
+	return err
+}
+
+// passwordSaltLen is the salt length of argon2id and scrypt hashes.
+const passwordSaltLen = 16
+
+// Argon2IDHasher makes argon2id hashes in the PHC string format:
+// $argon2id$v=19$m=<KiB>,t=<passes>,p=<threads>$<salt>$<key>.
+type Argon2IDHasher struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+	KeyLen  uint32
+}
+
+// defaultArgon2ID is OWASP's first recommended argon2id configuration.
+var defaultArgon2ID = Argon2IDHasher{Memory: 19 * 1024, Time: 2, Threads: 1, KeyLen: 32}
+
+// maxArgon2Memory bounds the memory a stored hash can ask for, so a
+// corrupted row can't make one login allocate gigabytes.
+const maxArgon2Memory = 1024 * 1024
+
+func (h Argon2IDHasher) Hash(password string) (string, error) {
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (Argon2IDHasher) Verify(password, hash string) error {
+	h, salt, key, err := parseArgon2ID(hash)
+	if err != nil {
+		return err
+	}
+	got := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	if subtle.ConstantTimeCompare(got, key) != 1 {
+		return errPasswordMismatch
+	}
+	return nil
+}
+
+func parseArgon2ID(hash string) (h Argon2IDHasher, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" || parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return h, nil, nil, errInvalidPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.Memory, &h.Time, &h.Threads); err != nil {
+		return h, nil, nil, errInvalidPasswordHash
+	}
+	salt, err1 := base64.RawStdEncoding.DecodeString(parts[4])
+	key, err2 := base64.RawStdEncoding.DecodeString(parts[5])
+	if err1 != nil || err2 != nil || len(key) == 0 ||
+		h.Time == 0 || h.Threads == 0 || h.Memory == 0 || h.Memory > maxArgon2Memory {
+		return h, nil, nil, errInvalidPasswordHash
+	}
+	h.KeyLen = uint32(len(key))
+	return h, salt, key, nil
+}
+
+// ScryptHasher makes scrypt hashes: $scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<key>.
+type ScryptHasher struct {
+	LogN   int
+	R      int
+	P      int
+	KeyLen int
+}
+
+// defaultScrypt is OWASP's recommended scrypt configuration (N=2^17).
+var defaultScrypt = ScryptHasher{LogN: 17, R: 8, P: 1, KeyLen: 32}
+
+// maxScryptLogN bounds the work, and memory (128*r*N bytes), a stored
+// hash can ask for.
+const maxScryptLogN = 20
+
+func (h ScryptHasher) Hash(password string) (string, error) {
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<h.LogN, h.R, h.P, h.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", h.LogN, h.R, h.P,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (ScryptHasher) Verify(password, hash string) error {
+	h, salt, key, err := parseScrypt(hash)
+	if err != nil {
+		return err
+	}
+	got, err := scrypt.Key([]byte(password), salt, 1<<h.LogN, h.R, h.P, h.KeyLen)
+	if err != nil {
+		return errInvalidPasswordHash
+	}
+	if subtle.ConstantTimeCompare(got, key) != 1 {
+		return errPasswordMismatch
+	}
+	return nil
+}
+
+func parseScrypt(hash string) (h ScryptHasher, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return h, nil, nil, errInvalidPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &h.LogN, &h.R, &h.P); err != nil {
+		return h, nil, nil, errInvalidPasswordHash
+	}
+	salt, err1 := base64.RawStdEncoding.DecodeString(parts[3])
+	key, err2 := base64.RawStdEncoding.DecodeString(parts[4])
+	if err1 != nil || err2 != nil || len(key) == 0 ||
+		h.LogN < 1 || h.LogN > maxScryptLogN || h.R < 1 || h.P < 1 || h.R*h.P >= 1<<30 {
+		return h, nil, nil, errInvalidPasswordHash
+	}
+	h.KeyLen = len(key)
+	return h, salt, key, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testHashers are each algorithm at its cheapest sensible parameters, so
+// property runs stay fast.
+var testHashers = map[string]Hasher{
+	hashBcrypt:   BcryptHasher{Cost: bcrypt.MinCost},
+	hashArgon2ID: Argon2IDHasher{Memory: 64, Time: 1, Threads: 1, KeyLen: 16},
+	hashScrypt:   ScryptHasher{LogN: 4, R: 8, P: 1, KeyLen: 16},
+}
+
+// testPassword bounds arbitrary input to what validation lets through to
+// a hasher: bcrypt rejects anything past 72 bytes.
+func testPassword(s string) string {
+	if len(s) > bcryptMaxPasswordLen {
+		s = s[:bcryptMaxPasswordLen]
+	}
+	return s
+}
+
+var quickConfig = &quick.Config{MaxCount: 20}
+
+// A hash verifies the password it was made from, and ParseHasher recovers
+// the hasher that made it.
+func TestHasherRoundTrip(t *testing.T) {
+	for name, h := range testHashers {
+		t.Run(name, func(t *testing.T) {
+			prop := func(raw string) bool {
+				password := testPassword(raw)
+				hash, err := h.Hash(password)
+				if err != nil {
+					return false
+				}
+				parsed, err := ParseHasher(hash)
+				return err == nil && parsed == h && h.Verify(password, hash) == nil
+			}
+			if err := quick.Check(prop, quickConfig); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// A hash never verifies a different password.
+func TestHasherRejectsOtherPasswords(t *testing.T) {
+	for name, h := range testHashers {
+		t.Run(name, func(t *testing.T) {
+			prop := func(rawA, rawB string) bool {
+				a, b := testPassword(rawA), testPassword(rawB)
+				if a == b {
+					return true
+				}
+				hash, err := h.Hash(a)
+				return err == nil && errors.Is(h.Verify(b, hash), errPasswordMismatch)
+			}
+			if err := quick.Check(prop, quickConfig); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Hashing the same password twice salts it differently.
+func TestHasherSalts(t *testing.T) {
+	for name, h := range testHashers {
+		a, errA := h.Hash("correct horse battery staple")
+		b, errB := h.Hash("correct horse battery staple")
+		if errA != nil || errB != nil {
+			t.Fatalf("%s: %v %v", name, errA, errB)
+		}
+		if a == b {
+			t.Errorf("%s: two hashes of one password are equal", name)
+		}
+	}
+}
+
+// TestLoginMigratesHash switches the service from bcrypt to argon2id: a
+// user with a bcrypt hash logs in as before, and their hash is replaced
+// with an argon2id one in the background, which the next login accepts
+// without replacing it again.
+func TestLoginMigratesHash(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	useFakeRefreshDB(t, s)
+	user := newTestUser(t, s, "a@example.com", "user")
+	if !strings.HasPrefix(user.PasswordHash, "$2a$") {
+		t.Fatalf("test user hash %q isn't bcrypt", user.PasswordHash)
+	}
+	s.passwordHasher.Close()
+	s.passwordHasher = NewBcryptWorkerPool(2, testHashers[hashArgon2ID], 100, time.Second)
+	h := s.Handler()
+	login := `{"email":"a@example.com","password":"correct horse"}`
+
+	if rec := postJSON(h, "/v1/login", `{"email":"a@example.com","password":"wrong"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: %d", rec.Code)
+	}
+	if rec := postJSON(h, "/v1/login", login); rec.Code != http.StatusOK {
+		t.Fatalf("login with the bcrypt hash: %d %s", rec.Code, rec.Body)
+	}
+	var migrated string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		u, err := s.users.GetByID(context.Background(), user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.PasswordHash != user.PasswordHash {
+			migrated = u.PasswordHash
+			break
+		}
+	}
+	if !strings.HasPrefix(migrated, "$argon2id$") {
+		t.Fatalf("hash after login %q, want argon2id", migrated)
+	}
+
+	if rec := postJSON(h, "/v1/login", login); rec.Code != http.StatusOK {
+		t.Fatalf("login with the argon2id hash: %d %s", rec.Code, rec.Body)
+	}
+	if s.passwordHasher.NeedsRehash([]byte(migrated)) {
+		t.Error("the argon2id hash would be replaced again")
+	}
+}
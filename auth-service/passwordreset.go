@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/tokens"
+)
+
+const (
+	// passwordResetEmailInterval limits reset emails per user, so the
+	// endpoint can't be used to flood someone's inbox.
+	passwordResetEmailInterval = time.Minute
+	passwordResetEmailTimeout  = 30 * time.Second
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+func (req forgotPasswordRequest) validate() error {
+	var v validator
+	v.email("email", req.Email)
+	return v.err()
+}
+
+// forgotPasswordHandler serves POST /v1/forgot-password. It always answers
+// 202 so the response doesn't reveal whether the email is registered; if
+// it is, a reset link is made and emailed in the background, so the
+// response takes no longer either.
+func (s *Server) forgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	user, err := s.users.GetByEmail(r.Context(), req.Email)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Println("forgot password lookup error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	if err == nil {
+		s.sendPasswordReset(r, user.ID, req.Email)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) sendPasswordReset(r *http.Request, userID int, email string) {
+	ev := s.auditEventFromRequest(r, "password.reset_requested")
+	ev.ActorID = &userID
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), passwordResetEmailTimeout)
+		defer cancel()
+
+		fresh, err := s.rdb.SetNX(ctx, "password_reset_sent:"+strconv.Itoa(userID), 1, passwordResetEmailInterval).Result()
+		if err != nil {
+			log.Println("password reset throttle error:", err)
+			return
+		}
+		if !fresh {
+			return
+		}
+
+		token, err := tokens.GeneratePasswordResetToken(userID, email, s.cfg.PasswordResetKey, s.cfg.PasswordResetTTL)
+		if err != nil {
+			log.Println("password reset token error:", err)
+			return
+		}
+		s.auditor.Record(ctx, ev)
+
+		msg, err := newEmail(ctx, email, "password-reset", EmailTemplateData{
+			Email:     email,
+			Link:      s.cfg.AppURL + "/reset-password?token=" + url.QueryEscape(token),
+			ExpiresIn: s.cfg.PasswordResetTTL,
+		})
+		if err == nil {
+			err = s.emailSender.Send(ctx, msg)
+		}
+		if err != nil {
+			log.Println("password reset email error:", err)
+		}
+	}()
+}
+
+type resetPasswordRequest struct {
+	Token    secret `json:"token"`
+	Password secret `json:"password"`
+}
+
+func (req resetPasswordRequest) validate() error {
+	var v validator
+	v.required("token", string(req.Token))
+	v.password("password", string(req.Password))
+	return v.err()
+}
+
+// resetPasswordHandler serves POST /v1/reset-password. The token is checked
+// from its signature alone; the only lookup is its jti, which is recorded in
+// Redis for the rest of the token's life so each link works once. A reset
+// ends every session and refresh token the user had.
+func (s *Server) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	claims, err := tokens.ParsePasswordResetToken(string(req.Token), &s.cfg.PasswordResetKey.PublicKey)
+	if err != nil {
+		apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid or expired token"))
+		return
+	}
+
+	usedKey := "password_reset_used:" + claims.ID
+	fresh, err := s.rdb.SetNX(r.Context(), usedKey, 1, time.Until(claims.ExpiresAt)).Result()
+	if err != nil {
+		log.Println("password reset revocation error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+	if !fresh {
+		apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid or expired token"))
+		return
+	}
+
+	hash, err := s.passwordHasher.HashPassword(r.Context(), string(req.Password))
+	if err != nil {
+		s.rdb.Del(r.Context(), usedKey)
+		if errors.Is(err, errBcryptBusy) {
+			writeServerBusy(w, r)
+			return
+		}
+		log.Printf("password reset hash error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	// Matching the email too means a link sent before an email change no
+	// longer works.
+	user, err := s.users.GetByID(r.Context(), claims.UserID)
+	if err == nil && user.Email != claims.Email {
+		err = ErrNotFound
+	}
+	if err == nil {
+		err = s.users.UpdatePassword(r.Context(), user.ID, user.PasswordHash, string(hash))
+	}
+	if err == nil {
+		err = s.revokeUserRefreshTokens(r.Context(), user.ID)
+	}
+	if errors.Is(err, ErrNotFound) {
+		apperror.WriteError(w, r, apperror.Unauthorized("token_invalid", "Invalid or expired token"))
+		return
+	}
+	if err != nil {
+		// Let the user try the same link again.
+		s.rdb.Del(r.Context(), usedKey)
+		log.Println("password reset update error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	if err := s.revokeUserSessions(r.Context(), claims.Email); err != nil {
+		log.Println("password reset session revocation error:", err)
+	}
+
+	ev := s.auditEventFromRequest(r, "password.reset")
+	ev.Level = "warning"
+	ev.ActorID = &claims.UserID
+	s.auditor.Record(r.Context(), ev)
+
+	w.WriteHeader(http.StatusNoContent)
+}
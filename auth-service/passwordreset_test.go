@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"resilient-auth-service/tokens"
+)
+
+// TestPasswordReset follows a reset link from the email: it sets the new
+// password and ends the user's sessions and refresh tokens, and it works
+// once. An expired link, or one for an email that has since changed, is
+// refused.
+func TestPasswordReset(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	fake := useFakeRefreshDB(t, s)
+	user := newTestUser(t, s, "a@example.com", "user")
+	ctx := context.Background()
+	sessionID, err := s.createSession(ctx, user.Email, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := s.issueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+	emails := s.emailSender.(*fakeEmailSender)
+
+	for _, email := range []string{"nobody@example.com", user.Email} {
+		if rec := postJSON(h, "/v1/forgot-password", `{"email":"`+email+`"}`); rec.Code != http.StatusAccepted {
+			t.Fatalf("forgot-password for %s: %d %s", email, rec.Code, rec.Body)
+		}
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(emails.messages()) == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("no reset email sent")
+		}
+	}
+	sent := emails.messages()
+	if len(sent) != 1 || sent[0].To != user.Email {
+		t.Fatalf("emails %+v, want one to %s", sent, user.Email)
+	}
+	m := regexp.MustCompile(`reset-password\?token=([^"&\s]+)`).FindStringSubmatch(sent[0].TextBody)
+	if m == nil {
+		t.Fatalf("email lacks a reset link:\n%s", sent[0].TextBody)
+	}
+	token, _ := url.QueryUnescape(m[1])
+
+	reset := func(token string) *httptest.ResponseRecorder {
+		return postJSON(h, "/v1/reset-password", `{"token":"`+token+`","password":"a new passphrase"}`)
+	}
+	if rec := reset(token); rec.Code != http.StatusNoContent {
+		t.Fatalf("reset: %d %s", rec.Code, rec.Body)
+	}
+	updated, err := s.users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.PasswordHash == user.PasswordHash {
+		t.Error("the password wasn't changed")
+	}
+	if _, _, err := s.lookupSession(ctx, sessionID, false); err == nil {
+		t.Error("the session survived the reset")
+	}
+	if !fake.byHash(refresh).revoked {
+		t.Error("the refresh token survived the reset")
+	}
+
+	if env := readEnvelope(t, reset(token), http.StatusUnauthorized); env.Error.Code != "token_invalid" {
+		t.Errorf("replayed link: code %q, want token_invalid", env.Error.Code)
+	}
+
+	expired, err := tokens.GeneratePasswordResetToken(user.ID, user.Email, s.cfg.PasswordResetKey, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleEmail, err := tokens.GeneratePasswordResetToken(user.ID, "old@example.com", s.cfg.PasswordResetKey, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{"expired": expired, "old email": staleEmail} {
+		if rec := reset(token); rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s link: %d, want 401", name, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+// poolStatsInterval is how often connection pool stats are sampled.
+const poolStatsInterval = 10 * time.Second
+
+var (
+	dbPoolConns = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "db_pool_connections",
+		Help: "Postgres pool connections by state: in_use or idle.",
+	}, []string{"state"})
+	dbPoolMaxOpen = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "db_pool_max_open_connections",
+		Help: "DB_MAX_OPEN_CONNS; 0 means no limit.",
+	})
+	dbPoolWaitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "db_pool_waits_total",
+		Help: "Times a query had to wait for a free Postgres connection.",
+	})
+	dbPoolWaitSecondsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "db_pool_wait_seconds_total",
+		Help: "Total time queries spent waiting for a free Postgres connection.",
+	})
+
+	redisPoolConns = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "redis_pool_connections",
+		Help: "Redis pool connections by state: in_use or idle.",
+	}, []string{"state"})
+	redisPoolTimeoutsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "redis_pool_timeouts_total",
+		Help: "Times a command gave up waiting for a free Redis connection.",
+	})
+	redisPoolMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "redis_pool_misses_total",
+		Help: "Times a command found no idle Redis connection and had to open or wait for one.",
+	})
+)
+
+// PoolStatsSampler copies the Postgres and Redis connection pool stats into
+// gauges and counters every poolStatsInterval. db_pool_waits_total rising,
+// with in_use at the cap, is the early sign of Postgres saturating.
+type PoolStatsSampler struct {
+	db  *sql.DB
+	rdb *redis.Client
+
+	// The pools' counters are cumulative; the last sample lets each tick
+	// add only what is new.
+	lastDB    sql.DBStats
+	lastRedis redis.PoolStats
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPoolStatsSampler(db *sql.DB, rdb *redis.Client) *PoolStatsSampler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &PoolStatsSampler{
+		db:     db,
+		rdb:    rdb,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Close stops the sampler.
+func (s *PoolStatsSampler) Close(ctx context.Context) error {
+	s.cancel()
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *PoolStatsSampler) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(poolStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sample()
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PoolStatsSampler) sample() {
+	st := s.db.Stats()
+	dbPoolConns.WithLabelValues("in_use").Set(float64(st.InUse))
+	dbPoolConns.WithLabelValues("idle").Set(float64(st.Idle))
+	dbPoolMaxOpen.Set(float64(st.MaxOpenConnections))
+	dbPoolWaitsTotal.Add(float64(st.WaitCount - s.lastDB.WaitCount))
+	dbPoolWaitSecondsTotal.Add((st.WaitDuration - s.lastDB.WaitDuration).Seconds())
+	s.lastDB = st
+
+	rs := *s.rdb.PoolStats()
+	redisPoolConns.WithLabelValues("in_use").Set(float64(rs.TotalConns - rs.IdleConns))
+	redisPoolConns.WithLabelValues("idle").Set(float64(rs.IdleConns))
+	redisPoolTimeoutsTotal.Add(float64(rs.Timeouts - s.lastRedis.Timeouts))
+	redisPoolMissesTotal.Add(float64(rs.Misses - s.lastRedis.Misses))
+	s.lastRedis = rs
+}
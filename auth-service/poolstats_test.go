@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestPoolStatsSampler holds a connection from each pool and makes a
+// query wait for the only Postgres connection. The sampler reports both
+// pools at start, stops on Close, and adds each wait to the counters once.
+func TestPoolStatsSampler(t *testing.T) {
+	ctx := context.Background()
+	db := sql.OpenDB(&slowConn{})
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	waitsBefore := testutil.ToFloat64(dbPoolWaitsTotal)
+	dbPoolMaxOpen.Set(-1) // until the first sample
+
+	s := NewPoolStatsSampler(db, rdb)
+	for deadline := time.Now().Add(5 * time.Second); testutil.ToFloat64(dbPoolMaxOpen) != 1; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("no sample taken at start")
+		}
+	}
+
+	closeCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := s.Close(closeCtx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("sampler still running after Close")
+	}
+	if got := testutil.ToFloat64(dbPoolConns.WithLabelValues("in_use")); got != 1 {
+		t.Errorf("db in_use %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(redisPoolConns.WithLabelValues("idle")); got != 1 {
+		t.Errorf("redis idle %v, want 1", got)
+	}
+
+	// Queue a query behind the held connection, then release it.
+	queried := make(chan error, 1)
+	go func() {
+		_, err := db.ExecContext(ctx, "SELECT 1")
+		queried <- err
+	}()
+	for db.Stats().WaitCount == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	conn.Close()
+	if err := <-queried; err != nil {
+		t.Fatal(err)
+	}
+
+	s.sample()
+	if got := testutil.ToFloat64(dbPoolWaitsTotal) - waitsBefore; got != 1 {
+		t.Errorf("db_pool_waits_total rose by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(dbPoolConns.WithLabelValues("idle")); got != 1 {
+		t.Errorf("db idle %v, want 1", got)
+	}
+	s.sample()
+	if got := testutil.ToFloat64(dbPoolWaitsTotal) - waitsBefore; got != 1 {
+		t.Errorf("db_pool_waits_total rose by %v after a quiet sample, want 1", got)
+	}
+}
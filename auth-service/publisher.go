@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher sends outbox events to a message broker. Publish must not return
+// until the broker has acknowledged the event.
+type Publisher interface {
+	Publish(ctx context.Context, ev OutboxEvent) error
+	Close() error
+}
+
+// newPublisher picks the driver named by cfg.OutboxBroker. It returns nil
+// when no broker is configured, in which case the relay is not started and
+// events accumulate in the table until one is.
+func newPublisher(cfg Config) (Publisher, error) {
+	switch cfg.OutboxBroker {
+	case "":
+		return nil, nil
+	case "kafka":
+		return NewKafkaPublisher(cfg.KafkaBrokers, cfg.OutboxTopic), nil
+	case "nats":
+		return NewNATSPublisher(cfg.NATSURL, cfg.OutboxTopic)
+	case "memory":
+		return &MemoryPublisher{}, nil
+	}
+	return nil, fmt.Errorf("unknown OUTBOX_BROKER %q (want kafka, nats or memory)", cfg.OutboxBroker)
+}
+
+// KafkaPublisher writes to a single topic keyed by aggregate id, so all of
+// a user's events land on one partition and keep their order.
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// The relay publishes one event at a time and waits for the ack.
+		BatchTimeout: time.Millisecond,
+	}}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, ev OutboxEvent) error {
+	value, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return p.w.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(ev.AggregateID),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "event-type", Value: []byte(ev.Type)},
+		},
+	})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
+
+// NATSPublisher publishes to JetStream on "<subject>.<event type>". The
+// outbox id is used as the message id, so JetStream drops republished
+// duplicates within the stream's deduplication window.
+type NATSPublisher struct {
+	nc      *nats.Conn
+	js      jetstream.JetStream
+	subject string
+}
+
+func NewNATSPublisher(url, subject string) (*NATSPublisher, error) {
+	nc, err := nats.Connect(url, nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return &NATSPublisher{nc: nc, js: js, subject: subject}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, ev OutboxEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = p.js.Publish(ctx, p.subject+"."+ev.Type, data,
+		jetstream.WithMsgID(strconv.FormatInt(ev.ID, 10)))
+	return err
+}
+
+func (p *NATSPublisher) Close() error {
+	return p.nc.Drain()
+}
+
+// MemoryPublisher keeps published events in memory, for tests and local
+// development without a broker.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []OutboxEvent
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, ev OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, ev)
+	log.Printf("outbox published id=%d type=%s aggregate=%s", ev.ID, ev.Type, ev.AggregateID)
+	return nil
+}
+
+// Events returns a copy of everything published so far, in order.
+func (p *MemoryPublisher) Events() []OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]OutboxEvent(nil), p.events...)
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var dbQueryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "db_query_duration_seconds",
+	Help:    "Time from sending a query to its first result.",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+})
+
+var dbSlowQueriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "db_slow_queries_total",
+	Help: "Queries that took at least SLOW_QUERY_THRESHOLD.",
+})
+
+const queryNameKey contextKey = "queryName"
+
+// withQueryName labels the queries made with ctx for the slow-query log,
+// which otherwise identifies them by their SQL.
+func withQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey, name)
+}
+
+// newTimedConnector opens lib/pq connections that time every query, for
+// the slow-query log and db_query_duration_seconds. It sits below
+// database/sql, so it sees every query without the call sites changing,
+// prepared statements included. Queries taking at least slow are logged;
+// zero turns the log off.
+func newTimedConnector(dsn string, slow time.Duration) (driver.Connector, error) {
+	c, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return timedConnector{c, slow}, nil
+}
+
+type timedConnector struct {
+	driver.Connector
+	slow time.Duration
+}
+
+func (c timedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pc, ok := conn.(pqConn)
+	if !ok {
+		return conn, nil
+	}
+	return timedConn{pc, c.slow}, nil
+}
+
+// pqConn is what lib/pq connections implement and database/sql looks for.
+// timedConn implements the same, so wrapping one changes nothing else
+// about how database/sql uses it.
+type pqConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+type timedConn struct {
+	pqConn
+	slow time.Duration
+}
+
+func (c timedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.pqConn.QueryContext(ctx, query, args)
+	observeQuery(ctx, c.slow, query, start)
+	return rows, err
+}
+
+func (c timedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := c.pqConn.ExecContext(ctx, query, args)
+	observeQuery(ctx, c.slow, query, start)
+	return res, err
+}
+
+func (c timedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c timedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.pqConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	ps, ok := stmt.(pqStmt)
+	if !ok {
+		return stmt, nil
+	}
+	return timedStmt{ps, query, c.slow}, nil
+}
+
+type pqStmt interface {
+	driver.Stmt
+	driver.StmtQueryContext
+	driver.StmtExecContext
+}
+
+type timedStmt struct {
+	pqStmt
+	query string
+	slow  time.Duration
+}
+
+func (s timedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.pqStmt.QueryContext(ctx, args)
+	observeQuery(ctx, s.slow, s.query, start)
+	return rows, err
+}
+
+func (s timedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := s.pqStmt.ExecContext(ctx, args)
+	observeQuery(ctx, s.slow, s.query, start)
+	return res, err
+}
+
+// observeQuery records a query's duration and logs it if it was slow. The
+// log names the query and never includes its arguments; the SQL itself
+// only holds placeholders.
+func observeQuery(ctx context.Context, slow time.Duration, query string, start time.Time) {
+	d := time.Since(start)
+	dbQueryDuration.Observe(d.Seconds())
+
+	if slow == 0 || d < slow {
+		return
+	}
+	dbSlowQueriesTotal.Inc()
+	log.Printf("slow query request_id=%s duration=%s statement=%q",
+		requestIDFromContext(ctx), d.Round(time.Millisecond), queryName(ctx, query))
+}
+
+// queryName is the name set with withQueryName, or else the start of the
+// query with its whitespace collapsed.
+func queryName(ctx context.Context, query string) string {
+	if name, ok := ctx.Value(queryNameKey).(string); ok {
+		return name
+	}
+	const maxLen = 120
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLen {
+		query = query[:maxLen] + "..."
+	}
+	return query
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// slowConn is a driver shim with the methods timedConnector expects of a
+// lib/pq connection. Statements containing pg_sleep take delay; the rest
+// answer at once with no rows.
+type slowConn struct{ delay time.Duration }
+
+func (c *slowConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *slowConn) Driver() driver.Driver                        { return nil }
+func (c *slowConn) Close() error                                 { return nil }
+func (c *slowConn) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+func (c *slowConn) Ping(context.Context) error                   { return nil }
+func (c *slowConn) ResetSession(context.Context) error           { return nil }
+func (c *slowConn) IsValid() bool                                { return true }
+
+func (c *slowConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *slowConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *slowConn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return slowStmt{c, query}, nil
+}
+
+func (c *slowConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.wait(query)
+	return &fakeRows{cols: []string{"id"}}, nil
+}
+
+func (c *slowConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.wait(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *slowConn) wait(query string) {
+	if strings.Contains(query, "pg_sleep") {
+		time.Sleep(c.delay)
+	}
+}
+
+type slowStmt struct {
+	conn  *slowConn
+	query string
+}
+
+func (s slowStmt) Close() error  { return nil }
+func (s slowStmt) NumInput() int { return -1 }
+
+func (s slowStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s slowStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s slowStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func (s slowStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+// TestSlowQueryLog runs fast and artificially slow queries through the
+// timed connector. Only the slow ones are logged and counted, each with
+// the request ID and the statement name or SQL but never the arguments,
+// whether run directly, as Exec or as a prepared statement.
+func TestSlowQueryLog(t *testing.T) {
+	var logs bytes.Buffer
+	saved := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(saved) })
+
+	const slow = 20 * time.Millisecond
+	db := sql.OpenDB(timedConnector{&slowConn{delay: 2 * slow}, slow})
+	t.Cleanup(func() { db.Close() })
+	ctx := context.WithValue(context.Background(), requestIDKey, "req-7")
+
+	run := func(query func() error) (string, float64) {
+		t.Helper()
+		logs.Reset()
+		slowBefore := testutil.ToFloat64(dbSlowQueriesTotal)
+		timedBefore := histogramSnapshot(t, dbQueryDuration).GetSampleCount()
+		if err := query(); err != nil {
+			t.Fatal(err)
+		}
+		if got := histogramSnapshot(t, dbQueryDuration).GetSampleCount() - timedBefore; got != 1 {
+			t.Errorf("db_query_duration_seconds observed %d queries, want 1", got)
+		}
+		if strings.Contains(logs.String(), secretMarker) {
+			t.Errorf("argument logged: %s", logs.String())
+		}
+		return logs.String(), testutil.ToFloat64(dbSlowQueriesTotal) - slowBefore
+	}
+	query := func(ctx context.Context, sql string) func() error {
+		return func() error {
+			rows, err := db.QueryContext(ctx, sql, secretMarker)
+			if err != nil {
+				return err
+			}
+			return rows.Close()
+		}
+	}
+
+	if out, n := run(query(ctx, "SELECT id FROM users WHERE email = $1")); out != "" || n != 0 {
+		t.Errorf("fast query: logged %q, counted %v", out, n)
+	}
+
+	for _, tt := range []struct {
+		name, statement string
+		query           func() error
+	}{
+		{"query", `"SELECT pg_sleep(1) WHERE $1 <> ''"`, query(ctx, "SELECT pg_sleep(1)\n\tWHERE $1 <> ''")},
+		{"named query", `"user_by_email"`, query(withQueryName(ctx, "user_by_email"), "SELECT pg_sleep(1) WHERE $1 <> ''")},
+		{"exec", `"UPDATE users SET name = pg_sleep(1) WHERE email = $1"`, func() error {
+			_, err := db.ExecContext(ctx, "UPDATE users SET name = pg_sleep(1) WHERE email = $1", secretMarker)
+			return err
+		}},
+		{"prepared", `"SELECT pg_sleep(1) WHERE $1 <> ''"`, func() error {
+			stmt, err := db.PrepareContext(ctx, "SELECT pg_sleep(1) WHERE $1 <> ''")
+			if err != nil {
+				return err
+			}
+			defer stmt.Close()
+			rows, err := stmt.QueryContext(ctx, secretMarker)
+			if err != nil {
+				return err
+			}
+			return rows.Close()
+		}},
+	} {
+		out, n := run(tt.query)
+		if n != 1 {
+			t.Errorf("%s: db_slow_queries_total rose by %v, want 1", tt.name, n)
+		}
+		if !strings.Contains(out, "slow query request_id=req-7 ") || !strings.Contains(out, "statement="+tt.statement) {
+			t.Errorf("%s: logged %q, want request_id=req-7 and statement=%s", tt.name, out, tt.statement)
+		}
+	}
+
+	off := sql.OpenDB(timedConnector{&slowConn{delay: 2 * slow}, 0})
+	t.Cleanup(func() { off.Close() })
+	if out, n := run(func() error {
+		_, err := off.ExecContext(ctx, "SELECT pg_sleep(1)")
+		return err
+	}); out != "" || n != 0 {
+		t.Errorf("threshold 0: logged %q, counted %v", out, n)
+	}
+}
+
+func TestQueryName(t *testing.T) {
+	long := "SELECT " + strings.Repeat("a, ", 60) + "b FROM t"
+	for _, tt := range []struct {
+		ctx         context.Context
+		query, want string
+	}{
+		{context.Background(), "SELECT 1\n\t  FROM t", "SELECT 1 FROM t"},
+		{withQueryName(context.Background(), "named"), "SELECT 1", "named"},
+		{context.Background(), long, strings.Join(strings.Fields(long), " ")[:120] + "..."},
+	} {
+		if got := queryName(tt.ctx, tt.query); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+
+	"resilient-auth-service/apperror"
+)
+
+// RateLimiter decides whether one more request for key is allowed.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+}
+
+// rateLimitScript counts a request and, on the first of a window, starts
+// the window's expiry. Doing both in one script costs a single round trip
+// and can't half-fail: a separate EXPIRE that never arrived would leave a
+// counter that never resets, locking the key out for good.
+var rateLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// RedisRateLimiter is a fixed-window counter shared by every replica.
+type RedisRateLimiter struct {
+	rdb    *redis.Client
+	limit  int
+	window time.Duration
+}
+
+func NewRedisRateLimiter(rdb *redis.Client, limit int, window time.Duration) *RedisRateLimiter {
+	return &RedisRateLimiter{rdb: rdb, limit: limit, window: window}
+}
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	key = "rate_limit:" + key
+
+	count, err := rateLimitScript.Run(ctx, l.rdb, []string{key}, l.window.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	if count > int64(l.limit) {
+		log.Printf("RATE LIMITED key=%s count=%d", key, count)
+		return false, nil
+	}
+	return true, nil
+}
+
+// TokenBucketLimiter keeps one token bucket per key in process memory. It
+// refills at limit per window with a burst of limit, so it admits roughly
+// what the Redis window does, but per replica rather than globally.
+type TokenBucketLimiter struct {
+	clock    Clock
+	limiters sync.Map // key -> *rate.Limiter
+	every    rate.Limit
+	burst    int
+	done     chan struct{}
+}
+
+// NewTokenBucketLimiter starts a goroutine that drops idle buckets every
+// cleanupInterval; Close stops it.
+func NewTokenBucketLimiter(clock Clock, limit int, window, cleanupInterval time.Duration) *TokenBucketLimiter {
+	l := &TokenBucketLimiter{
+		clock: clock,
+		every: rate.Every(window / time.Duration(limit)),
+		burst: limit,
+		done:  make(chan struct{}),
+	}
+	go l.cleanup(cleanupInterval)
+	return l
+}
+
+func (l *TokenBucketLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	v, ok := l.limiters.Load(key)
+	if !ok {
+		v, _ = l.limiters.LoadOrStore(key, rate.NewLimiter(l.every, l.burst))
+	}
+	allowed := v.(*rate.Limiter).AllowN(l.clock.Now(), 1)
+	if !allowed {
+		log.Printf("RATE LIMITED key=%s (in-memory)", key)
+	}
+	return allowed, nil
+}
+
+// cleanup removes buckets that have refilled completely. A full bucket
+// behaves exactly like a new one, so dropping it forgets nothing.
+func (l *TokenBucketLimiter) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			now := l.clock.Now()
+			l.limiters.Range(func(key, v any) bool {
+				if v.(*rate.Limiter).TokensAt(now) >= float64(l.burst) {
+					l.limiters.Delete(key)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func (l *TokenBucketLimiter) Close() {
+	close(l.done)
+}
+
+// FallbackRateLimiter asks primary and, when it fails, fallback. This keeps
+// rate limiting in force while Redis is down instead of failing open.
+type FallbackRateLimiter struct {
+	primary  RateLimiter
+	fallback RateLimiter
+}
+
+func NewFallbackRateLimiter(primary, fallback RateLimiter) *FallbackRateLimiter {
+	return &FallbackRateLimiter{primary: primary, fallback: fallback}
+}
+
+func (l *FallbackRateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	allowed, err := l.primary.Allow(ctx, key)
+	if err == nil {
+		return allowed, nil
+	}
+	log.Printf("rate limit falling back to in-memory key=%s redis_error=%q", key, err)
+	return l.fallback.Allow(ctx, key)
+}
+
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, err := s.rateLimiter.Allow(r.Context(), s.realIP(r))
+		if err != nil {
+			log.Println("rate limit error:", err)
+			next.ServeHTTP(w, r) // fail open
+			return
+		}
+		if !allowed {
+			rateLimitedTotal.Inc()
+			apperror.WriteError(w, r, apperror.TooManyRequests("rate_limited", "Too many requests"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
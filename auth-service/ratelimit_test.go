@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testRateWindow = time.Second
+
+// steppedLimiter drives a limiter on a simulated clock: allow(d) moves
+// time forward by d and then makes one request.
+type steppedLimiter struct {
+	allow func(d time.Duration) bool
+	close func()
+}
+
+// limiterImpls are the RateLimiters under test, each built fresh per
+// property run so runs can't see each other's counters.
+var limiterImpls = map[string]func(t *testing.T, limit int) steppedLimiter{
+	"redis": func(t *testing.T, limit int) steppedLimiter {
+		mr := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		l := NewRedisRateLimiter(client, limit, testRateWindow)
+		return steppedLimiter{
+			allow: func(d time.Duration) bool {
+				mr.FastForward(d)
+				ok, err := l.Allow(context.Background(), "k")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return ok
+			},
+			close: func() { client.Close(); mr.Close() },
+		}
+	},
+	"token_bucket": func(t *testing.T, limit int) steppedLimiter {
+		clock := newFakeClock()
+		l := NewTokenBucketLimiter(clock, limit, testRateWindow, time.Hour)
+		return steppedLimiter{
+			allow: func(d time.Duration) bool {
+				clock.Advance(d)
+				ok, _ := l.Allow(context.Background(), "k")
+				return ok
+			},
+			close: l.Close,
+		}
+	},
+}
+
+// gapsIn turns arbitrary input into inter-request gaps of up to two
+// windows, in milliseconds so windows are crossed both exactly and not.
+func gapsIn(raw []uint16) []time.Duration {
+	gaps := make([]time.Duration, len(raw))
+	for i, g := range raw {
+		gaps[i] = time.Duration(g%2000) * time.Millisecond
+	}
+	return gaps
+}
+
+func limitIn(raw uint8) int { return int(raw%20) + 1 }
+
+// Within one window, exactly limit requests are allowed, however many
+// arrive.
+func TestRateLimiterBurstAdmitsExactlyLimit(t *testing.T) {
+	quietLog(t)
+	for name, build := range limiterImpls {
+		t.Run(name, func(t *testing.T) {
+			prop := func(rawLimit, rawN uint8) bool {
+				limit, n := limitIn(rawLimit), int(rawN%60)
+				l := build(t, limit)
+				defer l.close()
+
+				allowed := 0
+				for range n {
+					if l.allow(0) {
+						allowed++
+					}
+				}
+				return allowed == min(n, limit)
+			}
+			if err := quick.Check(prop, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Raising the limit never admits fewer of the same requests.
+func TestRateLimiterMonotoneInLimit(t *testing.T) {
+	quietLog(t)
+	for name, build := range limiterImpls {
+		t.Run(name, func(t *testing.T) {
+			prop := func(rawLimit, rawExtra uint8, rawGaps []uint16) bool {
+				lo := limitIn(rawLimit)
+				hi := lo + int(rawExtra%10)
+				gaps := gapsIn(rawGaps)
+
+				count := func(limit int) int {
+					l := build(t, limit)
+					defer l.close()
+					n := 0
+					for _, d := range gaps {
+						if l.allow(d) {
+							n++
+						}
+					}
+					return n
+				}
+				return count(lo) <= count(hi)
+			}
+			if err := quick.Check(prop, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// After a full window with no requests, the whole limit is available
+// again, whatever happened before.
+func TestRateLimiterRecoversAfterIdleWindow(t *testing.T) {
+	quietLog(t)
+	for name, build := range limiterImpls {
+		t.Run(name, func(t *testing.T) {
+			prop := func(rawLimit uint8, rawGaps []uint16) bool {
+				limit := limitIn(rawLimit)
+				l := build(t, limit)
+				defer l.close()
+
+				for _, d := range gapsIn(rawGaps) {
+					l.allow(d)
+				}
+				if !l.allow(testRateWindow) {
+					return false
+				}
+				for range limit - 1 {
+					if !l.allow(0) {
+						return false
+					}
+				}
+				return !l.allow(0)
+			}
+			if err := quick.Check(prop, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// roundTrips is a redis.Hook counting round trips: a command sent on its
+// own or a whole pipeline is one.
+type roundTrips struct{ n atomic.Int64 }
+
+func (h *roundTrips) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *roundTrips) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *roundTrips) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmds)
+	}
+}
+
+// TestRedisRateLimiterRoundTrips checks that a request costs one round
+// trip once the script is loaded, and that the first hit of a window sets
+// its expiry in that same trip.
+func TestRedisRateLimiterRoundTrips(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	trips := &roundTrips{}
+	client.AddHook(trips)
+	l := NewRedisRateLimiter(client, 5, time.Minute)
+	ctx := context.Background()
+
+	// The first call loads the script.
+	if _, err := l.Allow(ctx, "warm-up"); err != nil {
+		t.Fatal(err)
+	}
+	before := trips.n.Load()
+	if ok, err := l.Allow(ctx, "k"); !ok || err != nil {
+		t.Fatalf("Allow: %v, %v", ok, err)
+	}
+	if got := trips.n.Load() - before; got != 1 {
+		t.Errorf("%d round trips, want 1", got)
+	}
+	if ttl := mr.TTL("rate_limit:k"); ttl != time.Minute {
+		t.Errorf("TTL %s after the first hit, want 1m", ttl)
+	}
+}
+
+// BenchmarkRedisRateLimiter reports the Redis round trips each Allow
+// costs, against miniredis.
+func BenchmarkRedisRateLimiter(b *testing.B) {
+	mr := miniredis.RunT(b)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	trips := &roundTrips{}
+	client.AddHook(trips)
+	l := NewRedisRateLimiter(client, 1<<30, time.Minute)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, err := l.Allow(ctx, "k"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(trips.n.Load())/float64(b.N), "round-trips/op")
+}
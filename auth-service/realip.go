@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseForwardedFor returns the client IP for a request that arrived from
+// remoteIP carrying the given X-Forwarded-For header.
+//
+// The header is only believed when remoteIP is one of our trusted proxies;
+// otherwise anyone could send "X-Forwarded-For: 8.8.8.8" and pick the IP we
+// rate limit on. When it is believed, the list is walked right to left (each
+// proxy appends the address it saw) skipping trusted proxies, and the first
+// untrusted address is the client. Entries to its left were supplied by the
+// client and are ignored, so a spoofed private address such as 10.0.0.5
+// can't pass as one of our proxies.
+//
+// If every entry is trusted, or the header is empty, remoteIP is returned.
+// An unparsable entry ends the walk at the last trusted hop, since nothing
+// beyond it can be attributed.
+func ParseForwardedFor(header string, trustedCIDRs []net.IPNet, remoteIP net.IP) net.IP {
+	if !ipTrusted(remoteIP, trustedCIDRs) {
+		return remoteIP
+	}
+
+	hops := strings.Split(header, ",")
+	last := remoteIP
+	for i := len(hops) - 1; i >= 0; i-- {
+		entry := strings.TrimSpace(hops[i])
+		if entry == "" {
+			continue
+		}
+		ip := parseForwardedIP(entry)
+		if ip == nil {
+			return last
+		}
+		if !ipTrusted(ip, trustedCIDRs) {
+			return ip
+		}
+		last = ip
+	}
+	return remoteIP
+}
+
+// parseForwardedIP accepts a bare IPv4 or IPv6 address, a bracketed IPv6
+// address, or either with a port ("1.2.3.4:5678", "[2001:db8::1]:443").
+// An IPv6 zone ("fe80::1%eth0") is dropped: it names an interface on the
+// proxy, not part of the address.
+func parseForwardedIP(s string) net.IP {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	} else {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	}
+	if i := strings.LastIndexByte(s, '%'); i > 0 && strings.Contains(s[:i], ":") {
+		s = s[:i]
+	}
+	return net.ParseIP(s)
+}
+
+func ipTrusted(ip net.IP, trustedCIDRs []net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range trustedCIDRs {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// realIP is the client address used for rate limiting and the audit log:
+// RemoteAddr without its port, or the forwarded client address when the
+// request came through a proxy listed in TRUSTED_PROXIES.
+func (s *Server) realIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(host)
+	if remote == nil {
+		return host
+	}
+	// Proxies may append a second header instead of extending the first.
+	header := strings.Join(r.Header.Values("X-Forwarded-For"), ",")
+	return ParseForwardedFor(header, s.cfg.TrustedProxies, remote).String()
+}
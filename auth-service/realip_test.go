@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func mustCIDRs(t *testing.T, cidrs ...string) []net.IPNet {
+	t.Helper()
+	var nets []net.IPNet
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nets = append(nets, *n)
+	}
+	return nets
+}
+
+func TestParseForwardedFor(t *testing.T) {
+	trusted := mustCIDRs(t, "10.0.0.0/8", "fd00::/8", "fe80::/10")
+	for _, tt := range []struct {
+		name   string
+		header string
+		remote string
+		want   string
+	}{
+		{"empty header", "", "10.0.0.1", "10.0.0.1"},
+		{"only separators", " , ,", "10.0.0.1", "10.0.0.1"},
+		{"one client", "203.0.113.7", "10.0.0.1", "203.0.113.7"},
+		{"untrusted peer", "203.0.113.7", "198.51.100.1", "198.51.100.1"},
+		{"all trusted", "10.0.0.3, 10.0.0.2", "10.0.0.1", "10.0.0.1"},
+		{"through two proxies", "203.0.113.7, 10.0.0.2", "10.0.0.1", "203.0.113.7"},
+		{"spoofed leftmost private address", "10.9.9.9, 203.0.113.7, 10.0.0.2", "10.0.0.1", "203.0.113.7"},
+		{"spoofed leftmost public address", "8.8.8.8, 203.0.113.7", "10.0.0.1", "203.0.113.7"},
+		{"with a port", "203.0.113.7:5678", "10.0.0.1", "203.0.113.7"},
+		{"bare IPv6", "2001:db8::7", "10.0.0.1", "2001:db8::7"},
+		{"bracketed IPv6", "[2001:db8::7]", "10.0.0.1", "2001:db8::7"},
+		{"bracketed IPv6 with a port", "[2001:db8::7]:443", "fd00::1", "2001:db8::7"},
+		{"zoned trusted hop", "2001:db8::7, fe80::2%eth0", "10.0.0.1", "2001:db8::7"},
+		{"bracketed zoned hop with a port", "2001:db8::7, [fe80::2%25eth0]:80", "10.0.0.1", "2001:db8::7"},
+		{"garbage nearest", "203.0.113.7, not-an-ip", "10.0.0.1", "10.0.0.1"},
+		{"garbage behind a trusted hop", "203.0.113.7, unknown, 10.0.0.2", "10.0.0.1", "10.0.0.2"},
+		{"garbage left of the client", "<script>, 203.0.113.7", "10.0.0.1", "203.0.113.7"},
+		{"out-of-range octet", "203.0.113.300", "10.0.0.1", "10.0.0.1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseForwardedFor(tt.header, trusted, net.ParseIP(tt.remote))
+			if !got.Equal(net.ParseIP(tt.want)) {
+				t.Errorf("ParseForwardedFor(%q) from %s = %s, want %s", tt.header, tt.remote, got, tt.want)
+			}
+		})
+	}
+}
+
+// Proxies that add their own header rather than extending the first are
+// read as one list.
+func TestRealIPJoinsHeaders(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.cfg.TrustedProxies = mustCIDRs(t, "10.0.0.0/8")
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:4321"
+	r.Header.Add("X-Forwarded-For", "8.8.8.8, 203.0.113.7")
+	r.Header.Add("X-Forwarded-For", "10.0.0.2")
+	if got := s.realIP(r); got != "203.0.113.7" {
+		t.Errorf("realIP = %s, want 203.0.113.7", got)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// withRequestID assigns a request ID unless an outer middleware already did.
+func withRequestID(r *http.Request) *http.Request {
+	if requestIDFromContext(r.Context()) != "" {
+		return r
+	}
+	id := strconv.FormatInt(time.Now().UnixNano(), 10)
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey, id))
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// recoverMiddleware turns a handler panic into a logged stack trace and a
+// JSON 500, instead of a dropped connection.
+func recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withRequestID(r)
+		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http uses ErrAbortHandler to abort a response silently;
+			// it must keep propagating.
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			panicsTotal.Inc()
+			log.Printf(
+				"PANIC request_id=%s method=%s path=%s panic=%v\n%s",
+				requestIDFromContext(r.Context()),
+				r.Method,
+				r.URL.Path,
+				rec,
+				debug.Stack(),
+			)
+
+			// Once bytes are on the wire the status can't be changed.
+			if !ww.wroteHeader {
+				apperror.WriteError(w, r, apperror.Internal(nil))
+			}
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// panicHandler backs /debug/panic, used to check recovery end to end.
+func panicHandler(w http.ResponseWriter, r *http.Request) {
+	panic("debug panic")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	quietLog(t)
+
+	t.Run("panic before writing", func(t *testing.T) {
+		before := testutil.ToFloat64(panicsTotal)
+		h := recoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var m map[string]int
+			m["boom"]++ // nil map write
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		env := readEnvelope(t, rec, http.StatusInternalServerError)
+		if env.Error.Code != "internal_error" {
+			t.Errorf("code %q, want internal_error", env.Error.Code)
+		}
+		if got := testutil.ToFloat64(panicsTotal) - before; got != 1 {
+			t.Errorf("panics_total went up by %v, want 1", got)
+		}
+	})
+
+	t.Run("panic after writing", func(t *testing.T) {
+		h := recoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("partial"))
+			panic("late")
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+			t.Errorf("got %d %q, want the partial 200 left alone", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("ErrAbortHandler propagates", func(t *testing.T) {
+		before := testutil.ToFloat64(panicsTotal)
+		h := recoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		defer func() {
+			if rec := recover(); rec != http.ErrAbortHandler {
+				t.Errorf("recovered %v, want http.ErrAbortHandler", rec)
+			}
+			if testutil.ToFloat64(panicsTotal) != before {
+				t.Error("an abort was counted as a panic")
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+// TestDebugPanicRoute drives a panic through the full router and a real
+// server: the client gets the JSON 500 with a request ID, and the server
+// keeps serving.
+func TestDebugPanicRoute(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	s.cfg.EnableDebugRoutes = true
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	for range 2 {
+		resp, err := http.Get(srv.URL + "/debug/panic")
+		if err != nil {
+			t.Fatalf("request failed, the connection was dropped: %v", err)
+		}
+		var env testEnvelope
+		err = json.NewDecoder(resp.Body).Decode(&env)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusInternalServerError || env.Error.Code != "internal_error" {
+			t.Errorf("got %d %+v, want 500 internal_error", resp.StatusCode, env)
+		}
+		if env.RequestID == "" {
+			t.Error("no request_id in the envelope")
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/livez")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/livez after the panics: %d", resp.StatusCode)
+	}
+}
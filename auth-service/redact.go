@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"regexp"
+)
+
+const redacted = "[REDACTED]"
+
+// secret is a string field of a request body, such as a password or a
+// single-use token, that must never be logged. Formatting, slog and JSON
+// encoding all print it as [REDACTED], so dumping a whole request struct
+// is safe; only an explicit string(...) conversion reveals the value, and
+// that should only happen where the value is used.
+type secret string
+
+func (secret) String() string               { return redacted }
+func (secret) GoString() string             { return `"` + redacted + `"` }
+func (secret) LogValue() slog.Value         { return slog.StringValue(redacted) }
+func (secret) MarshalJSON() ([]byte, error) { return json.Marshal(redacted) }
+
+// secretNames matches the names of fields and headers whose values are
+// masked by redact: anything containing password, token, secret,
+// authorization or cookie, such as refresh_token or Set-Cookie.
+const secretNames = `[\w-]*(?:password|token|secret|authorization|cookie)[\w-]*`
+
+var redactPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// "password": "..." in a JSON body.
+	{regexp.MustCompile(`(?i)("` + secretNames + `"\s*:\s*)"(?:[^"\\]|\\.)*"`), `$1"` + redacted + `"`},
+	// password=... in key=value log fields, form bodies and query strings.
+	{regexp.MustCompile(`(?i)\b(` + secretNames + `=)[^\s&"]+`), `${1}` + redacted},
+	// Authorization: ... and Cookie: ... in a header dump.
+	{regexp.MustCompile(`(?i)\b((?:authorization|cookie|set-cookie):[ \t]*)[^\r\n]+`), `${1}` + redacted},
+}
+
+// redact masks the values of secret-looking fields in s. It is a backstop
+// for text we don't control the shape of, like error strings and captured
+// bodies; request types should still hold secrets in secret fields.
+func redact(s string) string {
+	for _, p := range redactPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// redactingWriter passes each write through redact. main installs it as
+// the log package's output, so every log line is scrubbed, including
+// those from the standard library such as http.Server's error log. The
+// log package writes each entry in a single call, so a match is never
+// split between writes.
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (rw redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"resilient-auth-service/flags"
+)
+
+// secretMarker stands in for a password or token; it must never be
+// logged.
+const secretMarker = "hunter2-7f3a9c"
+
+func TestSecretFormatting(t *testing.T) {
+	req := loginRequest{Email: "a@example.com", Password: secretMarker}
+	var slogOut bytes.Buffer
+	slog.New(slog.NewJSONHandler(&slogOut, nil)).Info("login", "req", req, "password", req.Password)
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string]string{
+		"%v":   fmt.Sprintf("%v", req),
+		"%+v":  fmt.Sprintf("%+v", req),
+		"%#v":  fmt.Sprintf("%#v", req),
+		"%s":   fmt.Sprintf("%s", req.Password),
+		"%q":   fmt.Sprintf("%q", req.Password),
+		"json": string(b),
+		"slog": slogOut.String(),
+	} {
+		if strings.Contains(out, secretMarker) || !strings.Contains(out, redacted) {
+			t.Errorf("%s: %s", name, out)
+		}
+	}
+	if string(req.Password) != secretMarker {
+		t.Error("string(...) doesn't reveal the value")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{`{"email":"a@example.com","password":"p\"w"}`, `{"email":"a@example.com","password":"[REDACTED]"}`},
+		{`{"Refresh_Token" : "abc"}`, `{"Refresh_Token" : "[REDACTED]"}`},
+		{`login failed user=a password=abc attempt=2`, `login failed user=a password=[REDACTED] attempt=2`},
+		{`email=a%40example.com&new_password=abc`, `email=a%40example.com&new_password=[REDACTED]`},
+		{`GET /reset?token=abc&lang=en`, `GET /reset?token=[REDACTED]&lang=en`},
+		{"Authorization: Bearer abc\r\nAccept: */*", "Authorization: [REDACTED]\r\nAccept: */*"},
+		{"Cookie: session_id=abc; theme=dark", "Cookie: [REDACTED]"},
+		{"set-cookie: refresh_token=abc", "set-cookie: [REDACTED]"},
+		{`{"email":"a@example.com","role":"user"}`, `{"email":"a@example.com","role":"user"}`},
+	} {
+		if got := redact(tt.in); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := log.New(redactingWriter{&buf}, "", 0)
+	logger.Printf("decode error body=%s", `{"password":"`+secretMarker+`"}`)
+	if strings.Contains(buf.String(), secretMarker) {
+		t.Errorf("redactingWriter passed %q", buf.String())
+	}
+}
+
+// TestHandlersDontLogSecrets sends every API route a request carrying
+// the marker wherever a secret can go: in every secret-named body field,
+// in a body that doesn't parse, in the query, the Authorization header and
+// cookies. The marker must not show up in the log, which is
+// captured without redactingWriter so the handlers themselves are what's
+// tested.
+func TestHandlersDontLogSecrets(t *testing.T) {
+	var logs bytes.Buffer
+	saved := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(saved) })
+
+	s, _ := newTestServer(t)
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 10000, time.Minute)
+	for _, name := range []string{flagTOTPLogin, flagDeviceTrust, flagPasskeys} {
+		if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: name, Enabled: true, Percentage: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := newTestUser(t, s, "a@example.com", "admin")
+	auth := accessTokenCookie(t, s, user)
+	h := s.Handler()
+
+	fields := map[string]string{"email": "a@example.com"}
+	for _, name := range []string{"password", "new_password", "current_password", "token", "refresh_token",
+		"code", "flow_id", "secret", "captcha_token", "credential", "url"} {
+		fields[name] = secretMarker
+	}
+	good, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := []string{string(good), `{"password":"` + secretMarker + `",`, `{"password":` + secretMarker + `}`}
+	param := regexp.MustCompile(`\{[^}]+\}`)
+
+	for _, rt := range s.routes() {
+		path := apiPrefix + param.ReplaceAllString(rt.path, "1") + "?token=" + secretMarker
+		for _, body := range bodies {
+			// Streaming endpoints answer until the client leaves.
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			r := httptest.NewRequestWithContext(ctx, rt.method, path, strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Authorization", "Bearer "+secretMarker)
+			r.Header.Set("Idempotency-Key", secretMarker)
+			r.AddCookie(auth)
+			for _, name := range []string{"session_id", "refresh_token", "device_token"} {
+				r.AddCookie(&http.Cookie{Name: name, Value: secretMarker})
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			cancel()
+		}
+	}
+	// Emails and audit events are sent in the background.
+	time.Sleep(100 * time.Millisecond)
+
+	log.SetOutput(saved)
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, secretMarker) {
+			t.Errorf("logged: %s", line)
+		}
+	}
+}
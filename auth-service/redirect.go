@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net"
+	"net/http"
+)
+
+// httpsRedirectHandler serves the plain-HTTP listener that runs alongside
+// the TLS server. Every request is answered with 308 to the same URL over
+// https; 308 rather than 301 so a POST /login stays a POST.
+//
+// Behind a TLS-terminating load balancer (cfg.BehindProxy) requests it
+// received over HTTPS arrive here as HTTP with X-Forwarded-Proto: https.
+// Redirecting those would loop, so they are passed to next instead.
+func (s *Server) httpsRedirectHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.BehindProxy && r.Header.Get("X-Forwarded-Proto") == "https" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The redirect listener's port means nothing on the HTTPS side;
+		// the TLS listener is expected on the default port.
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+
+		w.Header().Set("Location", "https://"+host+r.URL.RequestURI())
+		w.WriteHeader(http.StatusPermanentRedirect)
+	})
+}
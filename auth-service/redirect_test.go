@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	s := &Server{cfg: testConfig}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := s.httpsRedirectHandler(next)
+
+	tests := []struct {
+		name        string
+		behindProxy bool
+		method      string
+		target      string
+		host        string
+		proto       string
+		wantCode    int
+		wantLoc     string
+	}{
+		{"plain GET", false, http.MethodGet, "/login?next=%2Fme", "auth.example.com",
+			"", http.StatusPermanentRedirect, "https://auth.example.com/login?next=%2Fme"},
+		{"POST keeps its method", false, http.MethodPost, "/login", "auth.example.com",
+			"", http.StatusPermanentRedirect, "https://auth.example.com/login"},
+		{"redirect port dropped", false, http.MethodGet, "/", "auth.example.com:8080",
+			"", http.StatusPermanentRedirect, "https://auth.example.com/"},
+		{"IPv6 host", false, http.MethodGet, "/x", "[::1]:8080",
+			"", http.StatusPermanentRedirect, "https://[::1]/x"},
+		{"IPv4 host", false, http.MethodGet, "/x", "127.0.0.1:8080",
+			"", http.StatusPermanentRedirect, "https://127.0.0.1/x"},
+		{"forwarded https behind a proxy", true, http.MethodGet, "/me", "auth.example.com",
+			"https", http.StatusTeapot, ""},
+		{"forwarded http behind a proxy", true, http.MethodGet, "/me", "auth.example.com",
+			"http", http.StatusPermanentRedirect, "https://auth.example.com/me"},
+		{"forwarded https not trusted", false, http.MethodGet, "/me", "auth.example.com",
+			"https", http.StatusPermanentRedirect, "https://auth.example.com/me"},
+	}
+	for _, tt := range tests {
+		s.cfg.BehindProxy = tt.behindProxy
+		r := httptest.NewRequest(tt.method, tt.target, nil)
+		r.Host = tt.host
+		if tt.proto != "" {
+			r.Header.Set("X-Forwarded-Proto", tt.proto)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.wantCode)
+		}
+		if got := rec.Header().Get("Location"); got != tt.wantLoc {
+			t.Errorf("%s: Location %q, want %q", tt.name, got, tt.wantLoc)
+		}
+	}
+}
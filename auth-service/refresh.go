@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	errRefreshTokenInvalid = errors.New("refresh token invalid")
+	errRefreshTokenExpired = errors.New("refresh token expired")
+	errRefreshTokenReuse   = errors.New("refresh token reuse detected")
+)
+
+// refreshTokenOwner identifies whose token family was touched by a rotation.
+type refreshTokenOwner struct {
+	UserID   int
+	Email    string
+	FamilyID string
+	// AuthTime is when the family was issued, i.e. when the user last
+	// actually logged in.
+	AuthTime time.Time
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Only a hash of the token is stored so a database leak can't be replayed.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueRefreshToken starts a new token family for the user (called on login).
+func (s *Server) issueRefreshToken(ctx context.Context, userID int) (string, error) {
+	token, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+		 VALUES ($1, $2, gen_random_uuid(), $3)`,
+		userID, hashRefreshToken(token), s.clock.Now().Add(refreshTokenTTL),
+	)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// revokeRefreshToken revokes a single token, e.g. one issued by a login
+// that then failed.
+func (s *Server) revokeRefreshToken(ctx context.Context, token string) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE refresh_tokens SET revoked = true WHERE token_hash = $1",
+		hashRefreshToken(token),
+	)
+	return err
+}
+
+// revokeUserRefreshTokens ends every token family the user has.
+func (s *Server) revokeUserRefreshTokens(ctx context.Context, userID int) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE refresh_tokens SET revoked = true WHERE user_id = $1 AND NOT revoked",
+		userID,
+	)
+	return err
+}
+
+// rotateRefreshToken revokes the presented token and issues its successor in
+// the same family. Presenting a token that was already rotated means it has
+// been copied: the whole family is revoked and errRefreshTokenReuse returned.
+func (s *Server) rotateRefreshToken(ctx context.Context, presented string) (refreshTokenOwner, string, error) {
+	var owner refreshTokenOwner
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return owner, "", err
+	}
+	defer tx.Rollback()
+
+	var (
+		tokenID   int
+		revoked   bool
+		expiresAt time.Time
+	)
+	// FOR UPDATE serializes concurrent rotations of the same token, so two
+	// racing requests can't both receive a successor.
+	err = tx.QueryRowContext(ctx,
+		`SELECT rt.id, rt.user_id, u.email, rt.family_id, rt.revoked, rt.expires_at,
+		        (SELECT min(f.created_at) FROM refresh_tokens f WHERE f.family_id = rt.family_id)
+		 FROM refresh_tokens rt JOIN active_users u ON u.id = rt.user_id
+		 WHERE rt.token_hash = $1
+		 FOR UPDATE OF rt`,
+		hashRefreshToken(presented),
+	).Scan(&tokenID, &owner.UserID, &owner.Email, &owner.FamilyID, &revoked, &expiresAt, &owner.AuthTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return owner, "", errRefreshTokenInvalid
+	}
+	if err != nil {
+		return owner, "", err
+	}
+
+	if revoked {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE refresh_tokens SET revoked = true WHERE family_id = $1",
+			owner.FamilyID,
+		)
+		if err != nil {
+			return owner, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return owner, "", err
+		}
+		return owner, "", errRefreshTokenReuse
+	}
+
+	if s.clock.Now().After(expiresAt) {
+		return owner, "", errRefreshTokenExpired
+	}
+
+	next, err := newRefreshToken()
+	if err != nil {
+		return owner, "", err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked = true WHERE id = $1", tokenID)
+	if err != nil {
+		return owner, "", err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+		 VALUES ($1, $2, $3, $4)`,
+		owner.UserID, hashRefreshToken(next), owner.FamilyID, s.clock.Now().Add(refreshTokenTTL),
+	)
+	if err != nil {
+		return owner, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return owner, "", err
+	}
+	return owner, next, nil
+}
+
+func (s *Server) refreshHandler(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("refresh_token")
+	if err != nil {
+		apperror.WriteError(w, r, apperror.Unauthorized("refresh_token_missing", "No refresh token"))
+		return
+	}
+
+	owner, next, err := s.rotateRefreshToken(r.Context(), cookie.Value)
+	switch {
+	case errors.Is(err, errRefreshTokenReuse):
+		if err := s.revokeUserSessions(r.Context(), owner.Email); err != nil {
+			log.Println("session revocation error:", err)
+		}
+		ev := s.auditEventFromRequest(r, "security.refresh_token_reuse")
+		ev.Level = "critical"
+		ev.ActorID = &owner.UserID
+		ev.Target = "token_family:" + owner.FamilyID
+		s.auditor.Record(r.Context(), ev)
+		clearAuthCookies(w)
+		apperror.WriteError(w, r, apperror.Unauthorized("refresh_token_reused", "Refresh token reuse detected; all sessions have been revoked"))
+		return
+	case errors.Is(err, errRefreshTokenInvalid), errors.Is(err, errRefreshTokenExpired):
+		clearAuthCookies(w)
+		apperror.WriteError(w, r, apperror.Unauthorized("refresh_token_invalid", "Invalid or expired refresh token"))
+		return
+	case err != nil:
+		log.Println("refresh token rotation error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	accessToken, err := s.signAccessToken(r.Context(), owner.UserID, owner.Email, owner.AuthTime)
+	if err != nil {
+		log.Println("access token sign error:", err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	s.setAuthCookies(w, accessToken, next)
+	w.Write([]byte("Token refreshed"))
+}
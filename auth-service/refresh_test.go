@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRefreshDB is a database/sql driver holding an in-memory
+// refresh_tokens table. It understands exactly the statements refresh.go
+// runs, so a test exercises the real rotation code, and fails loudly on
+// anything else.
+type fakeRefreshDB struct {
+	tokens   []*fakeRefreshToken
+	families int
+}
+
+type fakeRefreshToken struct {
+	id        int
+	userID    int
+	hash      string
+	familyID  string
+	revoked   bool
+	expiresAt time.Time
+	createdAt time.Time
+}
+
+func (f *fakeRefreshDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeRefreshDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeRefreshDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeRefreshDB: prepared statements not supported")
+}
+func (f *fakeRefreshDB) Close() error              { return nil }
+func (f *fakeRefreshDB) Begin() (driver.Tx, error) { return f, nil }
+
+// Statements apply immediately, which is all a single-goroutine test
+// needs from a transaction.
+func (f *fakeRefreshDB) Commit() error   { return nil }
+func (f *fakeRefreshDB) Rollback() error { return nil }
+
+func (f *fakeRefreshDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO refresh_tokens") && len(args) == 3:
+		f.families++
+		f.insert(args[0].Value, args[1].Value, fmt.Sprintf("family-%d", f.families), args[2].Value)
+	case strings.HasPrefix(query, "INSERT INTO refresh_tokens") && len(args) == 4:
+		f.insert(args[0].Value, args[1].Value, args[2].Value.(string), args[3].Value)
+	case query == "UPDATE refresh_tokens SET revoked = true WHERE id = $1":
+		for _, t := range f.tokens {
+			if int64(t.id) == args[0].Value.(int64) {
+				t.revoked = true
+			}
+		}
+	case query == "UPDATE refresh_tokens SET revoked = true WHERE token_hash = $1":
+		for _, t := range f.tokens {
+			if t.hash == args[0].Value.(string) {
+				t.revoked = true
+			}
+		}
+	case query == "UPDATE refresh_tokens SET revoked = true WHERE family_id = $1":
+		for _, t := range f.tokens {
+			if t.familyID == args[0].Value.(string) {
+				t.revoked = true
+			}
+		}
+	case query == "UPDATE refresh_tokens SET revoked = true WHERE user_id = $1 AND NOT revoked":
+		for _, t := range f.tokens {
+			if int64(t.userID) == args[0].Value.(int64) {
+				t.revoked = true
+			}
+		}
+	default:
+		return nil, fmt.Errorf("fakeRefreshDB: unexpected exec %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (f *fakeRefreshDB) insert(userID, hash any, familyID string, expiresAt any) {
+	f.tokens = append(f.tokens, &fakeRefreshToken{
+		id:        len(f.tokens) + 1,
+		userID:    int(userID.(int64)),
+		hash:      hash.(string),
+		familyID:  familyID,
+		expiresAt: expiresAt.(time.Time),
+		createdAt: time.Now(),
+	})
+}
+
+func (f *fakeRefreshDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "FROM refresh_tokens rt JOIN active_users u") {
+		return nil, fmt.Errorf("fakeRefreshDB: unexpected query %q", query)
+	}
+	rows := &fakeRows{cols: []string{"id", "user_id", "email", "family_id", "revoked", "expires_at", "min"}}
+	for _, t := range f.tokens {
+		if t.hash != args[0].Value.(string) {
+			continue
+		}
+		issued := t.createdAt
+		for _, o := range f.tokens {
+			if o.familyID == t.familyID && o.createdAt.Before(issued) {
+				issued = o.createdAt
+			}
+		}
+		rows.rows = append(rows.rows, []driver.Value{
+			int64(t.id), int64(t.userID), fmt.Sprintf("user%d@example.com", t.userID),
+			t.familyID, t.revoked, t.expiresAt, issued,
+		})
+	}
+	return rows, nil
+}
+
+// byHash finds the stored row for a plaintext token.
+func (f *fakeRefreshDB) byHash(token string) *fakeRefreshToken {
+	for _, t := range f.tokens {
+		if t.hash == hashRefreshToken(token) {
+			return t
+		}
+	}
+	return nil
+}
+
+// useFakeRefreshDB gives s a fake database holding refresh tokens.
+func useFakeRefreshDB(t testing.TB, s *Server) *fakeRefreshDB {
+	t.Helper()
+	fake := &fakeRefreshDB{}
+	s.db = sql.OpenDB(fake)
+	s.db.SetMaxOpenConns(1)
+	t.Cleanup(func() { s.db.Close() })
+	return fake
+}
+
+// TestRefreshTokenReuseRevokesFamily is the theft scenario: an attacker
+// copies token A, the victim rotates it to B, then the attacker replays A.
+// The replay must revoke B too, so neither party can keep refreshing.
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	s := &Server{clock: newFakeClock()}
+	fake := useFakeRefreshDB(t, s)
+	ctx := context.Background()
+
+	tokenA, err := s.issueRefreshToken(ctx, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner, tokenB, err := s.rotateRefreshToken(ctx, tokenA)
+	if err != nil {
+		t.Fatalf("rotate A: %v", err)
+	}
+	if owner.UserID != 42 || tokenB == "" || tokenB == tokenA {
+		t.Fatalf("rotate A: got owner %+v, token %q", owner, tokenB)
+	}
+	if !fake.byHash(tokenA).revoked || fake.byHash(tokenB).revoked {
+		t.Fatal("after rotation, A should be revoked and B live")
+	}
+	if fake.byHash(tokenA).familyID != fake.byHash(tokenB).familyID {
+		t.Fatal("B should join A's family")
+	}
+
+	owner, next, err := s.rotateRefreshToken(ctx, tokenA)
+	if !errors.Is(err, errRefreshTokenReuse) {
+		t.Fatalf("replay A: got %v, want errRefreshTokenReuse", err)
+	}
+	if next != "" {
+		t.Errorf("replay A issued a token")
+	}
+	if owner.Email != "user42@example.com" || owner.FamilyID != fake.byHash(tokenA).familyID {
+		t.Errorf("replay A: owner %+v doesn't identify the family", owner)
+	}
+
+	if !fake.byHash(tokenB).revoked {
+		t.Fatal("replaying A left B live")
+	}
+	if _, _, err := s.rotateRefreshToken(ctx, tokenB); !errors.Is(err, errRefreshTokenReuse) {
+		t.Fatalf("rotate B after theft: got %v, want errRefreshTokenReuse", err)
+	}
+}
+
+// TestRefreshTokenReuseLeavesOtherFamilies checks that revocation is
+// scoped to the stolen family, not every login the user has.
+func TestRefreshTokenReuseLeavesOtherFamilies(t *testing.T) {
+	s := &Server{clock: newFakeClock()}
+	fake := useFakeRefreshDB(t, s)
+	ctx := context.Background()
+
+	stolen, err := s.issueRefreshToken(ctx, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.issueRefreshToken(ctx, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.rotateRefreshToken(ctx, stolen); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.rotateRefreshToken(ctx, stolen); !errors.Is(err, errRefreshTokenReuse) {
+		t.Fatalf("replay: got %v, want errRefreshTokenReuse", err)
+	}
+	if fake.byHash(other).revoked {
+		t.Error("reuse in one family revoked another")
+	}
+}
+
+func TestRefreshTokenUnknown(t *testing.T) {
+	s := &Server{clock: newFakeClock()}
+	useFakeRefreshDB(t, s)
+	if _, _, err := s.rotateRefreshToken(context.Background(), "no-such-token"); !errors.Is(err, errRefreshTokenInvalid) {
+		t.Fatalf("got %v, want errRefreshTokenInvalid", err)
+	}
+}
+
+// A refresh token works until refreshTokenTTL has passed and not after.
+func TestRefreshTokenExpiry(t *testing.T) {
+	clock := newFakeClock()
+	s := &Server{clock: clock}
+	useFakeRefreshDB(t, s)
+	ctx := context.Background()
+
+	token, err := s.issueRefreshToken(ctx, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(refreshTokenTTL - time.Second)
+	_, next, err := s.rotateRefreshToken(ctx, token)
+	if err != nil {
+		t.Fatalf("a second before expiry: %v", err)
+	}
+
+	// The successor's lifetime starts at its rotation.
+	clock.Advance(refreshTokenTTL + time.Second)
+	if _, _, err := s.rotateRefreshToken(ctx, next); !errors.Is(err, errRefreshTokenExpired) {
+		t.Fatalf("after expiry: got %v, want errRefreshTokenExpired", err)
+	}
+}
+
+// FuzzRefreshToken presents arbitrary refresh_token cookie values. Only
+// the one issued token may rotate; anything else is simply invalid.
+func FuzzRefreshToken(f *testing.F) {
+	f.Add("")
+	f.Add("no-such-token")
+	f.Add("' OR 1=1 --")
+	f.Add("\x00")
+
+	f.Fuzz(func(t *testing.T, presented string) {
+		s := &Server{clock: newFakeClock()}
+		useFakeRefreshDB(t, s)
+		ctx := context.Background()
+		issued, err := s.issueRefreshToken(ctx, 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if presented == issued {
+			return
+		}
+		if _, _, err := s.rotateRefreshToken(ctx, presented); !errors.Is(err, errRefreshTokenInvalid) {
+			t.Fatalf("got %v, want errRefreshTokenInvalid", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"resilient-auth-service/apperror"
+)
+
+const (
+	// registrationAttemptInterval limits "you already have an account"
+	// emails per address, so registering in a loop can't flood an inbox.
+	registrationAttemptInterval = time.Hour
+	registrationEmailTimeout    = 30 * time.Second
+)
+
+// registrationResponse is the body of every successful POST /register,
+// whether or not the email was already taken, so the response doesn't
+// reveal which addresses have accounts. The email tells the user which
+// case they're in.
+var registrationResponse = map[string]string{"message": "Please check your email to continue"}
+
+// sendRegistrationEmail tells email the outcome of a registration in the
+// background: a welcome if the account was created, a notice that they
+// already have one if not. Both paths do the same work before the
+// response, so timing doesn't tell them apart either.
+func (s *Server) sendRegistrationEmail(ctx context.Context, email string, created bool) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), registrationEmailTimeout)
+		defer cancel()
+
+		tmpl := "welcome"
+		data := EmailTemplateData{Email: email, Link: s.cfg.AppURL + "/login", Time: time.Now().UTC()}
+		if !created {
+			fresh, err := s.rdb.SetNX(ctx, "registration_attempt_sent:"+email, 1, registrationAttemptInterval).Result()
+			if err != nil {
+				log.Println("registration attempt throttle error:", err)
+				return
+			}
+			if !fresh {
+				return
+			}
+			tmpl = "registration-attempt"
+			data.Link = s.cfg.AppURL + "/forgot-password"
+		}
+
+		msg, err := newEmail(ctx, email, tmpl, data)
+		if err == nil {
+			err = s.emailSender.Send(ctx, msg)
+		}
+		if err != nil {
+			log.Println("registration email error:", err)
+		}
+	}()
+}
+
+type registerRequest struct {
+	Email    string `json:"email"`
+	Password secret `json:"password"`
+	// CaptchaToken is required when CAPTCHA_PROVIDER is set.
+	CaptchaToken string `json:"captcha_token"`
+}
+
+func (req registerRequest) validate() error {
+	var v validator
+	v.email("email", req.Email)
+	v.password("password", string(req.Password))
+	return v.err()
+}
+
+func (s *Server) registerHandler(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	if !s.verifyCaptcha(w, r, req.CaptchaToken) {
+		return
+	}
+
+	hash, err := s.passwordHasher.HashPassword(r.Context(), string(req.Password))
+	if errors.Is(err, errBcryptBusy) {
+		writeServerBusy(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("register hash error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		apperror.WriteError(w, r, apperror.Internal(nil))
+		return
+	}
+
+	user, err := s.users.Create(r.Context(), req.Email, string(hash))
+	if errors.Is(err, ErrDuplicateEmail) {
+		ev := s.auditEventFromRequest(r, "user.register_existing")
+		ev.Metadata = map[string]any{"email": req.Email}
+		s.auditor.Record(r.Context(), ev)
+
+		s.sendRegistrationEmail(r.Context(), req.Email, false)
+		writeJSON(w, http.StatusCreated, registrationResponse)
+		return
+	}
+	if err != nil {
+		log.Printf("register error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "user.register")
+	ev.ActorID = &user.ID
+	ev.Target = "user:" + strconv.Itoa(user.ID)
+	s.auditor.Record(r.Context(), ev)
+
+	s.webhooks.Enqueue(r.Context(), "user.registered", map[string]any{"user_id": user.ID, "email": req.Email})
+
+	s.sendRegistrationEmail(r.Context(), req.Email, true)
+	writeJSON(w, http.StatusCreated, registrationResponse)
+}
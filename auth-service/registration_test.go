@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// register posts a registration through the full router.
+func register(t *testing.T, h http.Handler, idempotencyKey, email string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/v1/register",
+		strings.NewReader(`{"email":"`+email+`","password":"correct horse"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		r.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+// TestRegisterIdempotencyKey is a mobile client whose first response was
+// lost: its retries with the same key must not register twice, and a key
+// reused for a different registration is refused until it expires.
+func TestRegisterIdempotencyKey(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	h := s.Handler()
+	ctx := context.Background()
+	const key = "6f1c2c1e-4c38-4c1e-9d4b-1f0c1d2e3f40"
+
+	first := register(t, h, key, "a@example.com")
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first request: %d %v %s", first.Code, first.Header(), first.Body)
+	}
+	user, err := s.users.GetByEmail(ctx, "a@example.com")
+	if err != nil {
+		t.Fatalf("first request didn't create the user: %v", err)
+	}
+
+	t.Run("retry with the same body", func(t *testing.T) {
+		retry := register(t, h, key, "a@example.com")
+		if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+			t.Errorf("got %d %s, want the first response %d %s", retry.Code, retry.Body, first.Code, first.Body)
+		}
+		if retry.Header().Get("Idempotent-Replayed") != "true" {
+			t.Error("the retry wasn't marked as a replay")
+		}
+		if got, _ := s.users.GetByEmail(ctx, "a@example.com"); got.ID != user.ID {
+			t.Errorf("user %d after the retry, want %d", got.ID, user.ID)
+		}
+	})
+
+	t.Run("retry with a different body", func(t *testing.T) {
+		env := readEnvelope(t, register(t, h, key, "b@example.com"), http.StatusConflict)
+		if env.Error.Code != "idempotency_conflict" {
+			t.Errorf("code %q, want idempotency_conflict", env.Error.Code)
+		}
+		if _, err := s.users.GetByEmail(ctx, "b@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("the conflicting request registered b@example.com: %v", err)
+		}
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		mr.FastForward(idempotencyTTL + time.Second)
+		rec := register(t, h, key, "b@example.com")
+		if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatalf("after expiry: %d %v %s", rec.Code, rec.Header(), rec.Body)
+		}
+		if _, err := s.users.GetByEmail(ctx, "b@example.com"); err != nil {
+			t.Errorf("after expiry the request should run: %v", err)
+		}
+	})
+}
+
+// Without a key, every request runs; a taken email still answers 201 so
+// the response doesn't reveal it.
+func TestRegisterWithoutIdempotencyKey(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	h := s.Handler()
+
+	for range 2 {
+		rec := register(t, h, "", "a@example.com")
+		if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatalf("got %d %v %s", rec.Code, rec.Header(), rec.Body)
+		}
+	}
+	if users, _ := s.users.List(context.Background(), nil, 10); len(users) != 1 {
+		t.Errorf("%d users, want 1", len(users))
+	}
+}
+
+// TestRegisterExistingEmail registers a taken email: the response is the
+// same as for a new one, and the owner is told by email, at most once an
+// hour.
+func TestRegisterExistingEmail(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	h := s.Handler()
+	emails := s.emailSender.(*fakeEmailSender)
+	waitForEmails := func(n int) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); len(emails.messages()) < n; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d emails sent, want %d", len(emails.messages()), n)
+			}
+		}
+	}
+
+	created := register(t, h, "", "a@example.com")
+	waitForEmails(1)
+	taken := register(t, h, "", "a@example.com")
+	waitForEmails(2)
+	if created.Code != http.StatusCreated || taken.Code != created.Code || taken.Body.String() != created.Body.String() {
+		t.Errorf("new email: %d %s; taken email: %d %s; want the same", created.Code, created.Body, taken.Code, taken.Body)
+	}
+	register(t, h, "", "a@example.com")
+	mr.FastForward(registrationAttemptInterval)
+	register(t, h, "", "a@example.com")
+	waitForEmails(3)
+	time.Sleep(50 * time.Millisecond)
+
+	var subjects []string
+	for _, m := range emails.messages() {
+		subjects = append(subjects, m.Subject)
+	}
+	want := []string{"Welcome", "Someone tried to sign up with your email", "Someone tried to sign up with your email"}
+	if strings.Join(subjects, "|") != strings.Join(want, "|") {
+		t.Errorf("emails %q, want %q", subjects, want)
+	}
+}
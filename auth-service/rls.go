@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// withUserScope runs fn in a transaction as the authdb_app role with
+// app.user_id set, so row-level security limits every statement in fn to the
+// given user's rows. Use it for any query made on behalf of an authenticated
+// user; a bug in the WHERE clause then returns nothing instead of someone
+// else's data.
+func (s *Server) withUserScope(ctx context.Context, userID int, fn func(tx *sql.Tx) error) error {
+	return userScope(ctx, s.db, userID, fn)
+}
+
+func userScope(ctx context.Context, db *sql.DB, userID int, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE authdb_app"); err != nil {
+		return err
+	}
+	// SET LOCAL can't take bind parameters; set_config(..., true) is the
+	// transaction-scoped equivalent that can.
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('app.user_id', $1, true)", strconv.Itoa(userID)); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withAdminScope runs fn as authdb_admin, which bypasses row-level security.
+// Only admin endpoints that legitimately span users (e.g. listing) may use it.
+func (s *Server) withAdminScope(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return adminScope(ctx, s.db, fn)
+}
+
+func adminScope(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE authdb_admin"); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withTx runs fn in a transaction as the service's own role, for tables
+// without row-level security, whose handlers check access themselves.
+func (s *Server) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingDB logs every statement and transaction boundary it is sent,
+// with its arguments, and succeeds at all of them.
+type recordingDB struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (d *recordingDB) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *recordingDB) Driver() driver.Driver                        { return nil }
+func (d *recordingDB) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (d *recordingDB) Close() error                                 { return nil }
+func (d *recordingDB) Begin() (driver.Tx, error)                    { d.record("BEGIN"); return d, nil }
+func (d *recordingDB) Commit() error                                { d.record("COMMIT"); return nil }
+func (d *recordingDB) Rollback() error                              { d.record("ROLLBACK"); return nil }
+
+func (d *recordingDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	for _, a := range args {
+		query += fmt.Sprintf(" [%v]", a.Value)
+	}
+	d.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (d *recordingDB) record(s string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, s)
+}
+
+func TestScopes(t *testing.T) {
+	ctx := context.Background()
+	errFn := errors.New("fn failed")
+	for _, tt := range []struct {
+		name  string
+		scope func(db *sql.DB, fn func(tx *sql.Tx) error) error
+		fnErr error
+		want  []string
+	}{
+		{"user", func(db *sql.DB, fn func(tx *sql.Tx) error) error { return userScope(ctx, db, 7, fn) }, nil, []string{
+			"BEGIN", "SET LOCAL ROLE authdb_app", "SELECT set_config('app.user_id', $1, true) [7]", "UPDATE", "COMMIT",
+		}},
+		{"user, fn fails", func(db *sql.DB, fn func(tx *sql.Tx) error) error { return userScope(ctx, db, 7, fn) }, errFn, []string{
+			"BEGIN", "SET LOCAL ROLE authdb_app", "SELECT set_config('app.user_id', $1, true) [7]", "UPDATE", "ROLLBACK",
+		}},
+		{"admin", func(db *sql.DB, fn func(tx *sql.Tx) error) error { return adminScope(ctx, db, fn) }, nil, []string{
+			"BEGIN", "SET LOCAL ROLE authdb_admin", "UPDATE", "COMMIT",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingDB{}
+			db := sql.OpenDB(rec)
+			defer db.Close()
+			err := tt.scope(db, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, "UPDATE"); err != nil {
+					return err
+				}
+				return tt.fnErr
+			})
+			if !errors.Is(err, tt.fnErr) {
+				t.Errorf("err %v, want %v", err, tt.fnErr)
+			}
+			if !slices.Equal(rec.log, tt.want) {
+				t.Errorf("sent\n\t%s\nwant\n\t%s", strings.Join(rec.log, "\n\t"), strings.Join(tt.want, "\n\t"))
+			}
+		})
+	}
+}
+
+// TestRowLevelSecurity checks the policies themselves against a real
+// database when TEST_DATABASE_URL names one: as authdb_app, a user can't
+// read or change another's row even when the query asks for it, and
+// authdb_admin sees everyone. It migrates the database and empties users,
+// so point it at a scratch database.
+func TestRowLevelSecurity(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	if err := (&Migrator{db: db}).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "TRUNCATE users, outbox_events RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatal(err)
+	}
+	var a, b int
+	for email, id := range map[string]*int{"a@example.com": &a, "b@example.com": &b} {
+		err := db.QueryRowContext(ctx,
+			"INSERT INTO users (email, password_hash) VALUES ($1, 'x') RETURNING id", email).Scan(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = userScope(ctx, db, a, func(tx *sql.Tx) error {
+		var email string
+		err := tx.QueryRowContext(ctx, "SELECT email FROM users WHERE id = $1", b).Scan(&email)
+		if err != sql.ErrNoRows {
+			t.Errorf("read another user's row: %q, %v; want no rows", email, err)
+		}
+		res, err := tx.ExecContext(ctx, "UPDATE users SET email = 'stolen@example.com' WHERE id = $1", b)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n != 0 {
+			t.Errorf("updated %d of another user's rows", n)
+		}
+		var own string
+		return tx.QueryRowContext(ctx, "SELECT email FROM users WHERE id = $1", a).Scan(&own)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = adminScope(ctx, db, func(tx *sql.Tx) error {
+		var n int
+		if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM users").Scan(&n); err != nil {
+			return err
+		}
+		if n != 2 {
+			t.Errorf("admin sees %d users, want 2", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
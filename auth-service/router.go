@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"resilient-auth-service/apperror"
+)
+
+// apiVersions are the API versions served, oldest first. Every route is
+// mounted under each version's prefix (/v1, /v2, ...); the versions share
+// handlers, which branch on apiVersionFromContext where a response's shape
+// differs.
+var apiVersions = []int{1, 2}
+
+// apiPrefix is the version the legacy unversioned aliases belong to.
+const apiPrefix = "/v1"
+
+// route is one API endpoint, served at "/v<n>"+path for every API version.
+// Routes that predate versioning set legacy and are also served at the bare
+// path, marked deprecated; those aliases go away in the next release, so
+// new routes should not set it.
+type route struct {
+	method  string
+	path    string
+	handler http.Handler
+	legacy  bool
+}
+
+// Middleware chains. recoverMiddleware is always outermost so a panic
+// anywhere in the chain still gets a response and a stack trace. Security
+// headers and CORS come next so preflights get them and are answered before
+// rate limiting or auth. noStoreMiddleware marks auth-flow responses.
+
+// publicHandler is the chain for unauthenticated auth-flow endpoints.
+func (s *Server) publicHandler(h http.Handler) http.Handler {
+	return recoverMiddleware(s.securityHeadersMiddleware(s.noStoreMiddleware(s.corsMiddleware(
+		s.rateLimitMiddleware(loggingMiddleware(h)),
+	))))
+}
+
+// jwtHandler is the chain for endpoints authenticated by the access token.
+func (s *Server) jwtHandler(h http.HandlerFunc) http.Handler {
+	return recoverMiddleware(s.securityHeadersMiddleware(s.noStoreMiddleware(s.corsMiddleware(
+		s.jwtMiddleware(s.rateLimitMiddleware(loggingMiddleware(h))),
+	))))
+}
+
+// sessionHandler is the chain for endpoints authenticated by the Redis
+// session.
+func (s *Server) sessionHandler(h http.HandlerFunc) http.Handler {
+	return recoverMiddleware(s.securityHeadersMiddleware(s.noStoreMiddleware(s.corsMiddleware(
+		s.authMiddleware(s.rateLimitMiddleware(loggingMiddleware(h))),
+	))))
+}
+
+// probeHandler is the chain for probes, scrapers and static documents.
+// Kubelets and Prometheus poll these from a few fixed IPs, so rate limiting
+// them would answer healthy pods with 429s, and logging them would bury
+// the access log.
+func (s *Server) probeHandler(h http.Handler) http.Handler {
+	return recoverMiddleware(s.securityHeadersMiddleware(s.corsMiddleware(h)))
+}
+
+// limitedOpsHandler is the chain for unversioned endpoints that are
+// rate limited and logged like the client API.
+func (s *Server) limitedOpsHandler(h http.Handler) http.Handler {
+	return recoverMiddleware(s.securityHeadersMiddleware(s.corsMiddleware(
+		s.rateLimitMiddleware(loggingMiddleware(h)),
+	)))
+}
+
+// opsRoute is an unversioned endpoint; pattern is a ServeMux pattern.
+type opsRoute struct {
+	pattern string
+	handler http.Handler
+}
+
+// opsRoutes are the endpoints outside the client API: probes, the metrics
+// scraper, the API docs and the JWKS (whose path is fixed by convention).
+// They stay unversioned, and each row's chain says whether it is rate
+// limited.
+func (s *Server) opsRoutes() []opsRoute {
+	spec, err := openAPIJSON()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rs := []opsRoute{
+		{"GET /livez", s.probeHandler(http.HandlerFunc(livezHandler))},
+		{"GET /readyz", s.probeHandler(http.HandlerFunc(s.readyzHandler))},
+		{"GET /health", s.probeHandler(http.HandlerFunc(s.healthHandler))},
+		{"GET /version", s.probeHandler(http.HandlerFunc(versionHandler))},
+		{"GET /metrics", s.probeHandler(promhttp.Handler())},
+		{"GET /openapi.json", s.probeHandler(openAPIHandler(spec))},
+		{"GET /.well-known/jwks.json", s.limitedOpsHandler(http.HandlerFunc(s.jwksHandler))},
+	}
+	if s.cfg.EnableAPIDocs {
+		rs = append(rs, opsRoute{"GET /docs", s.probeHandler(http.HandlerFunc(apiDocsHandler))})
+	}
+	return rs
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{"POST", "/register", s.publicHandler(s.idempotencyMiddleware(http.HandlerFunc(s.registerHandler))), true},
+		{"POST", "/login", s.publicHandler(http.HandlerFunc(s.loginHandler)), true},
+		{"POST", "/login/totp", s.publicHandler(s.requireFlag(flagTOTPLogin, http.HandlerFunc(s.loginTOTPHandler))), true},
+		{"POST", "/login/device-trust", s.publicHandler(s.requireFlag(flagDeviceTrust, http.HandlerFunc(s.loginDeviceTrustHandler))), true},
+		{"POST", "/webauthn/login/begin", s.publicHandler(s.requireFlag(flagPasskeys, http.HandlerFunc(s.passkeyLoginBeginHandler))), false},
+		{"POST", "/webauthn/login/finish", s.publicHandler(s.requireFlag(flagPasskeys, http.HandlerFunc(s.passkeyLoginFinishHandler))), false},
+		{"POST", "/refresh", s.publicHandler(http.HandlerFunc(s.refreshHandler)), true},
+		{"POST", "/sessions/revoke", s.publicHandler(http.HandlerFunc(s.revokeSessionHandler)), false},
+		{"POST", "/forgot-password", s.publicHandler(http.HandlerFunc(s.forgotPasswordHandler)), false},
+		{"POST", "/reset-password", s.publicHandler(http.HandlerFunc(s.resetPasswordHandler)), false},
+
+		{"GET", "/me", s.jwtHandler(s.meHandler), true},
+		{"PUT", "/me", s.jwtHandler(s.meMetadataHandler), true},
+		{"GET", "/me/events", s.sessionHandler(s.sessionEventsHandler), true},
+		{"GET", "/me/sessions", s.sessionHandler(s.mySessionsHandler), false},
+		{"GET", "/me/login-history", s.jwtHandler(s.loginHistoryHandler), false},
+		{"GET", "/me/export", s.jwtHandler(s.requireRecentAuth(s.meExportHandler)), false},
+		{"POST", "/2fa/setup", s.jwtHandler(s.requireRecentAuth(s.twoFactorSetupHandler)), false},
+		{"POST", "/2fa/confirm", s.jwtHandler(s.requireRecentAuth(s.twoFactorConfirmHandler)), false},
+		{"POST", "/2fa/backup-codes", s.jwtHandler(s.requireRecentAuth(s.twoFactorBackupCodesHandler)), false},
+		{"POST", "/webauthn/register/begin", s.jwtHandler(s.requireFlag(flagPasskeys, s.requireRecentAuth(s.passkeyRegisterBeginHandler)).ServeHTTP), false},
+		{"POST", "/webauthn/register/finish", s.jwtHandler(s.requireFlag(flagPasskeys, s.requireRecentAuth(s.passkeyRegisterFinishHandler)).ServeHTTP), false},
+
+		{"POST", "/orgs", s.orgHandler("", s.createOrgHandler), false},
+		{"GET", "/orgs", s.orgHandler("", s.listOrgsHandler), false},
+		{"PUT", "/me/org", s.orgHandler("", s.switchOrgHandler), false},
+		{"GET", "/me/org-invitations", s.orgHandler("", s.listOrgInvitationsHandler), false},
+		{"POST", "/me/org-invitations/{org_id}/accept", s.orgHandler("", s.acceptOrgInvitationHandler), false},
+		{"GET", "/org/members", s.orgHandler(orgRoleMember, s.listOrgMembersHandler), false},
+		{"DELETE", "/org/members/{user_id}", s.orgHandler(orgRoleMember, s.removeOrgMemberHandler), false},
+		{"POST", "/org/invitations", s.orgHandler(orgRoleAdmin, s.inviteOrgMemberHandler), false},
+		{"GET", "/org/invitations", s.orgHandler(orgRoleAdmin, s.listSentOrgInvitationsHandler), false},
+		{"DELETE", "/org/invitations/{id}", s.orgHandler(orgRoleAdmin, s.revokeOrgInvitationHandler), false},
+		{"GET", "/invitations/{token}", s.publicHandler(http.HandlerFunc(s.previewOrgInvitationHandler)), false},
+		{"POST", "/invitations/{token}/accept", s.orgInvitationAcceptHandler(), false},
+		{"DELETE", "/org", s.orgHandler(orgRoleOwner, s.deleteOrgHandler), false},
+
+		{"GET", "/admin/users", s.adminHandler(s.adminUsersHandler), true},
+		{"GET", "/admin/users/{id}", s.adminHandler(s.adminUserHandler), true},
+		{"DELETE", "/admin/users/{id}", s.adminHandler(s.adminDeleteUserHandler), false},
+		{"GET", "/admin/audit", s.adminHandler(s.adminAuditHandler), true},
+		{"GET", "/admin/audit/stream", s.adminHandler(s.adminAuditStreamHandler), false},
+		{"GET", "/admin/events/stream", s.adminHandler(s.adminEventsSocketHandler), false},
+		{"POST", "/admin/keys/rotate", s.adminHandler(s.adminRotateKeysHandler), false},
+		{"GET", "/admin/schema-version", s.adminHandler(s.adminSchemaVersionHandler), true},
+		{"GET", "/admin/monitoring/alerts", s.adminHandler(adminAlertRulesHandler), false},
+		{"GET", "/admin/maintenance", s.adminHandler(s.adminGetMaintenanceHandler), true},
+		{"PUT", "/admin/maintenance", s.adminHandler(s.adminSetMaintenanceHandler), true},
+		{"GET", "/admin/flags", s.adminHandler(s.adminListFlagsHandler), true},
+		{"PUT", "/admin/flags/{name}", s.adminHandler(s.adminSetFlagHandler), true},
+		{"GET", "/admin/webhooks", s.adminHandler(s.adminListWebhooksHandler), true},
+		{"POST", "/admin/webhooks", s.adminHandler(s.adminCreateWebhookHandler), true},
+		{"POST", "/admin/webhooks/test", s.adminHandler(s.adminTestWebhookHandler), true},
+		{"GET", "/admin/webhooks/{id}", s.adminHandler(s.adminGetWebhookHandler), true},
+		{"PUT", "/admin/webhooks/{id}", s.adminHandler(s.adminUpdateWebhookHandler), true},
+		{"DELETE", "/admin/webhooks/{id}", s.adminHandler(s.adminDeleteWebhookHandler), true},
+		{"GET", "/admin/webhooks/{id}/deliveries", s.adminHandler(s.adminWebhookDeliveriesHandler), true},
+	}
+}
+
+// Handler builds the service's HTTP handler. main serves it; tests drive it
+// directly on a Server built around fakes.
+//
+// A request for a known path with the wrong method gets 405 with an Allow
+// header, and an unknown path gets a JSON 404, both in the usual error
+// envelope. Maintenance mode applies to every route, so it wraps the lot,
+// and the locale for error messages is picked before anything can fail.
+// recoverMiddleware is outermost here too, so a panic in those wrappers
+// is answered like one in a handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	for _, rt := range s.opsRoutes() {
+		mux.Handle(rt.pattern, rt.handler)
+	}
+
+	allowed := map[string][]string{}
+	for _, rt := range s.routes() {
+		for _, v := range apiVersions {
+			path := versionPrefix(v) + rt.path
+			mux.Handle(rt.method+" "+path, s.versionMiddleware(v, rt.handler))
+			allowed[path] = append(allowed[path], rt.method)
+		}
+		if rt.legacy {
+			mux.Handle(rt.method+" "+rt.path, deprecatedHandler(s.versionMiddleware(0, rt.handler)))
+			allowed[rt.path] = append(allowed[rt.path], rt.method)
+		}
+	}
+	// Method patterns don't match OPTIONS, so CORS preflights need routes
+	// of their own.
+	for path, methods := range allowed {
+		mux.Handle("OPTIONS "+path, s.optionsHandler(methods))
+	}
+
+	if s.cfg.EnableDebugRoutes {
+		mux.Handle("/debug/panic",
+			recoverMiddleware(loggingMiddleware(http.HandlerFunc(panicHandler))),
+		)
+	}
+
+	return recoverMiddleware(messages.Middleware(s.maintenanceMiddleware(s.jsonMuxErrors(mux))))
+}
+
+// deprecatedHandler serves an unversioned alias, pointing clients at the
+// /v1 path that replaces it.
+func deprecatedHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+apiPrefix+r.URL.Path+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// optionsHandler answers OPTIONS for a path: CORS preflights via
+// corsMiddleware, anything else with the path's Allow list.
+func (s *Server) optionsHandler(methods []string) http.Handler {
+	methods = append(slices.Clone(methods), http.MethodOptions)
+	if slices.Contains(methods, http.MethodGet) {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+	allow := strings.Join(slices.Compact(methods), ", ")
+
+	return recoverMiddleware(s.securityHeadersMiddleware(s.corsMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)))
+}
+
+// jsonMuxErrors replaces ServeMux's plain-text 404 and 405 responses with
+// the JSON error envelope. The mux's own handler still runs, so the Allow
+// header it computes for a 405 is kept; only its body is dropped.
+func (s *Server) jsonMuxErrors(mux *http.ServeMux) http.Handler {
+	writeMuxError := s.securityHeadersMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, _ := mux.Handler(r)
+		rec := &statusRecorder{header: w.Header(), status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		if rec.status == http.StatusMethodNotAllowed {
+			apperror.WriteError(w, r, apperror.New(http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed"))
+			return
+		}
+		apperror.WriteError(w, r, apperror.NotFound("not_found", "Not found"))
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern == "" {
+			writeMuxError.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder captures a handler's status code and discards its body.
+// Headers go straight to the real response.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (s *statusRecorder) Header() http.Header         { return s.header }
+func (s *statusRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (s *statusRecorder) WriteHeader(code int)        { s.status = code }
+
+// unversionedPath strips the version prefix, so checks on the path work the
+// same for every version of a route and its legacy alias.
+func unversionedPath(path string) string {
+	for _, v := range apiVersions {
+		if rest, ok := strings.CutPrefix(path, versionPrefix(v)); ok && strings.HasPrefix(rest, "/") {
+			return rest
+		}
+	}
+	return path
+}
+
+func versionPrefix(v int) string {
+	return "/v" + strconv.Itoa(v)
+}
+
+// apiPaths lists every path a legacy route is served at, for cookies that
+// must be scoped to one endpoint.
+func apiPaths(path string) []string {
+	paths := make([]string, 0, len(apiVersions)+1)
+	for _, v := range apiVersions {
+		paths = append(paths, versionPrefix(v)+path)
+	}
+	return append(paths, path)
+}
+
+const apiVersionKey contextKey = "apiVersion"
+
+// apiVersionFromContext returns the API version the request was made
+// against, 1 outside of API routes.
+func apiVersionFromContext(ctx context.Context) int {
+	if v, ok := ctx.Value(apiVersionKey).(int); ok {
+		return v
+	}
+	return 1
+}
+
+// versionMiddleware records the API version of a request in its context.
+// Under a version prefix that's the prefix's version (path is 0 for the
+// unversioned aliases); otherwise the client can ask for one with
+// "Accept: application/vnd.auth.v2+json", and gets v1 if it doesn't. A
+// vendor type naming a version we don't serve, or contradicting the path,
+// gets 406.
+func (s *Server) versionMiddleware(path int, next http.Handler) http.Handler {
+	reject := s.securityHeadersMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apperror.WriteError(w, r, apperror.New(http.StatusNotAcceptable, "unsupported_version",
+			"Unsupported API version requested in Accept"))
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, requested := acceptedAPIVersion(r.Header.Values("Accept"))
+		switch {
+		case requested && !slices.Contains(apiVersions, v):
+			reject.ServeHTTP(w, r)
+			return
+		case path != 0 && requested && v != path:
+			reject.ServeHTTP(w, r)
+			return
+		case path != 0:
+			v = path
+		case !requested:
+			v = 1
+		}
+		if path == 0 {
+			// The response depends on Accept here, so caches must too.
+			w.Header().Add("Vary", "Accept")
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey, v)))
+	})
+}
+
+// acceptedAPIVersion finds an application/vnd.auth.v<n>+json media range in
+// Accept headers. ok is false if there is none.
+func acceptedAPIVersion(accept []string) (v int, ok bool) {
+	for _, header := range accept {
+		for _, mediaRange := range strings.Split(header, ",") {
+			mediaType, _, _ := strings.Cut(mediaRange, ";")
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+			rest, ok := strings.CutPrefix(mediaType, "application/vnd.auth.v")
+			if !ok {
+				continue
+			}
+			n, ok := strings.CutSuffix(rest, "+json")
+			if !ok {
+				continue
+			}
+			if v, err := strconv.Atoi(n); err == nil && v > 0 {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	h := s.Handler()
+	for _, tt := range []struct {
+		name, method, path string
+		status             int
+		code               string // the error envelope's code, if any
+		deprecated         bool
+		allow              string
+	}{
+		{"versioned", http.MethodGet, "/v1/me", http.StatusUnauthorized, "unauthenticated", false, ""},
+		{"legacy alias", http.MethodGet, "/me", http.StatusUnauthorized, "unauthenticated", true, ""},
+		{"wrong method", http.MethodDelete, "/v1/me", http.StatusMethodNotAllowed, "method_not_allowed", false, "GET, HEAD, OPTIONS, PUT"},
+		{"wrong method on an alias", http.MethodGet, "/login", http.StatusMethodNotAllowed, "method_not_allowed", false, "OPTIONS, POST"},
+		{"unknown path", http.MethodGet, "/v1/nope", http.StatusNotFound, "not_found", false, ""},
+		{"no alias for a newer route", http.MethodGet, "/me/sessions", http.StatusNotFound, "not_found", false, ""},
+		{"options", http.MethodOptions, "/v1/me", http.StatusNoContent, "", false, "GET, HEAD, OPTIONS, PUT"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if tt.code != "" {
+				if env := readEnvelope(t, rec, tt.status); env.Error.Code != tt.code {
+					t.Errorf("code %q, want %q", env.Error.Code, tt.code)
+				}
+			} else if rec.Code != tt.status {
+				t.Errorf("status %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow %q, want %q", got, tt.allow)
+			}
+			deprecated := rec.Header().Get("Deprecation") == "true"
+			if deprecated != tt.deprecated {
+				t.Errorf("Deprecation %q", rec.Header().Get("Deprecation"))
+			}
+			if link := rec.Header().Get("Link"); deprecated && link != "</v1"+tt.path+`>; rel="successor-version"` {
+				t.Errorf("Link %q", link)
+			}
+		})
+	}
+}
+
+// TestMeVersions asks for /me as v1 and v2, by path and by Accept: v1 is
+// the plain-text greeting, v2 the user as JSON.
+func TestMeVersions(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	h := s.Handler()
+	user := newTestUser(t, s, "a@example.com", "admin")
+	cookie := accessTokenCookie(t, s, user)
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		r.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	const v2 = "application/vnd.auth.v2+json"
+
+	for _, tt := range []struct{ path, accept string }{
+		{"/v1/me", ""},
+		{"/me", ""},
+		{"/me", "application/json"},
+	} {
+		rec := get(tt.path, tt.accept)
+		if rec.Code != http.StatusOK || rec.Body.String() != "Hello a@example.com" {
+			t.Errorf("%s, Accept %q: %d %q, want the v1 greeting", tt.path, tt.accept, rec.Code, rec.Body)
+		}
+	}
+
+	for _, tt := range []struct{ path, accept string }{
+		{"/v2/me", ""},
+		{"/v2/me", v2},
+		{"/me", v2},
+		{"/me", "text/html, " + v2 + ";q=0.9"},
+	} {
+		rec := get(tt.path, tt.accept)
+		var got map[string]any
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &got) != nil {
+			t.Errorf("%s, Accept %q: %d %q, want v2 JSON", tt.path, tt.accept, rec.Code, rec.Body)
+			continue
+		}
+		if got["id"] != float64(user.ID) || got["email"] != user.Email || got["created_at"] == nil ||
+			!reflect.DeepEqual(got["roles"], []any{"admin"}) {
+			t.Errorf("%s, Accept %q: %v", tt.path, tt.accept, got)
+		}
+	}
+	if rec := get("/me", v2); rec.Header().Get("Deprecation") != "true" || !slices.Contains(rec.Header().Values("Vary"), "Accept") {
+		t.Errorf("alias headers %v, want Deprecation and Vary: Accept", rec.Header())
+	}
+
+	for _, tt := range []struct{ path, accept string }{
+		{"/v1/me", v2},
+		{"/me", "application/vnd.auth.v3+json"},
+	} {
+		if env := readEnvelope(t, get(tt.path, tt.accept), http.StatusNotAcceptable); env.Error.Code != "unsupported_version" {
+			t.Errorf("%s, Accept %q: code %q, want unsupported_version", tt.path, tt.accept, env.Error.Code)
+		}
+	}
+}
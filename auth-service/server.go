@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/flags"
+	"resilient-auth-service/mailer"
+)
+
+// Server is the auth service: the dependencies every handler needs, with
+// the handlers and middleware as its methods. main builds one with
+// NewServer; a test builds one around fakes, so nothing is shared through
+// package state.
+type Server struct {
+	cfg   Config
+	db    *sql.DB
+	rdb   *redis.Client
+	clock Clock
+
+	users          UserStore
+	accessKeys     *KeyStore
+	passwordHasher *BcryptWorkerPool
+	emailSender    mailer.Sender
+	auditor        *Auditor
+	webhooks       *WebhookDispatcher
+	featureFlags   *flags.Redis
+	claimsEnricher ClaimsEnricher
+	webAuthn       *webauthn.WebAuthn
+	passkeys       PasskeyStore
+	// captchaVerifier checks registrations; nil when CAPTCHA_PROVIDER is
+	// unset.
+	captchaVerifier CaptchaVerifier
+	// rateLimiter is used by rateLimitMiddleware; loginHistoryLimiter
+	// enforces loginHistoryRateLimit.
+	rateLimiter         RateLimiter
+	loginHistoryLimiter RateLimiter
+
+	// sessionCache is nil unless cfg.SessionCacheEnabled, sessionReplicator
+	// unless SESSION_SYNC_REDIS_ADDR is set, and syntheticMonitor unless
+	// SYNTHETIC_MONITORING is.
+	sessionCache      *SessionCache
+	sessionReplicator *SessionReplicator
+	syntheticMonitor  *SyntheticMonitor
+
+	maintenance maintenanceCache
+
+	// workers are the background goroutines Close stops, in order.
+	workers []worker
+}
+
+// worker is a background component that drains its queue on close.
+type worker struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// NewServer builds the service on a migrated database and a Redis client,
+// starting its background workers. Close stops them again.
+func NewServer(ctx context.Context, cfg Config, db *sql.DB, rdb *redis.Client) (*Server, error) {
+	s := &Server{cfg: cfg, db: db, rdb: rdb, clock: systemClock{}}
+
+	users, err := NewPostgresUserStore(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("user store: %w", err)
+	}
+	s.users = users
+
+	s.passkeys = NewPostgresPasskeyStore(db)
+	if s.webAuthn, err = newWebAuthn(cfg); err != nil {
+		return nil, err
+	}
+
+	s.accessKeys = NewKeyStore(db)
+	if err := s.accessKeys.Load(ctx); err != nil {
+		return nil, fmt.Errorf("loading signing keys: %w", err)
+	}
+
+	s.claimsEnricher, err = newClaimsEnricher(cfg.ClaimsEnricher, db)
+	if err != nil {
+		return nil, err
+	}
+	s.captchaVerifier, err = newCaptchaVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+	hasher, err := newHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	publisher, err := newPublisher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("outbox publisher: %w", err)
+	}
+
+	s.featureFlags = flags.NewRedis(rdb, flags.NewStatic(cfg.FeatureFlags), cfg.FlagsCacheTTL)
+	s.passwordHasher = NewBcryptWorkerPool(cfg.BcryptWorkers, hasher, cfg.BcryptMaxQueue, cfg.BcryptQueueTimeout)
+	s.auditor = NewAuditor(db, rdb, 1024)
+	s.webhooks = NewWebhookDispatcher(db, 1024)
+	emailSender, err := newEmailSender(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	emailQueue := mailer.NewQueue(emailSender, emailQueueWorkers, emailQueueSize)
+	s.emailSender = emailQueue
+
+	memoryLimiter := NewTokenBucketLimiter(s.clock, cfg.RateLimit, cfg.RateLimitWindow, 5*time.Minute)
+	s.rateLimiter = NewFallbackRateLimiter(
+		NewRedisRateLimiter(rdb, cfg.RateLimit, cfg.RateLimitWindow),
+		memoryLimiter,
+	)
+	loginHistoryMemoryLimiter := NewTokenBucketLimiter(s.clock, loginHistoryRateLimit, time.Minute, 5*time.Minute)
+	s.loginHistoryLimiter = NewFallbackRateLimiter(
+		NewRedisRateLimiter(rdb, loginHistoryRateLimit, time.Minute),
+		loginHistoryMemoryLimiter,
+	)
+
+	if cfg.SessionCacheEnabled {
+		s.sessionCache = NewSessionCache(s.clock, cfg.SessionCacheSize, cfg.SessionCacheTTL)
+	}
+	if cfg.SessionSyncRedisAddr != "" {
+		bus := redis.NewClient(&redis.Options{Addr: cfg.SessionSyncRedisAddr})
+		s.sessionReplicator, err = NewSessionReplicator(s, bus, cfg.SessionSyncRegion, cfg.SessionSyncKey)
+		if err != nil {
+			return nil, fmt.Errorf("session sync: %w", err)
+		}
+	}
+
+	// Workers are stopped in the order they are added: those that queue
+	// events, webhooks or emails before the queues themselves.
+	if publisher != nil {
+		s.addWorker("outbox relay", NewOutboxRelay(db, publisher, cfg.OutboxRelayInterval, cfg.OutboxRetention).Close)
+	}
+	// The monitor calls the handlers straight away, so it starts last.
+	if cfg.EnableSyntheticMonitoring {
+		s.emailSender = syntheticEmailFilter{s.emailSender}
+		s.syntheticMonitor, err = NewSyntheticMonitor(s, cfg.SyntheticMonitoringInterval)
+		if err != nil {
+			return nil, fmt.Errorf("synthetic monitor: %w", err)
+		}
+		s.addWorker("synthetic monitor", s.syntheticMonitor.Close)
+	}
+	if s.sessionReplicator != nil {
+		s.addWorker("session sync", s.sessionReplicator.Close)
+	}
+	if s.sessionCache != nil {
+		s.addWorker("session cache invalidator", NewSessionCacheInvalidator(rdb, s.sessionCache).Close)
+	}
+	s.addWorker("session cleanup", NewSessionCleanupWorker(rdb, cfg.SessionCleanupInterval).Close)
+	s.addWorker("pool stats", NewPoolStatsSampler(db, rdb).Close)
+	s.addWorker("email queue", emailQueue.Close)
+	s.addWorker("webhook dispatcher", s.webhooks.Close)
+	s.addWorker("auditor", s.auditor.Close)
+	s.addWorker("user store", func(context.Context) error { return users.Close() })
+	s.addWorker("rate limiter", func(context.Context) error {
+		memoryLimiter.Close()
+		loginHistoryMemoryLimiter.Close()
+		return nil
+	})
+	s.addWorker("password hasher", func(context.Context) error {
+		s.passwordHasher.Close()
+		return nil
+	})
+	return s, nil
+}
+
+func (s *Server) addWorker(name string, close func(context.Context) error) {
+	s.workers = append(s.workers, worker{name, close})
+}
+
+// Close stops the background workers once the HTTP and gRPC servers have
+// stopped handing them work, flushing what they still have queued until
+// ctx ends.
+func (s *Server) Close(ctx context.Context) {
+	for _, w := range s.workers {
+		if err := w.close(ctx); err != nil {
+			log.Printf("%s shutdown error: %v", w.name, err)
+		}
+	}
+}
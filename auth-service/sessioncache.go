@@ -0,0 +1,254 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var sessionCacheLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "session_cache_lookups_total",
+	Help: "In-process session cache lookups by result: hit or miss.",
+}, []string{"result"})
+
+// cachedSession is what lookupSession read for a session. hasBinding says
+// whether binding was read at all, since a lookup that skipped it can't
+// answer one that needs it.
+type cachedSession struct {
+	email      string
+	binding    string
+	hasBinding bool
+}
+
+type sessionCacheEntry struct {
+	id      string
+	session cachedSession
+	expires time.Time
+}
+
+// SessionCache is a small LRU of recent session lookups, so a client
+// firing many requests a second doesn't cost a Redis read for each. Only
+// live sessions are cached; a miss always goes to Redis.
+//
+// Sessions ended through this replica are dropped from it at once, and
+// ones ended through another replica as soon as a
+// SessionCacheInvalidator hears of it. While the invalidator is
+// disconnected from Redis those stay usable here for up to ttl, which is
+// why ttl is kept short and the cache is optional. A nil *SessionCache is
+// a disabled cache: Get misses and the rest do nothing.
+type SessionCache struct {
+	clock Clock
+	size  int
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	order *list.List // front is most recently used
+	byID  map[string]*list.Element
+}
+
+func NewSessionCache(clock Clock, size int, ttl time.Duration) *SessionCache {
+	return &SessionCache{
+		clock: clock,
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		byID:  make(map[string]*list.Element, size),
+	}
+}
+
+// Get returns the cached session, if there is one that hasn't expired and
+// was read with its binding when withBinding is set.
+func (c *SessionCache) Get(sessionID string, withBinding bool) (cachedSession, bool) {
+	if c == nil {
+		return cachedSession{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.byID[sessionID]
+	if ok {
+		e := el.Value.(*sessionCacheEntry)
+		switch {
+		case c.clock.Now().After(e.expires):
+			c.remove(el)
+			ok = false
+		case withBinding && !e.session.hasBinding:
+			ok = false
+		default:
+			c.order.MoveToFront(el)
+			sessionCacheLookupsTotal.WithLabelValues("hit").Inc()
+			return e.session, true
+		}
+	}
+	sessionCacheLookupsTotal.WithLabelValues("miss").Inc()
+	return cachedSession{}, false
+}
+
+// Put caches s for ttl, evicting the least recently used session if the
+// cache is full.
+func (c *SessionCache) Put(sessionID string, s cachedSession) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.clock.Now().Add(c.ttl)
+	if el, ok := c.byID[sessionID]; ok {
+		e := el.Value.(*sessionCacheEntry)
+		e.session, e.expires = s, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.byID[sessionID] = c.order.PushFront(&sessionCacheEntry{id: sessionID, session: s, expires: expires})
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Invalidate drops sessions that have just been ended.
+func (c *SessionCache) Invalidate(sessionIDs ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range sessionIDs {
+		if el, ok := c.byID[id]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *SessionCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.byID, el.Value.(*sessionCacheEntry).id)
+}
+
+// Purge drops every cached session.
+func (c *SessionCache) Purge() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.byID)
+}
+
+const (
+	sessionInvalidationMaxBackoff = 30 * time.Second
+	// sessionInvalidationPingInterval is how long the subscription may be
+	// quiet before it is pinged, to notice a connection that died silently.
+	sessionInvalidationPingInterval = 30 * time.Second
+)
+
+// SessionCacheInvalidator drops sessions from the cache when any replica
+// ends them. It listens for the session_invalidated events that
+// publishSessionInvalidated already sends for /me/events, with one
+// pattern subscription for all sessions, so ending a session costs no
+// extra publish.
+//
+// Pub/Sub delivers at most once, so whenever the subscription is
+// (re)established the whole cache is purged: anything ended while it was
+// down may still be cached.
+type SessionCacheInvalidator struct {
+	rdb   *redis.Client
+	cache *SessionCache
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSessionCacheInvalidator(rdb *redis.Client, cache *SessionCache) *SessionCacheInvalidator {
+	ctx, cancel := context.WithCancel(context.Background())
+	i := &SessionCacheInvalidator{
+		rdb:    rdb,
+		cache:  cache,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go i.run()
+	return i
+}
+
+// Close stops listening.
+func (i *SessionCacheInvalidator) Close(ctx context.Context) error {
+	i.cancel()
+	select {
+	case <-i.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run listens until Close, subscribing again with backoff whenever the
+// connection drops.
+func (i *SessionCacheInvalidator) run() {
+	defer close(i.done)
+
+	backoff := time.Second
+	for i.ctx.Err() == nil {
+		err := i.receive()
+		if i.ctx.Err() != nil {
+			return
+		}
+		log.Printf("session cache invalidation subscription lost, retrying in %s: %v", backoff, err)
+		select {
+		case <-i.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, sessionInvalidationMaxBackoff)
+	}
+}
+
+func (i *SessionCacheInvalidator) receive() error {
+	sub := i.rdb.PSubscribe(i.ctx, sessionEventsChannel("*"))
+	defer sub.Close()
+	// A blocked read doesn't watch the context; closing the subscription
+	// is what ends it on Close.
+	stop := context.AfterFunc(i.ctx, func() { sub.Close() })
+	defer stop()
+	if _, err := sub.Receive(i.ctx); err != nil {
+		return err
+	}
+	i.cache.Purge()
+
+	for {
+		msg, err := sub.ReceiveTimeout(i.ctx, sessionInvalidationPingInterval)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			if err := sub.Ping(i.ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		m, ok := msg.(*redis.Message)
+		if !ok || m.Payload != sessionInvalidatedEvent {
+			continue
+		}
+		i.cache.Invalidate(strings.TrimPrefix(m.Channel, sessionEventsChannel("")))
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+// A cached session expires ttl after it was put, however often it is read
+// meanwhile, so a session ended elsewhere can't stay usable here for
+// longer than that.
+func TestSessionCacheExpiry(t *testing.T) {
+	clock := newFakeClock()
+	c := NewSessionCache(clock, 10, time.Minute)
+	c.Put("s1", cachedSession{email: "a@example.com"})
+
+	clock.Advance(time.Minute - time.Second)
+	if got, ok := c.Get("s1", false); !ok || got.email != "a@example.com" {
+		t.Fatalf("a second before ttl: got %+v, %v", got, ok)
+	}
+	clock.Advance(2 * time.Second)
+	if _, ok := c.Get("s1", false); ok {
+		t.Fatal("still cached after ttl")
+	}
+
+	c.Put("s1", cachedSession{email: "a@example.com"})
+	clock.Advance(30 * time.Second)
+	if _, ok := c.Get("s1", false); !ok {
+		t.Error("a fresh Put didn't restart the ttl")
+	}
+}
+
+// TestSessionCacheLRU fills a cache of two: reading a session keeps it,
+// and the least recently used one is evicted. Hits and misses are
+// counted.
+func TestSessionCacheLRU(t *testing.T) {
+	c := NewSessionCache(newFakeClock(), 2, time.Minute)
+	hits := testutil.ToFloat64(sessionCacheLookupsTotal.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(sessionCacheLookupsTotal.WithLabelValues("miss"))
+
+	c.Put("s1", cachedSession{email: "a@example.com"})
+	c.Put("s2", cachedSession{email: "b@example.com"})
+	c.Get("s1", false)
+	c.Put("s3", cachedSession{email: "c@example.com"})
+	for id, want := range map[string]bool{"s1": true, "s2": false, "s3": true} {
+		if _, ok := c.Get(id, false); ok != want {
+			t.Errorf("%s cached: %v, want %v", id, ok, want)
+		}
+	}
+	if got := testutil.ToFloat64(sessionCacheLookupsTotal.WithLabelValues("hit")) - hits; got != 3 {
+		t.Errorf("%v hits, want 3", got)
+	}
+	if got := testutil.ToFloat64(sessionCacheLookupsTotal.WithLabelValues("miss")) - misses; got != 1 {
+		t.Errorf("%v misses, want 1", got)
+	}
+
+	// An entry read without the binding can't answer a lookup that needs
+	// it; one read with it answers both.
+	c.Put("s4", cachedSession{email: "d@example.com", binding: "fp", hasBinding: true})
+	if _, ok := c.Get("s3", true); ok {
+		t.Error("an entry without its binding answered a binding lookup")
+	}
+	if got, ok := c.Get("s4", true); !ok || got.binding != "fp" {
+		t.Errorf("s4: %+v, %v", got, ok)
+	}
+
+	c.Invalidate("s4", "nope")
+	if _, ok := c.Get("s4", false); ok {
+		t.Error("s4 cached after Invalidate")
+	}
+
+	var disabled *SessionCache
+	disabled.Put("s1", cachedSession{email: "a@example.com"})
+	disabled.Invalidate("s1")
+	disabled.Purge()
+	if _, ok := disabled.Get("s1", false); ok {
+		t.Error("a nil cache hit")
+	}
+}
+
+// TestSessionCacheConcurrent hammers one cache from many goroutines; run
+// it with -race.
+func TestSessionCacheConcurrent(t *testing.T) {
+	c := NewSessionCache(newFakeClock(), 8, time.Minute)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				id := fmt.Sprint((g + i) % 16)
+				c.Put(id, cachedSession{email: id})
+				if got, ok := c.Get(id, false); ok && got.email != id {
+					t.Errorf("%s: got %+v", id, got)
+				}
+				if i%100 == 0 {
+					c.Invalidate(id)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestSessionCacheLookups puts the cache in front of lookupSession. A
+// repeat lookup doesn't reach Redis. A session ended through this replica
+// is gone at once; one deleted behind its back stays usable until the ttl
+// passes.
+func TestSessionCacheLookups(t *testing.T) {
+	s, mr := newTestServer(t)
+	clock := s.clock.(*fakeClock)
+	s.sessionCache = NewSessionCache(clock, 10, 5*time.Second)
+	ctx := context.Background()
+	local, err := s.createSession(ctx, "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := s.createSession(ctx, "b@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{local, remote} {
+		if _, _, err := s.lookupSession(ctx, id, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commands := mr.CommandCount()
+	if email, _, err := s.lookupSession(ctx, local, false); email != "a@example.com" || err != nil {
+		t.Fatalf("cached lookup: %q, %v", email, err)
+	}
+	if got := mr.CommandCount(); got != commands {
+		t.Errorf("a cached lookup sent %d Redis commands", got-commands)
+	}
+
+	if err := s.DeleteSession(ctx, local); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.lookupSession(ctx, local, false); err != redis.Nil {
+		t.Errorf("after DeleteSession: %v, want redis.Nil", err)
+	}
+
+	mr.Del("session:" + remote)
+	if _, _, err := s.lookupSession(ctx, remote, false); err != nil {
+		t.Errorf("deleted elsewhere, within the ttl: %v, want the cached session", err)
+	}
+	clock.Advance(6 * time.Second)
+	if _, _, err := s.lookupSession(ctx, remote, false); err != redis.Nil {
+		t.Errorf("deleted elsewhere, after the ttl: %v, want redis.Nil", err)
+	}
+}
+
+// TestSessionCacheInvalidator runs two replicas sharing one Redis, each
+// with its own cache and invalidator. A session one replica ends, alone
+// or with the rest of the user's, leaves the other's cache within 100ms;
+// other events don't evict, and the cache is purged on resubscribing.
+func TestSessionCacheInvalidator(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	a, mr := newTestServer(t)
+	b, _ := newTestServer(t)
+	b.rdb = a.rdb
+	for _, s := range []*Server{a, b} {
+		s.sessionCache = NewSessionCache(s.clock, 10, time.Minute)
+		inv := NewSessionCacheInvalidator(s.rdb, s.sessionCache)
+		t.Cleanup(func() { inv.Close(ctx) })
+	}
+	subscribed := func() bool { return mr.PubSubNumPat() == 2 }
+	for deadline := time.Now().Add(5 * time.Second); !subscribed(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("invalidators didn't subscribe")
+		}
+	}
+
+	cachedOnB := func(email string) string {
+		id, err := a.createSession(ctx, email, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := b.lookupSession(ctx, id, false); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := b.sessionCache.Get(id, false); !ok {
+			t.Fatalf("%s not cached", id)
+		}
+		return id
+	}
+	evictedWithin := func(what, id string, d time.Duration) {
+		t.Helper()
+		for deadline := time.Now().Add(d); ; time.Sleep(time.Millisecond) {
+			if _, ok := b.sessionCache.Get(id, false); !ok {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: still cached after %v", what, d)
+			}
+		}
+	}
+
+	id := cachedOnB("a@example.com")
+	if err := a.DeleteSession(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	evictedWithin("DeleteSession", id, 100*time.Millisecond)
+	if _, _, err := b.lookupSession(ctx, id, false); err != redis.Nil {
+		t.Errorf("after eviction: %v, want redis.Nil", err)
+	}
+
+	first, second := cachedOnB("b@example.com"), cachedOnB("b@example.com")
+	if err := a.revokeUserSessions(ctx, "b@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	evictedWithin("revokeUserSessions", first, 100*time.Millisecond)
+	evictedWithin("revokeUserSessions", second, 100*time.Millisecond)
+
+	id = cachedOnB("c@example.com")
+	mr.Publish(sessionEventsChannel(id), "password_changed")
+	mr.Publish(sessionEventsChannel("other"), sessionInvalidatedEvent)
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := b.sessionCache.Get(id, false); !ok {
+		t.Error("evicted by another event or session")
+	}
+
+	// Invalidations sent while disconnected are lost, so reconnecting
+	// purges everything.
+	mr.Close()
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	evictedWithin("resubscribe", id, 5*time.Second)
+}
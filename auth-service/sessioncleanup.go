@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// sessionCleanupBatch is the default SCAN/SSCAN COUNT hint, and so
+	// roughly how many keys each round trip covers.
+	sessionCleanupBatch = 100
+	// sessionCleanupPause is the default pause between round trips, which
+	// keeps a pass over millions of keys from crowding out request traffic.
+	sessionCleanupPause = 10 * time.Millisecond
+
+	// sessionCleanupCursorKey holds the SCAN cursor of an unfinished pass,
+	// so the next pass resumes there instead of starting over. SCAN
+	// cursors stay valid indefinitely; the TTL only stops an abandoned one
+	// from lingering.
+	sessionCleanupCursorKey = "session_cleanup_cursor"
+	sessionCleanupCursorTTL = 24 * time.Hour
+)
+
+var sessionsCleanedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "sessions_cleaned_total",
+	Help: "Expired session IDs removed from user_sessions sets.",
+})
+
+// SessionCleaner prunes user_sessions:<email> sets. A session key expires
+// on its own, but its ID stays in the user's set, which only expires when
+// the user stops logging in altogether; for an active user the set grows
+// without bound. Each pass removes the members whose session:<id> key is
+// gone.
+//
+// It only ever uses SCAN and SSCAN, never KEYS or SMEMBERS, so no single
+// command blocks Redis however many keys there are, and it pauses between
+// round trips.
+type SessionCleaner struct {
+	rdb *redis.Client
+	// batch is the COUNT hint for each SCAN and SSCAN.
+	batch int64
+	// pause is slept between round trips.
+	pause time.Duration
+}
+
+func NewSessionCleaner(rdb *redis.Client, batch int64, pause time.Duration) *SessionCleaner {
+	return &SessionCleaner{rdb: rdb, batch: batch, pause: pause}
+}
+
+// SessionCleanupStats describes one cleanup pass.
+type SessionCleanupStats struct {
+	// Resumed is set if the pass continued an interrupted one.
+	Resumed bool
+	Sets    int
+	Checked int
+	Removed int
+}
+
+// SessionCleanupWorker runs a SessionCleaner every interval. Every replica
+// runs a worker, but a pass first takes a Redis lock that lasts one
+// interval, so only one replica does the work each time.
+type SessionCleanupWorker struct {
+	rdb      *redis.Client
+	cleaner  *SessionCleaner
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSessionCleanupWorker(rdb *redis.Client, interval time.Duration) *SessionCleanupWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &SessionCleanupWorker{
+		rdb:      rdb,
+		cleaner:  NewSessionCleaner(rdb, sessionCleanupBatch, sessionCleanupPause),
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// Close stops the worker, interrupting a pass in progress; the next pass,
+// here or on another replica, resumes from the saved cursor.
+func (c *SessionCleanupWorker) Close(ctx context.Context) error {
+	c.cancel()
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *SessionCleanupWorker) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := c.rdb.SetNX(c.ctx, "session_cleanup_lock", 1, c.interval).Result()
+		if err != nil || !ok {
+			continue
+		}
+		stats, err := c.cleaner.Cleanup(c.ctx)
+		if err != nil && c.ctx.Err() == nil {
+			log.Println("session cleanup error:", err)
+		}
+		if stats.Removed > 0 {
+			log.Printf("session cleanup removed=%d", stats.Removed)
+		}
+	}
+}
+
+// Cleanup makes one pass over every user_sessions set, starting from the
+// cursor an interrupted pass saved, if any. It saves its own cursor after
+// every SCAN page; a pass that fails or is cancelled can simply be run
+// again. Sets are cleaned idempotently, so a page done twice does no harm.
+func (c *SessionCleaner) Cleanup(ctx context.Context) (SessionCleanupStats, error) {
+	var stats SessionCleanupStats
+
+	cursor, err := c.rdb.Get(ctx, sessionCleanupCursorKey).Uint64()
+	if err != nil && err != redis.Nil {
+		return stats, err
+	}
+	stats.Resumed = cursor != 0
+
+	for {
+		keys, next, err := c.rdb.Scan(ctx, cursor, "user_sessions:*", c.batch).Result()
+		if err != nil {
+			return stats, err
+		}
+		for _, key := range keys {
+			if err := c.cleanSet(ctx, key, &stats); err != nil {
+				return stats, err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return stats, c.rdb.Del(ctx, sessionCleanupCursorKey).Err()
+		}
+		if err := c.rdb.Set(ctx, sessionCleanupCursorKey, cursor, sessionCleanupCursorTTL).Err(); err != nil {
+			return stats, err
+		}
+		if err := c.wait(ctx); err != nil {
+			return stats, err
+		}
+	}
+}
+
+// cleanSet removes the stale members of one set, checking them a batch at
+// a time with a pipeline of EXISTS.
+func (c *SessionCleaner) cleanSet(ctx context.Context, setKey string, stats *SessionCleanupStats) error {
+	stats.Sets++
+	var cursor uint64
+	for {
+		ids, next, err := c.rdb.SScan(ctx, setKey, cursor, "", c.batch).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(ids) > 0 {
+			pipe := c.rdb.Pipeline()
+			exists := make([]*redis.IntCmd, len(ids))
+			for i, id := range ids {
+				exists[i] = pipe.Exists(ctx, "session:"+id)
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+			stats.Checked += len(ids)
+
+			var stale []any
+			for i, id := range ids {
+				if exists[i].Val() == 0 {
+					stale = append(stale, id)
+				}
+			}
+			if len(stale) > 0 {
+				n, err := c.rdb.SRem(ctx, setKey, stale...).Result()
+				if err != nil {
+					return err
+				}
+				stats.Removed += int(n)
+				sessionsCleanedTotal.Add(float64(n))
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// wait sleeps for the pause between round trips.
+func (c *SessionCleaner) wait(ctx context.Context) error {
+	if c.pause <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(c.pause)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sessionsCommand implements "sessions cleanup [-count N] [-pause D]",
+// one cleanup pass run by hand, for instance to clear a backlog faster or
+// more gently than the background worker. It shares the worker's saved
+// cursor, so it resumes an interrupted pass and can itself be interrupted.
+// It doesn't take the worker's lock: a pass running at the same time only
+// makes the two skip pages of each other's, which the next pass covers.
+func sessionsCommand(cfg Config, args []string) error {
+	if len(args) == 0 || args[0] != "cleanup" {
+		return fmt.Errorf("usage: %s sessions cleanup [-count N] [-pause D]", os.Args[0])
+	}
+
+	fs := flag.NewFlagSet("sessions cleanup", flag.ContinueOnError)
+	count := fs.Int64("count", sessionCleanupBatch, "SCAN/SSCAN COUNT hint: keys covered per round trip")
+	pause := fs.Duration("pause", sessionCleanupPause, "pause between round trips")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *count <= 0 {
+		return fmt.Errorf("-count must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+	defer rdb.Close()
+	waitCtx, cancel := context.WithTimeout(ctx, cfg.RedisWaitTimeout)
+	err := waitForRedis(waitCtx, rdb, cfg.RedisMaxAttempts)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	stats, err := NewSessionCleaner(rdb, *count, *pause).Cleanup(ctx)
+	log.Printf("sessions cleanup: sets=%d checked=%d removed=%d resumed=%t elapsed=%s",
+		stats.Sets, stats.Checked, stats.Removed, stats.Resumed, time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		return fmt.Errorf("sessions cleanup stopped, run it again to resume: %w", err)
+	}
+	return nil
+}
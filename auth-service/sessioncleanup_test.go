@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestSessionCleanup lets ten of a user's sessions expire while their set
+// lives on, and runs one pass: the expired IDs are removed and a live one
+// is kept.
+func TestSessionCleanup(t *testing.T) {
+	s, mr := newTestServer(t)
+	ctx := context.Background()
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		for i := range 10 {
+			if err := s.storeSession(ctx, fmt.Sprintf("%s-%d", email, i), email, "", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// a logs in again, which keeps their set; b's is kept by hand.
+	live, err := s.createSession(ctx, "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr.SetTTL("user_sessions:b@example.com", time.Hour)
+	mr.FastForward(2 * time.Minute)
+
+	before := testutil.ToFloat64(sessionsCleanedTotal)
+	// One page per SSCAN: miniredis's SSCAN cursors are offsets, which
+	// removals shift, unlike Redis's.
+	stats, err := NewSessionCleaner(s.rdb, sessionCleanupBatch, 0).Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Sets != 2 || stats.Checked != 21 || stats.Removed != 20 || stats.Resumed {
+		t.Errorf("stats %+v, want 2 sets, 21 checked, 20 removed", stats)
+	}
+	if got := testutil.ToFloat64(sessionsCleanedTotal) - before; got != 20 {
+		t.Errorf("sessions_cleaned_total rose by %v, want 20", got)
+	}
+	if members, _ := mr.Members("user_sessions:a@example.com"); len(members) != 1 || members[0] != live {
+		t.Errorf("a's sessions %v, want only %s", members, live)
+	}
+	if mr.Exists("user_sessions:b@example.com") {
+		members, _ := mr.Members("user_sessions:b@example.com")
+		t.Errorf("b's sessions %v, want none", members)
+	}
+	if mr.Exists(sessionCleanupCursorKey) {
+		t.Error("a finished pass left its cursor behind")
+	}
+}
+
+// TestSessionCleanupWorker runs the worker: it cleans on its own, under
+// the lock that keeps other replicas out for the interval.
+func TestSessionCleanupWorker(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	ctx := context.Background()
+	if err := s.storeSession(ctx, "old", "a@example.com", "", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	mr.SetTTL("user_sessions:a@example.com", time.Hour)
+	mr.FastForward(2 * time.Minute)
+
+	w := NewSessionCleanupWorker(s.rdb, 10*time.Millisecond)
+	for deadline := time.Now().Add(5 * time.Second); mr.Exists("user_sessions:a@example.com"); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the worker didn't clean the set")
+		}
+	}
+	if !mr.Exists("session_cleanup_lock") {
+		t.Error("the worker cleaned without taking the lock")
+	}
+	if err := w.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// seedSessionSets gives n users one live session and two expired ones
+// each, straight into miniredis. The live session keeps every set alive,
+// so removals never shift miniredis's offset cursors.
+func seedSessionSets(mr *miniredis.Miniredis, n int) {
+	for i := range n {
+		set := fmt.Sprintf("user_sessions:user%d@example.com", i)
+		live := fmt.Sprintf("live-%d", i)
+		mr.Set("session:"+live, "x")
+		mr.SAdd(set, live, fmt.Sprintf("old-%d-a", i), fmt.Sprintf("old-%d-b", i))
+	}
+}
+
+// TestSessionCleanupThousands cleans 2000 users' sets in one pass.
+func TestSessionCleanupThousands(t *testing.T) {
+	s, mr := newTestServer(t)
+	seedSessionSets(mr, 2000)
+
+	stats, err := NewSessionCleaner(s.rdb, sessionCleanupBatch, 0).Cleanup(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Sets != 2000 || stats.Checked != 6000 || stats.Removed != 4000 {
+		t.Errorf("stats %+v, want 2000 sets, 6000 checked, 4000 removed", stats)
+	}
+	for _, i := range []int{0, 999, 1999} {
+		set := fmt.Sprintf("user_sessions:user%d@example.com", i)
+		if members, _ := mr.Members(set); len(members) != 1 || members[0] != fmt.Sprintf("live-%d", i) {
+			t.Errorf("%s: %v, want only the live session", set, members)
+		}
+	}
+}
+
+// TestSessionCleanupThrottled walks a set of 1000 live sessions 100 at a
+// time, pausing between round trips; cancelling it stops it in a pause.
+// miniredis ignores SCAN's COUNT, but SSCAN pages like Redis.
+func TestSessionCleanupThrottled(t *testing.T) {
+	s, mr := newTestServer(t)
+	for i := range 1000 {
+		id := fmt.Sprint(i)
+		mr.Set("session:"+id, "x")
+		mr.SAdd("user_sessions:a@example.com", id)
+	}
+	pause := 5 * time.Millisecond
+	cleaner := NewSessionCleaner(s.rdb, 100, pause)
+
+	start := time.Now()
+	stats, err := cleaner.Cleanup(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Checked != 1000 || stats.Removed != 0 {
+		t.Errorf("stats %+v, want 1000 checked, none removed", stats)
+	}
+	if elapsed := time.Since(start); elapsed < 9*pause {
+		t.Errorf("took %s, want at least 9 pauses of %s", elapsed, pause)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*pause)
+	defer cancel()
+	if stats, err := cleaner.Cleanup(ctx); !errors.Is(err, context.DeadlineExceeded) || stats.Checked >= 1000 {
+		t.Errorf("cut short: %+v, %v, want a partial pass and the context's error", stats, err)
+	}
+}
+
+// TestSessionCleanupResumes starts a pass with the cursor an interrupted
+// one saved: it carries on from there, says so, and clears the cursor
+// when done, so the next pass starts over.
+func TestSessionCleanupResumes(t *testing.T) {
+	s, mr := newTestServer(t)
+	seedSessionSets(mr, 10)
+	cleaner := NewSessionCleaner(s.rdb, sessionCleanupBatch, 0)
+	// miniredis returns every key from cursor 0, so any other cursor is
+	// past the end.
+	mr.Set(sessionCleanupCursorKey, "17")
+
+	stats, err := cleaner.Cleanup(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Resumed || stats.Sets != 0 {
+		t.Errorf("resumed pass %+v, want it resumed past every set", stats)
+	}
+	if mr.Exists(sessionCleanupCursorKey) {
+		t.Fatal("a finished pass left its cursor behind")
+	}
+
+	stats, err = cleaner.Cleanup(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Resumed || stats.Sets != 10 || stats.Removed != 20 {
+		t.Errorf("next pass %+v, want a fresh one over all 10 sets", stats)
+	}
+}
+
+func TestSessionsCommand(t *testing.T) {
+	quietLog(t)
+	mr := miniredis.RunT(t)
+	seedSessionSets(mr, 10)
+	cfg := testConfig
+	cfg.RedisAddr = mr.Addr()
+	cfg.RedisWaitTimeout = 5 * time.Second
+	cfg.RedisMaxAttempts = 3
+
+	for _, args := range [][]string{nil, {"purge"}, {"cleanup", "-count", "0"}} {
+		if err := sessionsCommand(cfg, args); err == nil {
+			t.Errorf("%q: no error", args)
+		}
+	}
+	if err := sessionsCommand(cfg, []string{"cleanup", "-count", "5", "-pause", "0"}); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.Members("user_sessions:user3@example.com"); len(members) != 1 {
+		t.Errorf("after the command: %v, want only the live session", members)
+	}
+}
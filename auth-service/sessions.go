@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+	"resilient-auth-service/pagination"
+)
+
+// sessionInfo is one entry of GET /me/sessions. The session ID itself is
+// the credential, so sessions are identified by its hash instead.
+type sessionInfo struct {
+	ID        string    `json:"id"`
+	Current   bool      `json:"current"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type sessionKey struct {
+	ID string `json:"id"`
+}
+
+func sessionPublicID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:])
+}
+
+// userSessions returns the user's live sessions, marking current (which may
+// be empty) as the one in use.
+func (s *Server) userSessions(ctx context.Context, email, current string) ([]sessionInfo, error) {
+	ids, err := s.rdb.SMembers(ctx, "user_sessions:"+email).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.rdb.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(ids))
+	for i, id := range ids {
+		ttls[i] = pipe.PTTL(ctx, "session:"+id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().UTC()
+	sessions := make([]sessionInfo, 0, len(ids))
+	for i, id := range ids {
+		// The set outlives sessions that expire on their own; PTTL is
+		// negative for those.
+		ttl := ttls[i].Val()
+		if ttl <= 0 {
+			continue
+		}
+		sessions = append(sessions, sessionInfo{
+			ID:        sessionPublicID(id),
+			Current:   id == current,
+			ExpiresAt: now.Add(ttl).Truncate(time.Second),
+		})
+	}
+	return sessions, nil
+}
+
+// mySessionsHandler serves GET /me/sessions?limit=&cursor=, the caller's
+// live sessions ordered by public ID. The whole set lives in one small
+// Redis set, so each page reads it and pages in memory; the cursor still
+// makes paging stable while sessions come and go.
+func (s *Server) mySessionsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := pagination.Limit(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	after, hasCursor, err := pagination.FromRequest[sessionKey](r, s.cfg.CursorSecret)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+		return
+	}
+
+	all, err := s.userSessions(r.Context(), user.Email, user.SessionID)
+	if err != nil {
+		log.Println("list sessions error:", err)
+		apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+		return
+	}
+	sessions := all[:0]
+	for _, sess := range all {
+		if !hasCursor || sess.ID > after.ID {
+			sessions = append(sessions, sess)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b sessionInfo) int { return strings.Compare(a.ID, b.ID) })
+	if len(sessions) > limit+1 {
+		sessions = sessions[:limit+1]
+	}
+
+	writeJSON(w, http.StatusOK, pagination.NewList(sessions, limit, func(sess sessionInfo) string {
+		return pagination.Encode(sessionKey{ID: sess.ID}, s.cfg.CursorSecret)
+	}))
+}
+
+// setSessionCookie sends the session cookie with the same lifetime as the
+// Redis session, so a browser restart doesn't drop a session that's still
+// valid and the cookie doesn't outlive it either.
+func (s *Server) setSessionCookie(w http.ResponseWriter, sessionID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_id",
+		Value:    sessionID,
+		Path:     "/",
+		MaxAge:   int(s.cfg.SessionTTL / time.Second),
+		Expires:  s.clock.Now().Add(s.cfg.SessionTTL),
+		HttpOnly: true,
+		Secure:   s.cfg.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: "session_id", Path: "/", MaxAge: -1})
+}
+
+// createSession stores a new Redis session for email and indexes it under
+// user_sessions:<email> so it can be revoked with the rest. A short-lived
+// rww:<id> marker lets authMiddleware tell "not replicated yet" apart from
+// "no such session" right after login. A non-empty binding (see
+// tokenBinding) is stored alongside for authMiddleware to check.
+func (s *Server) createSession(ctx context.Context, email, binding string) (string, error) {
+	defer observeSessionStore("create", time.Now())
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	sessionID := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.storeSession(ctx, sessionID, email, binding, s.cfg.SessionTTL); err != nil {
+		return "", err
+	}
+	s.sessionReplicator.SessionCreated(ctx, sessionID, email, binding, s.clock.Now().Add(s.cfg.SessionTTL))
+	return sessionID, nil
+}
+
+// storeSession writes a session to this region's Redis, for ttl.
+func (s *Server) storeSession(ctx context.Context, sessionID, email, binding string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	pipe := s.rdb.TxPipeline()
+	pipe.Set(ctx, "session:"+sessionID, email, ttl)
+	pipe.SAdd(ctx, "user_sessions:"+email, sessionID)
+	pipe.Expire(ctx, "user_sessions:"+email, ttl)
+	pipe.Set(ctx, "rww:"+sessionID, 1, s.cfg.ReadYourWritesTTL)
+	if binding != "" {
+		pipe.Set(ctx, sessionBindingKey(sessionID), binding, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// revokeUserSessions deletes every Redis session recorded for the user.
+func (s *Server) revokeUserSessions(ctx context.Context, email string) error {
+	defer observeSessionStore("revoke_user", time.Now())
+
+	if err := s.deleteUserSessions(ctx, email); err != nil {
+		return err
+	}
+	s.sessionReplicator.UserSessionsRevoked(ctx, email)
+	return nil
+}
+
+// deleteUserSessions is revokeUserSessions for this region only.
+func (s *Server) deleteUserSessions(ctx context.Context, email string) error {
+	setKey := "user_sessions:" + email
+
+	ids, err := s.rdb.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, 3*len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, "session:"+id, sessionBindingKey(id), sessionOrgKey(id))
+	}
+	keys = append(keys, setKey)
+
+	if err := s.rdb.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	s.sessionCache.Invalidate(ids...)
+	for _, id := range ids {
+		s.publishSessionInvalidated(ctx, id)
+	}
+	return nil
+}
+
+// DeleteSession ends a single session and tells any open /me/events
+// stream for it.
+func (s *Server) DeleteSession(ctx context.Context, sessionID string) error {
+	defer observeSessionStore("delete", time.Now())
+
+	if err := s.deleteSession(ctx, sessionID); err != nil {
+		return err
+	}
+	s.sessionReplicator.SessionDeleted(ctx, sessionID)
+	return nil
+}
+
+// deleteSession is DeleteSession for this region only.
+func (s *Server) deleteSession(ctx context.Context, sessionID string) error {
+	email, err := s.rdb.Get(ctx, "session:"+sessionID).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := s.rdb.TxPipeline()
+	pipe.Del(ctx, "session:"+sessionID, sessionBindingKey(sessionID), sessionOrgKey(sessionID))
+	if email != "" {
+		pipe.SRem(ctx, "user_sessions:"+email, sessionID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	s.sessionCache.Invalidate(sessionID)
+
+	s.publishSessionInvalidated(ctx, sessionID)
+	return nil
+}
+
+// sessionLookupTimeout bounds a session lookup, which sits in front of
+// every session-authenticated request: a slow Redis fails those requests
+// fast instead of piling them up.
+const sessionLookupTimeout = 200 * time.Millisecond
+
+// lookupSession returns the email of the session's user, or redis.Nil if
+// there is no such session. With withBinding it also returns the client
+// certificate the session is bound to, "" if none, read in the same round
+// trip.
+func (s *Server) lookupSession(ctx context.Context, sessionID string, withBinding bool) (email, binding string, err error) {
+	if cached, ok := s.sessionCache.Get(sessionID, withBinding); ok {
+		return cached.email, cached.binding, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sessionLookupTimeout)
+	defer cancel()
+
+	email, binding, err = s.readSession(ctx, sessionID, withBinding)
+	if err == redis.Nil && s.rdb.Exists(ctx, "rww:"+sessionID).Val() == 1 {
+		// The session was written moments ago and the read may have
+		// raced replication; give it one more chance. Our client only
+		// talks to the primary, so the retry sees the write.
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return "", "", ctx.Err()
+		}
+		email, binding, err = s.readSession(ctx, sessionID, withBinding)
+	}
+	if err == nil {
+		s.sessionCache.Put(sessionID, cachedSession{email: email, binding: binding, hasBinding: withBinding})
+	}
+	return email, binding, err
+}
+
+// readSession pipelines the session and binding reads. The session's own
+// error wins; a missing binding is just an unbound session, but any other
+// error reading it fails the lookup, as a separate read would have.
+func (s *Server) readSession(ctx context.Context, sessionID string, withBinding bool) (email, binding string, err error) {
+	defer observeSessionStore("lookup", time.Now())
+
+	pipe := s.rdb.Pipeline()
+	emailCmd := pipe.Get(ctx, "session:"+sessionID)
+	var bindingCmd *redis.StringCmd
+	if withBinding {
+		bindingCmd = pipe.Get(ctx, sessionBindingKey(sessionID))
+	}
+	// Exec's error is one of the commands'; they're checked one by one.
+	pipe.Exec(ctx)
+
+	if email, err = emailCmd.Result(); err != nil {
+		return "", "", err
+	}
+	if bindingCmd != nil {
+		binding, err = bindingCmd.Result()
+		if err != nil && err != redis.Nil {
+			return "", "", err
+		}
+	}
+	return email, binding, nil
+}
+
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		cookie, err := r.Cookie("session_id")
+		if err != nil {
+			apperror.WriteError(w, r, apperror.Unauthorized("unauthenticated", "Authentication required"))
+			return
+		}
+
+		email, binding, err := s.lookupSession(r.Context(), cookie.Value, s.cfg.TokenBindingMode != tokenBindingDisabled)
+		if err != nil && r.Context().Err() != nil {
+			// The client went away; nobody is left to answer.
+			return
+		}
+		if err == redis.Nil {
+			sessionLookupsTotal.WithLabelValues("miss").Inc()
+			// The session is gone for good, so the cookie is no use to
+			// the browser either.
+			clearSessionCookie(w)
+			apperror.WriteError(w, r, apperror.Unauthorized("session_invalid", "Session expired or invalid"))
+			return
+		}
+		if err != nil {
+			// Not the session's fault: a 401 would make clients drop a
+			// perfectly good cookie and send everyone back to login.
+			sessionLookupsTotal.WithLabelValues("error").Inc()
+			log.Printf("session lookup error request_id=%s err=%v", requestIDFromContext(r.Context()), err)
+			w.Header().Set("Retry-After", "1")
+			apperror.WriteError(w, r, apperror.Unavailable("service_unavailable", "Service temporarily unavailable"))
+			return
+		}
+		sessionLookupsTotal.WithLabelValues("hit").Inc()
+
+		if !s.sessionBindingMatches(r, binding) {
+			s.recordBindingMismatch(r, email, cookie.Value)
+			apperror.WriteError(w, r, apperror.Unauthorized("session_binding_mismatch", "Session is bound to another client"))
+			return
+		}
+
+		// Add user email and session to request context
+		ctxWithUser := auth.SetUser(r.Context(), auth.User{Email: email, SessionID: cookie.Value})
+		ctxWithUser = withUserBaggage(ctxWithUser, email)
+		next.ServeHTTP(w, r.WithContext(ctxWithUser))
+	})
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+
+	"resilient-auth-service/auth"
+	"resilient-auth-service/pagination"
+)
+
+// FuzzSessionCookie sends arbitrary session_id cookie values through
+// authMiddleware. Only the one stored session authenticates; anything else
+// is a well-formed 401.
+func FuzzSessionCookie(f *testing.F) {
+	s, _ := newTestServer(f)
+	quietLog(f)
+	sessionID, err := s.createSession(context.Background(), "a@example.com", "")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, seed := range []string{sessionID, "", "x", sessionID + "x", sessionID[:len(sessionID)-1], "*", "session:*", "\x00", "a b"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		h := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if u, ok := auth.UserFromContext(r.Context()); !ok || u.Email != "a@example.com" {
+				t.Errorf("authenticated as %+v", u)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+		r.AddCookie(&http.Cookie{Name: "session_id", Value: value})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		// AddCookie drops values a browser couldn't have sent, so what
+		// arrives may differ from value.
+		got, _ := r.Cookie("session_id")
+		if got != nil && got.Value == sessionID {
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("stored session got status %d", rec.Code)
+			}
+			return
+		}
+		readEnvelope(t, rec, http.StatusUnauthorized)
+	})
+}
+
+// laggingReplica makes the first session read miss, as a read from a
+// replica that hasn't caught up with the login would. It counts the reads.
+type laggingReplica struct{ reads atomic.Int32 }
+
+func (h *laggingReplica) DialHook(next redis.DialHook) redis.DialHook          { return next }
+func (h *laggingReplica) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h *laggingReplica) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			get, ok := cmd.(*redis.StringCmd)
+			if !ok || cmd.Name() != "get" || !strings.HasPrefix(cmd.Args()[1].(string), "session:") {
+				continue
+			}
+			if h.reads.Add(1) == 1 {
+				get.SetVal("")
+				get.SetErr(redis.Nil)
+			}
+		}
+		return err
+	}
+}
+
+// TestSessionReadYourWrites sends a request with a session whose first
+// read misses: right after login the lookup is retried and succeeds, but
+// once the rww marker has expired a miss is final.
+func TestSessionReadYourWrites(t *testing.T) {
+	quietLog(t)
+	for _, tt := range []struct {
+		name      string
+		wait      time.Duration
+		wantCode  int
+		wantReads int32
+	}{
+		{"just logged in", 0, http.StatusNoContent, 2},
+		{"marker expired", testConfig.ReadYourWritesTTL + time.Second, http.StatusUnauthorized, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mr := newTestServer(t)
+			sessionID, err := s.createSession(context.Background(), "a@example.com", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			mr.FastForward(tt.wait)
+			lag := &laggingReplica{}
+			s.rdb.AddHook(lag)
+
+			h := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+			r.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.wantCode || lag.reads.Load() != tt.wantReads {
+				t.Errorf("status %d after %d reads, want %d after %d", rec.Code, lag.reads.Load(), tt.wantCode, tt.wantReads)
+			}
+		})
+	}
+}
+
+// TestMySessionsPagination pages through a user's sessions two at a time
+// while the last one is revoked part-way: every other session is listed
+// once, in order.
+func TestMySessionsPagination(t *testing.T) {
+	quietLog(t)
+	s, _ := newTestServer(t)
+	h := s.Handler()
+	ctx := context.Background()
+	byPublicID := map[string]string{}
+	var want []string
+	for range 5 {
+		id, err := s.createSession(ctx, "a@example.com", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		byPublicID[sessionPublicID(id)] = id
+		want = append(want, sessionPublicID(id))
+	}
+	slices.Sort(want)
+	current, revoked := byPublicID[want[0]], byPublicID[want[4]]
+	want = want[:4]
+
+	var listed []string
+	query := url.Values{"limit": {"2"}}
+	for pages := 0; ; pages++ {
+		r := httptest.NewRequest(http.MethodGet, "/v1/me/sessions?"+query.Encode(), nil)
+		r.AddCookie(&http.Cookie{Name: "session_id", Value: current})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		var page pagination.ListResponse[sessionInfo]
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &page) != nil {
+			t.Fatalf("page %d: %d %s", pages+1, rec.Code, rec.Body)
+		}
+		for _, sess := range page.Items {
+			listed = append(listed, sess.ID)
+			if sess.Current != (sess.ID == want[0]) {
+				t.Errorf("session %s marked current %v", sess.ID, sess.Current)
+			}
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		if pages == 0 {
+			if err := s.DeleteSession(ctx, revoked); err != nil {
+				t.Fatal(err)
+			}
+		}
+		query.Set("cursor", *page.NextCursor)
+	}
+	if !slices.Equal(listed, want) {
+		t.Errorf("listed %v, want %v", listed, want)
+	}
+}
+
+// TestSessionLookupRedisDown fails Redis during a session lookup: the
+// client gets 503 with Retry-After and keeps its cookie. Only a session
+// that is really gone is a 401 that clears it.
+func TestSessionLookupRedisDown(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	sessionID, err := s.createSession(context.Background(), "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/me/sessions", nil)
+		r.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	lookups := func(result string) float64 {
+		return testutil.ToFloat64(sessionLookupsTotal.WithLabelValues(result))
+	}
+
+	failures := lookups("error")
+	mr.SetError("LOADING Redis is loading the dataset in memory")
+	rec := serve()
+	mr.SetError("")
+	if env := readEnvelope(t, rec, http.StatusServiceUnavailable); env.Error.Code != "service_unavailable" {
+		t.Errorf("code %q, want service_unavailable", env.Error.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" || rec.Header().Get("Set-Cookie") != "" {
+		t.Errorf("headers %v, want Retry-After and no Set-Cookie", rec.Header())
+	}
+	if got := lookups("error") - failures; got != 1 {
+		t.Errorf("session_lookups_total{result=error} rose by %v, want 1", got)
+	}
+
+	if rec := serve(); rec.Code != http.StatusNoContent {
+		t.Fatalf("once Redis is back: %d, want 204", rec.Code)
+	}
+
+	misses := lookups("miss")
+	if err := s.DeleteSession(context.Background(), sessionID); err != nil {
+		t.Fatal(err)
+	}
+	rec = serve()
+	if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "session_invalid" {
+		t.Errorf("deleted session: code %q, want session_invalid", env.Error.Code)
+	}
+	if c := rec.Result().Cookies(); len(c) != 1 || c[0].Name != "session_id" || c[0].MaxAge >= 0 {
+		t.Errorf("deleted session: cookies %v, want session_id cleared", c)
+	}
+	if got := lookups("miss") - misses; got != 1 {
+		t.Errorf("session_lookups_total{result=miss} rose by %v, want 1", got)
+	}
+}
+
+// TestReadSessionPipeline reads a session and its binding in one round
+// trip, and checks how a failure of either read is reported.
+func TestReadSessionPipeline(t *testing.T) {
+	s, mr := newTestServer(t)
+	trips := &roundTrips{}
+	s.rdb.AddHook(trips)
+	ctx := context.Background()
+	mr.Set("session:bound", "a@example.com")
+	mr.Set(sessionBindingKey("bound"), "fingerprint")
+	mr.Set("session:unbound", "b@example.com")
+	mr.Set("session:wrongtype", "c@example.com")
+	mr.SAdd(sessionBindingKey("wrongtype"), "x")
+	mr.Set(sessionBindingKey("gone"), "fingerprint")
+	// Open the connection first: its handshake is round trips too.
+	if err := s.rdb.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	before := trips.n.Load()
+	email, binding, err := s.readSession(ctx, "bound", true)
+	if email != "a@example.com" || binding != "fingerprint" || err != nil {
+		t.Errorf("bound: %q, %q, %v", email, binding, err)
+	}
+	if got := trips.n.Load() - before; got != 1 {
+		t.Errorf("%d round trips, want 1", got)
+	}
+
+	if email, binding, err := s.readSession(ctx, "unbound", true); email != "b@example.com" || binding != "" || err != nil {
+		t.Errorf("unbound: %q, %q, %v", email, binding, err)
+	}
+	if _, _, err := s.readSession(ctx, "wrongtype", true); err == nil || err == redis.Nil {
+		t.Errorf("binding read failed: got %v, want the error", err)
+	}
+	if _, _, err := s.readSession(ctx, "gone", true); err != redis.Nil {
+		t.Errorf("no session, binding left: got %v, want redis.Nil", err)
+	}
+	if email, binding, err := s.readSession(ctx, "wrongtype", false); email != "c@example.com" || binding != "" || err != nil {
+		t.Errorf("without binding: %q, %q, %v", email, binding, err)
+	}
+}
+
+// TestSessionStoreDuration checks that each session store operation is
+// timed under its own op label.
+func TestSessionStoreDuration(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+	count := func(op string) uint64 {
+		return histogramSnapshot(t, sessionStoreDuration.WithLabelValues(op).(prometheus.Histogram)).GetSampleCount()
+	}
+	before := map[string]uint64{}
+	for _, op := range []string{"create", "lookup", "delete", "revoke_user"} {
+		before[op] = count(op)
+	}
+
+	sessionID, err := s.createSession(ctx, "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.readSession(ctx, sessionID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteSession(ctx, sessionID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.revokeUserSessions(ctx, "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for op, n := range before {
+		if got := count(op) - n; got != 1 {
+			t.Errorf("session_store_duration_seconds{op=%s} observed %d, want 1", op, got)
+		}
+	}
+}
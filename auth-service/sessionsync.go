@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	sessionSyncChannel = "session_sync"
+	// sessionSyncMaxAge is how old a message may be when it arrives.
+	// Older ones are dropped, so a recorded session_created can't be
+	// replayed to bring back a session that has since ended.
+	sessionSyncMaxAge       = time.Minute
+	sessionSyncQueueSize    = 1024
+	sessionSyncPublishLimit = 2 * time.Second
+	sessionSyncMaxBackoff   = 30 * time.Second
+	// sessionSyncPingInterval is how long the subscription may be quiet
+	// before it is pinged, to notice a connection that died silently.
+	sessionSyncPingInterval = 30 * time.Second
+)
+
+// Session sync message types.
+const (
+	sessionSyncCreated     = "session_created"
+	sessionSyncDeleted     = "session_deleted"
+	sessionSyncUserRevoked = "user_sessions_revoked"
+)
+
+var (
+	sessionSyncPublishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "session_sync_published_total",
+		Help: "Session changes sent to other regions, by result: ok, error or dropped (queue full).",
+	}, []string{"result"})
+	sessionSyncReceivedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "session_sync_received_total",
+		Help: "Session changes received from other regions, by result: applied, error, stale or invalid.",
+	}, []string{"result"})
+)
+
+// sessionSyncMessage is one session change, sent encrypted on the bus.
+type sessionSyncMessage struct {
+	Type   string    `json:"type"`
+	Region string    `json:"region"`
+	SentAt time.Time `json:"sent_at"`
+
+	SessionID string    `json:"session_id,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Binding   string    `json:"binding,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// SessionReplicator copies session changes between regions in an
+// active-active deployment, so a session created in one region is
+// accepted in the others and one ended anywhere ends everywhere.
+//
+// Each region's sessions stay in its own Redis. Changes are published
+// on the session_sync channel of a bus Redis every region can reach,
+// and each region writes the ones from elsewhere into its own Redis.
+// Messages are AES-GCM encrypted with a key all regions share, so the
+// bus and the links to it see neither session IDs nor emails, and a
+// message can't be forged without the key.
+//
+// Pub/Sub delivers at most once: a change published while a region is
+// disconnected from the bus never reaches it. A session created then
+// is only good in its own region; one ended then stays usable in the
+// regions that missed it until it expires.
+//
+// A nil *SessionReplicator replicates nothing.
+type SessionReplicator struct {
+	local  localSessionStore
+	bus    *redis.Client
+	region string
+	aead   cipher.AEAD
+
+	outgoing chan sessionSyncMessage
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// localSessionStore is this region's Redis, where changes from other
+// regions are applied without being replicated again. *Server is one.
+type localSessionStore interface {
+	storeSession(ctx context.Context, sessionID, email, binding string, ttl time.Duration) error
+	deleteSession(ctx context.Context, sessionID string) error
+	deleteUserSessions(ctx context.Context, email string) error
+}
+
+func NewSessionReplicator(local localSessionStore, bus *redis.Client, region string, key []byte) (*SessionReplicator, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &SessionReplicator{
+		local:    local,
+		bus:      bus,
+		region:   region,
+		aead:     aead,
+		outgoing: make(chan sessionSyncMessage, sessionSyncQueueSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Close stops publishing and subscribing. Changes still queued are
+// dropped.
+func (s *SessionReplicator) Close(ctx context.Context) error {
+	s.cancel()
+	select {
+	case <-s.done:
+		return s.bus.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SessionCreated sends a new session to the other regions.
+func (s *SessionReplicator) SessionCreated(ctx context.Context, sessionID, email, binding string, expiresAt time.Time) {
+	s.send(ctx, sessionSyncMessage{Type: sessionSyncCreated, SessionID: sessionID, Email: email,
+		Binding: binding, ExpiresAt: expiresAt})
+}
+
+// SessionDeleted ends the session in the other regions.
+func (s *SessionReplicator) SessionDeleted(ctx context.Context, sessionID string) {
+	s.send(ctx, sessionSyncMessage{Type: sessionSyncDeleted, SessionID: sessionID})
+}
+
+// UserSessionsRevoked ends all of the user's sessions in the other
+// regions, including any created there that haven't reached this one.
+func (s *SessionReplicator) UserSessionsRevoked(ctx context.Context, email string) {
+	s.send(ctx, sessionSyncMessage{Type: sessionSyncUserRevoked, Email: email})
+}
+
+// send queues msg for the publisher rather than publishing it inline, so
+// a slow or distant bus doesn't slow down logins. Order is kept, so a
+// session's deletion can't overtake its creation.
+func (s *SessionReplicator) send(ctx context.Context, msg sessionSyncMessage) {
+	if s == nil || isSynthetic(ctx) {
+		return
+	}
+	msg.Region = s.region
+	select {
+	case s.outgoing <- msg:
+	default:
+		sessionSyncPublishedTotal.WithLabelValues("dropped").Inc()
+		log.Printf("session sync queue full, dropped type=%s", msg.Type)
+	}
+}
+
+func (s *SessionReplicator) run() {
+	defer close(s.done)
+
+	subscribed := make(chan struct{})
+	go func() {
+		defer close(subscribed)
+		s.subscribe()
+	}()
+	defer func() { <-subscribed }()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg := <-s.outgoing:
+			s.publish(msg)
+		}
+	}
+}
+
+func (s *SessionReplicator) publish(msg sessionSyncMessage) {
+	msg.SentAt = time.Now().UTC()
+	payload, err := s.seal(msg)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(s.ctx, sessionSyncPublishLimit)
+		err = s.bus.Publish(ctx, sessionSyncChannel, payload).Err()
+		cancel()
+	}
+	if err != nil {
+		sessionSyncPublishedTotal.WithLabelValues("error").Inc()
+		log.Printf("session sync publish error type=%s err=%v", msg.Type, err)
+		return
+	}
+	sessionSyncPublishedTotal.WithLabelValues("ok").Inc()
+}
+
+// subscribe applies changes from other regions until Close, subscribing
+// again with backoff whenever the bus connection drops.
+func (s *SessionReplicator) subscribe() {
+	backoff := time.Second
+	for s.ctx.Err() == nil {
+		err := s.receive()
+		if s.ctx.Err() != nil {
+			return
+		}
+		log.Printf("session sync subscription lost, retrying in %s: %v", backoff, err)
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, sessionSyncMaxBackoff)
+	}
+}
+
+func (s *SessionReplicator) receive() error {
+	sub := s.bus.Subscribe(s.ctx, sessionSyncChannel)
+	defer sub.Close()
+	// A blocked read doesn't watch the context; closing the subscription
+	// is what ends it on Close.
+	stop := context.AfterFunc(s.ctx, func() { sub.Close() })
+	defer stop()
+	if _, err := sub.Receive(s.ctx); err != nil {
+		return err
+	}
+	log.Println("session sync subscribed, region", s.region)
+
+	for {
+		msg, err := sub.ReceiveTimeout(s.ctx, sessionSyncPingInterval)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			if err := sub.Ping(s.ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if m, ok := msg.(*redis.Message); ok {
+			s.apply(m.Payload)
+		}
+	}
+}
+
+// apply writes a change from another region into this region's Redis,
+// through the same functions a local change goes through, minus
+// replication.
+func (s *SessionReplicator) apply(payload string) {
+	msg, err := s.open(payload)
+	if err != nil {
+		sessionSyncReceivedTotal.WithLabelValues("invalid").Inc()
+		log.Println("session sync message rejected:", err)
+		return
+	}
+	if msg.Region == s.region {
+		return
+	}
+	if time.Since(msg.SentAt) > sessionSyncMaxAge {
+		sessionSyncReceivedTotal.WithLabelValues("stale").Inc()
+		log.Printf("session sync message too old, dropped type=%s region=%s sent_at=%s", msg.Type, msg.Region, msg.SentAt)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+	switch msg.Type {
+	case sessionSyncCreated:
+		err = s.local.storeSession(ctx, msg.SessionID, msg.Email, msg.Binding, time.Until(msg.ExpiresAt))
+	case sessionSyncDeleted:
+		err = s.local.deleteSession(ctx, msg.SessionID)
+	case sessionSyncUserRevoked:
+		err = s.local.deleteUserSessions(ctx, msg.Email)
+	default:
+		err = errors.New("unknown message type " + msg.Type)
+	}
+	if err != nil {
+		sessionSyncReceivedTotal.WithLabelValues("error").Inc()
+		log.Printf("session sync apply error type=%s region=%s err=%v", msg.Type, msg.Region, err)
+		return
+	}
+	sessionSyncReceivedTotal.WithLabelValues("applied").Inc()
+}
+
+// seal encrypts msg as nonce || ciphertext. The channel name is the
+// additional data, so a message can't be moved to another channel.
+func (s *SessionReplicator) seal(msg sessionSyncMessage) (string, error) {
+	plain, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return string(s.aead.Seal(nonce, nonce, plain, []byte(sessionSyncChannel))), nil
+}
+
+func (s *SessionReplicator) open(payload string) (sessionSyncMessage, error) {
+	var msg sessionSyncMessage
+	n := s.aead.NonceSize()
+	if len(payload) < n {
+		return msg, errors.New("message too short")
+	}
+	plain, err := s.aead.Open(nil, []byte(payload[:n]), []byte(payload[n:]), []byte(sessionSyncChannel))
+	if err != nil {
+		return msg, err
+	}
+	err = json.Unmarshal(plain, &msg)
+	return msg, err
+}
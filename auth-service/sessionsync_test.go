@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+var testSessionSyncKey = bytes.Repeat([]byte{7}, 32)
+
+// replicate gives s a SessionReplicator for region on bus.
+func replicate(t *testing.T, s *Server, bus *miniredis.Miniredis, region string) *SessionReplicator {
+	t.Helper()
+	r, err := NewSessionReplicator(s, redis.NewClient(&redis.Options{Addr: bus.Addr()}), region, testSessionSyncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close(context.Background()) })
+	s.sessionReplicator = r
+	return r
+}
+
+// eventually retries cond until it holds or 5s have passed.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func waitSubscribers(t *testing.T, bus *miniredis.Miniredis, n int) {
+	t.Helper()
+	eventually(t, "subscribers", func() bool { return bus.PubSubNumSub(sessionSyncChannel)[sessionSyncChannel] == n })
+}
+
+// TestSessionReplication runs two regions, each with its own Redis, over
+// a shared bus. Sessions created, deleted and revoked in one show up in
+// the other, what crosses the bus is encrypted, replication picks up
+// again after the bus restarts, and Close returns promptly.
+func TestSessionReplication(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	bus := miniredis.RunT(t)
+	east, _ := newTestServer(t)
+	west, westRedis := newTestServer(t)
+	east.sessionCache, west.sessionCache = nil, nil
+	eastSync := replicate(t, east, bus, "us-east-1")
+	replicate(t, west, bus, "eu-west-1")
+	waitSubscribers(t, bus, 2)
+
+	// A third subscriber sees what an eavesdropper on the bus would.
+	spy := redis.NewClient(&redis.Options{Addr: bus.Addr()}).Subscribe(ctx, sessionSyncChannel)
+	t.Cleanup(func() { spy.Close() })
+	if _, err := spy.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	lookup := func(s *Server, id string) (string, string) {
+		email, binding, err := s.lookupSession(ctx, id, true)
+		if err != nil && err != redis.Nil {
+			t.Fatal(err)
+		}
+		return email, binding
+	}
+
+	id, err := east.createSession(ctx, "a@example.com", "fingerprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the session in the west", func() bool { email, _ := lookup(west, id); return email != "" })
+	if email, binding := lookup(west, id); email != "a@example.com" || binding != "fingerprint" {
+		t.Errorf("west has %q, %q", email, binding)
+	}
+	if ttl := westRedis.TTL("session:" + id); ttl <= west.cfg.SessionTTL-time.Minute || ttl > west.cfg.SessionTTL {
+		t.Errorf("west TTL %v, want about %v", ttl, west.cfg.SessionTTL)
+	}
+
+	msg, err := spy.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(msg.Payload, id) || strings.Contains(msg.Payload, "a@example.com") || strings.Contains(msg.Payload, sessionSyncCreated) {
+		t.Errorf("bus payload in the clear: %q", msg.Payload)
+	}
+
+	if err := west.DeleteSession(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the deletion in the east", func() bool { email, _ := lookup(east, id); return email == "" })
+
+	// Revoking in one region ends sessions created in both.
+	fromEast, err := east.createSession(ctx, "b@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromWest, err := west.createSession(ctx, "b@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "both sessions in both regions", func() bool {
+		e1, _ := lookup(east, fromWest)
+		e2, _ := lookup(west, fromEast)
+		return e1 != "" && e2 != ""
+	})
+	if err := east.revokeUserSessions(ctx, "b@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the revocation in the west", func() bool {
+		e1, _ := lookup(west, fromEast)
+		e2, _ := lookup(west, fromWest)
+		return e1 == "" && e2 == ""
+	})
+
+	// The subscribers reconnect after losing the bus.
+	bus.Close()
+	if err := bus.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	waitSubscribers(t, bus, 2)
+	id, err = west.createSession(ctx, "c@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the session in the east after a restart", func() bool { email, _ := lookup(east, id); return email != "" })
+
+	// Close doesn't wait for the idle subscription's ping interval.
+	closeCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := eastSync.Close(closeCtx); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+// TestSessionReplicatorApply hands the west region messages directly and
+// checks which are applied. Only fresh messages sealed with the shared key
+// by another region change anything.
+func TestSessionReplicatorApply(t *testing.T) {
+	quietLog(t)
+	ctx := context.Background()
+	bus := miniredis.RunT(t)
+	west, _ := newTestServer(t)
+	west.sessionCache = nil
+	r := replicate(t, west, bus, "eu-west-1")
+
+	otherKey, err := NewSessionReplicator(west, redis.NewClient(&redis.Options{Addr: bus.Addr()}), "us-east-1", bytes.Repeat([]byte{8}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { otherKey.Close(ctx) })
+
+	created := func(id, region string, sentAt time.Time) sessionSyncMessage {
+		return sessionSyncMessage{Type: sessionSyncCreated, Region: region, SentAt: sentAt,
+			SessionID: id, Email: "a@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	seal := func(r *SessionReplicator, msg sessionSyncMessage) string {
+		payload, err := r.seal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return payload
+	}
+	received := func(result string) float64 {
+		return testutil.ToFloat64(sessionSyncReceivedTotal.WithLabelValues(result))
+	}
+	good := seal(r, created("good", "us-east-1", time.Now()))
+	tampered := []byte(good)
+	tampered[len(tampered)-1] ^= 1
+
+	for _, tt := range []struct {
+		name, id, payload, result string
+	}{
+		{"fresh", "good", good, "applied"},
+		{"other key", "forged", seal(otherKey, created("forged", "us-east-1", time.Now())), "invalid"},
+		{"tampered", "", string(tampered), "invalid"},
+		{"truncated", "", "x", "invalid"},
+		{"replayed after a minute", "old", seal(r, created("old", "us-east-1", time.Now().Add(-2*time.Minute))), "stale"},
+		{"own region", "mine", seal(r, created("mine", "eu-west-1", time.Now())), ""},
+		{"unknown type", "", seal(r, sessionSyncMessage{Type: "session_renamed", Region: "us-east-1", SentAt: time.Now()}), "error"},
+	} {
+		before := map[string]float64{}
+		for _, result := range []string{"applied", "invalid", "stale", "error"} {
+			before[result] = received(result)
+		}
+		r.apply(tt.payload)
+		for result, n := range before {
+			want := 0.0
+			if result == tt.result {
+				want = 1
+			}
+			if got := received(result) - n; got != want {
+				t.Errorf("%s: session_sync_received_total{result=%s} rose by %v, want %v", tt.name, result, got, want)
+			}
+		}
+		if tt.id == "" {
+			continue
+		}
+		email, _, _ := west.lookupSession(ctx, tt.id, false)
+		if applied := email != ""; applied != (tt.result == "applied") {
+			t.Errorf("%s: session stored = %v", tt.name, applied)
+		}
+	}
+
+	// Synthetic checks aren't replicated; the queue stays empty.
+	west.sessionReplicator = nil
+	r.Close(ctx)
+	r.send(context.WithValue(ctx, syntheticKey{}, true), sessionSyncMessage{Type: sessionSyncDeleted, SessionID: "x"})
+	if n := len(r.outgoing); n != 0 {
+		t.Errorf("synthetic change queued: %d", n)
+	}
+}
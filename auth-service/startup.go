@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	startupBaseDelay    = 250 * time.Millisecond
+	startupMaxDelay     = 10 * time.Second
+	startupProbeTimeout = 5 * time.Second
+)
+
+// waitFor calls probe until it succeeds, ctx ends, or maxAttempts probes
+// have failed (0 means no limit). Callers bound the wait with a deadline on
+// ctx; it is also cancelled on SIGINT/SIGTERM during startup, so a stuck
+// start can be killed cleanly. Each probe gets its own startupProbeTimeout
+// so one hung connection attempt can't eat the whole deadline, and retries
+// back off exponentially with full jitter so replicas restarting together
+// don't probe in lockstep.
+func waitFor(ctx context.Context, name string, maxAttempts int, probe func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		probeCtx, cancel := context.WithTimeout(ctx, startupProbeTimeout)
+		err := probe(probeCtx)
+		cancel()
+		if err == nil {
+			log.Printf("Connected to %s (attempt %d)", name, attempt)
+			return nil
+		}
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			return fmt.Errorf("%s not ready after %d attempts: %w", name, attempt, err)
+		}
+
+		delay := startupBackoff(attempt)
+		log.Printf("Waiting for %s (attempt %d, retry in %s): %v", name, attempt, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s not ready after %d attempts (%v): %w", name, attempt, ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// startupBackoff returns the jittered delay before retry number attempt.
+func startupBackoff(attempt int) time.Duration {
+	delay := startupMaxDelay
+	if attempt < 16 {
+		delay = min(startupBaseDelay<<(attempt-1), startupMaxDelay)
+	}
+	return rand.N(delay) + time.Millisecond
+}
+
+// waitForDB blocks until Postgres answers a ping or cfg.DBWaitTimeout
+// passes, then exits.
+func waitForDB(ctx context.Context, db *sql.DB, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := waitFor(ctx, "DB", 0, db.PingContext); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// checkUserRows logs users that can't be valid accounts: a blank email, or a
+// password hash no Hasher made. Registration has long rejected them, but
+// older rows may remain and need an operator to clean them up; logins for
+// them already fail.
+func checkUserRows(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx,
+		`SELECT id FROM users
+		 WHERE btrim(email) = '' OR password_hash !~ '^(\$2[abxy]\$|\$argon2id\$|\$scrypt\$)'
+		 ORDER BY id LIMIT 20`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		log.Printf("WARNING: users with a blank email or invalid password hash (first %d ids): %v", len(ids), ids)
+	}
+	return nil
+}
+
+// prewarmDB opens count pool connections at once, since sql.Open is lazy
+// and would otherwise leave connection setup (TCP, TLS, auth) to the first
+// requests. Each connection is held until all are open; pinging through
+// db directly could hand one connection to several pings and open fewer.
+// They go back to the pool idle, so db's MaxIdleConns must be at least
+// count for them to be kept.
+func prewarmDB(ctx context.Context, db *sql.DB, count int) error {
+	if count <= 0 {
+		return nil
+	}
+	start := time.Now()
+
+	conns := make([]*sql.Conn, count)
+	g, gctx := errgroup.WithContext(ctx)
+	for i := range conns {
+		g.Go(func() error {
+			conn, err := db.Conn(gctx)
+			if err != nil {
+				return err
+			}
+			conns[i] = conn
+			return conn.PingContext(gctx)
+		})
+	}
+	err := g.Wait()
+	for _, conn := range conns {
+		if conn != nil {
+			conn.Close()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("pre-warming DB connections: %w", err)
+	}
+
+	log.Printf("Pre-warmed %d DB connections in %s", count, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// redisConnectAttempts counts failed PINGs since Redis was last reachable.
+// /health reports it while Redis is down.
+var redisConnectAttempts atomic.Int64
+
+// waitForRedis blocks until rdb answers PING, giving up after maxAttempts
+// (0 means no limit) or when ctx ends. It backs off like waitForDB.
+func waitForRedis(ctx context.Context, rdb redis.UniversalClient, maxAttempts int) error {
+	err := waitFor(ctx, "Redis", maxAttempts, func(ctx context.Context) error {
+		err := rdb.Ping(ctx).Err()
+		if err != nil {
+			redisConnectAttempts.Add(1)
+		}
+		return err
+	})
+	if err != nil {
+		log.Println("Redis connect failed:", err)
+		return err
+	}
+	redisConnectAttempts.Store(0)
+	return nil
+}
+
+// connectRedis runs waitForRedis at startup, bounded by
+// cfg.RedisWaitTimeout and cfg.RedisMaxAttempts. With cfg.RedisDegradedStart
+// the service starts anyway when Redis never comes up: rate limiting falls
+// back to memory, sessions are unavailable and /health reports redis down.
+// The wait then carries on in the background so the outage and its end are
+// logged; go-redis reconnects on its own once Redis is back.
+func connectRedis(ctx context.Context, rdb *redis.Client, cfg Config) {
+	waitCtx, cancel := context.WithTimeout(ctx, cfg.RedisWaitTimeout)
+	defer cancel()
+
+	err := waitForRedis(waitCtx, rdb, cfg.RedisMaxAttempts)
+	if err == nil {
+		return
+	}
+	if cfg.RedisDegradedStart && ctx.Err() == nil {
+		log.Println("Starting in degraded mode:", err)
+		go waitForRedis(context.Background(), rdb, 0)
+		return
+	}
+	log.Fatal(err)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var errProbe = errors.New("connection refused")
+
+// fakeProbe fails until its failures run out, recording each call.
+type fakeProbe struct {
+	failures  int
+	calls     int
+	deadlines []time.Duration
+}
+
+func (p *fakeProbe) probe(ctx context.Context) error {
+	p.calls++
+	if d, ok := ctx.Deadline(); ok {
+		p.deadlines = append(p.deadlines, time.Until(d))
+	}
+	if p.calls <= p.failures {
+		return errProbe
+	}
+	return nil
+}
+
+func TestStartupBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 40; attempt++ {
+		ceiling := startupMaxDelay
+		if attempt < 16 {
+			ceiling = min(startupBaseDelay<<(attempt-1), startupMaxDelay)
+		}
+		for range 200 {
+			d := startupBackoff(attempt)
+			if d <= 0 || d > ceiling+time.Millisecond {
+				t.Fatalf("attempt %d: delay %s outside (0, %s]", attempt, d, ceiling+time.Millisecond)
+			}
+		}
+	}
+	if startupBaseDelay<<3 != 2*time.Second {
+		t.Errorf("the fourth retry's ceiling is %s, want 2s", startupBaseDelay<<3)
+	}
+}
+
+func TestWaitForRetriesUntilSuccess(t *testing.T) {
+	quietLog(t)
+	p := &fakeProbe{failures: 2}
+	if err := waitFor(context.Background(), "test", 0, p.probe); err != nil {
+		t.Fatal(err)
+	}
+	if p.calls != 3 {
+		t.Errorf("probed %d times, want 3", p.calls)
+	}
+	for i, d := range p.deadlines {
+		if d <= 0 || d > startupProbeTimeout {
+			t.Errorf("probe %d had %s to run, want at most %s", i+1, d, startupProbeTimeout)
+		}
+	}
+	if len(p.deadlines) != p.calls {
+		t.Errorf("%d of %d probes had a deadline", len(p.deadlines), p.calls)
+	}
+}
+
+func TestWaitForMaxAttempts(t *testing.T) {
+	quietLog(t)
+	p := &fakeProbe{failures: 100}
+	err := waitFor(context.Background(), "test", 2, p.probe)
+	if !errors.Is(err, errProbe) {
+		t.Fatalf("got %v, want the probe's error", err)
+	}
+	if p.calls != 2 || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Errorf("probed %d times, error %q", p.calls, err)
+	}
+}
+
+// Cancelling ctx ends a wait at once: no backoff is slept out and no
+// further probe is made.
+func TestWaitForCancellation(t *testing.T) {
+	quietLog(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &fakeProbe{failures: 100}
+	probe := func(ctx context.Context) error {
+		err := p.probe(ctx)
+		if p.calls == 5 {
+			cancel()
+		}
+		return err
+	}
+
+	start := time.Now()
+	err := waitFor(ctx, "test", 0, probe)
+	if !errors.Is(err, errProbe) || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatalf("got %v, want the probe's error and the cancellation", err)
+	}
+	if p.calls != 5 {
+		t.Errorf("probed %d times after cancelling on the 5th", p.calls)
+	}
+	// Four backoffs of at most 250ms, 500ms, 1s and 2s, and no fifth.
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("took %s; the wait wasn't cut short", elapsed)
+	}
+
+	// Already cancelled: one probe, then give up.
+	p = &fakeProbe{failures: 100}
+	if err := waitFor(ctx, "test", 0, p.probe); err == nil || p.calls != 1 {
+		t.Errorf("with ctx done: probed %d times, err %v", p.calls, err)
+	}
+}
+
+// failingPings fails the first failures PINGs sent through it, as a Redis
+// that is still starting would, and counts them all.
+type failingPings struct {
+	failures, pings int
+}
+
+func (h *failingPings) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *failingPings) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() != "ping" {
+			return next(ctx, cmd)
+		}
+		h.pings++
+		if h.pings <= h.failures {
+			cmd.SetErr(errProbe)
+			return errProbe
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *failingPings) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestWaitForRedis(t *testing.T) {
+	quietLog(t)
+	t.Cleanup(func() { redisConnectAttempts.Store(0) })
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	pings := &failingPings{failures: 2}
+	rdb.AddHook(pings)
+	if err := waitForRedis(context.Background(), rdb, 5); err != nil {
+		t.Fatalf("got %v after %d pings, want success on the 3rd", err, pings.pings)
+	}
+	if pings.pings != 3 || redisConnectAttempts.Load() != 0 {
+		t.Errorf("%d pings, %d attempts left on record; want 3 and 0", pings.pings, redisConnectAttempts.Load())
+	}
+
+	pings.pings, pings.failures = 0, 100
+	if err := waitForRedis(context.Background(), rdb, 2); !errors.Is(err, errProbe) {
+		t.Errorf("got %v, want the ping's error", err)
+	}
+	if n := redisConnectAttempts.Load(); n != 2 {
+		t.Errorf("%d attempts on record, want 2", n)
+	}
+}
+
+// TestHealthReportsRedisAttempts checks /health shows the failed connect
+// attempts while Redis is down.
+func TestHealthReportsRedisAttempts(t *testing.T) {
+	quietLog(t)
+	t.Cleanup(func() { redisConnectAttempts.Store(0) })
+	s, mr := newTestServer(t)
+	useFakeRefreshDB(t, s)
+	mr.Close()
+	redisConnectAttempts.Store(4)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var status struct {
+		Redis         string `json:"redis"`
+		RedisAttempts int    `json:"redis_connect_attempts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	if status.Redis != "down" || status.RedisAttempts != 4 {
+		t.Errorf("got %s, want redis down after 4 attempts", rec.Body)
+	}
+}
+
+// countingConnector hands out a new connection per Connect, counting them,
+// and fails once fail is set.
+type countingConnector struct {
+	connects atomic.Int32
+	fail     bool
+}
+
+type countedConn struct{}
+
+func (countedConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (countedConn) Close() error                        { return nil }
+func (countedConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *countingConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.fail {
+		return nil, errProbe
+	}
+	c.connects.Add(1)
+	return countedConn{}, nil
+}
+
+func (c *countingConnector) Driver() driver.Driver { return nil }
+
+func TestPrewarmDB(t *testing.T) {
+	quietLog(t)
+	connector := &countingConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(5)
+	db.SetMaxIdleConns(5)
+
+	if err := prewarmDB(context.Background(), db, 5); err != nil {
+		t.Fatal(err)
+	}
+	if stats := db.Stats(); stats.OpenConnections != 5 || stats.Idle != 5 || connector.connects.Load() != 5 {
+		t.Errorf("%d connects, %d open, %d idle; want 5 of each", connector.connects.Load(), stats.OpenConnections, stats.Idle)
+	}
+
+	failing := sql.OpenDB(&countingConnector{fail: true})
+	defer failing.Close()
+	if err := prewarmDB(context.Background(), failing, 3); !errors.Is(err, errProbe) {
+		t.Errorf("unreachable database: %v, want the connect error", err)
+	}
+}
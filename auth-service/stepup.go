@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/auth"
+)
+
+// requireRecentAuth must run after jwtMiddleware. It admits only users who
+// logged in within cfg.StepUpMaxAge, so a stolen but long-lived session
+// can't reach the most sensitive endpoints. Otherwise clients get 401
+// reauthentication_required and should send the user through login again.
+func (s *Server) requireRecentAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Tokens issued before auth_time was added have none and never pass.
+		user, ok := auth.UserFromContext(r.Context())
+		if !ok || user.AuthTime.IsZero() || s.clock.Now().Sub(user.AuthTime) > s.cfg.StepUpMaxAge {
+			apperror.WriteError(w, r, apperror.Unauthorized("reauthentication_required", "Please log in again to continue"))
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"resilient-auth-service/pagination"
+)
+
+// ErrNotFound means a query matched no row. Only scanRow and the
+// UserStore return it, so a caller that checks for it can't mistake a
+// failed query for a missing row: every other error is a real database
+// failure and should be answered with writeDBError, not treated as "no
+// such user".
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicateEmail means a write hit the unique constraint on
+// users.email. Only the UserStore returns it; other unique violations
+// (there are none on users today) are real errors, not a sign the user
+// exists.
+var ErrDuplicateEmail = errors.New("email already registered")
+
+// scanRow scans a single-row result, reporting no row as ErrNotFound.
+func scanRow(row *sql.Row, dest ...any) error {
+	err := row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// User is an account as the UserStore keeps it.
+type User struct {
+	ID           int
+	Email        string
+	PasswordHash string
+	Role         string
+	// TOTPSecret is empty unless two-factor login is on.
+	TOTPSecret string
+	CreatedAt  time.Time
+}
+
+// UserStatus is whether an account can be used. Deleted accounts are
+// kept, and keep their email reserved, but no lookup finds them.
+type UserStatus string
+
+const (
+	UserActive  UserStatus = "active"
+	UserDeleted UserStatus = "deleted"
+)
+
+// UserKey is the order List pages in, carried in the admin user list's
+// cursors.
+type UserKey struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int       `json:"id"`
+}
+
+// UserStore is where accounts live. Lookups and updates only see active
+// users; a missing one is ErrNotFound, and an email already taken, by an
+// active or a deleted user, is ErrDuplicateEmail.
+type UserStore interface {
+	Create(ctx context.Context, email, passwordHash string) (User, error)
+	GetByEmail(ctx context.Context, email string) (User, error)
+	GetByID(ctx context.Context, id int) (User, error)
+	// UpdatePassword replaces the hash only if it is still oldHash, so it
+	// can't undo a password change made since the caller read the user.
+	// Otherwise it returns ErrNotFound.
+	UpdatePassword(ctx context.Context, id int, oldHash, newHash string) error
+	UpdateEmail(ctx context.Context, id int, email string) error
+	// SetStatus returns ErrNotFound only if there's no such user at all,
+	// deleted or not; setting the status a user already has does nothing.
+	SetStatus(ctx context.Context, id int, status UserStatus) error
+	// List returns up to limit active users in UserKey order, starting
+	// after the given key if there is one.
+	List(ctx context.Context, after *UserKey, limit int) ([]User, error)
+}
+
+// PostgresUserStore is the UserStore on the users table. Create and
+// GetByEmail run as the service's own role, since registration and login
+// don't know who the user is yet. The other methods keyed by id run in
+// that user's row-level security scope, so a bug in a WHERE clause still
+// can't reach another account; List and SetStatus are admin operations
+// and run as authdb_admin.
+//
+// Create and SetStatus also write user.registered and user.deleted to the
+// outbox, in the same transaction as the change.
+type PostgresUserStore struct {
+	db *sql.DB
+	// The hottest lookups are prepared once. lib/pq runs an unprepared
+	// query with arguments as a parse round trip followed by an execute
+	// round trip; a prepared one needs only the second. database/sql
+	// prepares each statement on every pooled connection it runs on,
+	// including the ones that replace broken connections, so nothing here
+	// re-prepares by hand.
+	//
+	// Named prepared statements outlive a transaction, so they don't work
+	// through a transaction-pooling proxy such as PgBouncer in transaction
+	// mode; connect directly or use session pooling.
+	byEmail *sql.Stmt
+	byID    *sql.Stmt
+}
+
+const userColumns = "id, email, password_hash, role, COALESCE(totp_secret, ''), created_at"
+
+var usersKeyset = pagination.Keyset{Columns: []string{"created_at", "id"}}
+
+// NewPostgresUserStore must run after migrations, since its statements
+// name tables and views they create.
+func NewPostgresUserStore(ctx context.Context, db *sql.DB) (*PostgresUserStore, error) {
+	p := &PostgresUserStore{db: db}
+	for _, s := range []struct {
+		dst   **sql.Stmt
+		query string
+	}{
+		{&p.byEmail, "SELECT " + userColumns + " FROM active_users WHERE email = $1"},
+		{&p.byID, "SELECT " + userColumns + " FROM active_users WHERE id = $1"},
+	} {
+		stmt, err := db.PrepareContext(ctx, s.query)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("prepare %q: %w", s.query, err)
+		}
+		*s.dst = stmt
+	}
+	return p, nil
+}
+
+// Close releases the statements on every connection they were prepared
+// on. Call it once nothing can run them any more.
+func (p *PostgresUserStore) Close() error {
+	var errs []error
+	for _, stmt := range []*sql.Stmt{p.byEmail, p.byID} {
+		if stmt != nil {
+			errs = append(errs, stmt.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func scanUser(row *sql.Row) (User, error) {
+	var u User
+	err := scanRow(row, &u.ID, &u.Email, &u.PasswordHash, &u.Role, &u.TOTPSecret, &u.CreatedAt)
+	return u, err
+}
+
+// insertUser creates a user in tx, which the caller commits along with
+// whatever else belongs with the new user. Create uses it; so does
+// signing up through an org invitation, which has to consume the
+// invitation in the same transaction.
+func insertUser(ctx context.Context, tx *sql.Tx, email, passwordHash string) (User, error) {
+	u, err := scanUser(tx.QueryRowContext(ctx,
+		"INSERT INTO users (email, password_hash) VALUES ($1, $2) RETURNING "+userColumns,
+		email, passwordHash,
+	))
+	if isDuplicateEmail(err) {
+		return User{}, ErrDuplicateEmail
+	}
+	if err != nil {
+		return User{}, err
+	}
+	err = writeOutbox(ctx, tx, "user:"+strconv.Itoa(u.ID), "user.registered",
+		map[string]any{"user_id": u.ID, "email": u.Email})
+	return u, err
+}
+
+func isDuplicateEmail(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && // unique_violation
+		pqErr.Constraint == "users_email_key"
+}
+
+func (p *PostgresUserStore) Create(ctx context.Context, email, passwordHash string) (User, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return User{}, err
+	}
+	defer tx.Rollback()
+
+	u, err := insertUser(ctx, tx, email, passwordHash)
+	if err != nil {
+		return User{}, err
+	}
+	return u, tx.Commit()
+}
+
+func (p *PostgresUserStore) GetByEmail(ctx context.Context, email string) (User, error) {
+	return scanUser(p.byEmail.QueryRowContext(ctx, email))
+}
+
+func (p *PostgresUserStore) GetByID(ctx context.Context, id int) (User, error) {
+	var u User
+	err := userScope(ctx, p.db, id, func(tx *sql.Tx) error {
+		var err error
+		u, err = scanUser(tx.StmtContext(ctx, p.byID).QueryRowContext(ctx, id))
+		return err
+	})
+	return u, err
+}
+
+func (p *PostgresUserStore) UpdatePassword(ctx context.Context, id int, oldHash, newHash string) error {
+	return userScope(ctx, p.db, id, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE active_users SET password_hash = $1 WHERE id = $2 AND password_hash = $3",
+			newHash, id, oldHash,
+		)
+		return updatedOne(res, err)
+	})
+}
+
+func (p *PostgresUserStore) UpdateEmail(ctx context.Context, id int, email string) error {
+	return userScope(ctx, p.db, id, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "UPDATE active_users SET email = $1 WHERE id = $2", email, id)
+		if isDuplicateEmail(err) {
+			return ErrDuplicateEmail
+		}
+		return updatedOne(res, err)
+	})
+}
+
+// SetStatus goes to the users table itself: restoring a user is the one
+// update that must see deleted rows.
+func (p *PostgresUserStore) SetStatus(ctx context.Context, id int, status UserStatus) error {
+	var query string
+	switch status {
+	case UserActive:
+		query = "UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	case UserDeleted:
+		query = "UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL"
+	default:
+		return fmt.Errorf("unknown user status %q", status)
+	}
+	return adminScope(ctx, p.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			var exists bool
+			err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", id).Scan(&exists)
+			if err == nil && !exists {
+				err = ErrNotFound
+			}
+			return err
+		}
+		if status == UserDeleted {
+			return writeOutbox(ctx, tx, "user:"+strconv.Itoa(id), "user.deleted", map[string]any{"user_id": id})
+		}
+		return nil
+	})
+}
+
+func (p *PostgresUserStore) List(ctx context.Context, after *UserKey, limit int) ([]User, error) {
+	query := "SELECT " + userColumns + " FROM active_users"
+	var args []any
+	if after != nil {
+		query += " WHERE " + usersKeyset.After(1)
+		args = append(args, after.CreatedAt, after.ID)
+	}
+	args = append(args, limit)
+	query += " ORDER BY " + usersKeyset.OrderBy() + " LIMIT $" + strconv.Itoa(len(args))
+
+	var users []User
+	err := adminScope(ctx, p.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var u User
+			if err := rows.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Role, &u.TOTPSecret, &u.CreatedAt); err != nil {
+				return err
+			}
+			users = append(users, u)
+		}
+		return rows.Err()
+	})
+	return users, err
+}
+
+// updatedOne turns an update that matched no row into ErrNotFound.
+func updatedOne(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		err = ErrNotFound
+	}
+	return err
+}
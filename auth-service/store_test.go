@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScanRow(t *testing.T) {
+	empty := sql.OpenDB(&staticRowsDB{fakeRows{cols: []string{"id"}}})
+	defer empty.Close()
+	down := errDB()
+	defer down.Close()
+
+	var id int
+	if err := scanRow(empty.QueryRow("SELECT id"), &id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("no row: got %v, want ErrNotFound", err)
+	}
+	if err := scanRow(down.QueryRow("SELECT id"), &id); !errors.Is(err, errDBDown) {
+		t.Errorf("database down: got %v, want errDBDown", err)
+	}
+}
+
+func TestMemoryUserStore(t *testing.T) {
+	testUserStore(t, func(t *testing.T) UserStore { return NewMemoryUserStore() })
+}
+
+// openTestDatabase opens and migrates the database TEST_DATABASE_URL
+// names, and skips the test when there is none.
+func openTestDatabase(tb testing.TB) *sql.DB {
+	tb.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		tb.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	if err := (&Migrator{db: db}).Up(context.Background()); err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+// TestPostgresUserStore runs the same suite against a real database when
+// TEST_DATABASE_URL names one. It migrates it and empties users before
+// every case, so point it at a scratch database.
+func TestPostgresUserStore(t *testing.T) {
+	db := openTestDatabase(t)
+	ctx := context.Background()
+	testUserStore(t, func(t *testing.T) UserStore {
+		if _, err := db.ExecContext(ctx, "TRUNCATE users, outbox_events RESTART IDENTITY CASCADE"); err != nil {
+			t.Fatal(err)
+		}
+		store, err := NewPostgresUserStore(ctx, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+// testUserStore is the contract every UserStore keeps. newStore must
+// return an empty store.
+func testUserStore(t *testing.T, newStore func(t *testing.T) UserStore) {
+	ctx := context.Background()
+	sameUser := func(t *testing.T, got, want User) {
+		t.Helper()
+		if got.ID != want.ID || got.Email != want.Email || got.PasswordHash != want.PasswordHash ||
+			got.Role != want.Role || got.TOTPSecret != want.TOTPSecret || !got.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+	create := func(t *testing.T, store UserStore, email string) User {
+		t.Helper()
+		u, err := store.Create(ctx, email, "hash-of-"+email)
+		if err != nil {
+			t.Fatalf("create %s: %v", email, err)
+		}
+		return u
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		store := newStore(t)
+		u := create(t, store, "a@example.com")
+		if u.ID == 0 || u.Email != "a@example.com" || u.PasswordHash != "hash-of-a@example.com" ||
+			u.Role != "user" || u.TOTPSecret != "" || u.CreatedAt.IsZero() {
+			t.Fatalf("created %+v", u)
+		}
+		got, err := store.GetByEmail(ctx, "a@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameUser(t, got, u)
+		got, err = store.GetByID(ctx, u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameUser(t, got, u)
+
+		if other := create(t, store, "b@example.com"); other.ID == u.ID {
+			t.Errorf("two users with id %d", u.ID)
+		}
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		store := newStore(t)
+		create(t, store, "a@example.com")
+		if _, err := store.Create(ctx, "a@example.com", "other"); !errors.Is(err, ErrDuplicateEmail) {
+			t.Errorf("got %v, want ErrDuplicateEmail", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		store := newStore(t)
+		if _, err := store.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetByEmail: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetByID(ctx, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetByID: got %v, want ErrNotFound", err)
+		}
+		if err := store.UpdatePassword(ctx, 999, "x", "y"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("UpdatePassword: got %v, want ErrNotFound", err)
+		}
+		if err := store.UpdateEmail(ctx, 999, "x@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("UpdateEmail: got %v, want ErrNotFound", err)
+		}
+		if err := store.SetStatus(ctx, 999, UserDeleted); !errors.Is(err, ErrNotFound) {
+			t.Errorf("SetStatus: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update password", func(t *testing.T) {
+		store := newStore(t)
+		u := create(t, store, "a@example.com")
+		if err := store.UpdatePassword(ctx, u.ID, "stale", "new"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("with a stale hash: got %v, want ErrNotFound", err)
+		}
+		if err := store.UpdatePassword(ctx, u.ID, u.PasswordHash, "new"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.GetByID(ctx, u.ID)
+		if err != nil || got.PasswordHash != "new" {
+			t.Errorf("after update: %+v, %v", got, err)
+		}
+	})
+
+	t.Run("update email", func(t *testing.T) {
+		store := newStore(t)
+		a := create(t, store, "a@example.com")
+		create(t, store, "b@example.com")
+		if err := store.UpdateEmail(ctx, a.ID, "b@example.com"); !errors.Is(err, ErrDuplicateEmail) {
+			t.Errorf("to a taken email: got %v, want ErrDuplicateEmail", err)
+		}
+		if err := store.UpdateEmail(ctx, a.ID, "c@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetByEmail(ctx, "a@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("old email: got %v, want ErrNotFound", err)
+		}
+		if got, err := store.GetByEmail(ctx, "c@example.com"); err != nil || got.ID != a.ID {
+			t.Errorf("new email: %+v, %v", got, err)
+		}
+	})
+
+	t.Run("set status", func(t *testing.T) {
+		store := newStore(t)
+		u := create(t, store, "a@example.com")
+		for range 2 {
+			if err := store.SetStatus(ctx, u.ID, UserDeleted); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := store.GetByID(ctx, u.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetByID a deleted user: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetByEmail(ctx, u.Email); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetByEmail a deleted user: got %v, want ErrNotFound", err)
+		}
+		if err := store.UpdatePassword(ctx, u.ID, u.PasswordHash, "new"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("UpdatePassword a deleted user: got %v, want ErrNotFound", err)
+		}
+		if users, err := store.List(ctx, nil, 10); err != nil || len(users) != 0 {
+			t.Errorf("List: %+v, %v; want no users", users, err)
+		}
+		if _, err := store.Create(ctx, u.Email, "other"); !errors.Is(err, ErrDuplicateEmail) {
+			t.Errorf("a deleted user's email: got %v, want ErrDuplicateEmail", err)
+		}
+
+		if err := store.SetStatus(ctx, u.ID, UserActive); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.GetByID(ctx, u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameUser(t, got, u)
+		if err := store.SetStatus(ctx, u.ID, "banned"); err == nil {
+			t.Error("an unknown status was accepted")
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		store := newStore(t)
+		var want []User
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+			want = append(want, create(t, store, email))
+		}
+		if err := store.SetStatus(ctx, want[2].ID, UserDeleted); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want[:2], want[3:]...)
+
+		var got []User
+		var after *UserKey
+		for {
+			page, err := store.List(ctx, after, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) > 2 {
+				t.Fatalf("page of %d, want at most 2", len(page))
+			}
+			got = append(got, page...)
+			if len(page) < 2 {
+				break
+			}
+			last := userKey(page[len(page)-1])
+			after = &last
+		}
+		if len(got) != len(want) {
+			t.Fatalf("listed %d users, want %d: %+v", len(got), len(want), got)
+		}
+		for i := range want {
+			sameUser(t, got[i], want[i])
+		}
+	})
+}
+
+// preparingDB is a driver without QueryerContext, so database/sql
+// prepares every query, ad hoc or not, through it. It counts the
+// statements prepared and closed, and answers every query with one user.
+type preparingDB struct {
+	prepared, closed atomic.Int32
+}
+
+func (d *preparingDB) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *preparingDB) Driver() driver.Driver                        { return nil }
+func (d *preparingDB) Close() error                                 { return nil }
+func (d *preparingDB) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (d *preparingDB) Prepare(string) (driver.Stmt, error) {
+	d.prepared.Add(1)
+	return preparedStmt{d}, nil
+}
+
+type preparedStmt struct{ d *preparingDB }
+
+func (s preparedStmt) Close() error  { s.d.closed.Add(1); return nil }
+func (s preparedStmt) NumInput() int { return -1 }
+
+func (s preparedStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s preparedStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{
+		cols: []string{"id", "email", "password_hash", "role", "totp_secret", "created_at"},
+		rows: [][]driver.Value{{int64(1), "a@example.com", "hash", "user", "", time.Now()}},
+	}, nil
+}
+
+// TestPostgresUserStoreStatements checks that the store prepares its
+// lookups once, not per call, and that Close releases them.
+func TestPostgresUserStoreStatements(t *testing.T) {
+	d := &preparingDB{}
+	db := sql.OpenDB(d)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	store, err := NewPostgresUserStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prepared := d.prepared.Load()
+	for range 10 {
+		if _, err := store.GetByEmail(ctx, "a@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := d.prepared.Load(); got != prepared {
+		t.Errorf("%d statements prepared by 10 lookups, want none", got-prepared)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.closed.Load(); got != prepared {
+		t.Errorf("%d of %d statements closed", got, prepared)
+	}
+}
+
+// BenchmarkUserLookup compares the login lookup as a prepared statement
+// and as an ad-hoc query, against TEST_DATABASE_URL:
+//
+//	TEST_DATABASE_URL=postgres://... go test -run '^$' -bench UserLookup
+func BenchmarkUserLookup(b *testing.B) {
+	db := openTestDatabase(b)
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "TRUNCATE users, outbox_events RESTART IDENTITY CASCADE"); err != nil {
+		b.Fatal(err)
+	}
+	store, err := NewPostgresUserStore(ctx, db)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { store.Close() })
+	if _, err := store.Create(ctx, "bench@example.com", "hash"); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("prepared", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := store.GetByEmail(ctx, "bench@example.com"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unprepared", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := scanUser(db.QueryRowContext(ctx,
+				"SELECT "+userColumns+" FROM active_users WHERE email = $1", "bench@example.com")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"resilient-auth-service/mailer"
+)
+
+// syntheticCheckTimeout bounds one run of the synthetic check.
+const syntheticCheckTimeout = 30 * time.Second
+
+var syntheticCheckSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "synthetic_check_success",
+	Help: "1 if the last synthetic register/login check passed, 0 if it failed.",
+})
+
+type syntheticKey struct{}
+
+// isSynthetic reports whether ctx belongs to a synthetic check request.
+// Those have no effects outside the service: no audit rows, outbox
+// events, webhooks or email. The key is unexported, so a client request
+// can't claim to be one.
+func isSynthetic(ctx context.Context) bool {
+	return ctx.Value(syntheticKey{}) != nil
+}
+
+// syntheticEmailFilter drops email sent on behalf of a synthetic check.
+type syntheticEmailFilter struct {
+	mailer.Sender
+}
+
+func (f syntheticEmailFilter) Send(ctx context.Context, msg mailer.Message) error {
+	if isSynthetic(ctx) {
+		return nil
+	}
+	return f.Sender.Send(ctx, msg)
+}
+
+// SyntheticResult is the outcome of the last synthetic check, as reported
+// in /health.
+type SyntheticResult struct {
+	Status string `json:"status"`
+	// Step is the step that failed: register, login, me, session or
+	// cleanup.
+	Step      string    `json:"step,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// SyntheticMonitor periodically registers a throwaway user, logs in, and
+// uses the access token and the session, calling the handlers in process.
+// That catches what a ping can't, like a migration that broke a query,
+// and the user is deleted afterwards. Every replica checks itself.
+type SyntheticMonitor struct {
+	s        *Server
+	interval time.Duration
+	// cert stands in for a client certificate, so the check passes under
+	// TOKEN_BINDING_MODE=required.
+	cert *x509.Certificate
+
+	mu   sync.Mutex
+	last *SyntheticResult
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSyntheticMonitor(s *Server, interval time.Duration) (*SyntheticMonitor, error) {
+	cert, err := selfSignedCert()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &SyntheticMonitor{
+		s:        s,
+		interval: interval,
+		cert:     cert,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go m.run()
+	return m, nil
+}
+
+// Close stops the monitor, cancelling a check in progress. The check's
+// user is then left behind; it is harmless, but its address is
+// recognisable by the synthetic.invalid domain.
+func (m *SyntheticMonitor) Close(ctx context.Context) error {
+	m.cancel()
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Last returns the most recent result, or nil before the first check.
+func (m *SyntheticMonitor) Last() *SyntheticResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+func (m *SyntheticMonitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		res := m.check()
+		if res.Status == "pass" {
+			syntheticCheckSuccess.Set(1)
+		} else {
+			syntheticCheckSuccess.Set(0)
+			log.Printf("synthetic check failed step=%s err=%s", res.Step, res.Error)
+		}
+		m.mu.Lock()
+		m.last = &res
+		m.mu.Unlock()
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *SyntheticMonitor) check() SyntheticResult {
+	ctx, cancel := context.WithTimeout(context.WithValue(m.ctx, syntheticKey{}, true), syntheticCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res := SyntheticResult{Status: "pass", CheckedAt: start.UTC()}
+	fail := func(step string, err error) {
+		if res.Status == "pass" {
+			res.Status, res.Step, res.Error = "fail", step, err.Error()
+		}
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		fail("register", err)
+		return res
+	}
+	email := "synthetic-" + hex.EncodeToString(b[:8]) + "@synthetic.invalid"
+	password := hex.EncodeToString(b[8:]) + "-Synthetic"
+
+	step, err := m.steps(ctx, email, password)
+	if err != nil {
+		fail(step, err)
+	}
+	// Clean up even after a failure: the user may exist all the same.
+	if err := m.s.deleteSyntheticUser(ctx, email); err != nil {
+		fail("cleanup", err)
+	}
+
+	res.LatencyMS = time.Since(start).Milliseconds()
+	return res
+}
+
+// steps runs the flow, returning the failed step's name with the error.
+func (m *SyntheticMonitor) steps(ctx context.Context, email, password string) (string, error) {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+
+	w := m.serve(ctx, http.HandlerFunc(m.s.registerHandler), http.MethodPost, "/v1/register", body)
+	if w.Code != http.StatusCreated {
+		return "register", unexpectedStatus(w)
+	}
+
+	w = m.serve(ctx, http.HandlerFunc(m.s.loginHandler), http.MethodPost, "/v1/login", body)
+	if w.Code != http.StatusOK {
+		return "login", unexpectedStatus(w)
+	}
+	cookies := w.Result().Cookies()
+
+	w = m.serve(ctx, m.s.jwtMiddleware(http.HandlerFunc(m.s.meHandler)), http.MethodGet, "/v1/me", nil, cookies...)
+	if w.Code != http.StatusOK {
+		return "me", unexpectedStatus(w)
+	}
+	if !strings.Contains(w.Body.String(), email) {
+		return "me", fmt.Errorf("response doesn't name the user")
+	}
+
+	w = m.serve(ctx, m.s.authMiddleware(http.HandlerFunc(m.s.mySessionsHandler)), http.MethodGet, "/v1/me/sessions", nil, cookies...)
+	if w.Code != http.StatusOK {
+		return "session", unexpectedStatus(w)
+	}
+	return "", nil
+}
+
+func (m *SyntheticMonitor) serve(ctx context.Context, h http.Handler, method, target string, body []byte, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	r.Header.Set("User-Agent", "synthetic-check")
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{m.cert}}
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func unexpectedStatus(w *httptest.ResponseRecorder) error {
+	return fmt.Errorf("status %d: %s", w.Code, strings.TrimSpace(w.Body.String()))
+}
+
+// deleteSyntheticUser removes the check's user for good, along with its
+// refresh tokens (by cascade), sessions and trusted devices. Unlike the
+// admin delete, it doesn't keep the row: nobody will ever need it.
+func (s *Server) deleteSyntheticUser(ctx context.Context, email string) error {
+	// The check's own context may have run out.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	var id int
+	err := s.withAdminScope(ctx, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, "DELETE FROM users WHERE email = $1 RETURNING id", email).Scan(&id)
+	})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.revokeUserSessions(ctx, email); err != nil {
+		return err
+	}
+	return s.rdb.Del(ctx, trustedDevicesKey(id)).Err()
+}
+
+func selfSignedCert() (*x509.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "synthetic-check"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
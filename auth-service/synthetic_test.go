@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// syntheticDB is fakeRefreshDB plus the statements deleteSyntheticUser
+// runs. Setting down breaks it, as a database lost after startup would
+// be: every statement and ping fails.
+type syntheticDB struct {
+	*fakeRefreshDB
+	down    atomic.Bool
+	deleted []string
+}
+
+func (d *syntheticDB) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *syntheticDB) Begin() (driver.Tx, error)                    { return d, nil }
+
+func (d *syntheticDB) Ping(context.Context) error {
+	if d.down.Load() {
+		return errDBDown
+	}
+	return nil
+}
+
+func (d *syntheticDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if d.down.Load() {
+		return nil, errDBDown
+	}
+	if query == "SET LOCAL ROLE authdb_admin" {
+		return driver.RowsAffected(0), nil
+	}
+	return d.fakeRefreshDB.ExecContext(ctx, query, args)
+}
+
+func (d *syntheticDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if d.down.Load() {
+		return nil, errDBDown
+	}
+	if query == "DELETE FROM users WHERE email = $1 RETURNING id" {
+		d.deleted = append(d.deleted, args[0].Value.(string))
+		return &fakeRows{cols: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}, nil
+	}
+	return d.fakeRefreshDB.QueryContext(ctx, query, args)
+}
+
+// TestSyntheticMonitor runs the synthetic check against a working
+// database, then breaks the database. The check passes, cleans up after
+// itself and sends nothing, then fails at login, and /health reports both
+// it and the database ping as failing.
+func TestSyntheticMonitor(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	db := &syntheticDB{fakeRefreshDB: &fakeRefreshDB{}}
+	s.db = sql.OpenDB(db)
+	s.db.SetMaxOpenConns(1)
+	t.Cleanup(func() { s.db.Close() })
+	emails := s.emailSender.(*fakeEmailSender)
+	s.emailSender = syntheticEmailFilter{emails}
+
+	m, err := NewSyntheticMonitor(s, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close(context.Background()) })
+	s.syntheticMonitor = m
+
+	waitFor := func(status string) *SyntheticResult {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+			if res := m.Last(); res != nil && res.Status == status {
+				return res
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("last result %+v, want %s", m.Last(), status)
+			}
+		}
+	}
+	health := func() map[string]any {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.healthHandler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	if res := waitFor("pass"); res.Step != "" || res.Error != "" {
+		t.Errorf("passing result %+v", res)
+	}
+	if got := testutil.ToFloat64(syntheticCheckSuccess); got != 1 {
+		t.Errorf("synthetic_check_success %v, want 1", got)
+	}
+	body := health()
+	if body["database"] != "up" || body["self_test"].(map[string]any)["status"] != "pass" {
+		t.Errorf("/health %v", body)
+	}
+
+	m.Close(context.Background())
+	if len(db.deleted) == 0 || !strings.HasSuffix(db.deleted[0], "@synthetic.invalid") {
+		t.Errorf("deleted users %v", db.deleted)
+	}
+	for _, key := range mr.Keys() {
+		if strings.HasPrefix(key, "session:") || strings.HasPrefix(key, "user_sessions:") {
+			t.Errorf("%s left behind", key)
+		}
+	}
+	if sent := emails.messages(); len(sent) != 0 {
+		t.Errorf("emails sent: %+v", sent)
+	}
+
+	db.down.Store(true)
+	m, err = NewSyntheticMonitor(s, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close(context.Background()) })
+	s.syntheticMonitor = m
+
+	if res := waitFor("fail"); res.Step != "login" || res.Error == "" {
+		t.Errorf("failing result %+v, want a login failure", res)
+	}
+	if got := testutil.ToFloat64(syntheticCheckSuccess); got != 0 {
+		t.Errorf("synthetic_check_success %v, want 0", got)
+	}
+	body = health()
+	if body["database"] != "down" || body["self_test"].(map[string]any)["status"] != "fail" {
+		t.Errorf("/health %v", body)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func initPropagation() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// withUserBaggage records the authenticated user in ctx's OpenTelemetry
+// baggage, so it travels with outgoing calls to downstream services, and
+// tags the current span with it.
+func withUserBaggage(ctx context.Context, email string) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("enduser.id", email))
+
+	// NewMember expects a percent-encoded value.
+	member, err := baggage.NewMember("auth.email", url.PathEscape(email))
+	if err != nil {
+		log.Println("baggage member error:", err)
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		log.Println("baggage set error:", err)
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// propagationHeaders captures ctx's trace context and baggage as headers,
+// for work that is carried out later, off the request goroutine.
+func propagationHeaders(ctx context.Context) propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
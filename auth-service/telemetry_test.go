@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// TestUserBaggage authenticates a request and enqueues a webhook from it:
+// the user's email is in the handler's baggage and reaches the webhook
+// endpoint in the baggage header.
+func TestUserBaggage(t *testing.T) {
+	quietLog(t)
+	initPropagation()
+	received := make(chan string, 1)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("baggage")
+	}))
+	defer endpoint.Close()
+
+	s, _ := newTestServer(t)
+	fake := &fakeWebhookDB{url: endpoint.URL, secret: "s3cret"}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	d := NewWebhookDispatcher(db, 8)
+
+	sessionID, err := s.createSession(context.Background(), "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := baggage.FromContext(r.Context()).Member("auth.email")
+		if got, _ := url.PathUnescape(m.Value()); got != "a@example.com" {
+			t.Errorf("auth.email baggage %q, want a@example.com", m.Value())
+		}
+		d.Enqueue(r.Context(), "user.updated", map[string]any{})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+	r.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status %d", rec.Code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case header := <-received:
+		bag, err := baggage.Parse(header)
+		if err != nil {
+			t.Fatalf("baggage header %q: %v", header, err)
+		}
+		if got, _ := url.PathUnescape(bag.Member("auth.email").Value()); got != "a@example.com" {
+			t.Errorf("webhook baggage header %q, want auth.email=a@example.com", header)
+		}
+	default:
+		t.Fatal("the webhook wasn't delivered")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestAccountTiming times the unauthenticated endpoints for a registered
+// and an unknown email: the medians must be within 5ms of each other, so
+// response time doesn't reveal who has an account. Passwords are hashed
+// at a cost high enough that skipping a bcrypt compare would show.
+func TestAccountTiming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+	quietLog(t)
+	s, _ := newTestServer(t)
+	s.passwordHasher.Close()
+	s.passwordHasher = NewBcryptWorkerPool(2, BcryptHasher{Cost: 8}, 100, time.Second)
+	s.rateLimiter = NewRedisRateLimiter(s.rdb, 1000, time.Minute)
+	newTestUser(t, s, "a@example.com", "user")
+	h := s.Handler()
+	// Hash the dummy password before timing anything.
+	postJSON(h, "/v1/login", `{"email":"nobody@example.com","password":"wrong password"}`)
+
+	const runs = 15
+	for _, tt := range []struct {
+		name         string
+		path, body   string
+		known, other func(i int) string
+	}{
+		{
+			name: "login", path: "/v1/login",
+			body:  `{"email":"%s","password":"wrong password"}`,
+			known: func(int) string { return "a@example.com" },
+			other: func(i int) string { return fmt.Sprintf("nobody%d@example.com", i) },
+		},
+		{
+			name: "register", path: "/v1/register",
+			body:  `{"email":"%s","password":"correct horse"}`,
+			known: func(int) string { return "a@example.com" },
+			other: func(i int) string { return fmt.Sprintf("new%d@example.com", i) },
+		},
+		{
+			name: "forgot-password", path: "/v1/forgot-password",
+			body:  `{"email":"%s"}`,
+			known: func(int) string { return "a@example.com" },
+			other: func(i int) string { return fmt.Sprintf("nobody%d@example.com", i) },
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var known, other []time.Duration
+			measure := func(email string) time.Duration {
+				start := time.Now()
+				rec := postJSON(h, tt.path, fmt.Sprintf(tt.body, email))
+				if rec.Code >= http.StatusInternalServerError {
+					t.Fatalf("%s: %d %s", email, rec.Code, rec.Body)
+				}
+				return time.Since(start)
+			}
+			for i := range runs {
+				known = append(known, measure(tt.known(i)))
+				other = append(other, measure(tt.other(i)))
+			}
+			if d := median(known) - median(other); d > 5*time.Millisecond || d < -5*time.Millisecond {
+				t.Errorf("registered email took %v, unknown email %v", median(known), median(other))
+			}
+		})
+	}
+}
+
+func median(d []time.Duration) time.Duration {
+	d = slices.Clone(d)
+	slices.Sort(d)
+	return d[len(d)/2]
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"resilient-auth-service/apperror"
+)
+
+// Token binding modes, for cfg.TokenBindingMode.
+const (
+	tokenBindingDisabled = "disabled"
+	tokenBindingOptional = "optional"
+	tokenBindingRequired = "required"
+)
+
+// clientCertFingerprintHeader is where a TLS-terminating proxy passes the
+// SHA-256 fingerprint of the client certificate it verified.
+const clientCertFingerprintHeader = "X-Client-Cert-Fingerprint"
+
+// tokenBinding returns the value a session created by r is bound to: the
+// SHA-256 fingerprint of the client certificate, or "" if the client
+// didn't present one. The TLS handshake proves the client holds the
+// certificate's key, so a stolen session cookie is useless without it.
+//
+// With TLS terminated here the certificate comes from the connection.
+// Behind a proxy it comes from clientCertFingerprintHeader, believed only
+// from a trusted proxy like X-Forwarded-For, and the proxy must overwrite
+// any value the client sent.
+func (s *Server) tokenBinding(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		sum := sha256.Sum256(r.TLS.PeerCertificates[0].Raw)
+		return hex.EncodeToString(sum[:])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !ipTrusted(net.ParseIP(host), s.cfg.TrustedProxies) {
+		return ""
+	}
+	// Proxies variously send the fingerprint as AA:BB:... or aabb...
+	fp := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(r.Header.Get(clientCertFingerprintHeader)), ":", ""))
+	if len(fp) != sha256.Size*2 {
+		return ""
+	}
+	if _, err := hex.DecodeString(fp); err != nil {
+		return ""
+	}
+	return fp
+}
+
+// loginTokenBinding returns the binding for a session about to be created
+// by r. In required mode a client without a certificate gets a 401 and ok
+// is false; the caller must stop.
+func (s *Server) loginTokenBinding(w http.ResponseWriter, r *http.Request) (binding string, ok bool) {
+	if s.cfg.TokenBindingMode == tokenBindingDisabled {
+		return "", true
+	}
+	binding = s.tokenBinding(r)
+	if binding == "" && s.cfg.TokenBindingMode == tokenBindingRequired {
+		apperror.WriteError(w, r, apperror.Unauthorized("token_binding_required", "A client certificate is required"))
+		return "", false
+	}
+	return binding, true
+}
+
+func sessionBindingKey(sessionID string) string {
+	return "session_binding:" + sessionID
+}
+
+// sessionBindingMatches reports whether r may use a session bound to
+// stored, as read by lookupSession. A session bound at login only accepts
+// requests presenting the same certificate; in required mode an unbound
+// session, from before the mode was turned on, is refused too.
+func (s *Server) sessionBindingMatches(r *http.Request, stored string) bool {
+	if s.cfg.TokenBindingMode == tokenBindingDisabled {
+		return true
+	}
+	if stored == "" {
+		return s.cfg.TokenBindingMode != tokenBindingRequired
+	}
+	return s.tokenBinding(r) == stored
+}
+
+// recordBindingMismatch audits a session used without its certificate,
+// which most likely means the cookie was stolen.
+func (s *Server) recordBindingMismatch(r *http.Request, email, sessionID string) {
+	log.Printf("session binding mismatch request_id=%s session=%s",
+		requestIDFromContext(r.Context()), sessionPublicID(sessionID))
+
+	ev := s.auditEventFromRequest(r, "session.binding_mismatch")
+	ev.Level = "warning"
+	ev.Target = "session:" + sessionPublicID(sessionID)
+	ev.Metadata = map[string]any{"email": email}
+	s.auditor.Record(r.Context(), ev)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"resilient-auth-service/flags"
+)
+
+// TestTokenBinding logs in with a client certificate and then uses the
+// session cookie with the same certificate, another one, none, and a
+// fingerprint passed on by a proxy. Only the login certificate is
+// accepted, and in required mode a login or session without one is
+// refused.
+func TestTokenBinding(t *testing.T) {
+	quietLog(t)
+	s, mr := newTestServer(t)
+	s.cfg.TokenBindingMode = tokenBindingOptional
+	s.cfg.TrustedProxies = mustCIDRs(t, "10.0.0.0/8")
+	useFakeRefreshDB(t, s)
+	newTestUser(t, s, "a@example.com", "user")
+	if err := s.featureFlags.Set(context.Background(), flags.Flag{Name: flagDeviceTrust}); err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+
+	certA := &x509.Certificate{Raw: []byte("certificate A")}
+	certB := &x509.Certificate{Raw: []byte("certificate B")}
+	sum := sha256.Sum256(certA.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	login := func(cert *x509.Certificate) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(`{"email":"a@example.com","password":"correct horse"}`))
+		r.Header.Set("Content-Type", "application/json")
+		if cert != nil {
+			r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	rec := login(certA)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body)
+	}
+	var sessionID string
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "session_id" {
+			sessionID = c.Value
+		}
+	}
+	if sessionID == "" {
+		t.Fatal("login set no session cookie")
+	}
+	if got, _ := mr.Get(sessionBindingKey(sessionID)); got != fingerprint {
+		t.Fatalf("binding %q, want %q", got, fingerprint)
+	}
+
+	protected := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	use := func(sessionID string, setup func(r *http.Request)) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/me/sessions", nil)
+		r.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+		setup(r)
+		rec := httptest.NewRecorder()
+		protected.ServeHTTP(rec, r)
+		return rec
+	}
+	withCert := func(cert *x509.Certificate) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+	}
+	fromProxy := func(addr, fp string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.RemoteAddr = addr
+			r.Header.Set(clientCertFingerprintHeader, fp)
+		}
+	}
+	colons := strings.ToUpper(fingerprint[0:2])
+	for i := 2; i < len(fingerprint); i += 2 {
+		colons += ":" + strings.ToUpper(fingerprint[i:i+2])
+	}
+
+	for _, tt := range []struct {
+		name  string
+		setup func(r *http.Request)
+		ok    bool
+	}{
+		{"same certificate", withCert(certA), true},
+		{"proxy fingerprint", fromProxy("10.0.0.1:1234", colons), true},
+		{"other certificate", withCert(certB), false},
+		{"no certificate", func(*http.Request) {}, false},
+		{"fingerprint from an untrusted client", fromProxy("192.0.2.1:1234", fingerprint), false},
+		{"malformed fingerprint", fromProxy("10.0.0.1:1234", fingerprint[1:]), false},
+	} {
+		rec := use(sessionID, tt.setup)
+		switch {
+		case tt.ok && rec.Code != http.StatusNoContent:
+			t.Errorf("%s: %d %s, want 204", tt.name, rec.Code, rec.Body)
+		case !tt.ok:
+			if env := readEnvelope(t, rec, http.StatusUnauthorized); env.Error.Code != "session_binding_mismatch" {
+				t.Errorf("%s: code %q, want session_binding_mismatch", tt.name, env.Error.Code)
+			}
+		}
+	}
+
+	unbound, err := s.createSession(context.Background(), "a@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := use(unbound, func(*http.Request) {}); rec.Code != http.StatusNoContent {
+		t.Errorf("unbound session, optional mode: %d, want 204", rec.Code)
+	}
+
+	s.cfg.TokenBindingMode = tokenBindingDisabled
+	if rec := use(sessionID, withCert(certB)); rec.Code != http.StatusNoContent {
+		t.Errorf("other certificate, disabled mode: %d, want 204", rec.Code)
+	}
+
+	s.cfg.TokenBindingMode = tokenBindingRequired
+	if env := readEnvelope(t, use(unbound, func(*http.Request) {}), http.StatusUnauthorized); env.Error.Code != "session_binding_mismatch" {
+		t.Errorf("unbound session, required mode: code %q, want session_binding_mismatch", env.Error.Code)
+	}
+	if env := readEnvelope(t, login(nil), http.StatusUnauthorized); env.Error.Code != "token_binding_required" {
+		t.Errorf("login without a certificate, required mode: code %q, want token_binding_required", env.Error.Code)
+	}
+	if rec := login(certB); rec.Code != http.StatusOK {
+		t.Errorf("login with a certificate, required mode: %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,172 @@
+package tokens
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownKey means a token names a kid the key set doesn't hold.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// SigningKey is one ES256 access-token key. ID is the kid put in the header
+// of every token it signs.
+type SigningKey struct {
+	ID        string
+	Key       *ecdsa.PrivateKey
+	Active    bool
+	CreatedAt time.Time
+}
+
+// GenerateSigningKey makes a new active P-256 key with a random kid.
+func GenerateSigningKey() (SigningKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	kid := make([]byte, 8)
+	if _, err := rand.Read(kid); err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{ID: hex.EncodeToString(kid), Key: key, Active: true, CreatedAt: time.Now()}, nil
+}
+
+// MarshalPrivateKey encodes k's private key as PKCS#8 PEM, for storage.
+func (k SigningKey) MarshalPrivateKey() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParsePrivateKey decodes a key written by MarshalPrivateKey.
+func ParsePrivateKey(s string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ec, ok := key.(*ecdsa.PrivateKey)
+	if !ok || ec.Curve != elliptic.P256() {
+		return nil, errors.New("not a P-256 key")
+	}
+	return ec, nil
+}
+
+// KeySet is the access-token keys currently trusted. Exactly one is active
+// and signs new tokens; the others were rotated out and are kept so tokens
+// they signed still verify until they expire. A KeySet is never modified
+// once built, so it can be shared between goroutines; rotating builds a
+// new one.
+type KeySet struct {
+	active *SigningKey
+	byID   map[string]*SigningKey
+	keys   []SigningKey
+}
+
+// NewKeySet requires exactly one active key among keys.
+func NewKeySet(keys []SigningKey) (*KeySet, error) {
+	s := &KeySet{
+		byID: make(map[string]*SigningKey, len(keys)),
+		keys: append([]SigningKey(nil), keys...),
+	}
+	for i := range s.keys {
+		k := &s.keys[i]
+		if _, dup := s.byID[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate kid %q", k.ID)
+		}
+		s.byID[k.ID] = k
+		if k.Active {
+			if s.active != nil {
+				return nil, fmt.Errorf("keys %q and %q are both active", s.active.ID, k.ID)
+			}
+			s.active = k
+		}
+	}
+	if s.active == nil {
+		return nil, errors.New("no active signing key")
+	}
+	return s, nil
+}
+
+// ActiveID is the kid new tokens are signed with.
+func (s *KeySet) ActiveID() string {
+	return s.active.ID
+}
+
+// Has reports whether kid is in the set.
+func (s *KeySet) Has(kid string) bool {
+	_, ok := s.byID[kid]
+	return ok
+}
+
+// Sign signs claims with the active key, naming it in the kid header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.Key)
+}
+
+// Keyfunc is a jwt.Keyfunc returning the public key the token's kid names.
+// Callers should also pass jwt.WithValidMethods, so a token can't pick an
+// algorithm the key wasn't made for.
+func (s *KeySet) Keyfunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	k, ok := s.byID[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return &k.Key.PublicKey, nil
+}
+
+// JWK is the public half of a signing key in RFC 7517 form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+// JWKS is a JSON Web Key Set document.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of every key in the set, active or not.
+func (s *KeySet) JWKS() (JWKS, error) {
+	out := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		pub, err := k.Key.PublicKey.ECDH()
+		if err != nil {
+			return JWKS{}, err
+		}
+		// Uncompressed point: 0x04 || X || Y, each 32 bytes for P-256.
+		b := pub.Bytes()
+		out.Keys = append(out.Keys, JWK{
+			KeyType:   "EC",
+			Curve:     "P-256",
+			X:         base64.RawURLEncoding.EncodeToString(b[1:33]),
+			Y:         base64.RawURLEncoding.EncodeToString(b[33:]),
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: jwt.SigningMethodES256.Alg(),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,85 @@
+// Package tokens issues and verifies self-contained signed tokens that are
+// handed to users out of band, such as password reset links.
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const purposePasswordReset = "password_reset"
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// PasswordResetClaims is what a password reset token carries. ID (the jti)
+// is unique per token, so a used token can be revoked for the rest of its
+// short life without storing anything else about it.
+type PasswordResetClaims struct {
+	UserID    int
+	Email     string
+	ID        string
+	ExpiresAt time.Time
+}
+
+type passwordResetJWT struct {
+	Email   string `json:"email"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GeneratePasswordResetToken signs a reset token for the user with RS256.
+// Verifying needs only the public key, so the token can be checked without
+// a database lookup.
+func GeneratePasswordResetToken(userID int, email string, key *rsa.PrivateKey, ttl time.Duration) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, passwordResetJWT{
+		Email:   email,
+		Purpose: purposePasswordReset,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			ID:        hex.EncodeToString(jti),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+	return token.SignedString(key)
+}
+
+// ParsePasswordResetToken checks the token's signature, expiry and purpose.
+// Any failure is ErrInvalidToken wrapped with the reason. It doesn't know
+// about revocation: callers must still check the jti hasn't been used.
+func ParsePasswordResetToken(token string, key *rsa.PublicKey) (PasswordResetClaims, error) {
+	var c passwordResetJWT
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) { return key, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return PasswordResetClaims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if c.Purpose != purposePasswordReset {
+		return PasswordResetClaims{}, fmt.Errorf("%w: wrong purpose %q", ErrInvalidToken, c.Purpose)
+	}
+	userID, err := strconv.Atoi(c.Subject)
+	if err != nil || c.ID == "" || c.Email == "" {
+		return PasswordResetClaims{}, fmt.Errorf("%w: missing claims", ErrInvalidToken)
+	}
+
+	return PasswordResetClaims{
+		UserID:    userID,
+		Email:     c.Email,
+		ID:        c.ID,
+		ExpiresAt: c.ExpiresAt.Time,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FuzzParsePasswordResetToken feeds arbitrary strings to the reset token
+// parser. Only the token we signed parses; everything else is
+// ErrInvalidToken.
+func FuzzParsePasswordResetToken(f *testing.F) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		f.Fatal(err)
+	}
+	valid, err := GeneratePasswordResetToken(42, "a@example.com", key, time.Hour)
+	if err != nil {
+		f.Fatal(err)
+	}
+	expired, _ := GeneratePasswordResetToken(42, "a@example.com", key, -time.Hour)
+	// A validly signed access-style token without the reset purpose.
+	wrongPurpose, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "42", "email": "a@example.com", "jti": "x", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(key)
+	// HS256 over the public key, the classic algorithm confusion.
+	confused, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "42", "purpose": purposePasswordReset,
+	}).SignedString(key.PublicKey.N.Bytes())
+
+	for _, seed := range []string{valid, expired, wrongPurpose, confused, "", "a.b.c", valid + "A"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, token string) {
+		c, err := ParsePasswordResetToken(token, &key.PublicKey)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("got %v, want ErrInvalidToken", err)
+			}
+			return
+		}
+		if c.UserID != 42 || c.Email != "a@example.com" {
+			t.Fatalf("parsed %q, which we never signed, to %+v", token, c)
+		}
+	})
+}
+
+func TestPasswordResetToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := GeneratePasswordResetToken(42, "a@example.com", key, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParsePasswordResetToken(token, &key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.UserID != 42 || c.Email != "a@example.com" || c.ID == "" || time.Until(c.ExpiresAt) > 15*time.Minute {
+		t.Errorf("parsed %+v", c)
+	}
+	again, _ := GeneratePasswordResetToken(42, "a@example.com", key, 15*time.Minute)
+	if c2, _ := ParsePasswordResetToken(again, &key.PublicKey); c2.ID == c.ID {
+		t.Error("two tokens share a jti")
+	}
+
+	expired, _ := GeneratePasswordResetToken(42, "a@example.com", key, -time.Second)
+	for name, tt := range map[string]struct {
+		token string
+		key   *rsa.PublicKey
+	}{
+		"expired":   {expired, &key.PublicKey},
+		"other key": {token, &other.PublicKey},
+	} {
+		if _, err := ParsePasswordResetToken(tt.token, tt.key); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: got %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import "strings"
+
+// userAgentBrowsers and userAgentOSes map User-Agent substrings to names,
+// checked in order. Order matters: Edge and Opera also claim Chrome, and
+// Chrome claims Safari.
+var (
+	userAgentBrowsers = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"EdgiOS/", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	userAgentOSes = []struct{ token, name string }{
+		{"Windows", "Windows"},
+		{"iPhone", "iOS"},
+		{"iPad", "iOS"},
+		{"Android", "Android"},
+		{"CrOS", "ChromeOS"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// parseUserAgent names the browser and operating system a User-Agent
+// header claims, for showing a user where they logged in from. It knows
+// the common ones only; anything else is "".
+func parseUserAgent(ua string) (browser, os string) {
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, o := range userAgentOSes {
+		if strings.Contains(ua, o.token) {
+			os = o.name
+			break
+		}
+	}
+	return browser, os
+}
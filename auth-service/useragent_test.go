@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestParseUserAgent(t *testing.T) {
+	for _, tt := range []struct {
+		ua, browser, os string
+	}{
+		{firefoxOnLinux, "Firefox", "Linux"},
+		{safariOnIPhone, "Safari", "iOS"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", "Chrome", "Windows"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0", "Edge", "Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 OPR/111.0.0.0", "Opera", "macOS"},
+		{"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/25.0 Chrome/121.0.0.0 Mobile Safari/537.36", "Samsung Internet", "Android"},
+		{"Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/126.0.6478.54 Mobile/15E148 Safari/604.1", "Chrome", "iOS"},
+		{"Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", "Chrome", "ChromeOS"},
+		{"curl/8.7.1", "", ""},
+		{"", "", ""},
+	} {
+		if browser, os := parseUserAgent(tt.ua); browser != tt.browser || os != tt.os {
+			t.Errorf("%q: %q on %q, want %q on %q", tt.ua, browser, os, tt.browser, tt.os)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"resilient-auth-service/apperror"
+)
+
+// Password policy. bcrypt ignores everything past 72 bytes, so two long
+// passwords sharing a 72-byte prefix would verify as each other. We reject
+// longer passwords, everywhere one is accepted, rather than pre-hashing
+// them: pre-hashing would change what every existing hash means, and 72
+// bytes is already far past any password a person types.
+const (
+	minPasswordLen = 8
+	maxPasswordLen = bcryptMaxPasswordLen
+)
+
+// validatable is implemented by request bodies that check their own
+// fields. decodeJSON runs it after a successful decode.
+type validatable interface {
+	validate() error
+}
+
+// validator collects field errors for one request so a client learns about
+// every bad input at once. The codes it produces are part of the API.
+type validator struct {
+	errs []apperror.FieldError
+}
+
+func (v *validator) add(field, code, message string) {
+	v.errs = append(v.errs, apperror.FieldError{Field: field, Code: code, Message: message})
+}
+
+// required reports whether value is non-blank, recording "required" if not.
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "required", "is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) email(field, value string) {
+	if !v.required(field, value) {
+		return
+	}
+	// mail.ParseAddress also takes "Name <a@b.c>" and dotless domains
+	// like "a@localhost"; neither is an email we can deliver to.
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || !strings.Contains(value[strings.LastIndexByte(value, '@'):], ".") {
+		v.add(field, "invalid_email", "must be a valid email address")
+	}
+}
+
+// length checks the length of value in characters; min or max of 0 means
+// no bound.
+func (v *validator) length(field, value string, min, max int) {
+	n := utf8.RuneCountInString(value)
+	switch {
+	case min > 0 && n < min:
+		v.add(field, "too_short", "must be at least "+strconv.Itoa(min)+" characters")
+	case max > 0 && n > max:
+		v.add(field, "too_long", "must be at most "+strconv.Itoa(max)+" characters")
+	}
+}
+
+func (v *validator) password(field, value string) {
+	if !v.required(field, value) {
+		return
+	}
+	switch {
+	case utf8.RuneCountInString(value) < minPasswordLen:
+		v.add(field, "too_short", "must be at least "+strconv.Itoa(minPasswordLen)+" characters")
+	case len(value) > maxPasswordLen:
+		v.add(field, "too_long", "must be at most "+strconv.Itoa(maxPasswordLen)+" bytes")
+	}
+}
+
+// passwordAttempt checks a password being verified rather than set: the
+// rest of the policy may have changed since it was set, but bcrypt can't
+// tell apart passwords past maxPasswordLen, so those are refused.
+func (v *validator) passwordAttempt(field, value string) {
+	if v.required(field, value) && len(value) > maxPasswordLen {
+		v.add(field, "too_long", "must be at most "+strconv.Itoa(maxPasswordLen)+" bytes")
+	}
+}
+
+func (v *validator) httpURL(field, value string) {
+	if !v.required(field, value) {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		v.add(field, "invalid_url", "must be an absolute http(s) URL")
+	}
+}
+
+func (v *validator) between(field string, n, min, max int) {
+	if n < min || n > max {
+		v.add(field, "out_of_range", "must be between "+strconv.Itoa(min)+" and "+strconv.Itoa(max))
+	}
+}
+
+// err returns the collected errors as a 422, or nil if there are none.
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	e := apperror.Unprocessable("validation_failed", "Some fields are invalid")
+	e.Fields = v.errs
+	return e
+}
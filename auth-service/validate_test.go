@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"resilient-auth-service/apperror"
+)
+
+// TestFieldCodes pins the field errors each request type produces. The
+// codes are part of the API, so a change to testdata/field_codes.golden
+// is a change clients will see.
+func TestFieldCodes(t *testing.T) {
+	long := strings.Repeat("x", maxPasswordLen+1)
+	pct := func(n int) *int { return &n }
+
+	tests := []struct {
+		name string
+		req  validatable
+	}{
+		{"register/valid", &registerRequest{Email: "a@example.com", Password: "correct horse"}},
+		{"register/empty", &registerRequest{}},
+		{"register/blank", &registerRequest{Email: "   ", Password: "   "}},
+		{"register/display name", &registerRequest{Email: "Ann <a@example.com>", Password: "correct horse"}},
+		{"register/dotless domain", &registerRequest{Email: "a@localhost", Password: "correct horse"}},
+		{"register/short password", &registerRequest{Email: "a@example.com", Password: "short"}},
+		{"register/short multibyte password", &registerRequest{Email: "a@example.com", Password: "äöüßäöü"}},
+		{"register/long password", &registerRequest{Email: "a@example.com", Password: secret(long)}},
+		{"login/empty", &loginRequest{}},
+		{"login/short password", &loginRequest{Email: "a", Password: "x"}},
+		{"login/long password", &loginRequest{Email: "a@example.com", Password: secret(long)}},
+		{"invite/bad role", &inviteOrgMemberRequest{Email: "a@example.com", Role: "owner"}},
+		{"invite/bad email and role", &inviteOrgMemberRequest{Email: "nope"}},
+		{"maintenance/long message", &maintenanceRequest{Message: strings.Repeat("m", maxMaintenanceMessage+1), ExpiresIn: -1}},
+		{"flag/percentage", &flagRequest{Percentage: pct(101)}},
+		{"flag/no percentage", &flagRequest{}},
+		{"session revoke/empty", &revokeSessionRequest{}},
+	}
+
+	var out strings.Builder
+	for _, tt := range tests {
+		fmt.Fprintf(&out, "%s:\n", tt.name)
+		err := tt.req.validate()
+		if err == nil {
+			out.WriteString("\tok\n")
+			continue
+		}
+		var e *apperror.Error
+		if !errors.As(err, &e) || e.Code != "validation_failed" || len(e.Fields) == 0 {
+			t.Errorf("%s: got %v, want a validation_failed with fields", tt.name, err)
+			continue
+		}
+		for _, f := range e.Fields {
+			fmt.Fprintf(&out, "\t%s %s %q\n", f.Field, f.Code, f.Message)
+		}
+	}
+	checkGolden(t, "field_codes.golden", []byte(out.String()))
+}
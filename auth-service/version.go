@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Set at build time with
+//
+//	go build -ldflags "-X main.version=v1.2.3 -X main.gitSHA=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%FT%TZ)"
+//
+// Anything left empty is filled from the module and VCS info the Go
+// toolchain embeds, when available.
+var (
+	version   string
+	gitSHA    string
+	buildTime string
+)
+
+var startTime = time.Now()
+
+func init() {
+	info, ok := debug.ReadBuildInfo()
+	if ok {
+		if version == "" && info.Main.Version != "(devel)" {
+			version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if gitSHA == "" {
+					gitSHA = s.Value
+				}
+			case "vcs.time":
+				if buildTime == "" {
+					buildTime = s.Value
+				}
+			}
+		}
+	}
+	if version == "" {
+		version = "dev"
+	}
+
+	buildInfo.WithLabelValues(version, gitSHA, runtime.Version()).Set(1)
+}
+
+// versionHandler serves GET /version. It touches no dependencies, so it is
+// safe to poll and is mounted without rate limiting.
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"version":        version,
+		"git_sha":        gitSHA,
+		"build_time":     buildTime,
+		"go_version":     runtime.Version(),
+		"uptime_seconds": int64(time.Since(startTime).Seconds()),
+	})
+}
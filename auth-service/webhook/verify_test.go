@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test"
+	utf8Body := []byte(`{"email":"zoë@example.com","name":"東京"}`)
+	binaryBody := []byte{0x00, 0xff, 0xfe, 0x80, '{', 0xc3, 0x28, 0x00}
+	// The same text with ë decomposed into e and a combining diaeresis:
+	// equal to a person, different bytes to the HMAC.
+	decomposed := []byte(`{"email":"zoe` + "\u0308" + `@example.com","name":"東京"}`)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		want      error
+	}{
+		{"correct signature", utf8Body, Sign(secret, utf8Body), nil},
+		{"uppercase hex", utf8Body, "sha256=" + strings.ToUpper(strings.TrimPrefix(Sign(secret, utf8Body), "sha256=")), nil},
+		{"wrong secret", utf8Body, Sign("other", utf8Body), ErrInvalidSignature},
+		{"tampered body", []byte(`{"email":"mallory@example.com"}`), Sign(secret, utf8Body), ErrInvalidSignature},
+		{"truncated signature", utf8Body, Sign(secret, utf8Body)[:20], ErrInvalidSignature},
+		{"missing prefix", utf8Body, strings.TrimPrefix(Sign(secret, utf8Body), "sha256="), ErrInvalidSignature},
+		{"other algorithm", utf8Body, "sha1=" + strings.TrimPrefix(Sign(secret, utf8Body), "sha256="), ErrInvalidSignature},
+		{"not hex", utf8Body, "sha256=zz", ErrInvalidSignature},
+		{"missing signature", utf8Body, "", ErrMissingSignature},
+
+		{"empty body", []byte{}, Sign(secret, nil), nil},
+		{"nil body", nil, Sign(secret, []byte{}), nil},
+		{"empty body, signature of another", []byte{}, Sign(secret, utf8Body), ErrInvalidSignature},
+		{"signature of empty, non-empty body", utf8Body, Sign(secret, nil), ErrInvalidSignature},
+
+		{"binary body", binaryBody, Sign(secret, binaryBody), nil},
+		{"binary body read as UTF-8", []byte(strings.ToValidUTF8(string(binaryBody), "�")), Sign(secret, binaryBody), ErrInvalidSignature},
+		{"UTF-8 body normalized differently", decomposed, Sign(secret, utf8Body), ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WebhookVerifier{}.Verify(secret, tt.body, tt.signature)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyMiddleware(t *testing.T) {
+	const secret = "whsec_test"
+	body := `{"event":"user.created"}`
+
+	var got string
+	h := VerifyMiddleware(secret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		want      int
+	}{
+		{"signed", body, Sign(secret, []byte(body)), http.StatusNoContent},
+		{"unsigned", body, "", http.StatusUnauthorized},
+		{"wrong signature", body, Sign("other", []byte(body)), http.StatusUnauthorized},
+		{"too large", strings.Repeat("x", MaxBodyBytes+1), "", http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			r := httptest.NewRequest(http.MethodPost, "/hooks/auth", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				r.Header.Set(SignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusNoContent && got != tt.body {
+				t.Errorf("handler read %q, want the body intact", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,650 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/propagation"
+
+	"resilient-auth-service/apperror"
+	"resilient-auth-service/pagination"
+	"resilient-auth-service/webhook"
+)
+
+const (
+	webhookMaxAttempts     = 5
+	webhookBaseDelay       = time.Second
+	webhookMaxDelay        = time.Minute
+	webhookRequestTimeout  = 10 * time.Second
+	webhookFailingAfter    = 5 // consecutive failed events before an endpoint is marked failing
+	webhookMaxResponseBody = 1024
+)
+
+// webhookEvent is the JSON payload POSTed to subscribers.
+type webhookEvent struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	Data      map[string]any `json:"data"`
+
+	// propagation holds the enqueuing request's trace context and baggage.
+	propagation propagation.MapCarrier
+}
+
+type webhookEndpoint struct {
+	ID     int
+	URL    string
+	Secret string
+}
+
+// WebhookDispatcher delivers lifecycle events to registered endpoints in the
+// background. Handlers call Enqueue, which never blocks.
+type WebhookDispatcher struct {
+	db     *sql.DB
+	client *http.Client
+	queue  chan webhookEvent
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inflight sync.WaitGroup
+	done     chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewWebhookDispatcher(db *sql.DB, queueSize int) *WebhookDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &WebhookDispatcher{
+		db:     db,
+		client: &http.Client{Timeout: webhookRequestTimeout},
+		queue:  make(chan webhookEvent, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// Enqueue schedules eventType for delivery. If the queue is full the event
+// is dropped and logged rather than delaying the request. Synthetic checks
+// send nothing.
+func (d *WebhookDispatcher) Enqueue(ctx context.Context, eventType string, data map[string]any) {
+	if isSynthetic(ctx) {
+		return
+	}
+	ev := webhookEvent{
+		ID:          newWebhookID(),
+		Type:        eventType,
+		CreatedAt:   time.Now().UTC(),
+		Data:        data,
+		propagation: propagationHeaders(ctx),
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+
+	select {
+	case d.queue <- ev:
+	default:
+		log.Printf("webhook queue full, dropped event=%s id=%s", ev.Type, ev.ID)
+	}
+}
+
+// Close stops accepting events and waits for in-flight deliveries. When ctx
+// expires, pending retries are abandoned.
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+		return ctx.Err()
+	}
+}
+
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+	defer d.inflight.Wait()
+
+	for ev := range d.queue {
+		endpoints, err := d.endpointsFor(ev.Type)
+		if err != nil {
+			log.Printf("webhook endpoint lookup error event=%s err=%v", ev.Type, err)
+			continue
+		}
+		// One goroutine per endpoint so a slow subscriber can't hold up
+		// the others.
+		for _, ep := range endpoints {
+			d.inflight.Add(1)
+			go func() {
+				defer d.inflight.Done()
+				d.deliver(ep, ev)
+			}()
+		}
+	}
+}
+
+func (d *WebhookDispatcher) endpointsFor(eventType string) ([]webhookEndpoint, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := d.db.QueryContext(ctx,
+		`SELECT id, url, secret FROM webhooks
+		 WHERE active AND (cardinality(events) = 0 OR $1 = ANY(events))`,
+		eventType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var eps []webhookEndpoint
+	for rows.Next() {
+		var ep webhookEndpoint
+		if err := rows.Scan(&ep.ID, &ep.URL, &ep.Secret); err != nil {
+			return nil, err
+		}
+		eps = append(eps, ep)
+	}
+	return eps, rows.Err()
+}
+
+// deliver POSTs ev to ep with exponential backoff, recording every attempt.
+func (d *WebhookDispatcher) deliver(ep webhookEndpoint, ev webhookEvent) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("webhook marshal error event=%s err=%v", ev.Type, err)
+		return
+	}
+
+	delay := webhookBaseDelay
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		status, respErr := d.post(ep, ev, body)
+		d.recordDelivery(ep.ID, ev, body, attempt, status, respErr)
+
+		if respErr == nil {
+			d.markResult(ep.ID, true)
+			return
+		}
+		if attempt == webhookMaxAttempts {
+			break
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, webhookMaxDelay)
+	}
+
+	log.Printf("webhook delivery failed webhook_id=%d event=%s id=%s", ep.ID, ev.Type, ev.ID)
+	d.markResult(ep.ID, false)
+}
+
+func (d *WebhookDispatcher) post(ep webhookEndpoint, ev webhookEvent, body []byte) (int, error) {
+	req, err := newWebhookRequest(d.ctx, ep, ev, body)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// newWebhookRequest builds the signed POST of body (the JSON-encoded ev) to ep.
+func newWebhookRequest(ctx context.Context, ep webhookEndpoint, ev webhookEvent, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", ev.Type)
+	req.Header.Set("X-Webhook-ID", ev.ID)
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(ep.Secret, body))
+	for k, v := range ev.propagation {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+func (d *WebhookDispatcher) recordDelivery(webhookID int, ev webhookEvent, body []byte, attempt, status int, deliveryErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		statusCode sql.NullInt64
+		errText    string
+	)
+	if status != 0 {
+		statusCode = sql.NullInt64{Int64: int64(status), Valid: true}
+	}
+	if deliveryErr != nil {
+		errText = deliveryErr.Error()
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, attempt, status_code, success, error)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		webhookID, ev.ID, ev.Type, body, attempt, statusCode, deliveryErr == nil, errText,
+	)
+	if err != nil {
+		log.Printf("webhook delivery record error webhook_id=%d err=%v", webhookID, err)
+	}
+}
+
+func (d *WebhookDispatcher) markResult(webhookID int, success bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	if success {
+		_, err = d.db.ExecContext(ctx,
+			"UPDATE webhooks SET consecutive_failures = 0, failing = false WHERE id = $1",
+			webhookID,
+		)
+	} else {
+		_, err = d.db.ExecContext(ctx,
+			`UPDATE webhooks SET consecutive_failures = consecutive_failures + 1,
+			                     failing = consecutive_failures + 1 >= $2
+			 WHERE id = $1`,
+			webhookID, webhookFailingAfter,
+		)
+	}
+	if err != nil {
+		log.Printf("webhook status update error webhook_id=%d err=%v", webhookID, err)
+	}
+}
+
+func newWebhookID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Admin CRUD
+
+type webhookResource struct {
+	ID                  int       `json:"id"`
+	URL                 string    `json:"url"`
+	Secret              string    `json:"secret,omitempty"` // only returned on create
+	Events              []string  `json:"events"`
+	Active              bool      `json:"active"`
+	Failing             bool      `json:"failing"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (req webhookRequest) validate() error {
+	var v validator
+	v.httpURL("url", req.URL)
+	for i, ev := range req.Events {
+		v.required("events["+strconv.Itoa(i)+"]", ev)
+	}
+	return v.err()
+}
+
+const webhookColumns = "id, url, events, active, failing, consecutive_failures, created_at"
+
+func scanWebhook(row interface{ Scan(...any) error }, wh *webhookResource) error {
+	return row.Scan(&wh.ID, &wh.URL, pq.Array(&wh.Events), &wh.Active, &wh.Failing,
+		&wh.ConsecutiveFailures, &wh.CreatedAt)
+}
+
+// adminListWebhooksHandler serves GET /admin/webhooks.
+func (s *Server) adminListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := s.db.QueryContext(r.Context(), "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+	if err != nil {
+		log.Println("list webhooks error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	list := []webhookResource{}
+	for rows.Next() {
+		var wh webhookResource
+		if err := scanWebhook(rows, &wh); err != nil {
+			log.Println("list webhooks scan error:", err)
+			writeDBError(w, r, err)
+			return
+		}
+		list = append(list, wh)
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("list webhooks rows error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"webhooks": list})
+}
+
+// adminCreateWebhookHandler serves POST /admin/webhooks. The generated
+// signing secret is returned once, in this response only.
+func (s *Server) adminCreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	if req.Events == nil {
+		req.Events = []string{}
+	}
+	active := req.Active == nil || *req.Active
+
+	secretBytes := make([]byte, 32)
+	rand.Read(secretBytes)
+	secret := hex.EncodeToString(secretBytes)
+
+	var wh webhookResource
+	err := scanWebhook(s.db.QueryRowContext(r.Context(),
+		`INSERT INTO webhooks (url, secret, events, active) VALUES ($1, $2, $3, $4)
+		 RETURNING `+webhookColumns,
+		req.URL, secret, pq.Array(req.Events), active,
+	), &wh)
+	if err != nil {
+		log.Println("create webhook error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	wh.Secret = secret
+
+	ev := s.auditEventFromRequest(r, "admin.webhook_create")
+	ev.Target = "webhook:" + strconv.Itoa(wh.ID)
+	s.auditor.Record(r.Context(), ev)
+
+	writeJSON(w, http.StatusCreated, wh)
+}
+
+type webhookTestRequest struct {
+	URL       string         `json:"url"`
+	EventType string         `json:"event_type"`
+	Payload   map[string]any `json:"payload"`
+}
+
+func (req webhookTestRequest) validate() error {
+	var v validator
+	v.httpURL("url", req.URL)
+	v.required("event_type", req.EventType)
+	return v.err()
+}
+
+type webhookTestResult struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	Body       string `json:"body"`
+	Error      string `json:"error,omitempty"`
+}
+
+// adminTestWebhookHandler serves POST /admin/webhooks/test. It sends one
+// signed delivery to a registered endpoint, marked with X-Webhook-Test:
+// true, and returns what the endpoint answered so consumers can check their
+// signature verification. Only registered URLs are accepted: the delivery
+// is signed with that webhook's secret, and it stops this endpoint being
+// used to make the service call arbitrary URLs. Test deliveries are not
+// retried, recorded, or counted towards failure state.
+func (s *Server) adminTestWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req webhookTestRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	if req.Payload == nil {
+		req.Payload = map[string]any{}
+	}
+
+	var ep webhookEndpoint
+	err := s.db.QueryRowContext(r.Context(),
+		"SELECT id, url, secret FROM webhooks WHERE url = $1 ORDER BY id LIMIT 1", req.URL,
+	).Scan(&ep.ID, &ep.URL, &ep.Secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, apperror.NotFound("webhook_not_found", "No webhook registered for this URL"))
+		return
+	}
+	if err != nil {
+		log.Println("test webhook lookup error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := webhookEvent{
+		ID:          newWebhookID(),
+		Type:        req.EventType,
+		CreatedAt:   time.Now().UTC(),
+		Data:        req.Payload,
+		propagation: propagationHeaders(r.Context()),
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_request", "Invalid payload"))
+		return
+	}
+
+	outReq, err := newWebhookRequest(r.Context(), ep, ev, body)
+	if err != nil {
+		apperror.WriteError(w, r, apperror.Unprocessable("invalid_url", "Invalid webhook URL").WithField("url", "invalid_url", "must be an absolute http(s) URL"))
+		return
+	}
+	outReq.Header.Set("X-Webhook-Test", "true")
+
+	var result webhookTestResult
+	resp, err := s.webhooks.client.Do(outReq)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+		resp.Body.Close()
+		result.StatusCode = resp.StatusCode
+		result.Body = string(respBody)
+	}
+
+	auditEv := s.auditEventFromRequest(r, "admin.webhook_test")
+	auditEv.Target = "webhook:" + strconv.Itoa(ep.ID)
+	auditEv.Metadata = map[string]any{"event_type": ev.Type, "status_code": result.StatusCode}
+	s.auditor.Record(r.Context(), auditEv)
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// adminGetWebhookHandler serves GET /admin/webhooks/{id}.
+func (s *Server) adminGetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid webhook id"))
+		return
+	}
+
+	var wh webhookResource
+	err = scanWebhook(s.db.QueryRowContext(r.Context(), "SELECT "+webhookColumns+" FROM webhooks WHERE id = $1", id), &wh)
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, apperror.NotFound("webhook_not_found", "Webhook not found"))
+		return
+	}
+	if err != nil {
+		log.Println("get webhook error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, wh)
+}
+
+// adminUpdateWebhookHandler serves PUT /admin/webhooks/{id}. Re-enabling an
+// endpoint clears its failure state.
+func (s *Server) adminUpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid webhook id"))
+		return
+	}
+
+	var req webhookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+	if req.Events == nil {
+		req.Events = []string{}
+	}
+	active := req.Active == nil || *req.Active
+
+	var wh webhookResource
+	err = scanWebhook(s.db.QueryRowContext(r.Context(),
+		`UPDATE webhooks SET url = $2, events = $3, active = $4,
+		        consecutive_failures = CASE WHEN $4 AND NOT active THEN 0 ELSE consecutive_failures END,
+		        failing = CASE WHEN $4 AND NOT active THEN false ELSE failing END
+		 WHERE id = $1
+		 RETURNING `+webhookColumns,
+		id, req.URL, pq.Array(req.Events), active,
+	), &wh)
+	if errors.Is(err, sql.ErrNoRows) {
+		apperror.WriteError(w, r, apperror.NotFound("webhook_not_found", "Webhook not found"))
+		return
+	}
+	if err != nil {
+		log.Println("update webhook error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "admin.webhook_update")
+	ev.Target = "webhook:" + strconv.Itoa(id)
+	s.auditor.Record(r.Context(), ev)
+
+	writeJSON(w, http.StatusOK, wh)
+}
+
+// adminDeleteWebhookHandler serves DELETE /admin/webhooks/{id}.
+func (s *Server) adminDeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid webhook id"))
+		return
+	}
+
+	res, err := s.db.ExecContext(r.Context(), "DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		log.Println("delete webhook error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		apperror.WriteError(w, r, apperror.NotFound("webhook_not_found", "Webhook not found"))
+		return
+	}
+
+	ev := s.auditEventFromRequest(r, "admin.webhook_delete")
+	ev.Target = "webhook:" + strconv.Itoa(id)
+	s.auditor.Record(r.Context(), ev)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type webhookDelivery struct {
+	ID         int64           `json:"id"`
+	EventID    string          `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempt    int             `json:"attempt"`
+	StatusCode *int            `json:"status_code"`
+	Success    bool            `json:"success"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// adminWebhookDeliveriesHandler serves GET /admin/webhooks/{id}/deliveries,
+// newest first.
+func (s *Server) adminWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		apperror.WriteError(w, r, apperror.BadRequest("invalid_id", "Invalid webhook id"))
+		return
+	}
+
+	limit, err := pagination.Limit(r)
+	if err != nil {
+		apperror.WriteError(w, r, err)
+		return
+	}
+
+	rows, err := s.db.QueryContext(r.Context(),
+		`SELECT id, event_id, event_type, payload, attempt, status_code, success, error, created_at
+		 FROM webhook_deliveries WHERE webhook_id = $1
+		 ORDER BY id DESC LIMIT $2`,
+		id, limit,
+	)
+	if err != nil {
+		log.Println("list webhook deliveries error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	list := []webhookDelivery{}
+	for rows.Next() {
+		var (
+			dl     webhookDelivery
+			status sql.NullInt64
+		)
+		err := rows.Scan(&dl.ID, &dl.EventID, &dl.EventType, &dl.Payload, &dl.Attempt,
+			&status, &dl.Success, &dl.Error, &dl.CreatedAt)
+		if err != nil {
+			log.Println("list webhook deliveries scan error:", err)
+			writeDBError(w, r, err)
+			return
+		}
+		if status.Valid {
+			code := int(status.Int64)
+			dl.StatusCode = &code
+		}
+		list = append(list, dl)
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("list webhook deliveries rows error:", err)
+		writeDBError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"deliveries": list})
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"resilient-auth-service/webhook"
+)
+
+// fakeWebhookDB is a database/sql driver holding one webhook endpoint and
+// its delivery log. It understands the statements the dispatcher and the
+// admin handlers that read deliveries or send test ones run. While block is open, endpoint lookups wait on it.
+type fakeWebhookDB struct {
+	url, secret string
+	block       chan struct{}
+
+	mu         sync.Mutex
+	deliveries [][]driver.Value
+	results    []bool
+}
+
+func (f *fakeWebhookDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeWebhookDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeWebhookDB) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeWebhookDB: prepared statements not supported")
+}
+func (f *fakeWebhookDB) Close() error { return nil }
+
+func (f *fakeWebhookDB) Begin() (driver.Tx, error) {
+	return nil, errors.New("fakeWebhookDB: transactions not supported")
+}
+
+func (f *fakeWebhookDB) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO webhook_deliveries"):
+		// id, event_id, event_type, payload, attempt, status_code, success, error, created_at
+		row := []driver.Value{int64(len(f.deliveries) + 1)}
+		for _, a := range args[1:] {
+			row = append(row, a.Value)
+		}
+		f.deliveries = append(f.deliveries, append(row, time.Now()))
+	case strings.HasPrefix(query, "UPDATE webhooks SET consecutive_failures = 0"):
+		f.results = append(f.results, true)
+	case strings.HasPrefix(query, "UPDATE webhooks SET consecutive_failures = consecutive_failures + 1"):
+		f.results = append(f.results, false)
+	default:
+		return nil, fmt.Errorf("fakeWebhookDB: unexpected exec %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (f *fakeWebhookDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "SELECT id, url, secret FROM webhooks"):
+		if strings.Contains(query, "WHERE url = $1") && args[0].Value != f.url {
+			return &fakeRows{cols: []string{"id", "url", "secret"}}, nil
+		}
+		if f.block != nil {
+			select {
+			case <-f.block:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return &fakeRows{cols: []string{"id", "url", "secret"}, rows: [][]driver.Value{{int64(1), f.url, f.secret}}}, nil
+	case strings.HasPrefix(query, "SELECT id, event_id, event_type, payload"):
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		rows := &fakeRows{cols: []string{"id", "event_id", "event_type", "payload", "attempt", "status_code", "success", "error", "created_at"}}
+		for i := len(f.deliveries) - 1; i >= 0; i-- {
+			rows.rows = append(rows.rows, f.deliveries[i])
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("fakeWebhookDB: unexpected query %q", query)
+}
+
+// TestWebhookDelivery delivers an event to an endpoint that fails once:
+// the retry succeeds, every attempt is signed and recorded, and the admin
+// API lists them newest first.
+func TestWebhookDelivery(t *testing.T) {
+	quietLog(t)
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := (webhook.WebhookVerifier{}).Verify("s3cret", body, r.Header.Get(webhook.SignatureHeader)); err != nil {
+			t.Errorf("signature: %v", err)
+		}
+		if got := r.Header.Get("X-Webhook-Event"); got != "user.registered" {
+			t.Errorf("X-Webhook-Event %q", got)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer endpoint.Close()
+
+	s, _ := newTestServer(t)
+	fake := &fakeWebhookDB{url: endpoint.URL, secret: "s3cret"}
+	s.db = sql.OpenDB(fake)
+	t.Cleanup(func() { s.db.Close() })
+
+	d := NewWebhookDispatcher(s.db, 8)
+	d.Enqueue(context.Background(), "user.registered", map[string]any{"user_id": 7})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if len(bodies) != 2 || bodies[0] != bodies[1] {
+		t.Fatalf("endpoint received %q, want the same event twice", bodies)
+	}
+	var ev webhookEvent
+	if err := json.Unmarshal([]byte(bodies[0]), &ev); err != nil || ev.Type != "user.registered" || ev.Data["user_id"] != float64(7) {
+		t.Errorf("payload %s (%v)", bodies[0], err)
+	}
+	if len(fake.results) != 1 || !fake.results[0] {
+		t.Errorf("endpoint results %v, want one success", fake.results)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/admin/webhooks/1/deliveries", nil)
+	r.AddCookie(accessTokenCookie(t, s, newTestUser(t, s, "admin@example.com", "admin")))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, r)
+	var resp struct{ Deliveries []webhookDelivery }
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil {
+		t.Fatalf("deliveries: %d %s", rec.Code, rec.Body)
+	}
+	got := resp.Deliveries
+	if len(got) != 2 || got[0].Attempt != 2 || !got[0].Success || *got[0].StatusCode != 200 ||
+		got[1].Attempt != 1 || got[1].Success || *got[1].StatusCode != 500 || got[1].Error == "" {
+		t.Errorf("deliveries %s", rec.Body)
+	}
+	if got[0].EventID != ev.ID || string(got[0].Payload) != bodies[0] {
+		t.Errorf("delivery %+v doesn't record the event sent", got[0])
+	}
+}
+
+// TestWebhookEnqueueNeverBlocks fills the queue while the dispatcher is
+// stuck on the database: Enqueue drops the overflow instead of waiting.
+func TestWebhookEnqueueNeverBlocks(t *testing.T) {
+	quietLog(t)
+	fake := &fakeWebhookDB{url: "http://127.0.0.1:1", block: make(chan struct{})}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	d := NewWebhookDispatcher(db, 1)
+
+	start := time.Now()
+	for i := range 10 {
+		d.Enqueue(context.Background(), "user.deleted", map[string]any{"user_id": i})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("10 enqueues took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("close: %v, want the deadline", err)
+	}
+}
+
+// TestAdminTestWebhook sends a test delivery to a registered endpoint,
+// which checks it is signed with the webhook's secret and marked as a
+// test, and gets back what the endpoint answered.
+func TestAdminTestWebhook(t *testing.T) {
+	quietLog(t)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := (webhook.WebhookVerifier{}).Verify("s3cret", body, r.Header.Get(webhook.SignatureHeader)); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var ev webhookEvent
+		if err := json.Unmarshal(body, &ev); err != nil || ev.Type != "user.registered" || ev.Data["plan"] != "pro" {
+			http.Error(w, "unexpected payload "+string(body), http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("X-Webhook-Test") != "true" {
+			http.Error(w, "not marked as a test", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "verified")
+	}))
+	defer endpoint.Close()
+
+	s, _ := newTestServer(t)
+	fake := &fakeWebhookDB{url: endpoint.URL, secret: "s3cret"}
+	s.db = sql.OpenDB(fake)
+	t.Cleanup(func() { s.db.Close() })
+	h := s.Handler()
+	cookie := accessTokenCookie(t, s, newTestUser(t, s, "admin@example.com", "admin"))
+
+	rec := postJSON(h, "/v1/admin/webhooks/test",
+		`{"url":"`+endpoint.URL+`","event_type":"user.registered","payload":{"plan":"pro"}}`, cookie)
+	var result webhookTestResult
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &result) != nil {
+		t.Fatalf("test delivery: %d %s", rec.Code, rec.Body)
+	}
+	if result.StatusCode != http.StatusAccepted || result.Body != "verified" {
+		t.Errorf("endpoint answered %d %q, want 202 \"verified\"", result.StatusCode, result.Body)
+	}
+	if len(fake.deliveries) != 0 || len(fake.results) != 0 {
+		t.Error("a test delivery was recorded")
+	}
+
+	rec = postJSON(h, "/v1/admin/webhooks/test", `{"url":"https://elsewhere.example.com/hook","event_type":"user.registered"}`, cookie)
+	if env := readEnvelope(t, rec, http.StatusNotFound); env.Error.Code != "webhook_not_found" {
+		t.Errorf("unregistered URL: %q, want webhook_not_found", env.Error.Code)
+	}
+}